@@ -0,0 +1,244 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrUnknownEndpoint is returned by administrative operations on a service
+// or endpoint that is not registered.
+var ErrUnknownEndpoint = errors.New("unknown service or endpoint")
+
+// EndpointState is the administrative state of an endpoint.  It is set by
+// operators and is independent of the learned pheromone values.
+type EndpointState int
+
+const (
+	// StateActive endpoints take part in selection (the default).
+	StateActive EndpointState = iota
+	// StateDrained endpoints receive no new picks, but calls already in
+	// flight finish normally: their reports are learned and counted, and
+	// the drain is complete once Stats shows no call in flight.  Draining
+	// is meant to be temporary, e.g. around maintenance.
+	StateDrained
+	// StateBanned endpoints receive no picks until an operator restores
+	// them, and the ban also cuts off calls in flight: their count is
+	// cleared and their reports are ignored, so a bad endpoint's last
+	// answers don't feed back into its learned state.
+	StateBanned
+)
+
+// String returns the lower-case name used by the control plane.
+func (s EndpointState) String() string {
+	switch s {
+	case StateActive:
+		return "active"
+	case StateDrained:
+		return "drained"
+	case StateBanned:
+		return "banned"
+	default:
+		return fmt.Sprintf("EndpointState(%d)", int(s))
+	}
+}
+
+// ParseEndpointState parses the names returned by EndpointState.String.
+func ParseEndpointState(s string) (EndpointState, error) {
+	switch s {
+	case "active":
+		return StateActive, nil
+	case "drained":
+		return StateDrained, nil
+	case "banned":
+		return StateBanned, nil
+	}
+	return 0, fmt.Errorf("unknown endpoint state %q", s)
+}
+
+// without returns eps minus the endpoints in exclude, or eps itself if that
+// would leave none.
+func without(eps []*Endpoint, exclude []string) []*Endpoint {
+	out := make([]*Endpoint, 0, len(eps))
+	for _, ep := range eps {
+		if !slices.Contains(exclude, ep.Address) {
+			out = append(out, ep)
+		}
+	}
+	if len(out) == 0 {
+		return eps
+	}
+	return out
+}
+
+// selectable returns the endpoints that may be picked at now.  It returns
+// eps itself when every endpoint is available.
+func selectable(eps []*Endpoint, now time.Time) []*Endpoint {
+	for i, ep := range eps {
+		if ep.available(now) {
+			continue
+		}
+		out := append(make([]*Endpoint, 0, len(eps)-1), eps[:i]...)
+		for _, ep := range eps[i+1:] {
+			if ep.available(now) {
+				out = append(out, ep)
+			}
+		}
+		return out
+	}
+	return eps
+}
+
+// SetEndpointState sets the administrative state of an endpoint.  It
+// returns ErrUnknownEndpoint if the service or endpoint is not registered.
+func (sr *SwarmRoute) SetEndpointState(service, endpoint string, state EndpointState) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	ep := sr.findLocked(service, endpoint)
+	if ep == nil {
+		return ErrUnknownEndpoint
+	}
+	if state == StateBanned {
+		ep.inFlight = 0
+	}
+	ep.state = state
+	return nil
+}
+
+// EndpointStates returns the administrative state of every endpoint of a
+// service.
+func (sr *SwarmRoute) EndpointStates(service string) map[string]EndpointState {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	out := make(map[string]EndpointState, len(sr.services[service]))
+	for _, ep := range sr.services[service] {
+		out[ep.Address] = ep.state
+	}
+	return out
+}
+
+// AddEndpoint adds a single endpoint to a service, creating the service if
+// needed.  Adding an endpoint that already exists only refreshes its
+// discovery confirmation (see SetEndpointTTL).
+func (sr *SwarmRoute) AddEndpoint(service, endpoint string) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if ep := sr.findLocked(service, endpoint); ep != nil {
+		ep.confirmed = sr.now()
+		return
+	}
+	sr.services[service] = append(sr.services[service], newEndpoint(endpoint, sr.now()))
+}
+
+// RemoveEndpoint removes a single endpoint and its learned state.  It
+// returns ErrUnknownEndpoint if the endpoint is not registered.
+func (sr *SwarmRoute) RemoveEndpoint(service, endpoint string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	eps := sr.services[service]
+	for i, ep := range eps {
+		if ep.Address == endpoint {
+			sr.services[service] = append(eps[:i:i], eps[i+1:]...)
+			return nil
+		}
+	}
+	return ErrUnknownEndpoint
+}
+
+// RemoveService removes a service and all of its endpoints.
+func (sr *SwarmRoute) RemoveService(name string) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	delete(sr.services, name)
+	delete(sr.pickCount, name)
+}
+
+// SetEndpointTTL sets how long an endpoint may go without a report and
+// without being listed by discovery (SetEndpoints, AddEndpoint, AddService)
+// before it is dropped.  Expiry is checked by the background evaporation
+// loop, so endpoints that vanished from discovery without an explicit
+// removal don't linger forever.  A ttl <= 0 disables expiry (the default).
+func (sr *SwarmRoute) SetEndpointTTL(ttl time.Duration) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.endpointTTL = max(ttl, 0)
+}
+
+// ExpireStaleEndpoints drops endpoints whose TTL has elapsed right away and
+// returns how many were dropped.
+func (sr *SwarmRoute) ExpireStaleEndpoints() int {
+	sr.mu.Lock()
+	expired := sr.expireLocked()
+	obs := sr.observers
+	sr.mu.Unlock()
+	for _, ro := range obs {
+		for _, ev := range expired {
+			ro.o.ObserveExpiry(ev)
+		}
+	}
+	return len(expired)
+}
+
+// expireLocked drops endpoints past their service's TTL and returns an
+// event for each.  Callers must hold sr.mu.
+func (sr *SwarmRoute) expireLocked() []ExpiryEvent {
+	if sr.endpointTTL <= 0 && len(sr.overrides) == 0 {
+		return nil
+	}
+	now := sr.now()
+	var expired []ExpiryEvent
+	for svc, eps := range sr.services {
+		ttl := secondsToDuration(sr.paramsLocked(svc).EndpointTTLSec)
+		if ttl <= 0 {
+			continue
+		}
+		var kept []*Endpoint // allocated on the first expired endpoint
+		for i, ep := range eps {
+			last := ep.confirmed
+			if ep.lastSeen.After(last) {
+				last = ep.lastSeen
+			}
+			if now.Sub(last) >= ttl {
+				if kept == nil {
+					kept = append(make([]*Endpoint, 0, len(eps)), eps[:i]...)
+				}
+				expired = append(expired, ExpiryEvent{Service: svc, Endpoint: ep.Address, Idle: now.Sub(last)})
+				continue
+			}
+			if kept != nil {
+				kept = append(kept, ep)
+			}
+		}
+		if kept != nil {
+			sr.services[svc] = kept
+		}
+	}
+	return expired
+}
+
+// findLocked returns the endpoint or nil.  Callers must hold sr.mu.
+func (sr *SwarmRoute) findLocked(service, endpoint string) *Endpoint {
+	for _, ep := range sr.services[service] {
+		if ep.Address == endpoint {
+			return ep
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"math"
+	"time"
+)
+
+// Online self-tuning lets one configuration serve services with very
+// different dynamics.  Every endpoint's log latency and error rate feed a
+// two-sided Page-Hinkley change detector; when one fires, the service's
+// memory is shortened and its exploration made more frequent by a boost
+// that fades over the configured half-life.  Independently, services
+// with noisy latencies remember longer, since each sample says less.
+
+// AutoTunePolicy configures self-tuning; see SetAutoTune.  Zero fields
+// take the defaults of DefaultAutoTunePolicy.
+type AutoTunePolicy struct {
+	// DriftBoost multiplies the per-request evaporation rate, and divides
+	// the exploration interval, right after a change is detected.
+	DriftBoost float64
+	// Threshold is the Page-Hinkley threshold in standard deviations of
+	// the monitored signal; higher detects fewer, larger changes.
+	Threshold float64
+	// MinReqEvapRate and MaxReqEvapRate bound the tuned per-request
+	// evaporation rate.  MinReqEvapRate is also used for services
+	// configured without per-request evaporation.
+	MinReqEvapRate, MaxReqEvapRate float64
+	// MinExploreEveryN bounds the tuned exploration interval.  Services
+	// with exploration disabled keep it disabled.
+	MinExploreEveryN int
+}
+
+// DefaultAutoTunePolicy boosts by 8 on a change detected at 10 standard
+// deviations and keeps the half-life between ~50 and ~20000 requests and
+// exploration at most every 20 picks.
+func DefaultAutoTunePolicy() AutoTunePolicy {
+	return AutoTunePolicy{
+		DriftBoost:       8,
+		Threshold:        10,
+		MinReqEvapRate:   halfLifeRate(20000),
+		MaxReqEvapRate:   halfLifeRate(50),
+		MinExploreEveryN: 20,
+	}
+}
+
+// AutoTuneStatus is the self-tuning state of a service.
+type AutoTuneStatus struct {
+	// ReqEvapRate and ExploreEveryN are the values in effect.
+	ReqEvapRate   float64 `json:"req_evap_rate"`
+	ExploreEveryN int     `json:"explore_every_n"`
+	// Boost is the current drift boost, 1 when no change is recent.
+	Boost float64 `json:"boost"`
+	// LatencyCV is the mean standard deviation of the endpoints' log
+	// latencies, roughly their coefficient of variation.
+	LatencyCV float64 `json:"latency_cv"`
+	// Drifts counts detected changes; LastDrift is the time of the last.
+	Drifts    uint64    `json:"drifts"`
+	LastDrift time.Time `json:"last_drift"`
+}
+
+// Change detector constants: the weight of a sample in the signal's mean
+// and variance, the samples an endpoint needs before changes are
+// detected, the per-sample slack in standard deviations, and the
+// standard deviation floors of log latency and the error indicator.
+const (
+	driftAlpha       = 0.02
+	driftWarmup      = 30
+	driftSlack       = 0.5
+	latencyMinSD     = 0.05
+	errorIndicatorSD = 0.2
+)
+
+// autoTuner is the self-tuning state of a SwarmRoute.
+type autoTuner struct {
+	policy   AutoTunePolicy
+	services map[string]*tunedService
+}
+
+type tunedService struct {
+	boost     float64
+	cv        float64
+	drifts    uint64
+	lastDrift time.Time
+	endpoints map[string]*driftDetector
+}
+
+type driftDetector struct {
+	latency, errors pageHinkley
+}
+
+// pageHinkley tracks the mean and variance of a signal and detects
+// sustained shifts of either sign, in the cumulative-sum form.
+type pageHinkley struct {
+	n              int
+	mean, variance float64
+	up, down       float64
+}
+
+// add feeds x and returns the sign of a detected change, 0 if none; on a
+// change the detector restarts at the new level.
+func (d *pageHinkley) add(x, minSD, threshold float64) int {
+	d.n++
+	if d.n == 1 {
+		d.mean = x
+		return 0
+	}
+	z := (x - d.mean) / max(math.Sqrt(d.variance), minSD)
+	diff := x - d.mean
+	d.mean += driftAlpha * diff
+	d.variance = (1 - driftAlpha) * (d.variance + driftAlpha*diff*diff)
+	if d.n < driftWarmup {
+		return 0
+	}
+	d.up = max(0, d.up+z-driftSlack)
+	d.down = max(0, d.down-z-driftSlack)
+	sign := 0
+	switch {
+	case d.up > threshold:
+		sign = 1
+	case d.down > threshold:
+		sign = -1
+	default:
+		return 0
+	}
+	*d = pageHinkley{n: 1, mean: x, variance: d.variance}
+	return sign
+}
+
+// SetAutoTune enables online self-tuning with p, or disables it if p is
+// nil.  While enabled, the ReqEvapRate and ExploreEveryN of every service
+// (global or overridden) are adjusted as described above; Config and
+// ServiceConfigs still return the configured values, AutoTuneStatus the
+// tuned ones.  Enabling or disabling resets the detectors.
+func (sr *SwarmRoute) SetAutoTune(p *AutoTunePolicy) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if p == nil {
+		sr.autoTune = nil
+		return
+	}
+	policy := *p
+	def := DefaultAutoTunePolicy()
+	if policy.DriftBoost <= 0 {
+		policy.DriftBoost = def.DriftBoost
+	}
+	if policy.Threshold <= 0 {
+		policy.Threshold = def.Threshold
+	}
+	if policy.MinReqEvapRate <= 0 {
+		policy.MinReqEvapRate = def.MinReqEvapRate
+	}
+	if policy.MaxReqEvapRate <= 0 {
+		policy.MaxReqEvapRate = def.MaxReqEvapRate
+	}
+	if policy.MinExploreEveryN <= 0 {
+		policy.MinExploreEveryN = def.MinExploreEveryN
+	}
+	sr.autoTune = &autoTuner{policy: policy, services: make(map[string]*tunedService)}
+}
+
+// AutoTuneStatus returns the self-tuning state of every service that has
+// reported results, or nil if self-tuning is disabled.
+func (sr *SwarmRoute) AutoTuneStatus() map[string]AutoTuneStatus {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	if sr.autoTune == nil {
+		return nil
+	}
+	out := make(map[string]AutoTuneStatus, len(sr.autoTune.services))
+	for svc, ts := range sr.autoTune.services {
+		p := sr.paramsLocked(svc)
+		out[svc] = AutoTuneStatus{ReqEvapRate: p.ReqEvapRate, ExploreEveryN: p.ExploreEveryN, Boost: ts.boost,
+			LatencyCV: ts.cv, Drifts: ts.drifts, LastDrift: ts.lastDrift}
+	}
+	return out
+}
+
+// adjust applies the self-tuning of service to its configured tunables c.
+func (t *autoTuner) adjust(service string, c *Config) {
+	boost, cv := 1.0, 0.0
+	if ts, ok := t.services[service]; ok {
+		boost, cv = ts.boost, ts.cv
+	}
+	base := c.ReqEvapRate
+	if base <= 0 {
+		base = t.policy.MinReqEvapRate
+	}
+	rate := base / (1 + cv*cv) * boost
+	c.ReqEvapRate = min(max(rate, min(base, t.policy.MinReqEvapRate)), max(base, t.policy.MaxReqEvapRate))
+	if c.ExploreEveryN > 0 {
+		c.ExploreEveryN = max(int(float64(c.ExploreEveryN)/boost), min(c.ExploreEveryN, t.policy.MinExploreEveryN))
+	}
+}
+
+// observe feeds a report of endpoint to the detectors.  c is the
+// service's configured, untuned, tunables.
+func (t *autoTuner) observe(service, endpoint string, c Config, latency float64, success bool, now time.Time) {
+	ts, ok := t.services[service]
+	if !ok {
+		ts = &tunedService{boost: 1, endpoints: make(map[string]*driftDetector)}
+		t.services[service] = ts
+	}
+	// The boost fades at the untuned rate, i.e. over one half-life.
+	rate := c.ReqEvapRate
+	if rate <= 0 {
+		rate = t.policy.MinReqEvapRate
+	}
+	ts.boost = 1 + (ts.boost-1)*(1-rate)
+
+	d, ok := ts.endpoints[endpoint]
+	if !ok {
+		d = &driftDetector{}
+		ts.endpoints[endpoint] = d
+	}
+	indicator := 0.0
+	if !success {
+		indicator = 1
+	}
+	// Rising error rates are left to the error pheromone, which already
+	// reacts within a few failures; forgetting faster would only let the
+	// failing endpoint back sooner.  Recoveries and latency shifts, which
+	// stale pheromones hide, boost.
+	drift := d.errors.add(indicator, errorIndicatorSD, t.policy.Threshold) < 0
+	if success && d.latency.add(math.Log(latency+1e-6), latencyMinSD, t.policy.Threshold) != 0 {
+		drift = true
+	}
+	if drift {
+		ts.boost = t.policy.DriftBoost
+		ts.drifts++
+		ts.lastDrift = now
+	}
+
+	var sum float64
+	var n int
+	for _, d := range ts.endpoints {
+		if d.latency.n >= driftWarmup {
+			sum += math.Sqrt(d.latency.variance)
+			n++
+		}
+	}
+	if n > 0 {
+		ts.cv = sum / float64(n)
+	}
+}
+
+// clone returns a deep copy of t.
+func (t *autoTuner) clone() *autoTuner {
+	c := &autoTuner{policy: t.policy, services: make(map[string]*tunedService, len(t.services))}
+	for svc, ts := range t.services {
+		cs := *ts
+		cs.endpoints = make(map[string]*driftDetector, len(ts.endpoints))
+		for addr, d := range ts.endpoints {
+			cd := *d
+			cs.endpoints[addr] = &cd
+		}
+		c.services[svc] = &cs
+	}
+	return c
+}
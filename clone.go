@@ -0,0 +1,65 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import "slices"
+
+// Clone returns a deep copy of sr (services, endpoints, pheromones,
+// counters and configuration) for offline what-if analysis: the copy can
+// be re-tuned, fed synthetic reports and queried, e.g. with
+// PickEndpointExplained, without affecting sr.
+//
+// The clone has no observers and no background evaporation, so its
+// pheromones only change through explicit calls; it needs no cleanup.
+func (sr *SwarmRoute) Clone() *SwarmRoute {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	c := &SwarmRoute{
+		services:  make(map[string][]*Endpoint, len(sr.services)),
+		pickCount: make(map[string]int, len(sr.pickCount)),
+		now:       sr.now,
+		failover:  sr.failover, // Order is never mutated in place
+	}
+	c.setConfigLocked(sr.configLocked())
+	for svc, eps := range sr.services {
+		cps := make([]*Endpoint, len(eps))
+		for i, ep := range eps {
+			cp := *ep
+			cp.Pheromones = make(map[string]*Pheromone, len(ep.Pheromones))
+			for ch, p := range ep.Pheromones {
+				v := *p
+				cp.Pheromones[ch] = &v
+			}
+			cp.latency.window = slices.Clone(ep.latency.window)
+			cps[i] = &cp
+		}
+		c.services[svc] = cps
+	}
+	for svc, o := range sr.overrides {
+		if c.overrides == nil {
+			c.overrides = make(map[string]Config, len(sr.overrides))
+		}
+		c.overrides[svc] = o
+	}
+	for svc, n := range sr.pickCount {
+		c.pickCount[svc] = n
+	}
+	if sr.autoTune != nil {
+		c.autoTune = sr.autoTune.clone()
+	}
+	return c
+}
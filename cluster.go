@@ -0,0 +1,172 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Multi-cluster services are registered as one service per cluster, named
+// "name@cluster" (see ClusterService), so every cluster keeps its own
+// endpoint pool and pheromones.  PickCluster adds cluster failover on top:
+// it prefers the local cluster and spills over to others while the local
+// pool is unhealthy.
+
+// ClusterService returns the service name of a cluster's pool, "name@cluster".
+func ClusterService(name, cluster string) string {
+	return name + "@" + cluster
+}
+
+// ParseClusterService splits "name@cluster".  cluster is empty for names
+// without a cluster suffix.
+func ParseClusterService(service string) (name, cluster string) {
+	if i := strings.LastIndexByte(service, '@'); i >= 0 {
+		return service[:i], service[i+1:]
+	}
+	return service, ""
+}
+
+// FailoverPolicy configures PickCluster.
+type FailoverPolicy struct {
+	// Local is the caller's own cluster, preferred while healthy.
+	Local string
+	// Order lists the clusters to spill over to, most preferred first.
+	// Clusters not listed are tried afterwards, healthiest first.
+	Order []string
+	// MinHealth is the health score (see ClusterHealth) below which a
+	// cluster is skipped.  A cluster without selectable endpoints is always
+	// skipped.
+	MinHealth float64
+}
+
+// SetFailoverPolicy sets the policy used by PickCluster.
+func (sr *SwarmRoute) SetFailoverPolicy(p FailoverPolicy) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	p.Order = slices.Clone(p.Order)
+	sr.failover = p
+}
+
+// ClusterHealth returns the health score of every cluster pool of name: the
+// fraction of the pool's endpoints that are active and not ejected.
+func (sr *SwarmRoute) ClusterHealth(name string) map[string]float64 {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	health, _ := sr.clusterHealthLocked(name)
+	return health
+}
+
+// clusterHealthLocked returns the health score of every cluster pool of
+// name and whether the pool has any selectable endpoint, ejected or not.
+// Callers must hold sr.mu.
+func (sr *SwarmRoute) clusterHealthLocked(name string) (health map[string]float64, selectable map[string]bool) {
+	health, selectable = make(map[string]float64), make(map[string]bool)
+	now := sr.now()
+	for svc, eps := range sr.services {
+		n, cluster := ParseClusterService(svc)
+		if n != name || cluster == "" {
+			continue
+		}
+		healthy := 0
+		for _, ep := range eps {
+			if !ep.available(now) {
+				continue
+			}
+			selectable[cluster] = true
+			if !ep.ejected {
+				healthy++
+			}
+		}
+		health[cluster] = 0
+		if len(eps) > 0 {
+			health[cluster] = float64(healthy) / float64(len(eps))
+		}
+	}
+	return health, selectable
+}
+
+// PickCluster selects a cluster of the multi-cluster service name according
+// to the failover policy and an endpoint within it.  The local cluster is
+// used while its health is at least MinHealth; otherwise the first healthy
+// cluster in the policy order wins.  If no cluster meets the threshold, the
+// healthiest one with a selectable endpoint is used, even if all of them
+// are ejected.  Report the outcome with the returned service:
+//
+//	svc, ep, err := sr.PickCluster("search")
+//	...
+//	sr.ReportResult(svc, ep, latency, ok)
+func (sr *SwarmRoute) PickCluster(name string) (service, endpoint string, err error) {
+	sr.mu.RLock()
+	health, selectable := sr.clusterHealthLocked(name)
+	p := sr.failover
+	sr.mu.RUnlock()
+	if len(health) == 0 {
+		return "", "", fmt.Errorf("no clusters for service %s", name)
+	}
+
+	order := make([]string, 0, len(health))
+	if _, ok := health[p.Local]; ok {
+		order = append(order, p.Local)
+	}
+	for _, c := range p.Order {
+		if _, ok := health[c]; ok && !slices.Contains(order, c) {
+			order = append(order, c)
+		}
+	}
+	rest := make([]string, 0, len(health))
+	for c := range health {
+		if !slices.Contains(order, c) {
+			rest = append(rest, c)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		if health[rest[i]] != health[rest[j]] {
+			return health[rest[i]] > health[rest[j]]
+		}
+		return rest[i] < rest[j]
+	})
+	order = append(order, rest...)
+
+	// A cluster without selectable endpoints is skipped, in the fallback
+	// too: PickEndpoint would fail on it.
+	order = slices.DeleteFunc(order, func(c string) bool { return !selectable[c] })
+	if len(order) == 0 {
+		return "", "", fmt.Errorf("no selectable endpoints in any cluster of service %s", name)
+	}
+	chosen := ""
+	for _, c := range order {
+		if h := health[c]; h > 0 && h >= p.MinHealth {
+			chosen = c
+			break
+		}
+	}
+	if chosen == "" {
+		// Nothing meets the threshold: take the healthiest, preferring the
+		// policy order among equals.
+		for _, c := range order {
+			if chosen == "" || health[c] > health[chosen] {
+				chosen = c
+			}
+		}
+	}
+	service = ClusterService(name, chosen)
+	endpoint, err = sr.PickEndpoint(service)
+	return service, endpoint, err
+}
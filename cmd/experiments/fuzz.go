@@ -0,0 +1,85 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"swarmroute/harness"
+)
+
+// runFuzz runs the -strategies on n random scenarios and prints the ones
+// where SwarmRoute trails the best other strategy by the most.
+func runFuzz(n int) error {
+	fz := harness.Fuzz{Seed: *fuzzSeed, Count: n, Strategies: specs, Seeds: seeds, Objective: score,
+		OnDone: func(done, total int) { fmt.Fprintf(os.Stderr, "\rfuzz: %d/%d scenarios", done, total) }}
+	cases, err := fz.Run()
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "text":
+		fmt.Printf("seeds=%v fuzz-seed=%d\nworst of %d scenarios for SwarmRoute (margin = best other score - SwarmRoute score):\n", seeds, *fuzzSeed, n)
+		for _, c := range cases[:min(10, len(cases))] {
+			fmt.Printf("  seed=%d margin=%.3f vs %s: %s\n", c.Seed, c.Margin, c.Best, strings.Join(c.Patterns, "; "))
+			for _, a := range c.Aggregations {
+				fmt.Printf("    %-20s success=%.2f%% p95=%.1fms bad-share=%.2f%%\n", a.Strategy, a.MeanSuccessPct, a.MeanP95ms, a.MeanBadShare)
+			}
+		}
+		behind := 0
+		for _, c := range cases {
+			if c.Margin > 0 {
+				behind++
+			}
+		}
+		fmt.Printf("SwarmRoute trailed in %d of %d scenarios\n", behind, n)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"seed", "margin", "target_score", "best", "best_score", "patterns"})
+		for _, c := range cases {
+			w.Write([]string{strconv.FormatInt(c.Seed, 10), strconv.FormatFloat(c.Margin, 'f', -1, 64),
+				strconv.FormatFloat(c.TargetScore, 'f', -1, 64), c.Best, strconv.FormatFloat(c.BestScore, 'f', -1, 64),
+				strings.Join(c.Patterns, "; ")})
+		}
+		w.Flush()
+		return w.Error()
+	case "json":
+		type caseJSON struct {
+			Seed        int64            `json:"seed"`
+			Scenario    harness.Scenario `json:"scenario"`
+			Patterns    []string         `json:"patterns"`
+			TargetScore float64          `json:"target_score"`
+			Best        string           `json:"best"`
+			BestScore   float64          `json:"best_score"`
+			Margin      float64          `json:"margin"`
+		}
+		out := make([]caseJSON, len(cases))
+		for i, c := range cases {
+			out[i] = caseJSON{c.Seed, c.Scenario, c.Patterns, c.TargetScore, c.Best, c.BestScore, c.Margin}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	return nil
+}
@@ -17,48 +17,361 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
 	"swarmroute/harness"
+	"swarmroute/harness/plot"
 )
 
+var (
+	perSeed       = flag.Bool("per-seed", false, "print the per-seed rows behind every aggregate")
+	format        = flag.String("format", "text", "output format: text, csv or json")
+	charts        = flag.String("charts", "", "write SVG charts of selection share and rolling p95 (first seed) to this directory")
+	golden        = flag.String("golden", "", "compare the aggregated metrics against this baseline file and fail on regressions")
+	update        = flag.Bool("update-golden", false, "write the aggregated metrics to the -golden file instead of comparing")
+	tolBad        = flag.Float64("tol-bad-share", harness.DefaultTolerances.BadSharePts, "allowed bad-window share increase in percentage points")
+	tolP95        = flag.Float64("tol-p95", harness.DefaultTolerances.P95Pct, "allowed p95 increase in percent")
+	serveAddr     = flag.String("serve", "", "once done, serve a dashboard of the runs' charts on this address, e.g. localhost:8080")
+	strategySpecs = flag.String("strategies", harness.DefaultStrategies, "strategies to compare, as space-separated name[:key=value,...] specs; registered: "+strings.Join(harness.RegisteredStrategies(), ", "))
+	sweep         = flag.String("sweep", "", "instead of comparing strategies, grid-search SwarmRoute parameters given as space-separated name=value|value|... entries (swarmroute.Config JSON names, e.g. \"req_evap_rate=0.0005|0.001 base_weight=0.01|0.05\") and print the Pareto-best configurations")
+	tune          = flag.String("tune", "", "instead of comparing strategies, search SwarmRoute parameters given as space-separated name=min:max[:log] ranges for the best -objective score within -budget configurations")
+	budget        = flag.Int("budget", 40, "configurations to evaluate with -tune")
+	tpe           = flag.Bool("tpe", false, "with -tune, propose configurations with a TPE (Bayesian) estimator after the first quarter of the budget instead of at random")
+	objective     = flag.String("objective", "success_pct=1 p95_ms=-0.1 bad_share=-0.5", "the score that ranks strategies and sweep configurations, -tune maximizes and -fuzz compares by, as space-separated metric=weight entries over success_pct, p95_ms and bad_share")
+	scoreBase     = flag.String("score-baseline", "", "also report every strategy's -objective score as the difference from this strategy's, e.g. RoundRobin")
+	presetOut     = flag.String("preset-out", "", "with -tune, write the best configuration to this file as a swarmroute.LoadConfig preset")
+	fuzz          = flag.Int("fuzz", 0, "instead of the fixed scenarios, run -strategies on this many random scenarios and print those where SwarmRoute trails the best other strategy by the most -objective score")
+	fuzzSeed      = flag.Int64("fuzz-seed", 1, "seed of the random scenarios drawn by -fuzz")
+	tui           = flag.Bool("tui", false, "draw live progress, rolling success/p95 and selection shares on stderr (redirect stdout to keep the results)")
+)
+
+// specs are the strategies of -strategies.
+var specs []harness.StrategySpec
+
+// score is the -objective.
+var score harness.Objective
+
+var seeds = []int64{1, 2, 3, 42, 123456, 987654321}
+
+// out collects every section as one CSV table with -format=csv, and
+// reports as one JSON array with -format=json.
+var (
+	out     *harness.CSVWriter
+	reports []harness.Report
+)
+
+// dash is the live dashboard with -tui.
+var dash *dashboard
+
+// suite collects the aggregations of every scenario for -golden.
+var suite []harness.MultiSeedAggregation
+
 func main() {
-	seeds := []int64{1, 2, 3, 42, 123456, 987654321}
-	fmt.Printf("seeds=%v\n", seeds)
+	var variants []harness.StrategySpec
+	flag.Func("swarmroute", "add a SwarmRoute configuration named SwarmRoute-label to the strategies, as label[:key=value,...] with a preset, config file, autotune or swarmroute.Config JSON names, e.g. tight:preset=aggressive,base_weight=0.05; repeatable", func(s string) error {
+		spec, err := harness.ParseSwarmRouteVariant(s)
+		variants = append(variants, spec)
+		return err
+	})
+	flag.Parse()
+	var err error
+	if specs, err = harness.ParseStrategySpecs(*strategySpecs); err == nil {
+		specs = append(specs, variants...)
+		for _, spec := range specs {
+			if _, err = spec.New(); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		score, err = harness.ParseObjective(*objective)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *sweep != "" {
+		if err := runSweep(*sweep); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *tune != "" {
+		if err := runTune(*tune); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *fuzz > 0 {
+		if err := runFuzz(*fuzz); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *tui {
+		dash = newDashboard(os.Stderr)
+	}
+	switch *format {
+	case "text":
+		fmt.Printf("seeds=%v\n", seeds)
+	case "csv":
+		out = harness.NewCSVWriter(os.Stdout)
+	case "json":
+		reports = []harness.Report{}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown -format %q\n", *format)
+		os.Exit(2)
+	}
 
 	// Base 3-endpoint scenario with degrade window 2000-6000 on b
 	base := baseScenario()
 	strategies := createStrategies()
-	fmt.Println("\n=== Base scenario (3 endpoints, degrade b at 2000, recover at 6000) ===")
-	aggs := harness.AggregateMultiSeed(base, strategies, seeds)
-	fmt.Print(harness.FormatAggregatedResults(aggs))
+	section("=== Base scenario (3 endpoints, degrade b at 2000, recover at 6000) ===")
+	aggs := aggregate(base, strategies)
+	printAggregates(base, aggs)
 
-	// Harder scenario A: 10 endpoints, degrade two at different times (one at 2000 so bad-window share applies)
-	fmt.Println("\n=== Harder A: 10 endpoints; degrade e3 at 2000 and e7 at 3500; recover later ===")
+	// Harder scenario A: 10 endpoints, degrade two at different times; both count towards bad-window share
+	section("=== Harder A: 10 endpoints; degrade e3 at 2000 and e7 at 3500; recover later ===")
 	many := manyEndpointsScenario()
-	aggs = harness.AggregateMultiSeed(many, strategies, seeds)
-	fmt.Print(harness.FormatAggregatedResults(aggs))
+	aggs = aggregate(many, strategies)
+	printAggregates(many, aggs)
 
 	// Harder scenario B: Drift on b from 35ms->120ms between 2000..4000; drift back 6000..8000
-	fmt.Println("\n=== Harder B: Drift (b ramps latency 35->120ms from 2000..4000, then recovers 6000..8000) ===")
+	section("=== Harder B: Drift (b ramps latency 35->120ms from 2000..4000, then recovers 6000..8000) ===")
 	drift := driftScenario()
-	aggs = harness.AggregateMultiSeed(drift, strategies, seeds)
-	fmt.Print(harness.FormatAggregatedResults(aggs))
+	aggs = aggregate(drift, strategies)
+	printAggregates(drift, aggs)
 
-	// Harder scenario C: Flaky-but-fast endpoint; we mark it with an event at 2000 to keep bad-window metric meaningful
-	fmt.Println("\n=== Harder C: Flaky-but-fast (one very fast endpoint with ~35% error) ===")
+	// Harder scenario C: Flaky-but-fast endpoint that turns very flaky between 2000 and 6000
+	section("=== Harder C: Flaky-but-fast (one very fast endpoint with ~35% error) ===")
 	flaky := flakyFastScenario()
-	aggs = harness.AggregateMultiSeed(flaky, strategies, seeds)
+	aggs = aggregate(flaky, strategies)
+	printAggregates(flaky, aggs)
+
+	// Harder scenario D: Correlated outage of a whole zone plus a single hard-down endpoint
+	section("=== Harder D: Correlated outage (zone a down 2000..4000; c1 refuses connections 6000..8000) ===")
+	outage := correlatedOutageScenario()
+	aggs = aggregate(outage, strategies)
+	printAggregates(outage, aggs)
+
+	// Harder scenario E: Same topology, but zone a is partitioned (requests hang until a 1s timeout)
+	section("=== Harder E: Partition (zone a blackholed 2000..4000, 1s client timeout) ===")
+	partition := partitionScenario()
+	aggs = aggregate(partition, strategies)
+	printAggregates(partition, aggs)
+
+	// Harder scenario F: the base scenario under open-loop Poisson load with a client concurrency limit
+	section("=== Harder F: Open loop (base scenario at 150 RPS Poisson, concurrency 8) ===")
+	open := base
+	open.Name = "open-loop"
+	open.Load = &harness.Load{RPS: 150, Poisson: true, Concurrency: 8}
+	aggs = aggregate(open, strategies)
+	printAggregates(open, aggs)
+
+	// Harder scenario G: retry storm; one endpoint slows down and client retries overload the rest
+	section("=== Harder G: Retry storm (s1 slows 20->80ms 6000..12000, 300 RPS, 100ms timeout, 3 retries) ===")
+	storm := harness.RetryStormScenario(0)
+	aggs = aggregate(storm, strategies)
+	printAggregates(storm, aggs)
+
+	// Harder scenario H: GC pauses; a, the fastest endpoint, spikes briefly but is otherwise healthy
+	section("=== Harder H: GC pauses (a spikes 30->300ms for 20 steps every 500, no sustained degradation) ===")
+	pauses := gcPauseScenario()
+	aggs = aggregate(pauses, strategies)
+	printAggregates(pauses, aggs)
+
+	// Population I: many independent clients of one strategy share capacity-limited endpoints
+	section("=== Population I: 20 clients per strategy, 4 endpoints (20..35ms, capacity 8) at 600 RPS Poisson ===")
+	pop := live(populationScenario(), len(strategyFactories()))
+	for _, newStrategy := range strategyFactories() {
+		r := harness.RunPopulation(pop, harness.Clients(20, newStrategy))
+		printResults(pop, r.Groups)
+	}
+
+	// Rollout J: SwarmRoute on a growing share of 20 clients, the rest on RoundRobin
+	section("=== Rollout J: base scenario, 20 clients, SwarmRoute share 10%..100% (rest RoundRobin) ===")
+	shares := []float64{0.1, 0.3, 0.7, 1}
+	liveBase := live(base, len(shares))
+	for _, share := range shares {
+		clients := harness.MixedClients(20,
+			harness.ClientGroup{Share: 1 - share, New: func(int) harness.Strategy { return harness.NewRoundRobinStrategy() }},
+			harness.ClientGroup{Share: share, New: func(int) harness.Strategy { return harness.NewSwarmRouteAdapter() }},
+		)
+		rollout := liveBase
+		rollout.Name = fmt.Sprintf("rollout-%.0f%%", 100*share)
+		r := harness.RunPopulation(rollout, clients)
+		if *format != "text" {
+			printResults(rollout, r.Groups)
+			continue
+		}
+		for _, g := range r.Groups {
+			fmt.Printf("SwarmRoute %3.0f%%, %s clients: success=%.2f%%, p95=%.2fms, bad-window share=%.2f%%\n",
+				100*share, g.Strategy, 100*float64(g.Success)/float64(g.Total), g.P95LatMS, 100*g.BadWindowDegradedShare)
+		}
+	}
+	if dash != nil {
+		dash.draw()
+	}
+	switch *format {
+	case "csv":
+		check(out.Flush())
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		check(enc.Encode(reports))
+	}
+	if *golden != "" {
+		compareGolden()
+	}
+	if *serveAddr != "" {
+		check(serve(*serveAddr))
+	}
+}
+
+// compareGolden writes the suite's baseline with -update-golden, and
+// otherwise compares the run against it and exits non-zero on regressions.
+func compareGolden() {
+	run := harness.NewGolden("experiments", seeds, suite)
+	if *update {
+		check(run.WriteFile(*golden))
+		fmt.Fprintf(os.Stderr, "golden baseline written to %s\n", *golden)
+		return
+	}
+	base, err := harness.ReadGolden(*golden)
+	check(err)
+	tol := harness.DefaultTolerances
+	tol.BadSharePts, tol.P95Pct = *tolBad, *tolP95
+	regs, err := base.Compare(run, tol)
+	check(err)
+	if len(regs) == 0 {
+		fmt.Fprintf(os.Stderr, "golden baseline %s: no regressions\n", *golden)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "REGRESSION: %d metrics worse than the golden baseline %s:\n", len(regs), *golden)
+	for _, r := range regs {
+		fmt.Fprintln(os.Stderr, "  "+r.String())
+	}
+	os.Exit(1)
+}
+
+// section prints the title of an experiment in text mode, and starts it on
+// the dashboard.
+func section(title string) {
+	if dash != nil {
+		dash.section(title)
+	}
+	if *format == "text" {
+		fmt.Println("\n" + title)
+	}
+}
+
+// aggregate runs sc with strategies over all seeds.
+func aggregate(sc harness.Scenario, strategies []harness.Strategy) []harness.MultiSeedAggregation {
+	return harness.AggregateMultiSeed(live(sc, len(seeds)*len(strategies)), strategies, seeds)
+}
+
+// live returns sc followed by the dashboard for the given number of runs
+// with -tui, and sc itself otherwise.
+func live(sc harness.Scenario, runs int) harness.Scenario {
+	if dash == nil {
+		return sc
+	}
+	return dash.track(sc, runs)
+}
+
+// printResults prints the results of a single run of sc.
+func printResults(sc harness.Scenario, results []harness.Results) {
+	writeCharts(results)
+	keep(sc, results, false)
+	switch *format {
+	case "csv":
+		check(out.Results(results))
+	case "json":
+		reports = append(reports, harness.Report{Scenario: sc, Seeds: []int64{sc.Seed}, Results: results})
+	default:
+		fmt.Print(harness.FormatResults(results))
+	}
+}
+
+// writeCharts writes the charts of results with -charts.
+func writeCharts(results []harness.Results) {
+	if *charts != "" {
+		_, err := plot.WriteCharts(*charts, results)
+		check(err)
+	}
+}
+
+// check exits on a failure to write the output.
+func check(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// printAggregates prints the aggregations of sc, the rows behind them
+// with -per-seed, and the strategies' ranks.
+func printAggregates(sc harness.Scenario, aggs []harness.MultiSeedAggregation) {
+	var first []harness.Results
+	for _, a := range aggs {
+		if len(a.Runs) > 0 {
+			first = append(first, a.Runs[0])
+		}
+	}
+	writeCharts(first)
+	keep(sc, first, true)
+	suite = append(suite, aggs...)
+	switch *format {
+	case "csv":
+		check(out.Aggregations(aggs))
+		check(out.Scores(aggs, score, *scoreBase))
+		return
+	case "json":
+		scores := make(map[string]float64, len(aggs))
+		for _, a := range aggs {
+			scores[a.Strategy] = score.ScoreAggregation(a)
+		}
+		reports = append(reports, harness.Report{Scenario: sc, Seeds: seeds, Aggregations: aggs, Scores: scores})
+		return
+	}
 	fmt.Print(harness.FormatAggregatedResults(aggs))
+	if *perSeed {
+		fmt.Print(harness.FormatPerSeedResults(aggs))
+	}
+	fmt.Print("\n" + harness.FormatRankTable(aggs))
+	fmt.Print("\n" + harness.FormatScoreTable(aggs, score, *scoreBase))
 }
 
+// strategyFactories mirrors createStrategies for populations; the
+// simulator seeds each client differently.
+func strategyFactories() []func(i int) harness.Strategy {
+	var out []func(i int) harness.Strategy
+	for _, spec := range specs {
+		out = append(out, func(int) harness.Strategy { return newStrategy(spec) })
+	}
+	return out
+}
+
+// createStrategies returns new strategies of -strategies.
 func createStrategies() []harness.Strategy {
-	return []harness.Strategy{
-		harness.NewRandomStrategy(1),
-		harness.NewRoundRobinStrategy(),
-		harness.NewPowerOfTwoChoicesStrategy(2, 0.2),
-		harness.NewLeastLatencyStrategy(3, 0.2),
-		harness.NewSwarmRouteAdapter(),
+	var out []harness.Strategy
+	for _, spec := range specs {
+		out = append(out, newStrategy(spec))
 	}
+	return out
+}
+
+// newStrategy returns a new strategy of spec, which main validated.
+func newStrategy(spec harness.StrategySpec) harness.Strategy {
+	s, err := spec.New()
+	check(err)
+	return s
 }
 
 func baseScenario() harness.Scenario {
@@ -71,6 +384,7 @@ func baseScenario() harness.Scenario {
 	normLat := e2.MeanLatencySec
 	normErr := e2.ErrorRate
 	return harness.Scenario{
+		Name:          "base",
 		Service:       svc,
 		Endpoints:     []harness.EndpointSpec{e1, e2, e3},
 		Events:        []harness.EnvironmentEvent{{Step: 2000, Endpoint: e2.Addr, NewMeanLatency: &slowLat, NewErrorRate: &highErr}, {Step: 6000, Endpoint: e2.Addr, NewMeanLatency: &normLat, NewErrorRate: &normErr}},
@@ -78,6 +392,31 @@ func baseScenario() harness.Scenario {
 	}
 }
 
+func gcPauseScenario() harness.Scenario {
+	sc := baseScenario()
+	sc.Name = "gc-pauses"
+	sc.Events = nil
+	sc.Generators = []harness.EventGenerator{
+		harness.Spikes{Endpoint: sc.Endpoints[0].Addr, BaseSec: sc.Endpoints[0].MeanLatencySec, SpikeSec: 0.300, Every: 500, Length: 20, StartStep: 500},
+	}
+	return sc
+}
+
+func populationScenario() harness.Scenario {
+	eps := make([]harness.EndpointSpec, 0, 4)
+	for i, lat := range []float64{0.020, 0.025, 0.030, 0.035} {
+		eps = append(eps, harness.EndpointSpec{Addr: fmt.Sprintf("http://p%d:8080", i+1), MeanLatencySec: lat, JitterSec: 0.2 * lat, ErrorRate: 0.005, Capacity: 8})
+	}
+	return harness.Scenario{
+		Name:          "population",
+		Service:       "api",
+		Endpoints:     eps,
+		TotalRequests: 20000,
+		Seed:          1,
+		Load:          &harness.Load{RPS: 600, Poisson: true},
+	}
+}
+
 func manyEndpointsScenario() harness.Scenario {
 	svc := "api"
 	eps := make([]harness.EndpointSpec, 0, 10)
@@ -102,7 +441,7 @@ func manyEndpointsScenario() harness.Scenario {
 		{Step: 7000, Endpoint: eps[2].Addr, NewMeanLatency: &norm3, NewErrorRate: &normErr},
 		{Step: 8000, Endpoint: eps[6].Addr, NewMeanLatency: &norm7, NewErrorRate: &normErr},
 	}
-	return harness.Scenario{Service: svc, Endpoints: eps, Events: events, TotalRequests: 12000}
+	return harness.Scenario{Name: "many-endpoints", Service: svc, Endpoints: eps, Events: events, TotalRequests: 12000}
 }
 
 func driftScenario() harness.Scenario {
@@ -110,28 +449,19 @@ func driftScenario() harness.Scenario {
 	a := harness.EndpointSpec{Addr: "http://a:8080", MeanLatencySec: 0.030, JitterSec: 0.009, ErrorRate: 0.01}
 	b := harness.EndpointSpec{Addr: "http://b:8080", MeanLatencySec: 0.035, JitterSec: 0.0105, ErrorRate: 0.01}
 	c := harness.EndpointSpec{Addr: "http://c:8080", MeanLatencySec: 0.040, JitterSec: 0.012, ErrorRate: 0.02}
-	// Create incremental latency increases for b from step 2000 to 4000, then decreases 6000 to 8000
-	events := []harness.EnvironmentEvent{}
-	// Ramp up 35ms -> 120ms over 10 steps
-	for i := 0; i <= 10; i++ {
-		step := 2000 + i*200
-		val := 0.035 + (0.120-0.035)*(float64(i)/10.0)
-		v := val
-		events = append(events, harness.EnvironmentEvent{Step: step, Endpoint: b.Addr, NewMeanLatency: &v})
-	}
-	// Increase error slightly during ramp
+	// Ramp b's latency 35ms -> 120ms over 2000..4000 and back over 6000..8000,
+	// raising its error rate in between.
 	hiErr := 0.20
-	events = append(events, harness.EnvironmentEvent{Step: 3000, Endpoint: b.Addr, NewErrorRate: &hiErr})
-	// Ramp down 0.120 -> 0.035 from 6000 to 8000
-	for i := 0; i <= 10; i++ {
-		step := 6000 + i*200
-		val := 0.120 - (0.120-0.035)*(float64(i)/10.0)
-		v := val
-		events = append(events, harness.EnvironmentEvent{Step: step, Endpoint: b.Addr, NewMeanLatency: &v})
-	}
 	normErr := b.ErrorRate
-	events = append(events, harness.EnvironmentEvent{Step: 8000, Endpoint: b.Addr, NewErrorRate: &normErr})
-	return harness.Scenario{Service: svc, Endpoints: []harness.EndpointSpec{a, b, c}, Events: events, TotalRequests: 10000}
+	events := []harness.EnvironmentEvent{
+		{Step: 3000, Endpoint: b.Addr, NewErrorRate: &hiErr},
+		{Step: 8000, Endpoint: b.Addr, NewErrorRate: &normErr},
+	}
+	generators := []harness.EventGenerator{
+		harness.Ramp{Endpoint: b.Addr, Param: harness.ParamLatency, From: 0.035, To: 0.120, StartStep: 2000, EndStep: 4000, Every: 200},
+		harness.Ramp{Endpoint: b.Addr, Param: harness.ParamLatency, From: 0.120, To: 0.035, StartStep: 6000, EndStep: 8000, Every: 200},
+	}
+	return harness.Scenario{Name: "drift", Service: svc, Endpoints: []harness.EndpointSpec{a, b, c}, Events: events, Generators: generators, TotalRequests: 10000, Phases: []int{2000, 6000}}
 }
 
 func flakyFastScenario() harness.Scenario {
@@ -145,5 +475,33 @@ func flakyFastScenario() harness.Scenario {
 	// Recover at 6000
 	normErr := fast.ErrorRate
 	events = append(events, harness.EnvironmentEvent{Step: 6000, Endpoint: fast.Addr, NewErrorRate: &normErr})
-	return harness.Scenario{Service: svc, Endpoints: []harness.EndpointSpec{fast, med, slow}, Events: events, TotalRequests: 10000}
+	return harness.Scenario{Name: "flaky-fast", Service: svc, Endpoints: []harness.EndpointSpec{fast, med, slow}, Events: events, TotalRequests: 10000}
+}
+
+func correlatedOutageScenario() harness.Scenario {
+	svc := "api"
+	eps := make([]harness.EndpointSpec, 0, 6)
+	for _, zone := range []string{"a", "b", "c"} {
+		for i := 1; i <= 2; i++ {
+			addr := fmt.Sprintf("http://%s%d:8080", zone, i)
+			eps = append(eps, harness.EndpointSpec{Addr: addr, MeanLatencySec: 0.030, JitterSec: 0.009, ErrorRate: 0.01})
+		}
+	}
+	// Zone a shares a failing dependency; c1 later goes hard down on its own.
+	generators := []harness.EventGenerator{
+		harness.Outage{Endpoints: []string{eps[0].Addr, eps[1].Addr}, StartStep: 2000, EndStep: 4000},
+		harness.Outage{Endpoints: []string{eps[4].Addr}, StartStep: 6000, EndStep: 8000},
+	}
+	return harness.Scenario{Name: "correlated-outage", Service: svc, Endpoints: eps, Generators: generators, TotalRequests: 10000, Phases: []int{2000, 4000, 6000, 8000}}
+}
+
+func partitionScenario() harness.Scenario {
+	sc := correlatedOutageScenario()
+	sc.Name = "partition"
+	sc.Generators = []harness.EventGenerator{
+		harness.Partition{Endpoints: []string{sc.Endpoints[0].Addr, sc.Endpoints[1].Addr}, StartStep: 2000, EndStep: 4000},
+	}
+	sc.TimeoutSec = 1.0
+	sc.Phases = []int{2000, 4000}
+	return sc
 }
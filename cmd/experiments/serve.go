@@ -0,0 +1,193 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"swarmroute/harness"
+	"swarmroute/harness/plot"
+)
+
+// pheromoneEvery is the step interval of the pheromone traces.
+const pheromoneEvery = 10
+
+// servedRun is a completed run shown by the -serve dashboard.  Pheromones
+// are traced for the SwarmRoute runs of aggregated scenarios.
+type servedRun struct {
+	Results    harness.Results
+	Pheromones []harness.PheromoneSample
+}
+
+// served are the runs of the -serve dashboard, in the order they ran.
+var served []servedRun
+
+// keep records results of sc for the dashboard with -serve.  With trace,
+// SwarmRoute runs are repeated with a new adapter whose pheromones are
+// sampled as it goes.
+func keep(sc harness.Scenario, results []harness.Results, trace bool) {
+	if *serveAddr == "" {
+		return
+	}
+	for _, r := range results {
+		run := servedRun{Results: r}
+		if trace && r.Strategy == "SwarmRoute" {
+			adapter := harness.NewSwarmRouteAdapter()
+			sc.Seed = r.Seed
+			sc.Hooks = &harness.Hooks{OnStep: func(step int) {
+				if step%pheromoneEvery == 0 {
+					run.Pheromones = append(run.Pheromones, adapter.SamplePheromones(step, sc.Service))
+				}
+			}}
+			run.Results = harness.RunScenario(sc, adapter)
+		}
+		served = append(served, run)
+	}
+}
+
+// serve serves the dashboard of the served runs on addr until it fails:
+//
+//	GET /                      scenario, strategy and chart pickers
+//	GET /chart/{run}/{kind}    SVG chart of a run: share, p95, latency, pos or neg
+func serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = dashboardPage.Execute(w, dashboardView())
+	})
+	mux.HandleFunc("GET /chart/{run}/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		i, err := strconv.Atoi(r.PathValue("run"))
+		if err != nil || i < 0 || i >= len(served) {
+			http.NotFound(w, r)
+			return
+		}
+		run := served[i]
+		var render func(io.Writer) error
+		switch kind := r.PathValue("kind"); kind {
+		case "share":
+			render = func(w io.Writer) error { return plot.SelectionShare(w, run.Results) }
+		case "p95":
+			render = func(w io.Writer) error { return plot.RollingP95(w, run.Results) }
+		case "latency":
+			render = func(w io.Writer) error { return plot.LatencyCDF(w, run.Results) }
+		case "pos", "neg":
+			if run.Pheromones == nil {
+				http.NotFound(w, r)
+				return
+			}
+			render = func(w io.Writer) error { return plot.Pheromones(w, run.Results, run.Pheromones, kind) }
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		_ = render(w)
+	})
+	fmt.Fprintf(os.Stderr, "serving %d runs on http://%s/\n", len(served), addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+type servedScenario struct {
+	Name string
+	Runs []servedRef
+}
+
+type servedRef struct {
+	ID         int
+	Strategy   string
+	Seed       int64
+	Pheromones bool
+}
+
+// dashboardView groups the served runs by scenario, in the order they ran.
+func dashboardView() []servedScenario {
+	var view []servedScenario
+	index := make(map[string]int)
+	for id, run := range served {
+		name := run.Results.Scenario
+		i, ok := index[name]
+		if !ok {
+			i = len(view)
+			index[name] = i
+			view = append(view, servedScenario{Name: name})
+		}
+		view[i].Runs = append(view[i].Runs, servedRef{id, run.Results.Strategy, run.Results.Seed, run.Pheromones != nil})
+	}
+	return view
+}
+
+// dashboardPage shows the charts of the runs of the picked scenario, inlined
+// so that hovering a line names it.
+var dashboardPage = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html><head><title>SwarmRoute experiments</title>
+<style>body{font-family:sans-serif}label{margin-right:1em}.run{margin:1em 0}.charts{display:flex;flex-wrap:wrap}.charts svg{margin:4px;border:1px solid #ddd}</style>
+</head><body>
+<h1>SwarmRoute experiments</h1>
+<p>
+<label>Scenario <select id="scenario">{{range $i, $s := .}}<option value="{{$i}}">{{$s.Name}}</option>{{end}}</select></label>
+<label><input type="checkbox" class="kind" value="share" checked>selection share</label>
+<label><input type="checkbox" class="kind" value="p95" checked>rolling p95</label>
+<label><input type="checkbox" class="kind" value="latency">latency distribution</label>
+<label><input type="checkbox" class="kind" value="pos">pheromone pos</label>
+<label><input type="checkbox" class="kind" value="neg">pheromone neg</label>
+</p>
+<p id="strategies"></p>
+<div id="runs"></div>
+<script>
+const scenarios = [{{range .}}{name: {{.Name}}, runs: [{{range .Runs}}{id: {{.ID}}, strategy: {{.Strategy}}, seed: {{.Seed}}, pheromones: {{.Pheromones}}},{{end}}]},{{end}}];
+const hidden = new Set();
+async function show() {
+  const s = scenarios[document.getElementById("scenario").value];
+  if (!s) return;
+  const kinds = [...document.querySelectorAll(".kind:checked")].map(k => k.value);
+  const strategies = document.getElementById("strategies");
+  strategies.innerHTML = "";
+  for (const run of s.runs) {
+    const label = document.createElement("label");
+    const box = document.createElement("input");
+    box.type = "checkbox";
+    box.checked = !hidden.has(run.strategy);
+    box.onchange = () => { box.checked ? hidden.delete(run.strategy) : hidden.add(run.strategy); show(); };
+    label.append(box, run.strategy);
+    strategies.append(label);
+  }
+  const runs = document.getElementById("runs");
+  runs.innerHTML = "";
+  for (const run of s.runs.filter(r => !hidden.has(r.strategy))) {
+    const div = document.createElement("div");
+    div.className = "run";
+    div.innerHTML = "<h2></h2><div class=charts></div>";
+    div.querySelector("h2").textContent = run.strategy + " (seed " + run.seed + ")";
+    runs.append(div);
+    for (const kind of kinds) {
+      if ((kind === "pos" || kind === "neg") && !run.pheromones) continue;
+      const resp = await fetch("/chart/" + run.id + "/" + kind);
+      div.querySelector(".charts").insertAdjacentHTML("beforeend", await resp.text());
+    }
+  }
+}
+document.getElementById("scenario").onchange = show;
+document.querySelectorAll(".kind").forEach(k => k.onchange = show);
+show();
+</script>
+</body></html>
+`))
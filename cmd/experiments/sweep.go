@@ -0,0 +1,181 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"swarmroute/harness"
+)
+
+// sweepScenarios are the closed-loop scenarios a -sweep tunes for.
+func sweepScenarios() []harness.Scenario {
+	return []harness.Scenario{baseScenario(), driftScenario(), flakyFastScenario(), correlatedOutageScenario(), partitionScenario()}
+}
+
+// runSweep grid-searches SwarmRoute's parameters over the sweep scenarios
+// and seeds and prints every configuration, marking the Pareto front.
+func runSweep(spec string) error {
+	grid, err := harness.ParseParamGrid(spec)
+	if err != nil {
+		return err
+	}
+	sw := harness.Sweep{Grid: grid, Scenarios: sweepScenarios(), Seeds: seeds,
+		OnDone: func(done, total int) { fmt.Fprintf(os.Stderr, "\rsweep: %d/%d configurations", done, total) }}
+	res, err := sw.Run()
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	names := slices.Sorted(maps.Keys(grid))
+	onFront := func(p harness.SweepPoint) bool {
+		return slices.ContainsFunc(res.Pareto, func(q harness.SweepPoint) bool { return maps.Equal(p.Params, q.Params) })
+	}
+	switch *format {
+	case "text":
+		fmt.Printf("seeds=%v\nPareto front (success, p95, bad share over %d scenarios):\n", seeds, len(sw.Scenarios))
+		for _, p := range res.Pareto {
+			fmt.Printf("  %s success=%.2f%% p95=%.1fms bad-share=%.2f%% score=%.2f\n", formatParams(names, p.Params), p.SuccessPct, p.P95ms, p.BadShare, score.Score(p))
+		}
+		for _, metric := range []string{"success_pct", "p95_ms", "bad_share"} {
+			fmt.Printf("best %s: %s\n", metric, formatParams(names, res.Best[metric].Params))
+		}
+		best := slices.MaxFunc(res.Points, func(p, q harness.SweepPoint) int { return cmp.Compare(score.Score(p), score.Score(q)) })
+		fmt.Printf("best score: %s (%.2f)\n", formatParams(names, best.Params), score.Score(best))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(append(slices.Clone(names), "success_pct", "p95_ms", "bad_share", "score", "pareto"))
+		for _, p := range res.Points {
+			var row []string
+			for _, name := range names {
+				row = append(row, strconv.FormatFloat(p.Params[name], 'g', -1, 64))
+			}
+			w.Write(append(row, strconv.FormatFloat(p.SuccessPct, 'f', -1, 64), strconv.FormatFloat(p.P95ms, 'f', -1, 64),
+				strconv.FormatFloat(p.BadShare, 'f', -1, 64), strconv.FormatFloat(score.Score(p), 'f', -1, 64), strconv.FormatBool(onFront(p))))
+		}
+		w.Flush()
+		return w.Error()
+	case "json":
+		type pointJSON struct {
+			Params     map[string]float64 `json:"params"`
+			Config     any                `json:"config"`
+			SuccessPct float64            `json:"success_pct"`
+			P95ms      float64            `json:"p95_ms"`
+			BadShare   float64            `json:"bad_share"`
+			Score      float64            `json:"score"`
+			Pareto     bool               `json:"pareto"`
+		}
+		points := make([]pointJSON, len(res.Points))
+		for i, p := range res.Points {
+			points[i] = pointJSON{p.Params, p.Config, p.SuccessPct, p.P95ms, p.BadShare, score.Score(p), onFront(p)}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(points)
+	}
+	return nil
+}
+
+// formatParams renders params as name=value in the order of names.
+func formatParams(names []string, params map[string]float64) string {
+	s := ""
+	for i, name := range names {
+		if i > 0 {
+			s += " "
+		}
+		s += name + "=" + strconv.FormatFloat(params[name], 'g', -1, 64)
+	}
+	return s
+}
+
+// runTune searches SwarmRoute's parameters over the sweep scenarios and
+// seeds within -budget configurations, prints the best ones and writes
+// the winner to -preset-out.
+func runTune(spec string) error {
+	space, err := harness.ParseParamSpace(spec)
+	if err != nil {
+		return err
+	}
+	tn := harness.Tune{Space: space, Objective: score, Scenarios: sweepScenarios(), Seeds: seeds, Budget: *budget, TPE: *tpe,
+		OnDone: func(done, total int, best harness.TuneTrial) {
+			fmt.Fprintf(os.Stderr, "\rtune: %d/%d configurations, best score %.3f", done, total, best.Score)
+		}}
+	res, err := tn.Run()
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	if *presetOut != "" {
+		f, err := os.Create(*presetOut)
+		if err != nil {
+			return err
+		}
+		if err := harness.WritePreset(f, res.Best.Config); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	names := slices.Sorted(maps.Keys(space))
+	switch *format {
+	case "text":
+		ranked := slices.SortedStableFunc(slices.Values(res.Trials), func(a, b harness.TuneTrial) int { return cmp.Compare(b.Score, a.Score) })
+		fmt.Printf("seeds=%v\nbest of %d configurations (score, success, p95, bad share over %d scenarios):\n", seeds, len(res.Trials), len(tn.Scenarios))
+		for _, t := range ranked[:min(5, len(ranked))] {
+			fmt.Printf("  %s score=%.3f success=%.2f%% p95=%.1fms bad-share=%.2f%%\n", formatParams(names, t.Params), t.Score, t.SuccessPct, t.P95ms, t.BadShare)
+		}
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(append(slices.Clone(names), "score", "success_pct", "p95_ms", "bad_share"))
+		for _, t := range res.Trials {
+			var row []string
+			for _, name := range names {
+				row = append(row, strconv.FormatFloat(t.Params[name], 'g', -1, 64))
+			}
+			w.Write(append(row, strconv.FormatFloat(t.Score, 'f', -1, 64), strconv.FormatFloat(t.SuccessPct, 'f', -1, 64),
+				strconv.FormatFloat(t.P95ms, 'f', -1, 64), strconv.FormatFloat(t.BadShare, 'f', -1, 64)))
+		}
+		w.Flush()
+		return w.Error()
+	case "json":
+		type trialJSON struct {
+			Params     map[string]float64 `json:"params"`
+			Config     any                `json:"config"`
+			Score      float64            `json:"score"`
+			SuccessPct float64            `json:"success_pct"`
+			P95ms      float64            `json:"p95_ms"`
+			BadShare   float64            `json:"bad_share"`
+		}
+		trials := make([]trialJSON, len(res.Trials))
+		for i, t := range res.Trials {
+			trials[i] = trialJSON{t.Params, t.Config, t.Score, t.SuccessPct, t.P95ms, t.BadShare}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(trials)
+	}
+	return nil
+}
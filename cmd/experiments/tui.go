@@ -0,0 +1,171 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"swarmroute/harness"
+)
+
+const (
+	// rollingWindow is the number of latest reports and picks the live
+	// success rate, p95 and selection shares are computed over.
+	rollingWindow = 500
+	// redrawEvery throttles redraws; every step would flood the terminal.
+	redrawEvery = 100 * time.Millisecond
+	barWidth    = 30
+)
+
+// dashboard draws the progress of the experiments on a terminal with
+// -tui: a progress bar of the section, and for every strategy the rolling
+// success rate, p95 and per-endpoint selection share of its latest run.
+// It follows the runs through Scenario.Hooks.
+type dashboard struct {
+	w     io.Writer
+	title string
+	start time.Time
+	drawn time.Time
+
+	done, total int // steps of the section
+	seed        int64
+	clients     []string // strategy of every client of the current run
+	order       []string // strategies in the order they first ran
+	live        map[string]*liveStats
+}
+
+// liveStats are the rolling metrics of one strategy.
+type liveStats struct {
+	seed  int64
+	ok    []bool
+	lat   []float64
+	picks []string
+}
+
+func newDashboard(w io.Writer) *dashboard {
+	return &dashboard{w: w, live: make(map[string]*liveStats)}
+}
+
+// section starts a new section titled title.
+func (d *dashboard) section(title string) {
+	d.title, d.start = title, time.Now()
+	d.done, d.total = 0, 0
+	d.order, d.live = nil, make(map[string]*liveStats)
+	d.draw()
+}
+
+// track adds runs of sc to the section and returns sc with the hooks that
+// follow them.
+func (d *dashboard) track(sc harness.Scenario, runs int) harness.Scenario {
+	d.total += runs * sc.TotalRequests
+	sc.Hooks = &harness.Hooks{
+		OnStart: d.onStart,
+		OnPick: func(step, client int, service, endpoint string) {
+			d.stats(client).picks = push(d.stats(client).picks, endpoint)
+		},
+		OnReport: func(client int, service, endpoint string, latencySec float64, success bool) {
+			st := d.stats(client)
+			st.ok = push(st.ok, success)
+			st.lat = push(st.lat, latencySec*1000)
+		},
+		OnStep: func(int) {
+			d.done++
+			if time.Since(d.drawn) >= redrawEvery {
+				d.draw()
+			}
+		},
+	}
+	return sc
+}
+
+// onStart resets the rolling metrics of the strategies of a new run.
+func (d *dashboard) onStart(seed int64, strategies []string) {
+	d.seed, d.clients = seed, strategies
+	for _, name := range strategies {
+		if _, ok := d.live[name]; !ok {
+			d.order = append(d.order, name)
+		}
+		d.live[name] = &liveStats{seed: seed}
+	}
+}
+
+func (d *dashboard) stats(client int) *liveStats {
+	return d.live[d.clients[client]]
+}
+
+// push appends v to the rolling window xs, dropping the oldest value.
+func push[T any](xs []T, v T) []T {
+	if len(xs) == rollingWindow {
+		xs = xs[1:]
+	}
+	return append(xs, v)
+}
+
+// draw redraws the whole dashboard.
+func (d *dashboard) draw() {
+	d.drawn = time.Now()
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J") // cursor home, clear screen
+	b.WriteString(d.title + "\n")
+	frac := 0.0
+	if d.total > 0 {
+		frac = min(float64(d.done)/float64(d.total), 1)
+	}
+	fmt.Fprintf(&b, "%s %3.0f%%  %s  seed=%d\n\n", bar(frac), 100*frac, time.Since(d.start).Round(time.Second), d.seed)
+	for _, name := range d.order {
+		st := d.live[name]
+		ok := 0
+		for _, v := range st.ok {
+			if v {
+				ok++
+			}
+		}
+		success, p95 := 0.0, 0.0
+		if n := len(st.ok); n > 0 {
+			success = 100 * float64(ok) / float64(n)
+			lat := slices.Clone(st.lat)
+			slices.Sort(lat)
+			p95 = lat[int(0.95*float64(n-1))]
+		}
+		fmt.Fprintf(&b, "%-18s seed=%-10d success=%6.2f%%  p95=%7.1fms\n", name, st.seed, success, p95)
+		share := make(map[string]int)
+		for _, e := range st.picks {
+			share[e]++
+		}
+		endpoints := make([]string, 0, len(share))
+		for e := range share {
+			endpoints = append(endpoints, e)
+		}
+		sort.Strings(endpoints)
+		for _, e := range endpoints {
+			s := float64(share[e]) / float64(len(st.picks))
+			fmt.Fprintf(&b, "  %-20s %s %5.1f%%\n", e, bar(s), 100*s)
+		}
+	}
+	fmt.Fprint(d.w, b.String())
+}
+
+// bar renders frac (0..1) as a bar of barWidth characters.
+func bar(frac float64) string {
+	n := int(frac*barWidth + 0.5)
+	return "[" + strings.Repeat("#", n) + strings.Repeat(".", barWidth-n) + "]"
+}
@@ -17,12 +17,35 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
 	"swarmroute/harness"
+	"swarmroute/harness/plot"
 )
 
 // A tiny world simulator entrypoint to compare SwarmRoute against baseline balancers.
 func main() {
+	format := flag.String("format", "text", "output format: text, csv or json")
+	charts := flag.String("charts", "", "write SVG charts of selection share and rolling p95 to this directory")
+	stepLog := flag.String("steplog", "", "write every request and environment change of every run to this file as NDJSON")
+	decisions := flag.String("decisions", "", "write every pick with the strategy's candidate scores to this file, as CSV or Parquet if it ends in .csv or .parquet and NDJSON otherwise")
+	keys := flag.Int("keys", 0, "give requests one of this many synthetic keys, for key-aware strategies such as chash and the key affinity metric")
+	keySkew := flag.Float64("key-skew", 0, "Zipf exponent (>1) of the key popularity with -keys; uniform otherwise")
+	trace := flag.String("trace", "", "instead of the demo scenario, replay this access log (timestamp, upstream, latency and status fields) as CSV, NDJSON or logfmt by its extension (.csv, .json/.ndjson, otherwise logfmt)")
+	traceWindow := flag.Float64("trace-window", 10, "seconds per window of the -trace statistics that set the endpoints' latency and error rate")
+	traceReplay := flag.Bool("trace-replay", true, "with -trace, replay the recorded outcomes; if false, draw synthetic outcomes from the window statistics")
+	strategySpecs := flag.String("strategies", harness.DefaultStrategies, "strategies to compare, as space-separated name[:key=value,...] specs; registered: "+strings.Join(harness.RegisteredStrategies(), ", "))
+	var variants []harness.StrategySpec
+	flag.Func("swarmroute", "add a SwarmRoute configuration named SwarmRoute-label to the strategies, as label[:key=value,...] with a preset, config file, autotune or swarmroute.Config JSON names, e.g. tight:preset=aggressive,base_weight=0.05; repeatable", func(s string) error {
+		spec, err := harness.ParseSwarmRouteVariant(s)
+		variants = append(variants, spec)
+		return err
+	})
+	flag.Parse()
+
 	// Define a simple scenario: two healthy endpoints, then one degrades mid-run, later recovers.
 	svc := "api"
 	// Provide per-endpoint jitter (stddev) ~30% of mean latency
@@ -37,6 +60,7 @@ func main() {
 	normErr := e2.ErrorRate
 
 	sc := harness.Scenario{
+		Name:      "demo",
 		Service:   svc,
 		Endpoints: []harness.EndpointSpec{e1, e2, e3},
 		Events: []harness.EnvironmentEvent{
@@ -47,17 +71,120 @@ func main() {
 		// Pin the seed for reproducible runs; change if you want different runs.
 		Seed: 123456789,
 	}
+	var strategies []harness.Strategy
+	var err error
+
+	if *trace != "" {
+		if sc, err = traceScenario(*trace, *traceWindow, *traceReplay); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 
-	strategies := []harness.Strategy{
-		harness.NewRandomStrategy(1),
-		harness.NewRoundRobinStrategy(),
-		harness.NewPowerOfTwoChoicesStrategy(2, 0.2),
-		harness.NewLeastLatencyStrategy(3, 0.2),
-		harness.NewSwarmRouteAdapter(),
+	if *keys > 0 {
+		sc.Keys = &harness.KeySpace{Count: *keys, Skew: *keySkew}
 	}
 
-	// Print seed so results can be reproduced exactly.
-	fmt.Printf("seed=%d\n", sc.Seed)
+	strategies, err = harness.NewStrategies(*strategySpecs)
+	for _, spec := range variants {
+		if err != nil {
+			break
+		}
+		var s harness.Strategy
+		if s, err = spec.New(); err == nil {
+			strategies = append(strategies, s)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *stepLog != "" {
+		f, err := os.Create(*stepLog)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		sc.StepLog = f
+	}
+	if *decisions != "" {
+		f, err := os.Create(*decisions)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		format := "ndjson"
+		switch {
+		case strings.HasSuffix(*decisions, ".csv"):
+			format = "csv"
+		case strings.HasSuffix(*decisions, ".parquet"):
+			format = "parquet"
+		}
+		sc.Decisions, _ = harness.NewDecisionWriter(f, format)
+	}
 	results := harness.RunAll(sc, strategies)
-	fmt.Print(harness.FormatResults(results))
+	if sc.Decisions != nil {
+		if err := sc.Decisions.Flush(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *charts != "" {
+		if _, err := plot.WriteCharts(*charts, results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	switch *format {
+	case "text":
+		// Print seed so results can be reproduced exactly.
+		fmt.Printf("seed=%d\n", sc.Seed)
+		fmt.Print(harness.FormatResults(results))
+	case "csv":
+		if err := harness.WriteResultsCSV(os.Stdout, results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(harness.Report{Scenario: sc, Seeds: []int64{sc.Seed}, Results: results}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown -format %q\n", *format)
+		os.Exit(2)
+	}
+}
+
+// traceScenario reads the access log at path into a scenario, replaying
+// its outcomes if replay is set.
+func traceScenario(path string, windowSec float64, replay bool) (harness.Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return harness.Scenario{}, err
+	}
+	defer f.Close()
+	format := "logfmt"
+	switch {
+	case strings.HasSuffix(path, ".csv"):
+		format = "csv"
+	case strings.HasSuffix(path, ".json"), strings.HasSuffix(path, ".ndjson"):
+		format = "ndjson"
+	}
+	records, err := harness.ReadAccessLog(f, harness.AccessLogFormat{Format: format})
+	if err != nil {
+		return harness.Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	tr := harness.NewTrace(records)
+	sc := tr.Scenario(windowSec)
+	sc.Seed = 123456789
+	if replay {
+		sc.Trace = tr
+	}
+	return sc, nil
 }
@@ -0,0 +1,172 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// chaosEvent is a fault injected from From to To since the run started:
+//
+//	kill   the endpoint's server is closed, then restarted on its address
+//	reset  the share Rate of the endpoint's requests get a connection reset
+//	cpu    Procs goroutines saturate the CPU, slowing down every endpoint
+//	       and the client alike
+type chaosEvent struct {
+	Kind     string
+	Endpoint int
+	From, To time.Duration
+	Rate     float64
+	Procs    int
+}
+
+// parseChaos parses "kill:endpoint=2,from=5s,to=8s",
+// "reset:endpoint=0,from=2s,to=6s[,rate=0.5]" or "cpu:from=3s,to=9s[,procs=4]".
+func parseChaos(s string) (chaosEvent, error) {
+	kind, kvs, _ := strings.Cut(s, ":")
+	ev := chaosEvent{Kind: kind, Rate: 0.5, Procs: runtime.NumCPU()}
+	keys := map[string]func(string) error{
+		"from": durationVar(&ev.From),
+		"to":   durationVar(&ev.To),
+	}
+	switch kind {
+	case "kill", "reset":
+		keys["endpoint"] = func(v string) (err error) { ev.Endpoint, err = strconv.Atoi(v); return }
+		if kind == "reset" {
+			keys["rate"] = floatVar(&ev.Rate)
+		}
+	case "cpu":
+		keys["procs"] = func(v string) (err error) { ev.Procs, err = strconv.Atoi(v); return }
+	default:
+		return ev, fmt.Errorf("%q: unknown fault %q, expected kill, reset or cpu", s, kind)
+	}
+	if err := parseKV(kvs, keys); err != nil {
+		return ev, err
+	}
+	if ev.To <= ev.From {
+		return ev, fmt.Errorf("%q: to must be after from", s)
+	}
+	return ev, nil
+}
+
+// randomChaos returns the next random fault after the one that ended at
+// prev: faults start after exponentially distributed gaps averaging
+// every, last between a quarter and three quarters of every, and pick
+// their kind and endpoint uniformly.  Times are rounded to milliseconds.
+func randomChaos(rng *rand.Rand, prev time.Duration, every time.Duration, endpoints int) chaosEvent {
+	from := prev + time.Duration(rng.ExpFloat64()*float64(every)).Round(time.Millisecond)
+	return chaosEvent{
+		Kind:     []string{"kill", "reset", "cpu"}[rng.Intn(3)],
+		Endpoint: rng.Intn(endpoints),
+		From:     from,
+		To:       from + time.Duration((0.25+rng.Float64()/2)*float64(every)).Round(time.Millisecond),
+		Rate:     0.5,
+		Procs:    runtime.NumCPU(),
+	}
+}
+
+// chaos injects faults into the backends of one run.
+type chaos struct {
+	backends []*backend
+	start    time.Time
+	wg       sync.WaitGroup
+}
+
+// startChaos injects events and, if every is set, random faults from a
+// generator seeded with seed, so every strategy faces the same chaos,
+// until ctx is done.  wait returns once every fault is reverted.
+func startChaos(ctx context.Context, backends []*backend, start time.Time, events []chaosEvent, every time.Duration, seed int64) (wait func()) {
+	c := &chaos{backends: backends, start: start}
+	for _, ev := range events {
+		c.wg.Go(func() { c.inject(ctx, ev) })
+	}
+	if every > 0 {
+		c.wg.Go(func() {
+			rng := rand.New(rand.NewSource(seed))
+			var prev time.Duration
+			for ctx.Err() == nil {
+				ev := randomChaos(rng, prev, every, len(backends))
+				prev = ev.To
+				if !sleepUntil(ctx, start.Add(ev.From)) {
+					return
+				}
+				c.wg.Go(func() { c.inject(ctx, ev) })
+			}
+		})
+	}
+	return c.wg.Wait
+}
+
+// sleepUntil waits for t and reports whether ctx is still live.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// inject applies ev from its start to its end or until ctx is done.
+func (c *chaos) inject(ctx context.Context, ev chaosEvent) {
+	if !sleepUntil(ctx, c.start.Add(ev.From)) {
+		return
+	}
+	b := c.backends[ev.Endpoint]
+	switch ev.Kind {
+	case "kill":
+		fmt.Printf("  chaos %v: kill %s until %v\n", ev.From, b.cfg.Addr, ev.To)
+		b.kill()
+		if sleepUntil(ctx, c.start.Add(ev.To)) {
+			if err := b.revive(); err != nil {
+				log.Printf("chaos: restart %s: %v", b.cfg.Addr, err)
+			}
+		}
+	case "reset":
+		fmt.Printf("  chaos %v: reset %.0f%% of requests to %s until %v\n", ev.From, 100*ev.Rate, b.cfg.Addr, ev.To)
+		b.addReset(ev.Rate)
+		sleepUntil(ctx, c.start.Add(ev.To))
+		b.removeReset(ev.Rate)
+	case "cpu":
+		fmt.Printf("  chaos %v: saturate the CPU with %d goroutines until %v\n", ev.From, ev.Procs, ev.To)
+		burn, stop := context.WithDeadline(ctx, c.start.Add(ev.To))
+		defer stop()
+		var wg sync.WaitGroup
+		for range ev.Procs {
+			wg.Go(func() {
+				var sum [sha256.Size]byte
+				for burn.Err() == nil {
+					for range 1000 {
+						sum = sha256.Sum256(sum[:])
+					}
+				}
+			})
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// profile is the undegraded behaviour of an endpoint.
+type profile struct {
+	BaseLat time.Duration
+	Jitter  time.Duration
+	BaseErr float64
+}
+
+// defaultProfile is the profile of endpoint i when no -profile is given:
+// the three classic endpoints, then ever slower ones.
+func defaultProfile(i int) profile {
+	switch i {
+	case 0:
+		return profile{BaseLat: 30 * time.Millisecond, Jitter: 9 * time.Millisecond, BaseErr: 0.01}
+	case 1:
+		return profile{BaseLat: 35 * time.Millisecond, Jitter: 10*time.Millisecond + 500*time.Microsecond, BaseErr: 0.01}
+	case 2:
+		return profile{BaseLat: 40 * time.Millisecond, Jitter: 12 * time.Millisecond, BaseErr: 0.02}
+	}
+	lat := time.Duration(30+5*i) * time.Millisecond
+	return profile{BaseLat: lat, Jitter: lat * 3 / 10, BaseErr: 0.01}
+}
+
+// degradeWindow degrades one endpoint from From to To since the run started.
+type degradeWindow struct {
+	Endpoint int // index into the endpoints
+	From, To time.Duration
+	Lat      time.Duration
+	Err      float64
+}
+
+// defaultDegrade is the classic window: the second endpoint is slow and
+// failing from 4s to 12s.
+var defaultDegrade = degradeWindow{Endpoint: 1, From: 4 * time.Second, To: 12 * time.Second, Lat: 120 * time.Millisecond, Err: 0.20}
+
+// parseKV parses "k=v,k=v" with a parser per key.
+func parseKV(s string, keys map[string]func(string) error) error {
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("%q: expected key=value, got %q", s, kv)
+		}
+		set, ok := keys[k]
+		if !ok {
+			return fmt.Errorf("%q: unknown key %q", s, k)
+		}
+		if err := set(v); err != nil {
+			return fmt.Errorf("%q: %s: %v", s, k, err)
+		}
+	}
+	return nil
+}
+
+func durationVar(d *time.Duration) func(string) error {
+	return func(v string) (err error) { *d, err = time.ParseDuration(v); return }
+}
+
+func floatVar(f *float64) func(string) error {
+	return func(v string) (err error) { *f, err = strconv.ParseFloat(v, 64); return }
+}
+
+// parseProfile parses "lat=30ms,jitter=9ms,err=0.01"; omitted keys are 0.
+func parseProfile(s string) (profile, error) {
+	var p profile
+	err := parseKV(s, map[string]func(string) error{
+		"lat":    durationVar(&p.BaseLat),
+		"jitter": durationVar(&p.Jitter),
+		"err":    floatVar(&p.BaseErr),
+	})
+	return p, err
+}
+
+// parseDegrade parses "endpoint=1,from=4s,to=12s,lat=120ms,err=0.2".
+func parseDegrade(s string) (degradeWindow, error) {
+	var w degradeWindow
+	err := parseKV(s, map[string]func(string) error{
+		"endpoint": func(v string) (err error) { w.Endpoint, err = strconv.Atoi(v); return },
+		"from":     durationVar(&w.From),
+		"to":       durationVar(&w.To),
+		"lat":      durationVar(&w.Lat),
+		"err":      floatVar(&w.Err),
+	})
+	if err == nil && w.To <= w.From {
+		err = fmt.Errorf("%q: to must be after from", s)
+	}
+	return w, err
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// load is an open-loop request schedule: requests are due at RPS,
+// ramping up linearly from 0 over Ramp, whether or not earlier ones have
+// been answered, and Workers send them.  Unlike a closed loop, a slow
+// endpoint cannot slow down the arrivals, so the strategies are measured
+// under the concurrency they would see in production.
+type load struct {
+	RPS     float64
+	Workers int
+	Ramp    time.Duration
+}
+
+// arrival returns when request k (0-based) is due after the start.
+func (l load) arrival(k int) time.Duration {
+	ramp := l.Ramp.Seconds()
+	// During the ramp k(t) = RPS t^2 / (2 ramp), afterwards it grows by RPS.
+	var t float64
+	if inRamp := l.RPS * ramp / 2; float64(k) < inRamp {
+		t = math.Sqrt(2 * float64(k) * ramp / l.RPS)
+	} else {
+		t = ramp + (float64(k)-inRamp)/l.RPS
+	}
+	return time.Duration(t * float64(time.Second))
+}
+
+// run calls do with the due time of total requests, or of all requests
+// due before until if it is not zero, and returns once they are done.
+// Requests wait for a free worker in a backlog of a second's worth of
+// arrivals; they are dropped, and counted, when it is full.
+func (l load) run(total int, until time.Time, do func(due time.Time)) (sent, dropped int) {
+	backlog := make(chan time.Time, max(int(l.RPS), l.Workers))
+	var wg sync.WaitGroup
+	for range l.Workers {
+		wg.Go(func() {
+			for due := range backlog {
+				do(due)
+			}
+		})
+	}
+	begin := time.Now()
+	for k := 0; until.IsZero() && k < total || !until.IsZero(); k++ {
+		due := begin.Add(l.arrival(k))
+		if !until.IsZero() && due.After(until) {
+			break
+		}
+		time.Sleep(time.Until(due))
+		select {
+		case backlog <- due:
+			sent++
+		default:
+			dropped++
+		}
+	}
+	close(backlog)
+	wg.Wait()
+	return sent, dropped
+}
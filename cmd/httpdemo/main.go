@@ -14,73 +14,207 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Command httpdemo replays the simulator's degrade scenario against real
+// HTTP servers on localhost and prints per-strategy results.  By default
+// it starts three endpoints on ports 8091-8093 and degrades the second
+// from 4s to 12s; flags vary the endpoints, their profiles, the run
+// length and the degrade schedule.  Requests arrive open loop at -rps,
+// optionally ramped up over -ramp, and up to -workers are in flight.
+// -chaos and -chaos-every kill and restart servers, reset connections
+// and saturate the CPU (see chaosEvent).  -soak runs for hours with
+// drifting endpoints and prints periodic snapshots (see soak):
+//
+//	go run ./cmd/httpdemo -endpoints 6 -duration 1m -rps 200 -workers 32 \
+//		-degrade endpoint=1,from=10s,to=30s,lat=120ms,err=0.2 \
+//		-degrade endpoint=4,from=20s,to=50s,err=0.5 \
+//		-chaos kill:endpoint=2,from=15s,to=25s -chaos-every 20s
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
+	"log"
 	"math"
 	"math/rand"
+	"net"
 	"net/http"
+	"slices"
 	"sort"
+	"strings"
 	"swarmroute/harness"
+	"swarmroute/internal/debugserver"
+	"sync"
 	"time"
 )
 
 type endpointConfig struct {
-	Addr           string
-	BaseLat        time.Duration
-	Jitter         time.Duration
-	BaseErr        float64
-	DegradeLat     time.Duration
-	DegradeErr     float64
-	DegradeStart   time.Duration
-	DegradeEnd     time.Duration
-	IsDegradedNode bool // true for the one we degrade
-}
-
-func startServer(cfg endpointConfig, start time.Time) *http.Server {
-	mux := http.NewServeMux()
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		// choose parameters depending on time window
-		now := time.Since(start)
-		lat := cfg.BaseLat
-		errRate := cfg.BaseErr
-		if cfg.IsDegradedNode && now >= cfg.DegradeStart && now < cfg.DegradeEnd {
-			lat = cfg.DegradeLat
-			errRate = cfg.DegradeErr
-		}
-		// jittered latency, truncated
-		jitter := cfg.Jitter
-		mean := float64(lat)
-		sd := float64(jitter)
-		sample := mean + rng.NormFloat64()*sd
-		minLat := 0.2 * mean
-		maxLat := 5.0 * mean
-		if sample < minLat {
-			sample = minLat
-		}
-		if sample > maxLat {
-			sample = maxLat
-		}
-		time.Sleep(time.Duration(sample))
-		if rng.Float64() < errRate {
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte("error"))
+	Addr string
+	profile
+	Degrades []degradeWindow
+	// The base latency and error rate drift by the factor
+	// 1 + Drift*sin(2*pi*t/DriftPeriod + DriftPhase).
+	Drift       float64
+	DriftPeriod time.Duration
+	DriftPhase  float64
+}
+
+// at returns the latency and error rate at time now since the run started
+// and whether the endpoint is degraded then.  A window without a latency
+// keeps the base latency.
+func (c endpointConfig) at(now time.Duration) (lat time.Duration, errRate float64, degraded bool) {
+	baseLat, baseErr := c.BaseLat, c.BaseErr
+	if c.Drift != 0 && c.DriftPeriod > 0 {
+		f := 1 + c.Drift*math.Sin(2*math.Pi*float64(now)/float64(c.DriftPeriod)+c.DriftPhase)
+		baseLat, baseErr = time.Duration(float64(baseLat)*f), math.Min(baseErr*f, 1)
+	}
+	for _, w := range c.Degrades {
+		if now >= w.From && now < w.To {
+			lat = baseLat
+			if w.Lat > 0 {
+				lat = w.Lat
+			}
+			return lat, w.Err, true
+		}
+	}
+	return baseLat, baseErr, false
+}
+
+// backend is the demo server of an endpoint.  Chaos can kill it, restart
+// it on the same address and make it reset connections.  Faults are
+// counted, so overlapping ones on the same backend last until the last of
+// them ends.
+type backend struct {
+	cfg   endpointConfig
+	start time.Time
+
+	mu     sync.Mutex
+	srv    *http.Server // nil while killed
+	rng    *rand.Rand
+	kills  int       // active kill faults
+	resets []float64 // shares of requests answered with a connection reset, one per active fault
+}
+
+func newBackend(cfg endpointConfig, start time.Time) *backend {
+	return &backend{cfg: cfg, start: start, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// up starts the server unless it is running.  The first start fills in
+// the port of an address without one.
+func (b *backend) up() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.srv != nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", b.cfg.Addr[len("http://"):])
+	if err != nil {
+		return err
+	}
+	b.cfg.Addr = "http://" + ln.Addr().String()
+	b.srv = &http.Server{Handler: http.HandlerFunc(b.serveHTTP)}
+	go func(srv *http.Server) { _ = srv.Serve(ln) }(b.srv)
+	return nil
+}
+
+// kill closes the server and its connections at once and keeps it down
+// until every kill has been matched by a revive.
+func (b *backend) kill() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.kills++
+	if b.srv != nil {
+		_ = b.srv.Close()
+		b.srv = nil
+	}
+}
+
+// revive ends a kill and restarts the server once no other kill is active.
+func (b *backend) revive() error {
+	b.mu.Lock()
+	b.kills--
+	last := b.kills == 0
+	b.mu.Unlock()
+	if !last {
+		return nil
+	}
+	return b.up()
+}
+
+func (b *backend) shutdown(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.srv != nil {
+		_ = b.srv.Shutdown(ctx)
+		b.srv = nil
+	}
+}
+
+// addReset starts resetting share of the requests.  While resets overlap
+// the largest share applies.
+func (b *backend) addReset(share float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resets = append(b.resets, share)
+}
+
+// removeReset ends a reset started by addReset(share).
+func (b *backend) removeReset(share float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i := slices.Index(b.resets, share); i >= 0 {
+		b.resets = slices.Delete(b.resets, i, i+1)
+	}
+}
+
+func (b *backend) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// choose parameters depending on time window
+	lat, errRate, _ := b.cfg.at(time.Since(b.start))
+	b.mu.Lock()
+	share := 0.0
+	if len(b.resets) > 0 {
+		share = slices.Max(b.resets)
+	}
+	norm, u, reset := b.rng.NormFloat64(), b.rng.Float64(), b.rng.Float64() < share
+	b.mu.Unlock()
+	if reset {
+		// Closing with a zero linger sends a RST instead of a FIN.
+		if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+			if tc, ok := conn.(*net.TCPConn); ok {
+				_ = tc.SetLinger(0)
+			}
+			_ = conn.Close()
 			return
 		}
-		_, _ = w.Write([]byte("ok"))
-	})
-	srv := &http.Server{Addr: cfg.Addr[len("http://"):], Handler: mux}
-	go func() { _ = srv.ListenAndServe() }()
-	return srv
+	}
+	// jittered latency, truncated
+	jitter := b.cfg.Jitter
+	mean := float64(lat)
+	sd := float64(jitter)
+	sample := mean + norm*sd
+	minLat := 0.2 * mean
+	maxLat := 5.0 * mean
+	if sample < minLat {
+		sample = minLat
+	}
+	if sample > maxLat {
+		sample = maxLat
+	}
+	time.Sleep(time.Duration(sample))
+	if u < errRate {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("error"))
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
 }
 
 type runResult struct {
 	Strategy string
 	Total    int
 	Success  int
+	Dropped  int // requests the workers could not keep up with
 	MeanMS   float64
 	P95MS    float64
 	Select   map[string]int
@@ -88,6 +222,94 @@ type runResult struct {
 }
 
 func main() {
+	pprofAddr := flag.String("pprof", "", "serve net/http/pprof and runtime stats on this address (e.g. localhost:6060)")
+	n := flag.Int("endpoints", 3, "number of endpoints; -profile sets the first ones, the rest are ever slower defaults")
+	host := flag.String("host", "127.0.0.1", "the address the endpoints listen on")
+	basePort := flag.Int("base-port", 8091, "port of the first endpoint, the others follow; 0 picks free ports")
+	total := flag.Int("requests", 1000, "requests per strategy")
+	duration := flag.Duration("duration", 0, "run each strategy for this long instead of -requests")
+	rps := flag.Float64("rps", 50, "target requests per second, sent whether or not earlier ones were answered")
+	workers := flag.Int("workers", 16, "concurrent requests at most")
+	ramp := flag.Duration("ramp", 0, "ramp the request rate up from 0 over this long")
+	var profiles []profile
+	flag.Func("profile", "an endpoint profile, lat=30ms,jitter=9ms,err=0.01 (repeatable, in endpoint order)", func(s string) error {
+		p, err := parseProfile(s)
+		profiles = append(profiles, p)
+		return err
+	})
+	var degrades []degradeWindow
+	noDegrade := false
+	flag.Func("degrade", "a degrade window, endpoint=1,from=4s,to=12s[,lat=120ms][,err=0.2] with a 0-based endpoint, or none (repeatable; default: the classic window)", func(s string) error {
+		if s == "none" {
+			noDegrade = true
+			return nil
+		}
+		w, err := parseDegrade(s)
+		degrades = append(degrades, w)
+		return err
+	})
+	var events []chaosEvent
+	flag.Func("chaos", "a fault, kill:endpoint=2,from=5s,to=8s, reset:endpoint=0,from=2s,to=6s[,rate=0.5] or cpu:from=3s,to=9s[,procs=N] (repeatable)", func(s string) error {
+		ev, err := parseChaos(s)
+		events = append(events, ev)
+		return err
+	})
+	chaosEvery := flag.Duration("chaos-every", 0, "also inject random faults, on average this often")
+	chaosSeed := flag.Int64("chaos-seed", 1, "seed of the random faults, the same for every strategy")
+	drift := flag.Float64("drift", 0, "let base latencies and error rates drift sinusoidally by this fraction, out of phase between endpoints (default 0, 0.5 with -soak)")
+	driftPeriod := flag.Duration("drift-period", 30*time.Minute, "the period of -drift")
+	soakFor := flag.Duration("soak", 0, "run every strategy at once for this long, e.g. 4h, printing snapshots instead of one result")
+	snapshotEvery := flag.Duration("snapshot-every", time.Minute, "the interval of -soak snapshots")
+	flag.Parse()
+	if *pprofAddr != "" {
+		debugserver.Serve(*pprofAddr)
+	}
+	if *rps <= 0 || *workers < 1 {
+		log.Fatal("-rps and -workers must be positive")
+	}
+	ld := load{RPS: *rps, Workers: *workers, Ramp: *ramp}
+	if *soakFor > 0 {
+		// Every strategy has its own servers at the same time.
+		*basePort = 0
+		driftSet := false
+		flag.Visit(func(f *flag.Flag) { driftSet = driftSet || f.Name == "drift" })
+		if !driftSet {
+			*drift = 0.5
+		}
+	}
+	if *n < len(profiles) {
+		*n = len(profiles)
+	}
+	if *n < 1 {
+		log.Fatal("-endpoints: need at least one endpoint")
+	}
+	if len(degrades) == 0 && !noDegrade && *n > defaultDegrade.Endpoint {
+		degrades = []degradeWindow{defaultDegrade}
+	}
+	cfgs := make([]endpointConfig, *n)
+	for i := range cfgs {
+		port := 0
+		if *basePort != 0 {
+			port = *basePort + i
+		}
+		cfgs[i] = endpointConfig{Addr: fmt.Sprintf("http://%s:%d", *host, port), profile: defaultProfile(i),
+			Drift: *drift, DriftPeriod: *driftPeriod, DriftPhase: 2 * math.Pi * float64(i) / float64(len(cfgs))}
+		if i < len(profiles) {
+			cfgs[i].profile = profiles[i]
+		}
+	}
+	for _, w := range degrades {
+		if w.Endpoint < 0 || w.Endpoint >= len(cfgs) {
+			log.Fatalf("-degrade: endpoint %d out of range [0, %d)", w.Endpoint, len(cfgs))
+		}
+		cfgs[w.Endpoint].Degrades = append(cfgs[w.Endpoint].Degrades, w)
+	}
+	for _, ev := range events {
+		if ev.Endpoint < 0 || ev.Endpoint >= len(cfgs) {
+			log.Fatalf("-chaos: endpoint %d out of range [0, %d)", ev.Endpoint, len(cfgs))
+		}
+	}
+
 	strategies := []harness.Strategy{
 		harness.NewRandomStrategy(1),
 		harness.NewRoundRobinStrategy(),
@@ -96,26 +318,41 @@ func main() {
 		harness.NewSwarmRouteAdapter(),
 	}
 	svc := "api"
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := &http.Client{Timeout: 2 * time.Second, Transport: &http.Transport{MaxIdleConnsPerHost: *workers}}
+	if *soakFor > 0 {
+		soak(client, strategies, svc, cfgs, ld, *soakFor, *snapshotEvery, func(ctx context.Context, backends []*backend, start time.Time) func() {
+			return startChaos(ctx, backends, start, events, *chaosEvery, *chaosSeed)
+		})
+		return
+	}
 
 	for _, s := range strategies {
 		// For fair comparison, start fresh servers per strategy so degrade window aligns with this run
 		start := time.Now()
-		dStart := 4 * time.Second
-		dEnd := 12 * time.Second
-		a := endpointConfig{Addr: "http://127.0.0.1:8091", BaseLat: 30 * time.Millisecond, Jitter: 9 * time.Millisecond, BaseErr: 0.01}
-		b := endpointConfig{Addr: "http://127.0.0.1:8092", BaseLat: 35 * time.Millisecond, Jitter: 10*time.Millisecond + 500*time.Microsecond, BaseErr: 0.01, DegradeLat: 120 * time.Millisecond, DegradeErr: 0.20, DegradeStart: dStart, DegradeEnd: dEnd, IsDegradedNode: true}
-		c := endpointConfig{Addr: "http://127.0.0.1:8093", BaseLat: 40 * time.Millisecond, Jitter: 12 * time.Millisecond, BaseErr: 0.02}
-		servers := []*http.Server{startServer(a, start), startServer(b, start), startServer(c, start)}
+		// With -base-port 0 the ports, and so the addresses, change between runs.
+		backends, run := startBackends(cfgs, start)
 		// Allow to start
 		time.Sleep(200 * time.Millisecond)
 
-		eps := []string{a.Addr, b.Addr, c.Addr}
-		fmt.Println("HTTP demo (", s.Name(), "): degrade=", dStart, "..", dEnd, "on", b.Addr)
+		eps := make([]string, len(run))
+		for i, c := range run {
+			eps[i] = c.Addr
+		}
+		fmt.Println("HTTP demo (", s.Name(), "): degrade=", describeDegrades(run))
 		s.AddService(svc, eps)
-		r := runHTTP(client, s, svc, eps, start, dStart, dEnd, b.Addr, 1000)
+		chaosCtx, stopChaos := context.WithCancel(context.Background())
+		waitChaos := startChaos(chaosCtx, backends, start, events, *chaosEvery, *chaosSeed)
+		t := newTally(s)
+		sent, dropped := runHTTP(client, t, svc, run, start, *total, *duration, ld)
+		r := t.take()
+		r.Total, r.Dropped = sent+dropped, dropped
+		stopChaos()
+		waitChaos()
 		fmt.Printf("%s: success=%d/%d (%.1f%%), mean=%.1fms p95=%.1fms, bad-window share=%.2f%%\n",
 			r.Strategy, r.Success, r.Total, 100.0*float64(r.Success)/float64(r.Total), r.MeanMS, r.P95MS, r.BadShare)
+		if r.Dropped > 0 {
+			fmt.Printf("  dropped %d requests: raise -workers\n", r.Dropped)
+		}
 		// Print selections
 		keys := make([]string, 0, len(r.Select))
 		for k := range r.Select {
@@ -127,8 +364,8 @@ func main() {
 		}
 		// Shutdown servers
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		for _, srv := range servers {
-			_ = srv.Shutdown(ctx)
+		for _, b := range backends {
+			b.shutdown(ctx)
 		}
 		cancel()
 		// Give sockets a moment to release
@@ -136,47 +373,126 @@ func main() {
 	}
 }
 
-func runHTTP(client *http.Client, strat harness.Strategy, svc string, eps []string, start time.Time, dStart, dEnd time.Duration, degraded string, total int) runResult {
-	sel := make(map[string]int)
-	success := 0
-	lats := make([]float64, 0, total)
-	badSel := 0
-	badTotal := 0
-	for i := 0; i < total; i++ {
-		addr, err := strat.PickEndpoint(svc)
+// startBackends starts the servers of a run and returns them with their
+// configs, whose addresses have ports.
+func startBackends(cfgs []endpointConfig, start time.Time) ([]*backend, []endpointConfig) {
+	backends := make([]*backend, len(cfgs))
+	run := make([]endpointConfig, len(cfgs))
+	for i, c := range cfgs {
+		backends[i] = newBackend(c, start)
+		if err := backends[i].up(); err != nil {
+			log.Fatal(err)
+		}
+		run[i] = backends[i].cfg
+	}
+	return backends, run
+}
+
+// describeDegrades lists the degrade windows, e.g. "4s .. 12s on http://127.0.0.1:8092".
+func describeDegrades(eps []endpointConfig) string {
+	var parts []string
+	for _, c := range eps {
+		for _, w := range c.Degrades {
+			parts = append(parts, fmt.Sprint(w.From, " .. ", w.To, " on ", c.Addr))
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// tally accumulates the outcomes of requests until it is taken.  Its
+// mutex also serializes the strategy, which need not be safe for
+// concurrent use.
+type tally struct {
+	mu       sync.Mutex
+	strat    harness.Strategy
+	sel      map[string]int
+	done     int
+	success  int
+	lats     []float64
+	badSel   int
+	badTotal int
+}
+
+func newTally(strat harness.Strategy) *tally {
+	return &tally{strat: strat, sel: make(map[string]int)}
+}
+
+// take returns the outcomes since the last take and starts over.  Total
+// is the number of requests answered or failed; Select counts picks, which
+// differ by the requests in flight.
+func (t *tally) take() runResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	mean, p95 := meanP95(t.lats)
+	share := 0.0
+	if t.badTotal > 0 {
+		share = 100.0 * float64(t.badSel) / float64(t.badTotal)
+	}
+	r := runResult{Strategy: t.strat.Name(), Total: t.done, Success: t.success, MeanMS: mean, P95MS: p95, Select: t.sel, BadShare: share}
+	t.sel, t.done, t.success, t.lats, t.badSel, t.badTotal = make(map[string]int), 0, 0, t.lats[:0:0], 0, 0
+	return r
+}
+
+// runHTTP sends total requests with the load, or all requests due
+// within duration if it is set, and tallies them in t.  Latencies count
+// from the time a request was due, so they include waiting for a worker;
+// the strategy is told the latency of the HTTP request.  The bad-window
+// share is the share of requests sent while some endpoint is degraded
+// that went to a degraded endpoint.
+func runHTTP(client *http.Client, t *tally, svc string, eps []endpointConfig, start time.Time, total int, duration time.Duration, ld load) (sent, dropped int) {
+	byAddr := make(map[string]endpointConfig, len(eps))
+	for _, c := range eps {
+		byAddr[c.Addr] = c
+	}
+	var until time.Time
+	if duration > 0 {
+		until = start.Add(duration)
+	}
+	return ld.run(total, until, func(due time.Time) {
+		t.mu.Lock()
+		addr, err := t.strat.PickEndpoint(svc)
+		if err == nil {
+			t.sel[addr]++
+		}
+		t.mu.Unlock()
 		if err != nil {
-			continue
+			return
 		}
-		sel[addr]++
 		t0 := time.Now()
 		resp, err := client.Get(addr)
 		lat := time.Since(t0)
 		latSec := float64(lat) / float64(time.Second)
 		ok := (err == nil && resp != nil && resp.StatusCode == http.StatusOK)
 		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
 			_ = resp.Body.Close()
 		}
-		strat.ReportResult(svc, addr, latSec, ok)
 
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.strat.ReportResult(svc, addr, latSec, ok)
+		t.done++
 		now := time.Since(start)
-		if now >= dStart && now < dEnd {
-			badTotal++
-			if addr == degraded {
-				badSel++
+		anyDegraded := false
+		for _, c := range eps {
+			if _, _, d := c.at(now); d {
+				anyDegraded = true
+			}
+		}
+		if anyDegraded {
+			t.badTotal++
+			if _, _, d := byAddr[addr].at(now); d {
+				t.badSel++
 			}
 		}
-
 		if ok {
-			success++
-			lats = append(lats, float64(lat)/float64(time.Millisecond))
+			t.success++
+			t.lats = append(t.lats, float64(time.Since(due))/float64(time.Millisecond))
 		}
-	}
-	mean, p95 := meanP95(lats)
-	share := 0.0
-	if badTotal > 0 {
-		share = 100.0 * float64(badSel) / float64(badTotal)
-	}
-	return runResult{Strategy: strat.Name(), Total: total, Success: success, MeanMS: mean, P95MS: p95, Select: sel, BadShare: share}
+	})
 }
 
 func meanP95(xs []float64) (mean, p95 float64) {
@@ -0,0 +1,133 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"swarmroute/harness"
+)
+
+// soak runs every strategy at once for length, each against its own
+// servers with the same drift, degrade windows and chaos, and prints a
+// snapshot every interval: per strategy the interval's requests, success,
+// latency and traffic shares of the endpoints in order, and the size of
+// its learned state (plus the largest pheromones for SwarmRoute), and the
+// process's heap and goroutines.  Long runs surface what short ones hide,
+// such as state that grows without bound or drifts away from the
+// endpoints' current quality.
+func soak(client *http.Client, strategies []harness.Strategy, svc string, cfgs []endpointConfig, ld load, length, interval time.Duration, chaos func(context.Context, []*backend, time.Time) func()) {
+	start := time.Now()
+	fmt.Printf("HTTP soak: %d strategies, %d endpoints, %v, snapshots every %v\n", len(strategies), len(cfgs), length, interval)
+	tallies := make([]*tally, len(strategies))
+	runs := make([][]endpointConfig, len(strategies))
+	var wg sync.WaitGroup
+	for k, s := range strategies {
+		backends, run := startBackends(cfgs, start)
+		eps := make([]string, len(run))
+		for i, c := range run {
+			eps[i] = c.Addr
+		}
+		s.AddService(svc, eps)
+		tallies[k], runs[k] = newTally(s), run
+		wg.Go(func() {
+			ctx, stop := context.WithCancel(context.Background())
+			wait := chaos(ctx, backends, start)
+			runHTTP(client, tallies[k], svc, run, start, 0, length, ld)
+			stop()
+			wait()
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			for _, b := range backends {
+				b.shutdown(ctx)
+			}
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// The snapshot after done is the final one, however short its
+		// interval.
+		select {
+		case <-ticker.C:
+		case <-done:
+		}
+		elapsed := time.Since(start).Round(time.Second)
+		for k, t := range tallies {
+			fmt.Printf("[%v] %s\n", elapsed, snapshot(t, runs[k], svc))
+		}
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		fmt.Printf("[%v] process: heap=%.1fMiB goroutines=%d\n", elapsed, float64(m.HeapAlloc)/(1<<20), runtime.NumGoroutine())
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
+// snapshot takes the tally and describes it with the strategy's state.
+func snapshot(t *tally, eps []endpointConfig, svc string) string {
+	r := t.take()
+	picks := 0
+	for _, c := range r.Select {
+		picks += c
+	}
+	shares := make([]string, len(eps))
+	for i, c := range eps {
+		shares[i] = "0"
+		if picks > 0 {
+			shares[i] = fmt.Sprintf("%.0f", 100*float64(r.Select[c.Addr])/float64(picks))
+		}
+	}
+	success := 0.0
+	if r.Total > 0 {
+		success = 100 * float64(r.Success) / float64(r.Total)
+	}
+	line := fmt.Sprintf("%s: requests=%d success=%.1f%% mean=%.1fms p95=%.1fms shares=%s%%",
+		r.Strategy, r.Total, success, r.MeanMS, r.P95MS, strings.Join(shares, "/"))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cs, ok := t.strat.(harness.CheckpointStrategy); ok {
+		if state, err := cs.MarshalState(); err == nil {
+			line += fmt.Sprintf(" state=%dB", len(state))
+		}
+	}
+	if a, ok := t.strat.(*harness.SwarmRouteAdapter); ok {
+		ph := a.SamplePheromones(0, svc)
+		maxPos, maxNeg := 0.0, 0.0
+		for addr := range ph.Pos {
+			maxPos, maxNeg = max(maxPos, ph.Pos[addr]), max(maxNeg, ph.Neg[addr])
+		}
+		line += fmt.Sprintf(" max-pos=%.3g max-neg=%.3g", maxPos, maxNeg)
+	}
+	return line
+}
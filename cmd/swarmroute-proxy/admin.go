@@ -0,0 +1,117 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// reloader re-reads the config file and applies it to a running proxy.
+type reloader struct {
+	mu   sync.Mutex
+	path string
+	p    *proxy
+	// started is the config the listeners were started with.
+	started *proxyConfig
+}
+
+// reload applies the config file.  The listen addresses and the admin
+// token only take effect on restart, and settings changed through the
+// admin API are replaced by the file's.
+func (rl *reloader) reload() error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	cfg, err := loadConfig(rl.path)
+	if err != nil {
+		return err
+	}
+	if err := rl.p.apply(cfg); err != nil {
+		return err
+	}
+	old := rl.started
+	if cfg.Listen != old.Listen || (cfg.Admin == nil) != (old.Admin == nil) || (cfg.Admin != nil && *cfg.Admin != *old.Admin) {
+		log.Printf("reload: listen and admin changes take effect on restart")
+	}
+	log.Printf("reloaded %s: %d routes to %d services", rl.path, len(cfg.Routes), len(cfg.Services))
+	return nil
+}
+
+// adminHandler serves SwarmRoute's control plane (see
+// swarmroute.SwarmRoute.ControlHandler: services, endpoint states for
+// draining and banning, pheromone snapshots and the configuration) and
+//
+//	POST /reload  re-read and apply the config file, like SIGHUP
+//
+// Every request must carry "Authorization: Bearer <token>".
+func adminHandler(rl *reloader, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", rl.p.sr.ControlHandler(token))
+	mux.HandleFunc("POST /reload", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="swarmroute"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err := rl.reload(); err != nil {
+			log.Printf("reload: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// authorized reports whether r carries the bearer token.
+func authorized(r *http.Request, token string) bool {
+	got := []byte(r.Header.Get("Authorization"))
+	return token != "" && subtle.ConstantTimeCompare(got, []byte("Bearer "+token)) == 1
+}
+
+// controlHandler serves the sidecar control socket: the admin API of
+// adminHandler, through which the application pushes its services with
+// POST /services, and
+//
+//	GET /explain/{service}  the explanation of the service's last pick
+//
+// Requests need no token: the socket's file permissions decide who may
+// connect.
+func controlHandler(rl *reloader) http.Handler {
+	// The admin API requires a token, so requests are given an internal one.
+	token := rand.Text()
+	admin := adminHandler(rl, token)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+		admin.ServeHTTP(w, r)
+	})
+	mux.HandleFunc("GET /explain/{service}", func(w http.ResponseWriter, r *http.Request) {
+		ex, ok := rl.p.explained.Load(r.PathValue("service"))
+		if !ok {
+			http.Error(w, "no pick explained yet", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ex)
+	})
+	return mux
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"swarmroute"
+)
+
+// proxyConfig is the JSON layout of the -config file:
+//
+//	{
+//	  "listen": ":8080",
+//	  "settings": "swarmroute.json",
+//	  "upstream_timeout_sec": 10,
+//	  "retries": 1,
+//	  "services": {
+//	    "search": ["http://10.0.0.7:8080", "http://10.0.0.8:8080"],
+//	    "web": ["http://10.0.1.2:8080"]
+//	  },
+//	  "routes": [
+//	    {"host": "search.example.com", "service": "search"},
+//	    {"path": "/api/search/", "service": "search"},
+//	    {"path": "/", "service": "web"}
+//	  ],
+//	  "admin": {"listen": "127.0.0.1:9901", "token_file": "admin.token"},
+//	  "sidecar": {"control_socket": "/run/swarmroute/control.sock"}
+//	}
+//
+// Endpoints are absolute URLs; only their scheme and host are used.
+// Routes follow http.ServeMux patterns: host, if set, matches the request
+// host without its port, a path ending in "/" (the default) matches every
+// path below it, and the most specific route wins, with routes naming a
+// host more specific than any without.  settings is an optional
+// swarmroute.LoadConfig file tuning the selection, relative to this file.
+// admin, if set, serves the admin API (see adminHandler); its token is
+// read from token_file, relative to this file, or $SWARMROUTE_ADMIN_TOKEN.
+//
+// Listen addresses are TCP addresses or "unix:" followed by a socket
+// path.  sidecar, if set, runs the proxy next to a single application,
+// which typically reaches it on localhost or a socket: the application
+// manages the proxy through control_socket (see controlHandler), so no
+// admin port is exposed, and routes may name services that only the
+// application defines.
+type proxyConfig struct {
+	Listen             string              `json:"listen"`
+	Settings           string              `json:"settings"`
+	UpstreamTimeoutSec float64             `json:"upstream_timeout_sec"`
+	Retries            int                 `json:"retries"`
+	Services           map[string][]string `json:"services"`
+	Routes             []route             `json:"routes"`
+	Admin              *adminConfig        `json:"admin"`
+	Sidecar            *sidecarConfig      `json:"sidecar"`
+
+	// settings is the loaded Settings file.
+	settings swarmroute.Settings
+}
+
+type adminConfig struct {
+	Listen    string `json:"listen"`
+	TokenFile string `json:"token_file"`
+
+	// token is the loaded token.
+	token string
+}
+
+type sidecarConfig struct {
+	ControlSocket string `json:"control_socket"`
+}
+
+type route struct {
+	Host    string `json:"host"`
+	Path    string `json:"path"`
+	Service string `json:"service"`
+}
+
+// pattern returns the http.ServeMux pattern of r.
+func (r route) pattern() string {
+	path := r.Path
+	if path == "" {
+		path = "/"
+	}
+	return strings.ToLower(r.Host) + path
+}
+
+// loadConfig reads and validates the config file at path.
+func loadConfig(path string) (*proxyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &proxyConfig{Listen: ":8080", UpstreamTimeoutSec: 10}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.settings, err = swarmroute.LoadConfig(relative(path, cfg.Settings)); err != nil {
+		return nil, fmt.Errorf("%s: settings: %w", path, err)
+	}
+	if a := cfg.Admin; a != nil {
+		a.token = os.Getenv("SWARMROUTE_ADMIN_TOKEN")
+		if a.TokenFile != "" {
+			data, err := os.ReadFile(relative(path, a.TokenFile))
+			if err != nil {
+				return nil, fmt.Errorf("%s: admin: %w", path, err)
+			}
+			a.token = strings.TrimSpace(string(data))
+		}
+		if a.token == "" {
+			return nil, fmt.Errorf("%s: admin: no token in token_file or $SWARMROUTE_ADMIN_TOKEN", path)
+		}
+	}
+	return cfg, nil
+}
+
+// relative resolves name relative to the directory of the config file at
+// path.
+func relative(path, name string) string {
+	if name == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(path), name)
+}
+
+func (c *proxyConfig) validate() error {
+	var errs []error
+	if c.UpstreamTimeoutSec <= 0 {
+		errs = append(errs, errors.New("upstream_timeout_sec must be positive"))
+	}
+	if c.Retries < 0 {
+		errs = append(errs, errors.New("retries must not be negative"))
+	}
+	for name, eps := range c.Services {
+		if len(eps) == 0 {
+			errs = append(errs, fmt.Errorf("service %s has no endpoints", name))
+		}
+		for _, ep := range eps {
+			if u, err := url.Parse(ep); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("service %s: endpoint %q is not an absolute URL", name, ep))
+			}
+		}
+	}
+	if c.Admin != nil && c.Admin.Listen == "" {
+		errs = append(errs, errors.New("admin: listen is required"))
+	}
+	if c.Sidecar != nil && c.Sidecar.ControlSocket == "" {
+		errs = append(errs, errors.New("sidecar: control_socket is required"))
+	}
+	if len(c.Routes) == 0 {
+		errs = append(errs, errors.New("no routes"))
+	}
+	for _, r := range c.Routes {
+		if r.Service == "" {
+			errs = append(errs, fmt.Errorf("route %s: service is required", r.pattern()))
+		} else if _, ok := c.Services[r.Service]; !ok && c.Sidecar == nil {
+			errs = append(errs, fmt.Errorf("route %s: unknown service %q", r.pattern(), r.Service))
+		}
+		if r.Path != "" && !strings.HasPrefix(r.Path, "/") {
+			errs = append(errs, fmt.Errorf("route %s: path must start with /", r.pattern()))
+		}
+	}
+	return errors.Join(errs...)
+}
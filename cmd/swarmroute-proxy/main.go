@@ -0,0 +1,150 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command swarmroute-proxy is a standalone HTTP reverse proxy that routes
+// requests to services by host and path and balances them over their
+// endpoints with SwarmRoute, reporting every outcome, so the library can
+// be deployed without writing Go:
+//
+//	swarmroute-proxy -config proxy.json
+//
+// See proxyConfig for the config file.  SIGHUP or POST /reload on the
+// admin listener re-reads it without dropping connections or learned
+// state; see adminHandler for the rest of the admin API.  -pprof
+// localhost:6060 serves net/http/pprof and runtime stats, as in httpdemo.
+//
+// In sidecar mode the proxy serves one application, typically on
+// localhost or a Unix socket, and the application manages it through a
+// control socket instead of the admin listener:
+//
+//	curl --unix-socket control.sock -d '{"name":"api","endpoints":["10.0.0.1:80"]}' http://sidecar/services
+//	curl --unix-socket control.sock http://sidecar/explain/api
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"swarmroute/internal/debugserver"
+)
+
+func main() {
+	configPath := flag.String("config", "proxy.json", "the proxy config file")
+	listen := flag.String("listen", "", "listen on this address instead of the config file's")
+	pprofAddr := flag.String("pprof", "", "serve net/http/pprof and runtime stats on this address (e.g. localhost:6060)")
+	flag.Parse()
+	if *pprofAddr != "" {
+		debugserver.Serve(*pprofAddr)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *listen != "" {
+		cfg.Listen = *listen
+	}
+	p := newProxy()
+	if cfg.Sidecar != nil {
+		p.explained = new(sync.Map)
+	}
+	if err := p.apply(cfg); err != nil {
+		log.Fatal(err)
+	}
+	rl := &reloader{path: *configPath, p: p, started: cfg}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := rl.reload(); err != nil {
+				log.Printf("reload: %v", err)
+			}
+		}
+	}()
+
+	srv := &http.Server{Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	ln, err := listenAddr(cfg.Listen, 0o660)
+	if err != nil {
+		log.Fatal(err)
+	}
+	servers := []*http.Server{srv}
+	serve := func(what, addr string, h http.Handler, mode os.FileMode) {
+		s := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+		l, err := listenAddr(addr, mode)
+		if err != nil {
+			log.Fatal(err)
+		}
+		servers = append(servers, s)
+		go func() {
+			log.Printf("%s on %s", what, addr)
+			if err := s.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+	}
+	if cfg.Admin != nil {
+		serve("admin API", cfg.Admin.Listen, adminHandler(rl, cfg.Admin.token), 0o600)
+	}
+	if cfg.Sidecar != nil {
+		serve("control socket", "unix:"+cfg.Sidecar.ControlSocket, controlHandler(rl), 0o600)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, s := range servers {
+			s.Shutdown(shutdown)
+		}
+	}()
+	log.Printf("proxying %d routes to %d services on %s", len(cfg.Routes), len(cfg.Services), cfg.Listen)
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// listenAddr listens on a TCP address or, for "unix:path", on a Unix socket
+// with the given permissions, replacing a stale socket file.
+func listenAddr(addr string, mode os.FileMode) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
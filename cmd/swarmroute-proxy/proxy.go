@@ -0,0 +1,182 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"swarmroute"
+)
+
+// proxy routes requests to services by host and path and forwards them to
+// the endpoint SwarmRoute picks, reporting every outcome.
+type proxy struct {
+	sr      *swarmroute.SwarmRoute
+	handler atomic.Pointer[http.ServeMux]
+	// configured are the services of the applied config file; services
+	// pushed through the admin API are kept across reloads.
+	configured map[string]bool
+	// explained, if set, records the explanation of every first pick.
+	explained *sync.Map // service -> swarmroute.Explanation
+}
+
+func newProxy() *proxy {
+	return &proxy{sr: swarmroute.NewSwarmRoute()}
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.handler.Load().ServeHTTP(w, r)
+}
+
+// apply configures the routes, services and selection of cfg.  Endpoints
+// of services that remain keep their learned state.
+func (p *proxy) apply(cfg *proxyConfig) error {
+	mux := http.NewServeMux()
+	for _, r := range cfg.Routes {
+		if err := handle(mux, r.pattern(), p.forwarder(cfg, r.Service)); err != nil {
+			return err
+		}
+	}
+	if err := p.sr.ApplySettings(cfg.settings); err != nil {
+		return err
+	}
+	for name := range p.configured {
+		if _, ok := cfg.Services[name]; !ok {
+			p.sr.RemoveService(name)
+		}
+	}
+	p.configured = make(map[string]bool, len(cfg.Services))
+	for name, eps := range cfg.Services {
+		p.sr.SetEndpoints(name, eps)
+		p.configured[name] = true
+	}
+	p.handler.Store(mux)
+	return nil
+}
+
+// handle registers h for pattern, returning the panics of conflicting or
+// invalid patterns as errors.
+func handle(mux *http.ServeMux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("route %s: %v", pattern, v)
+		}
+	}()
+	mux.Handle(pattern, h)
+	return nil
+}
+
+// forwarder returns a reverse proxy to the endpoints of service.
+func (p *proxy) forwarder(cfg *proxyConfig, service string) http.Handler {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.ResponseHeaderTimeout = time.Duration(cfg.UpstreamTimeoutSec * float64(time.Second))
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetXForwarded()
+		},
+		Transport: &upstreamTransport{sr: p.sr, service: service, base: base, retries: cfg.Retries, explained: p.explained},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			status := http.StatusBadGateway
+			if errors.Is(err, errNoEndpoint) {
+				status = http.StatusServiceUnavailable
+			}
+			log.Printf("%s %s %s: %v", service, r.Method, r.URL.Path, err)
+			w.WriteHeader(status)
+		},
+	}
+}
+
+// errNoEndpoint marks requests that found no endpoint to go to, which
+// are answered 503 Service Unavailable rather than 502 Bad Gateway.
+var errNoEndpoint = errors.New("no endpoint")
+
+// upstreamTransport sends a request to the endpoint SwarmRoute picks and
+// reports the outcome, counting transport errors and 5xx responses as
+// failures.  Failed requests without a body and with an idempotent
+// method are retried on other endpoints up to retries times.
+type upstreamTransport struct {
+	sr        *swarmroute.SwarmRoute
+	service   string
+	base      http.RoundTripper
+	retries   int
+	explained *sync.Map
+}
+
+// pick selects the endpoint of an attempt, recording the explanation of
+// first attempts if explained is set.
+func (t *upstreamTransport) pick(tried []string) (string, error) {
+	if t.explained == nil || len(tried) > 0 {
+		return t.sr.PickEndpointExcluding(t.service, tried...)
+	}
+	ex, err := t.sr.PickEndpointExplained(t.service)
+	if err != nil {
+		return "", err
+	}
+	t.explained.Store(t.service, ex)
+	return ex.Endpoint, nil
+}
+
+func (t *upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var tried []string
+	for attempt := 0; ; attempt++ {
+		endpoint, err := t.pick(tried)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errNoEndpoint, err)
+		}
+		target, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: %w", endpoint, err)
+		}
+		out := req.Clone(req.Context())
+		out.URL.Scheme = target.Scheme
+		out.URL.Host = target.Host
+		out.Host = ""
+		t0 := time.Now()
+		resp, err := t.base.RoundTrip(out)
+		ok := err == nil && resp.StatusCode < 500
+		t.sr.ReportResult(t.service, endpoint, time.Since(t0).Seconds(), ok)
+		if ok || attempt >= t.retries || !replayable(req) || req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		tried = append(tried, endpoint)
+	}
+}
+
+// replayable reports whether req can be sent again after a failure.
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
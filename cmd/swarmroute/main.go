@@ -35,4 +35,6 @@ func main() {
 	}
 
 	fmt.Println("Selected endpoint:", addr)
+	fmt.Println()
+	fmt.Print(sr.DebugString())
 }
@@ -0,0 +1,173 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config is the set of SwarmRoute tunables.  Each field mirrors one of the
+// Set* methods; durations are expressed in seconds like the rest of the API.
+type Config struct {
+	// EvaporationRate is the fraction of pheromone evaporated per second by
+	// the background loop (SetEvaporationRate); it also sets how much error
+	// pheromone a fast success forgives.
+	EvaporationRate float64 `json:"evaporation_rate"`
+	// PosReinforce and NegReinforce scale positive and negative deposits
+	// (SetPosNegScale).
+	PosReinforce float64 `json:"pos_reinforce"`
+	NegReinforce float64 `json:"neg_reinforce"`
+	// ReqEvapRate is the per-request evaporation rate (SetRequestEvapRate).
+	ReqEvapRate float64 `json:"req_evap_rate"`
+	// BaseWeight is the additive selection weight (SetBaseWeight).
+	BaseWeight float64 `json:"base_weight"`
+	// ExploreEveryN and ExploreNegThreshold configure periodic exploration
+	// (SetPeriodicExploration).  The threshold also defines ejection.
+	ExploreEveryN       int     `json:"explore_every_n"`
+	ExploreNegThreshold float64 `json:"explore_neg_threshold"`
+	// SlowThresholdSec treats slower successes as bad (SetSlowThresholdSec).
+	SlowThresholdSec float64 `json:"slow_threshold_sec"`
+	// BadPosDecay is the positive pheromone decay on bad events (SetBadPosDecay).
+	BadPosDecay float64 `json:"bad_pos_decay"`
+	// ConnLifetimeTargetSec is the healthy connection lifetime
+	// (SetConnectionLifetimeTarget).
+	ConnLifetimeTargetSec float64 `json:"conn_lifetime_target_sec"`
+	// ReuseBonus and WarmIdleTimeoutSec configure reuse awareness (SetReuseBonus).
+	ReuseBonus         float64 `json:"reuse_bonus"`
+	WarmIdleTimeoutSec float64 `json:"warm_idle_timeout_sec"`
+	// EndpointTTLSec drops endpoints with neither a report nor a discovery
+	// confirmation for this long (SetEndpointTTL).  0 keeps them forever.
+	EndpointTTLSec float64 `json:"endpoint_ttl_sec"`
+}
+
+// Config returns the current tunables.
+func (sr *SwarmRoute) Config() Config {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	return sr.configLocked()
+}
+
+func (sr *SwarmRoute) configLocked() Config {
+	return Config{
+		EvaporationRate:       sr.evaporationRate,
+		PosReinforce:          sr.posReinforce,
+		NegReinforce:          sr.negReinforce,
+		ReqEvapRate:           sr.reqEvapRate,
+		BaseWeight:            sr.baseWeight,
+		ExploreEveryN:         sr.exploreEveryN,
+		ExploreNegThreshold:   sr.exploreNegThreshold,
+		SlowThresholdSec:      sr.slowThresholdSec,
+		BadPosDecay:           sr.alphaBad,
+		ConnLifetimeTargetSec: sr.connLifetimeTarget.Seconds(),
+		ReuseBonus:            sr.reuseBonus,
+		WarmIdleTimeoutSec:    sr.warmIdleTimeout.Seconds(),
+		EndpointTTLSec:        sr.endpointTTL.Seconds(),
+	}
+}
+
+// ApplyConfig replaces every tunable with the values in c in a single
+// critical section, so concurrent picks and reports never observe a mix of
+// old and new settings.  Values are clamped like the individual setters do.
+// Hot reload typically starts from the current configuration:
+//
+//	cfg := sr.Config()
+//	cfg.ReqEvapRate, cfg.ExploreEveryN = 0.001, 200
+//	sr.ApplyConfig(cfg)
+func (sr *SwarmRoute) ApplyConfig(c Config) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.setConfigLocked(c)
+}
+
+// setConfigLocked applies c with the setters' clamping.  Callers must hold
+// sr.mu.
+func (sr *SwarmRoute) setConfigLocked(c Config) {
+	sr.evaporationRate = clamp01(c.EvaporationRate)
+	sr.posReinforce = max(c.PosReinforce, 0)
+	sr.negReinforce = max(c.NegReinforce, 0)
+	sr.reqEvapRate = clamp01(c.ReqEvapRate)
+	sr.baseWeight = max(c.BaseWeight, 0)
+	sr.exploreEveryN = max(c.ExploreEveryN, 0)
+	sr.exploreNegThreshold = max(c.ExploreNegThreshold, 0)
+	sr.slowThresholdSec = max(c.SlowThresholdSec, 0)
+	sr.alphaBad = clamp01(c.BadPosDecay)
+	sr.connLifetimeTarget = max(secondsToDuration(c.ConnLifetimeTargetSec), 0)
+	sr.reuseBonus = max(c.ReuseBonus, 0)
+	sr.warmIdleTimeout = max(secondsToDuration(c.WarmIdleTimeoutSec), 0)
+	sr.endpointTTL = max(secondsToDuration(c.EndpointTTLSec), 0)
+}
+
+// paramsLocked returns the tunables in effect for service: its override if
+// one is set, the global configuration otherwise, adjusted by self-tuning
+// if enabled.  Callers must hold sr.mu.
+func (sr *SwarmRoute) paramsLocked(service string) Config {
+	c := sr.configuredParamsLocked(service)
+	if sr.autoTune != nil {
+		sr.autoTune.adjust(service, &c)
+	}
+	return c
+}
+
+// configuredParamsLocked is paramsLocked without self-tuning.
+func (sr *SwarmRoute) configuredParamsLocked(service string) Config {
+	if c, ok := sr.overrides[service]; ok {
+		return c
+	}
+	return sr.configLocked()
+}
+
+// SetServiceConfig overrides the global tunables for one service; the
+// service's endpoints are then scored, reinforced, evaporated and ejected
+// with c.  The override may be set before the service is registered and
+// survives RemoveService.  It returns an error, and changes nothing, if c
+// is invalid (see Config.Validate).
+func (sr *SwarmRoute) SetServiceConfig(service string, c Config) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("invalid swarmroute config for service %s: %w", service, err)
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.overrides == nil {
+		sr.overrides = make(map[string]Config)
+	}
+	sr.overrides[service] = c
+	return nil
+}
+
+// ClearServiceConfig removes the override of a service, which reverts to
+// the global tunables.
+func (sr *SwarmRoute) ClearServiceConfig(service string) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	delete(sr.overrides, service)
+}
+
+// ServiceConfigs returns the per-service overrides.
+func (sr *SwarmRoute) ServiceConfigs() map[string]Config {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	out := make(map[string]Config, len(sr.overrides))
+	for svc, c := range sr.overrides {
+		out[svc] = c
+	}
+	return out
+}
+
+func clamp01(v float64) float64 { return min(max(v, 0), 1) }
+
+func secondsToDuration(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
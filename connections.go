@@ -0,0 +1,203 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"fmt"
+	"time"
+)
+
+// CloseReason describes why a long-lived connection (WebSocket, gRPC stream,
+// etc.) ended.  It is reported back via ReportConnectionClosed.
+type CloseReason int
+
+const (
+	// CloseNormal means the connection ended cleanly (client done, idle close).
+	CloseNormal CloseReason = iota
+	// CloseGoingAway means the server asked the client to reconnect elsewhere
+	// (e.g. GOAWAY during a deploy).  It is neither rewarded nor penalized.
+	CloseGoingAway
+	// CloseError means the connection was reset or failed.
+	CloseError
+)
+
+// String returns a short, lower-case name for the reason.
+func (r CloseReason) String() string {
+	switch r {
+	case CloseNormal:
+		return "normal"
+	case CloseGoingAway:
+		return "going_away"
+	case CloseError:
+		return "error"
+	default:
+		return fmt.Sprintf("CloseReason(%d)", int(r))
+	}
+}
+
+// SetConnectionLifetimeTarget sets the lifetime a healthy long-lived
+// connection is expected to reach.  Connections that close normally earn
+// positive pheromone in proportion to lifetime/target (capped at 1), and
+// connections that end in an error before reaching the target are treated
+// as bad events.  Set to <=0 to reward every normal close fully and
+// penalize every error fully.
+func (sr *SwarmRoute) SetConnectionLifetimeTarget(d time.Duration) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	sr.connLifetimeTarget = d
+}
+
+// PickConnection selects an endpoint for a new long-lived connection.  In
+// this connection-balancing mode the unit of balancing is the connection,
+// not the request: the endpoint with the lowest (liveConns+1)/weight score is
+// chosen, which equalizes live connection counts across equally healthy
+// endpoints while still steering away from endpoints with poor pheromone.
+// Ties are broken uniformly at random.  The caller must pair every successful
+// PickConnection with a ReportConnectionClosed once the connection ends.
+func (sr *SwarmRoute) PickConnection(service string) (string, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	eps, ok := sr.services[service]
+	if !ok || len(eps) == 0 {
+		return "", fmt.Errorf("no endpoints for service %s", service)
+	}
+	if eps = selectable(eps, sr.now()); len(eps) == 0 {
+		return "", fmt.Errorf("no available endpoints for service %s (all drained, banned or in maintenance)", service)
+	}
+	p := sr.paramsLocked(service)
+	best := make([]*Endpoint, 0, 1)
+	bestScore := 0.0
+	for _, ep := range eps {
+		w := sr.weightLocked(&p, ep)
+		if w <= 0 {
+			w = 1e-9
+		}
+		score := float64(ep.conns+1) / w
+		switch {
+		case len(best) == 0 || score < bestScore:
+			best = append(best[:0], ep)
+			bestScore = score
+		case score == bestScore:
+			best = append(best, ep)
+		}
+	}
+	ep := best[sr.intn(len(best))]
+	ep.conns++
+	return ep.Address, nil
+}
+
+// ReportConnectionClosed releases a connection opened via PickConnection and
+// feeds its lifetime and termination reason back into the pheromone tables.
+// Normal closes deposit positive pheromone scaled by lifetime; errors before
+// the lifetime target deposit negative pheromone, the full NegReinforce if
+// no target is set.  GOAWAY-style closes only release the connection slot.
+func (sr *SwarmRoute) ReportConnectionClosed(service, endpoint string, lifetime time.Duration, reason CloseReason) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	p := sr.paramsLocked(service)
+	for _, ep := range sr.services[service] {
+		if ep.Address != endpoint {
+			continue
+		}
+		if ep.conns > 0 {
+			ep.conns--
+		}
+		frac := 1.0
+		if target := secondsToDuration(p.ConnLifetimeTargetSec); target > 0 {
+			frac = float64(lifetime) / float64(target)
+			if frac > 1 {
+				frac = 1
+			}
+		}
+		switch reason {
+		case CloseNormal:
+			ep.Pheromones["latency"].Pos += p.PosReinforce * frac
+			ep.Pheromones["error"].Neg *= (1 - p.EvaporationRate)
+		case CloseError:
+			// Without a target frac is 1, yet an error is still fully bad.
+			bad := 1.0
+			if p.ConnLifetimeTargetSec > 0 {
+				bad = 1 - frac
+			}
+			if bad > 0 {
+				ep.Pheromones["error"].Neg += p.NegReinforce * bad
+				if p.BadPosDecay > 0 {
+					ep.Pheromones["latency"].Pos *= (1 - p.BadPosDecay)
+				}
+			}
+		}
+		return
+	}
+}
+
+// Connections returns the live connection count per endpoint for a service,
+// as tracked by PickConnection and ReportConnectionClosed.
+func (sr *SwarmRoute) Connections(service string) map[string]int {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	out := make(map[string]int, len(sr.services[service]))
+	for _, ep := range sr.services[service] {
+		out[ep.Address] = ep.conns
+	}
+	return out
+}
+
+// SetReuseBonus enables connection-reuse awareness.  Switching endpoints has a
+// cost (new TCP+TLS handshake, cold pool), so endpoints believed to hold warm
+// pooled connections get their selection weight multiplied by (1 + bonus).
+// The bonus is multiplicative, so it only tips the balance between endpoints
+// with comparable pheromone and cannot rescue an endpoint with heavy negative
+// pheromone.  An endpoint is considered warm for idleTimeout after its last
+// successful call or after a ReportPoolState with idle connections; match it
+// to the client's idle-connection timeout (e.g. http.Transport.IdleConnTimeout).
+// A bonus <=0 disables reuse awareness.
+func (sr *SwarmRoute) SetReuseBonus(bonus float64, idleTimeout time.Duration) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if bonus < 0 {
+		bonus = 0
+	}
+	if idleTimeout < 0 {
+		idleTimeout = 0
+	}
+	sr.reuseBonus = bonus
+	sr.warmIdleTimeout = idleTimeout
+}
+
+// ReportPoolState tells SwarmRoute how many idle pooled connections the
+// caller currently holds to an endpoint.  A positive count marks the endpoint
+// warm for the configured idle timeout; zero marks it cold immediately (e.g.
+// after the pool was flushed).
+func (sr *SwarmRoute) ReportPoolState(service, endpoint string, idleConns int) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	p := sr.paramsLocked(service)
+	for _, ep := range sr.services[service] {
+		if ep.Address != endpoint {
+			continue
+		}
+		if idleConns > 0 {
+			ep.warmUntil = sr.now().Add(secondsToDuration(p.WarmIdleTimeoutSec))
+		} else {
+			ep.warmUntil = time.Time{}
+		}
+		return
+	}
+}
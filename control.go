@@ -0,0 +1,168 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ControlHandler returns an HTTP handler for operating a running SwarmRoute.
+// Every request must carry "Authorization: Bearer <token>"; an empty token
+// rejects all requests.
+//
+//	GET    /services                             service -> endpoint addresses
+//	POST   /services                             {"name","endpoints"}: SetEndpoints
+//	DELETE /services/{name}                      RemoveService
+//	GET    /services/{name}/endpoints            address -> state
+//	POST   /services/{name}/endpoints            {"addr"}: AddEndpoint
+//	DELETE /services/{name}/endpoints?addr=ADDR  RemoveEndpoint
+//	POST   /services/{name}/endpoints/state      {"addr","state"}: SetEndpointState
+//	GET    /pheromones                           PheromoneSnapshot
+//	GET    /config                               Config
+//	PATCH  /config                               partial Config: UpdateConfig, omitted fields kept
+//
+// Mutating requests answer 204 No Content on success.  Mount it under a
+// prefix with http.StripPrefix if needed, and serve it on an address that is
+// not reachable by ordinary clients.
+func (sr *SwarmRoute) ControlHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /services", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, sr.Services()) })
+	mux.HandleFunc("POST /services", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name      string   `json:"name"`
+			Endpoints []string `json:"endpoints"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if req.Name == "" {
+			http.Error(w, "name is required", http.StatusBadRequest)
+			return
+		}
+		sr.SetEndpoints(req.Name, req.Endpoints)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /services/{name}", func(w http.ResponseWriter, r *http.Request) {
+		sr.RemoveService(r.PathValue("name"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /services/{name}/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		states := sr.EndpointStates(r.PathValue("name"))
+		out := make(map[string]string, len(states))
+		for addr, st := range states {
+			out[addr] = st.String()
+		}
+		writeJSON(w, out)
+	})
+	mux.HandleFunc("POST /services/{name}/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Addr string `json:"addr"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if req.Addr == "" {
+			http.Error(w, "addr is required", http.StatusBadRequest)
+			return
+		}
+		sr.AddEndpoint(r.PathValue("name"), req.Addr)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /services/{name}/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminResult(w, sr.RemoveEndpoint(r.PathValue("name"), r.URL.Query().Get("addr")))
+	})
+	mux.HandleFunc("POST /services/{name}/endpoints/state", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Addr  string `json:"addr"`
+			State string `json:"state"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		st, err := ParseEndpointState(req.State)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeAdminResult(w, sr.SetEndpointState(r.PathValue("name"), req.Addr, st))
+	})
+	mux.HandleFunc("GET /pheromones", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, sr.PheromoneSnapshot()) })
+	mux.HandleFunc("GET /config", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, sr.Config()) })
+	mux.HandleFunc("PATCH /config", func(w http.ResponseWriter, r *http.Request) {
+		// Read the body before locking, and merge it into the current
+		// configuration under the lock so concurrent PATCHes compose.
+		var patch json.RawMessage
+		if !decodeBody(w, r, &patch) {
+			return
+		}
+		cfg, err := sr.UpdateConfig(func(c *Config) error {
+			dec := json.NewDecoder(bytes.NewReader(patch))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(c); err != nil {
+				return fmt.Errorf("bad request body: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, cfg)
+	})
+	return requireBearer(token, mux)
+}
+
+// requireBearer rejects requests whose bearer token does not match token.
+func requireBearer(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if token == "" || subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="swarmroute"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decodeBody decodes a JSON request body into v, answering 400 on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		http.Error(w, "bad request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeAdminResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrUnknownEndpoint):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
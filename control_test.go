@@ -0,0 +1,82 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestControlHandlerManagesEndpoints(t *testing.T) {
+	sr := NewSwarmRoute()
+	h := sr.ControlHandler("s3cret")
+
+	do := func(method, path, body, token string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := do(http.MethodPost, "/services", `{"name":"api","endpoints":["a","b"]}`, "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("bad token: status %d, want 401", code)
+	}
+	steps := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/services", `{"name":"api","endpoints":["a","b"]}`, http.StatusNoContent},
+		{http.MethodPost, "/services/api/endpoints", `{"addr":"c"}`, http.StatusNoContent},
+		{http.MethodPost, "/services/api/endpoints/state", `{"addr":"a","state":"banned"}`, http.StatusNoContent},
+		{http.MethodPost, "/services/api/endpoints/state", `{"addr":"b","state":"drained"}`, http.StatusNoContent},
+		{http.MethodPost, "/services/api/endpoints/state", `{"addr":"x","state":"drained"}`, http.StatusNotFound},
+		{http.MethodPatch, "/config", `{"base_weight":0.5,"bogus":1}`, http.StatusBadRequest},
+		{http.MethodPatch, "/config", `{"base_weight":-1}`, http.StatusBadRequest},
+		{http.MethodPatch, "/config", `{"base_weight":0.5}`, http.StatusOK},
+	}
+	for _, s := range steps {
+		if code := do(s.method, s.path, s.body, "s3cret"); code != s.want {
+			t.Fatalf("%s %s: status %d, want %d", s.method, s.path, code, s.want)
+		}
+	}
+	for i := 0; i < 200; i++ {
+		if ep, err := sr.PickEndpoint("api"); err != nil || ep != "c" {
+			t.Fatalf("expected only active endpoint c to be picked, got %q %v", ep, err)
+		}
+	}
+	if got := sr.Config().BaseWeight; got != 0.5 {
+		t.Fatalf("PATCH /config: base weight %v, want 0.5", got)
+	}
+
+	if code := do(http.MethodDelete, "/services/api/endpoints?addr=c", "", "s3cret"); code != http.StatusNoContent {
+		t.Fatalf("DELETE endpoint: status %d", code)
+	}
+	if _, err := sr.PickEndpoint("api"); err == nil {
+		t.Fatal("expected an error with every remaining endpoint drained or banned")
+	}
+	if code := do(http.MethodDelete, "/services/api", "", "s3cret"); code != http.StatusNoContent {
+		t.Fatalf("DELETE service: status %d", code)
+	}
+	if len(sr.Services()) != 0 {
+		t.Fatalf("expected no services, got %v", sr.Services())
+	}
+}
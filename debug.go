@@ -0,0 +1,146 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+)
+
+// DebugHandler returns a read-only HTTP handler exposing routing state:
+//
+//	GET /services    service -> endpoint addresses
+//	GET /pheromones  PheromoneSnapshot
+//	GET /stats       Counters
+//	GET /config      Config
+//	GET /            HTML table of all of the above
+//
+// Mount it under a prefix with http.StripPrefix if needed.
+func (sr *SwarmRoute) DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, sr.Services()) })
+	mux.HandleFunc("/pheromones", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, sr.PheromoneSnapshot()) })
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, sr.Counters()) })
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, sr.Config()) })
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugPage.Execute(w, sr.debugView())
+	})
+	return mux
+}
+
+// ServeDebug serves DebugHandler on addr.  Like http.ListenAndServe it
+// blocks, so run it in its own goroutine:
+//
+//	go func() { log.Println(sr.ServeDebug("localhost:6061")) }()
+func (sr *SwarmRoute) ServeDebug(addr string) error {
+	return http.ListenAndServe(addr, sr.DebugHandler())
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+type debugEndpointRow struct {
+	Address     string
+	State       string // administrative state, or "maintenance"
+	Ejected     bool
+	Pos, Neg    float64
+	Weight      float64
+	Share       float64 // share of the service's total weight, in percent
+	Picks       uint64
+	SuccessRate float64
+	InFlight    int
+}
+
+type debugServiceView struct {
+	Name      string
+	Endpoints []debugEndpointRow
+}
+
+type debugPageView struct {
+	Config   Config
+	Services []debugServiceView
+}
+
+// debugView gathers a consistent view of all services under one lock.
+func (sr *SwarmRoute) debugView() debugPageView {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	names := make([]string, 0, len(sr.services))
+	for name := range sr.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	view := debugPageView{Config: sr.configLocked(), Services: make([]debugServiceView, 0, len(names))}
+	for _, name := range names {
+		eps := sr.services[name]
+		p := sr.paramsLocked(name)
+		rows := make([]debugEndpointRow, len(eps))
+		total := 0.0
+		now := sr.now()
+		for i, ep := range eps {
+			c := EndpointCounters{Successes: ep.successes, Failures: ep.failures}
+			rows[i] = debugEndpointRow{
+				Address:     ep.Address,
+				State:       ep.state.String(),
+				Ejected:     ep.ejected,
+				Pos:         ep.Pheromones["latency"].Pos,
+				Neg:         ep.Pheromones["error"].Neg,
+				Weight:      sr.weightLocked(&p, ep),
+				Picks:       ep.picks,
+				SuccessRate: 100 * c.SuccessRate(),
+				InFlight:    ep.inFlight,
+			}
+			if ep.state == StateActive && !ep.available(now) {
+				rows[i].State = "maintenance"
+			}
+			total += rows[i].Weight
+		}
+		for i := range rows {
+			if total > 0 {
+				rows[i].Share = 100 * rows[i].Weight / total
+			}
+		}
+		view.Services = append(view.Services, debugServiceView{Name: name, Endpoints: rows})
+	}
+	return view
+}
+
+var debugPage = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html><head><title>SwarmRoute</title>
+<style>body{font-family:sans-serif}table{border-collapse:collapse;margin-bottom:1em}td,th{border:1px solid #ccc;padding:2px 8px;text-align:right}td:first-child,th:first-child{text-align:left}</style>
+</head><body>
+<h1>SwarmRoute</h1>
+{{range .Services}}<h2>{{.Name}}</h2>
+<table><tr><th>endpoint</th><th>state</th><th>pos</th><th>neg</th><th>weight</th><th>share %</th><th>picks</th><th>success %</th><th>in-flight</th></tr>
+{{range .Endpoints}}<tr><td>{{.Address}}</td><td>{{.State}}{{if .Ejected}} (ejected){{end}}</td><td>{{printf "%.3f" .Pos}}</td><td>{{printf "%.3f" .Neg}}</td><td>{{printf "%.4f" .Weight}}</td><td>{{printf "%.1f" .Share}}</td><td>{{.Picks}}</td><td>{{printf "%.1f" .SuccessRate}}</td><td>{{.InFlight}}</td></tr>
+{{end}}</table>
+{{end}}<h2>config</h2>
+<pre>{{printf "%+v" .Config}}</pre>
+<p>JSON: <a href="services">services</a> · <a href="pheromones">pheromones</a> · <a href="stats">stats</a> · <a href="config">config</a></p>
+</body></html>
+`))
@@ -0,0 +1,87 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandlerServesJSONAndHTML(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.AddService("api", []string{"http://a:8080", "http://b:8080"})
+	sr.ReportResult("api", "http://a:8080", 0.02, true)
+	h := sr.DebugHandler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, rec.Code)
+		}
+		return rec
+	}
+
+	var services map[string][]string
+	if err := json.Unmarshal(get("/services").Body.Bytes(), &services); err != nil || len(services["api"]) != 2 {
+		t.Fatalf("unexpected /services: %v %v", services, err)
+	}
+	var snap map[string]map[string]Pheromone
+	if err := json.Unmarshal(get("/pheromones").Body.Bytes(), &snap); err != nil || snap["api"]["http://a:8080"].Pos <= 0 {
+		t.Fatalf("unexpected /pheromones: %v %v", snap, err)
+	}
+	if body := get("/").Body.String(); !strings.Contains(body, "http://b:8080") {
+		t.Fatalf("expected HTML view to list endpoints, got:\n%s", body)
+	}
+}
+
+func TestFprintRendersTablesAndHeatmap(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.AddService("api", []string{"http://a:8080", "http://b:8080"})
+	sr.ReportResult("api", "http://a:8080", 0.02, true)
+	_ = sr.SetEndpointState("api", "http://b:8080", StateDrained)
+
+	dump := sr.DebugString()
+	for _, want := range []string{"service api", "http://a:8080", "drained", "[####"} {
+		if !strings.Contains(dump, want) {
+			t.Fatalf("expected dump to contain %q, got:\n%s", want, dump)
+		}
+	}
+
+	h := sr.RecordHistory(0, 8)
+	h.Sample()
+	sr.AddEndpoint("api", "http://c:8080")
+	for range 3 {
+		sr.ReportResult("api", "http://a:8080", 0.02, false)
+		h.Sample()
+	}
+	var b strings.Builder
+	if err := h.Fprint(&b, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "http://a:8080  | -*@|") || !strings.Contains(b.String(), "http://c:8080  |    |") {
+		t.Fatalf("unexpected heatmap:\n%s", b.String())
+	}
+	b.Reset()
+	_ = h.Fprint(&b, 2)
+	if !strings.Contains(b.String(), "|.#|") {
+		t.Fatalf("expected samples averaged into 2 columns:\n%s", b.String())
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// shareBarWidth is the width of the selection share bars in Fprint.
+const shareBarWidth = 20
+
+// heatmapShades are the heatmap cells from no to maximal error pheromone.
+const heatmapShades = " .:-=+*#%@"
+
+// Fprint writes a human-readable dump of every service to w: one table per
+// service listing each endpoint's state, pheromones, selection weight and a
+// bar of its share of the service's total weight.  The output is plain
+// text, suitable for terminals and for pasting into incident docs.
+func (sr *SwarmRoute) Fprint(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, svc := range sr.debugView().Services {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "service %s\n", svc.Name)
+		fmt.Fprintln(tw, "  ENDPOINT\tSTATE\tPOS\tNEG\tWEIGHT\tPICKS\tSUCCESS\tSHARE")
+		for _, ep := range svc.Endpoints {
+			state := ep.State
+			if ep.Ejected {
+				state += " (ejected)"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%.3f\t%.3f\t%.4f\t%d\t%.1f%%\t%s %5.1f%%\n",
+				ep.Address, state, ep.Pos, ep.Neg, ep.Weight, ep.Picks, ep.SuccessRate, bar(ep.Share/100, shareBarWidth), ep.Share)
+		}
+	}
+	return tw.Flush()
+}
+
+// DebugString returns the output of Fprint.
+func (sr *SwarmRoute) DebugString() string {
+	var b strings.Builder
+	_ = sr.Fprint(&b)
+	return b.String()
+}
+
+// bar renders frac (0..1) as a fixed-width ASCII bar.
+func bar(frac float64, width int) string {
+	n := int(min(max(frac, 0), 1)*float64(width) + 0.5)
+	return "[" + strings.Repeat("#", n) + strings.Repeat(" ", width-n) + "]"
+}
+
+// Fprint writes an ASCII heatmap of the recorded error pheromone to w: one
+// row per endpoint, one column per sample with the newest on the right,
+// shaded from ' ' (none) to '@' (the highest value in the history); cells
+// before an endpoint's first sample are blank as well.  If width > 0 and
+// there are more samples than width, adjacent samples are averaged into
+// width columns.
+func (h *History) Fprint(w io.Writer, width int) error {
+	all := h.All()
+	services := make([]string, 0, len(all))
+	for svc := range all {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+	peak, samples := 0.0, 0
+	for _, eps := range all {
+		for _, series := range eps {
+			samples = max(samples, len(series))
+			for _, s := range series {
+				peak = max(peak, s.Neg)
+			}
+		}
+	}
+	cols := samples
+	if width > 0 {
+		cols = min(cols, width)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "error pheromone heatmap, %q = 0 .. %q = %.3f\n", heatmapShades[0], heatmapShades[len(heatmapShades)-1], peak)
+	for _, svc := range services {
+		addrs := make([]string, 0, len(all[svc]))
+		for addr := range all[svc] {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+		fmt.Fprintf(tw, "service %s\n", svc)
+		for _, addr := range addrs {
+			fmt.Fprintf(tw, "  %s\t|%s|\n", addr, heatmapRow(all[svc][addr], samples, cols, peak))
+		}
+	}
+	return tw.Flush()
+}
+
+// heatmapRow renders series into cols cells.  The row is right-aligned in
+// a row of total samples, so endpoints registered later line up by time;
+// each cell averages total/cols samples.
+func heatmapRow(series []HistorySample, total, cols int, peak float64) string {
+	var b strings.Builder
+	n := len(series)
+	for c := range cols {
+		lo, hi := c*total/cols, (c+1)*total/cols
+		lo, hi = max(lo-(total-n), 0), hi-(total-n)
+		if hi <= 0 {
+			b.WriteByte(' ')
+			continue
+		}
+		sum := 0.0
+		for _, s := range series[lo:hi] {
+			sum += s.Neg
+		}
+		level := 0
+		if peak > 0 {
+			level = int(sum / float64(hi-lo) / peak * float64(len(heatmapShades)-1))
+		}
+		b.WriteByte(heatmapShades[level])
+	}
+	return b.String()
+}
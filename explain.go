@@ -0,0 +1,144 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+// Explanation describes a single routing decision.
+type Explanation struct {
+	Service  string `json:"service"`
+	Endpoint string `json:"endpoint"`
+	// Explored is true if the pick was a periodic forced exploration, in
+	// which case the candidates were sampled uniformly instead of by weight.
+	Explored   bool                 `json:"explored"`
+	Candidates []CandidateBreakdown `json:"candidates"`
+}
+
+// CandidateBreakdown is the weight breakdown of one endpoint at decision
+// time.  Weight = Static * (Pos + Base) / (1 + Neg) * ReuseMultiplier *
+// SlowStartMultiplier.
+type CandidateBreakdown struct {
+	Endpoint        string  `json:"endpoint"`
+	Pos             float64 `json:"pos"`
+	Neg             float64 `json:"neg"`
+	Base            float64 `json:"base"`
+	Static          float64 `json:"static"`
+	ReuseMultiplier float64 `json:"reuse_multiplier"`
+	// SlowStartMultiplier ramps from 0 to 1 after a maintenance window.
+	SlowStartMultiplier float64 `json:"slow_start_multiplier"`
+	Weight              float64 `json:"weight"`
+	// Probability is the chance this endpoint had of being chosen by this
+	// pick; it is 0 for excluded endpoints.
+	Probability float64 `json:"probability"`
+	// Ejected reports whether the error pheromone is above the exploration
+	// threshold.
+	Ejected bool `json:"ejected"`
+	// Excluded is empty for eligible endpoints, otherwise the reason the
+	// endpoint could not be chosen: "drained", "banned", "maintenance", or
+	// "ejected" (only for exploration picks).
+	Excluded string `json:"excluded,omitempty"`
+}
+
+// PickEndpointExplained selects an endpoint exactly like PickEndpoint (it
+// counts as a pick and must be followed by ReportResult) and additionally
+// returns the weight breakdown of every endpoint of the service.
+func (sr *SwarmRoute) PickEndpointExplained(service string) (Explanation, error) {
+	_, ex, err := sr.pick(service, nil, true)
+	if err != nil {
+		return Explanation{}, err
+	}
+	return *ex, nil
+}
+
+// SetEndpointWeight sets a static weight multiplier for an endpoint, e.g. to
+// send proportionally more traffic to larger instances.  The default is 1;
+// negative weights are treated as 0, which removes the endpoint from
+// weighted sampling (exploration may still pick it).  It returns
+// ErrUnknownEndpoint if the endpoint is not registered.
+func (sr *SwarmRoute) SetEndpointWeight(service, endpoint string, weight float64) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	ep := sr.findLocked(service, endpoint)
+	if ep == nil {
+		return ErrUnknownEndpoint
+	}
+	if weight < 0 {
+		weight = 0
+	}
+	ep.static = weight
+	return nil
+}
+
+// explainLocked builds the Explanation for a pick of chosen among all.
+// Callers must hold sr.mu.
+func (sr *SwarmRoute) explainLocked(p *Config, service string, all []*Endpoint, chosen *Endpoint, explored bool) *Explanation {
+	ex := &Explanation{Service: service, Endpoint: chosen.Address, Explored: explored}
+	now := sr.now()
+	total := 0.0
+	anyHealthy := false
+	for _, ep := range all {
+		c := CandidateBreakdown{
+			Endpoint:        ep.Address,
+			Pos:             ep.Pheromones["latency"].Pos,
+			Neg:             ep.Pheromones["error"].Neg,
+			Base:            p.BaseWeight,
+			Static:          ep.static,
+			ReuseMultiplier: 1,
+			Weight:          sr.weightLocked(p, ep),
+		}
+		c.Ejected = c.Neg > p.ExploreNegThreshold
+		if p.ReuseBonus > 0 && now.Before(ep.warmUntil) {
+			c.ReuseMultiplier = 1 + p.ReuseBonus
+		}
+		inMaintenance, ramp := ep.maintenance(now)
+		c.SlowStartMultiplier = ramp
+		switch {
+		case ep.state != StateActive:
+			c.Excluded = ep.state.String()
+		case inMaintenance:
+			c.Excluded = "maintenance"
+		default:
+			total += c.Weight
+			anyHealthy = anyHealthy || !c.Ejected
+		}
+		ex.Candidates = append(ex.Candidates, c)
+	}
+	// Exploration samples uniformly among non-ejected endpoints, falling
+	// back to every selectable endpoint when all are ejected.
+	if explored && anyHealthy {
+		for i := range ex.Candidates {
+			if c := &ex.Candidates[i]; c.Excluded == "" && c.Ejected {
+				c.Excluded = "ejected"
+			}
+		}
+	}
+	eligible := 0
+	for _, c := range ex.Candidates {
+		if c.Excluded == "" {
+			eligible++
+		}
+	}
+	for i := range ex.Candidates {
+		c := &ex.Candidates[i]
+		switch {
+		case c.Excluded != "":
+		case explored:
+			c.Probability = 1 / float64(eligible)
+		case total > 0:
+			c.Probability = c.Weight / total
+		}
+	}
+	return ex
+}
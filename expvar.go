@@ -0,0 +1,40 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import "expvar"
+
+// ExpvarState is the JSON document published by PublishExpvar.
+type ExpvarState struct {
+	Config     Config                                 `json:"config"`
+	Pheromones map[string]map[string]Pheromone        `json:"pheromones"`
+	Counters   map[string]map[string]EndpointCounters `json:"counters"`
+}
+
+// ExpvarState returns the routing state published under expvar.
+func (sr *SwarmRoute) ExpvarState() ExpvarState {
+	return ExpvarState{Config: sr.Config(), Pheromones: sr.PheromoneSnapshot(), Counters: sr.Counters()}
+}
+
+// PublishExpvar publishes the pheromone snapshot, pick counters and config
+// under the given expvar name, so the standard /debug/vars endpoint shows
+// routing state without extra dependencies.  The value is computed on each
+// read.  Like expvar.Publish, it panics if name is already published, so
+// give each SwarmRoute instance its own name (e.g. "swarmroute").
+func (sr *SwarmRoute) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return sr.ExpvarState() }))
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Router is what the balancer needs to route a service: *swarmroute.SwarmRoute
+// and every harness.Strategy implement it.
+type Router interface {
+	AddService(name string, endpoints []string)
+	PickEndpoint(service string) (string, error)
+	ReportResult(service, endpoint string, latencySec float64, success bool)
+}
+
+// NewBalancerBuilder returns a gRPC load balancer that routes every RPC of a
+// client connection to the backend r picks for service and reports the
+// outcome: the time from pick to completion and whether the RPC succeeded.
+// Register it with balancer.Register during initialization and select it
+// by name in the service config:
+//
+//	balancer.Register(grpc.NewBalancerBuilder("swarmroute", sr, "api"))
+//	cc, err := ggrpc.NewClient("dns:///api:8080",
+//		ggrpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"swarmroute":{}}]}`), ...)
+//
+// The service's endpoints are the addresses of the ready backends; they
+// are updated with SetEndpoints if r has it, which keeps learned state,
+// otherwise with AddService.  r must be safe for concurrent use if RPCs
+// are.
+func NewBalancerBuilder(name string, r Router, service string) balancer.Builder {
+	return base.NewBalancerBuilder(name, &pickerBuilder{r: r, service: service}, base.Config{})
+}
+
+type pickerBuilder struct {
+	r       Router
+	service string
+}
+
+func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	subConns := make(map[string]balancer.SubConn, len(info.ReadySCs))
+	addrs := make([]string, 0, len(info.ReadySCs))
+	for sc, sci := range info.ReadySCs {
+		subConns[sci.Address.Addr] = sc
+		addrs = append(addrs, sci.Address.Addr)
+	}
+	if s, ok := b.r.(interface{ SetEndpoints(string, []string) }); ok {
+		s.SetEndpoints(b.service, addrs)
+	} else {
+		b.r.AddService(b.service, addrs)
+	}
+	return &picker{pickerBuilder: b, subConns: subConns}
+}
+
+type picker struct {
+	*pickerBuilder
+	subConns map[string]balancer.SubConn
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	addr, err := p.r.PickEndpoint(p.service)
+	if err != nil {
+		return balancer.PickResult{}, status.Errorf(codes.Unavailable, "swarmroute: %v", err)
+	}
+	sc, ok := p.subConns[addr]
+	if !ok {
+		return balancer.PickResult{}, status.Errorf(codes.Unavailable, "swarmroute: picked %s, which is not ready", addr)
+	}
+	start := time.Now()
+	var once sync.Once
+	return balancer.PickResult{SubConn: sc, Done: func(di balancer.DoneInfo) {
+		once.Do(func() { p.r.ReportResult(p.service, addr, time.Since(start).Seconds(), di.Err == nil) })
+	}}, nil
+}
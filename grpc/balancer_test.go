@@ -0,0 +1,108 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// alternator picks the ready endpoints in turn and records every report.
+type alternator struct {
+	mu        sync.Mutex
+	endpoints []string
+	next      int
+	reports   map[string][]bool
+}
+
+func (a *alternator) AddService(_ string, endpoints []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.endpoints = slices.Sorted(slices.Values(endpoints))
+}
+
+func (a *alternator) PickEndpoint(string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.next++
+	return a.endpoints[a.next%len(a.endpoints)], nil
+}
+
+func (a *alternator) ReportResult(_, endpoint string, _ float64, success bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reports[endpoint] = append(a.reports[endpoint], success)
+}
+
+func TestBalancer(t *testing.T) {
+	// healthy serves the health service; the other backend does not, so
+	// its calls fail with Unimplemented.
+	var addrs []string
+	for _, healthy := range []bool{true, false} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := ggrpc.NewServer()
+		if healthy {
+			healthpb.RegisterHealthServer(s, health.NewServer())
+		}
+		go s.Serve(lis)
+		defer s.Stop()
+		addrs = append(addrs, lis.Addr().String())
+	}
+	good, bad := addrs[0], addrs[1]
+
+	a := &alternator{reports: make(map[string][]bool)}
+	balancer.Register(NewBalancerBuilder("swarmroute-test", a, "svc"))
+	res := manual.NewBuilderWithScheme("test")
+	res.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: good}, {Addr: bad}}})
+	cc, err := ggrpc.NewClient("test:///svc", ggrpc.WithResolvers(res),
+		ggrpc.WithTransportCredentials(insecure.NewCredentials()),
+		ggrpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"swarmroute-test":{}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	client := healthpb.NewHealthClient(cc)
+	calls, failed := 0, 0
+	for ; calls < 200 && failed < 3; calls++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, ggrpc.WaitForReady(true)); err != nil {
+			failed++
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.reports[good])+len(a.reports[bad]) != calls {
+		t.Fatalf("expected %d reports, got %v", calls, a.reports)
+	}
+	if len(a.reports[bad]) != failed || slices.Contains(a.reports[good], false) || slices.Contains(a.reports[bad], true) {
+		t.Fatalf("expected the healthy backend's calls to succeed and the other's %d to fail, got %v", failed, a.reports)
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command grpcdemo is cmd/httpdemo for gRPC: it starts local gRPC servers
+// with latency and error profiles, degrades some of them for a window, and
+// runs every strategy through the SwarmRoute gRPC balancer (see
+// grpc.NewBalancerBuilder), printing the same comparison metrics:
+//
+//	go run ./cmd/grpcdemo -requests 1000 -degrade 4s,12s
+//
+// The servers implement the standard health service, whose Check calls
+// sleep for the profile's latency and fail with its error rate.  The
+// endpoint that served a call is the one the balancer picked, read from
+// the call's peer.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+
+	"swarmroute/grpc"
+	"swarmroute/harness"
+)
+
+// profile is the behaviour of one server; it is degraded between the
+// -degrade times if DegradeLat or DegradeErr is set.
+type profile struct {
+	BaseLat    time.Duration
+	Jitter     time.Duration
+	BaseErr    float64
+	DegradeLat time.Duration
+	DegradeErr float64
+}
+
+func (p profile) degraded() bool { return p.DegradeLat > 0 || p.DegradeErr > 0 }
+
+// parseProfile parses "lat=30ms,jitter=9ms,err=0.01,degrade_lat=120ms,degrade_err=0.2".
+func parseProfile(s string) (profile, error) {
+	var p profile
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return p, fmt.Errorf("profile %q: expected key=value, got %q", s, kv)
+		}
+		var err error
+		switch k {
+		case "lat":
+			p.BaseLat, err = time.ParseDuration(v)
+		case "jitter":
+			p.Jitter, err = time.ParseDuration(v)
+		case "err":
+			p.BaseErr, err = strconv.ParseFloat(v, 64)
+		case "degrade_lat":
+			p.DegradeLat, err = time.ParseDuration(v)
+		case "degrade_err":
+			p.DegradeErr, err = strconv.ParseFloat(v, 64)
+		default:
+			err = fmt.Errorf("unknown key %q", k)
+		}
+		if err != nil {
+			return p, fmt.Errorf("profile %q: %v", s, err)
+		}
+	}
+	return p, nil
+}
+
+// server answers health checks according to a profile.
+type server struct {
+	healthpb.UnimplementedHealthServer
+	p      profile
+	start  time.Time
+	dStart time.Duration
+	dEnd   time.Duration
+	mu     sync.Mutex
+	rng    *rand.Rand
+}
+
+func (s *server) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	lat, errRate := s.p.BaseLat, s.p.BaseErr
+	if now := time.Since(s.start); s.p.degraded() && now >= s.dStart && now < s.dEnd {
+		lat, errRate = s.p.DegradeLat, s.p.DegradeErr
+	}
+	s.mu.Lock()
+	// jittered latency, truncated as in cmd/httpdemo
+	sample := float64(lat) + s.rng.NormFloat64()*float64(s.p.Jitter)
+	sample = math.Min(math.Max(sample, 0.2*float64(lat)), 5.0*float64(lat))
+	fail := s.rng.Float64() < errRate
+	s.mu.Unlock()
+	select {
+	case <-time.After(time.Duration(sample)):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if fail {
+		return nil, status.Error(codes.Internal, "injected error")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// lockedRouter serializes a harness strategy, which the balancer updates
+// and picks with from different goroutines.
+type lockedRouter struct {
+	mu        sync.Mutex
+	s         harness.Strategy
+	endpoints int
+}
+
+func (r *lockedRouter) AddService(name string, endpoints []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.s.AddService(name, endpoints)
+	r.endpoints = len(endpoints)
+}
+
+func (r *lockedRouter) PickEndpoint(service string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.s.PickEndpoint(service)
+}
+
+func (r *lockedRouter) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.s.ReportResult(service, endpoint, latencySec, success)
+}
+
+func (r *lockedRouter) ready() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.endpoints
+}
+
+type runResult struct {
+	Strategy string
+	Total    int
+	Success  int
+	MeanMS   float64
+	P95MS    float64
+	Select   map[string]int
+	BadShare float64 // % selections to degraded during window
+}
+
+func main() {
+	total := flag.Int("requests", 1000, "requests per strategy")
+	degrade := flag.String("degrade", "4s,12s", "the degrade window, start,end since the run started")
+	var profiles []profile
+	flag.Func("profile", "a server profile, lat=30ms,jitter=9ms,err=0.01[,degrade_lat=120ms,degrade_err=0.2] (repeatable; default: the three servers of cmd/httpdemo)", func(s string) error {
+		p, err := parseProfile(s)
+		profiles = append(profiles, p)
+		return err
+	})
+	flag.Parse()
+	ds, de, ok := strings.Cut(*degrade, ",")
+	dStart, err1 := time.ParseDuration(ds)
+	dEnd, err2 := time.ParseDuration(de)
+	if !ok || err1 != nil || err2 != nil {
+		log.Fatalf("-degrade: expected start,end durations, got %q", *degrade)
+	}
+	if len(profiles) == 0 {
+		profiles = []profile{
+			{BaseLat: 30 * time.Millisecond, Jitter: 9 * time.Millisecond, BaseErr: 0.01},
+			{BaseLat: 35 * time.Millisecond, Jitter: 10*time.Millisecond + 500*time.Microsecond, BaseErr: 0.01, DegradeLat: 120 * time.Millisecond, DegradeErr: 0.20},
+			{BaseLat: 40 * time.Millisecond, Jitter: 12 * time.Millisecond, BaseErr: 0.02},
+		}
+	}
+
+	strategies := []harness.Strategy{
+		harness.NewRandomStrategy(1),
+		harness.NewRoundRobinStrategy(),
+		harness.NewPowerOfTwoChoicesStrategy(2, 0.2),
+		harness.NewLeastLatencyStrategy(3, 0.2),
+		harness.NewSwarmRouteAdapter(),
+	}
+	svc := "api"
+
+	for i, s := range strategies {
+		// Fresh servers per strategy so the degrade window aligns with this run.
+		start := time.Now()
+		var servers []*ggrpc.Server
+		var addrs []resolver.Address
+		degraded := make(map[string]bool)
+		for j, p := range profiles {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				log.Fatal(err)
+			}
+			srv := ggrpc.NewServer()
+			healthpb.RegisterHealthServer(srv, &server{p: p, start: start, dStart: dStart, dEnd: dEnd, rng: rand.New(rand.NewSource(int64(j + 1)))})
+			go srv.Serve(lis)
+			servers = append(servers, srv)
+			addr := lis.Addr().String()
+			addrs = append(addrs, resolver.Address{Addr: addr})
+			degraded[addr] = p.degraded()
+		}
+
+		// Balancers are looked up by name, so every strategy gets its own.
+		r := &lockedRouter{s: s}
+		name := fmt.Sprintf("grpcdemo-%d", i)
+		balancer.Register(grpc.NewBalancerBuilder(name, r, svc))
+		res := manual.NewBuilderWithScheme("grpcdemo")
+		res.InitialState(resolver.State{Addresses: addrs})
+		cc, err := ggrpc.NewClient("grpcdemo:///"+svc, ggrpc.WithResolvers(res),
+			ggrpc.WithTransportCredentials(insecure.NewCredentials()),
+			ggrpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, name)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Allow every connection to become ready.
+		cc.Connect()
+		for wait := time.Now(); r.ready() < len(addrs) && time.Since(wait) < 2*time.Second; {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		var bad []string
+		for _, a := range addrs {
+			if degraded[a.Addr] {
+				bad = append(bad, a.Addr)
+			}
+		}
+		fmt.Println("gRPC demo (", s.Name(), "): degrade=", dStart, "..", dEnd, "on", strings.Join(bad, " "))
+		result := runGRPC(healthpb.NewHealthClient(cc), s.Name(), start, dStart, dEnd, degraded, *total)
+		fmt.Printf("%s: success=%d/%d (%.1f%%), mean=%.1fms p95=%.1fms, bad-window share=%.2f%%\n",
+			result.Strategy, result.Success, result.Total, 100.0*float64(result.Success)/float64(result.Total), result.MeanMS, result.P95MS, result.BadShare)
+		keys := make([]string, 0, len(result.Select))
+		for k := range result.Select {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  %s: %d\n", k, result.Select[k])
+		}
+		cc.Close()
+		for _, srv := range servers {
+			srv.Stop()
+		}
+	}
+}
+
+func runGRPC(client healthpb.HealthClient, strategy string, start time.Time, dStart, dEnd time.Duration, degraded map[string]bool, total int) runResult {
+	sel := make(map[string]int)
+	success := 0
+	lats := make([]float64, 0, total)
+	badSel := 0
+	badTotal := 0
+	for i := 0; i < total; i++ {
+		var p peer.Peer
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		t0 := time.Now()
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, ggrpc.Peer(&p))
+		lat := time.Since(t0)
+		cancel()
+		if p.Addr == nil {
+			// The balancer failed the call before picking a server.
+			continue
+		}
+		addr := p.Addr.String()
+		sel[addr]++
+
+		now := time.Since(start)
+		if now >= dStart && now < dEnd {
+			badTotal++
+			if degraded[addr] {
+				badSel++
+			}
+		}
+
+		if err == nil {
+			success++
+			lats = append(lats, float64(lat)/float64(time.Millisecond))
+		}
+	}
+	mean, p95 := meanP95(lats)
+	share := 0.0
+	if badTotal > 0 {
+		share = 100.0 * float64(badSel) / float64(badTotal)
+	}
+	return runResult{Strategy: strategy, Total: total, Success: success, MeanMS: mean, P95MS: p95, Select: sel, BadShare: share}
+}
+
+func meanP95(xs []float64) (mean, p95 float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	s := 0.0
+	for _, v := range xs {
+		s += v
+	}
+	mean = s / float64(len(xs))
+	cp := append([]float64(nil), xs...)
+	sort.Float64s(cp)
+	idx := int(math.Ceil(0.95*float64(len(cp)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(cp) {
+		idx = len(cp) - 1
+	}
+	p95 = cp[idx]
+	return
+}
@@ -0,0 +1,1475 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Control mirrors the REST control plane (SwarmRoute.ControlHandler).
+//
+// Regenerate with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative control.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: control.proto
+
+package controlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EndpointState int32
+
+const (
+	EndpointState_ENDPOINT_STATE_ACTIVE  EndpointState = 0
+	EndpointState_ENDPOINT_STATE_DRAINED EndpointState = 1
+	EndpointState_ENDPOINT_STATE_BANNED  EndpointState = 2
+)
+
+// Enum value maps for EndpointState.
+var (
+	EndpointState_name = map[int32]string{
+		0: "ENDPOINT_STATE_ACTIVE",
+		1: "ENDPOINT_STATE_DRAINED",
+		2: "ENDPOINT_STATE_BANNED",
+	}
+	EndpointState_value = map[string]int32{
+		"ENDPOINT_STATE_ACTIVE":  0,
+		"ENDPOINT_STATE_DRAINED": 1,
+		"ENDPOINT_STATE_BANNED":  2,
+	}
+)
+
+func (x EndpointState) Enum() *EndpointState {
+	p := new(EndpointState)
+	*p = x
+	return p
+}
+
+func (x EndpointState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EndpointState) Descriptor() protoreflect.EnumDescriptor {
+	return file_control_proto_enumTypes[0].Descriptor()
+}
+
+func (EndpointState) Type() protoreflect.EnumType {
+	return &file_control_proto_enumTypes[0]
+}
+
+func (x EndpointState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EndpointState.Descriptor instead.
+func (EndpointState) EnumDescriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{0}
+}
+
+type Endpoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	State         EndpointState          `protobuf:"varint,2,opt,name=state,proto3,enum=swarmroute.control.v1.EndpointState" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Endpoint) Reset() {
+	*x = Endpoint{}
+	mi := &file_control_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Endpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Endpoint) ProtoMessage() {}
+
+func (x *Endpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Endpoint.ProtoReflect.Descriptor instead.
+func (*Endpoint) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Endpoint) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Endpoint) GetState() EndpointState {
+	if x != nil {
+		return x.State
+	}
+	return EndpointState_ENDPOINT_STATE_ACTIVE
+}
+
+type Service struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Endpoints     []*Endpoint            `protobuf:"bytes,2,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_control_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Service) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Service) GetEndpoints() []*Endpoint {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+type ListServicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServicesRequest) Reset() {
+	*x = ListServicesRequest{}
+	mi := &file_control_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServicesRequest) ProtoMessage() {}
+
+func (x *ListServicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServicesRequest.ProtoReflect.Descriptor instead.
+func (*ListServicesRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{2}
+}
+
+type ListServicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Services      []*Service             `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServicesResponse) Reset() {
+	*x = ListServicesResponse{}
+	mi := &file_control_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServicesResponse) ProtoMessage() {}
+
+func (x *ListServicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServicesResponse.ProtoReflect.Descriptor instead.
+func (*ListServicesResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListServicesResponse) GetServices() []*Service {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+type GetPheromonesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPheromonesRequest) Reset() {
+	*x = GetPheromonesRequest{}
+	mi := &file_control_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPheromonesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPheromonesRequest) ProtoMessage() {}
+
+func (x *GetPheromonesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPheromonesRequest.ProtoReflect.Descriptor instead.
+func (*GetPheromonesRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{4}
+}
+
+type EndpointStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	Pos           float64                `protobuf:"fixed64,3,opt,name=pos,proto3" json:"pos,omitempty"`
+	Neg           float64                `protobuf:"fixed64,4,opt,name=neg,proto3" json:"neg,omitempty"`
+	Picks         uint64                 `protobuf:"varint,5,opt,name=picks,proto3" json:"picks,omitempty"`
+	Successes     uint64                 `protobuf:"varint,6,opt,name=successes,proto3" json:"successes,omitempty"`
+	Failures      uint64                 `protobuf:"varint,7,opt,name=failures,proto3" json:"failures,omitempty"`
+	InFlight      int64                  `protobuf:"varint,8,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndpointStatus) Reset() {
+	*x = EndpointStatus{}
+	mi := &file_control_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndpointStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointStatus) ProtoMessage() {}
+
+func (x *EndpointStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndpointStatus.ProtoReflect.Descriptor instead.
+func (*EndpointStatus) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EndpointStatus) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *EndpointStatus) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *EndpointStatus) GetPos() float64 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *EndpointStatus) GetNeg() float64 {
+	if x != nil {
+		return x.Neg
+	}
+	return 0
+}
+
+func (x *EndpointStatus) GetPicks() uint64 {
+	if x != nil {
+		return x.Picks
+	}
+	return 0
+}
+
+func (x *EndpointStatus) GetSuccesses() uint64 {
+	if x != nil {
+		return x.Successes
+	}
+	return 0
+}
+
+func (x *EndpointStatus) GetFailures() uint64 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *EndpointStatus) GetInFlight() int64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+// State is a point-in-time view of every endpoint, sorted by service and
+// address.
+type State struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Endpoints     []*EndpointStatus      `protobuf:"bytes,1,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *State) Reset() {
+	*x = State{}
+	mi := &file_control_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *State) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*State) ProtoMessage() {}
+
+func (x *State) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use State.ProtoReflect.Descriptor instead.
+func (*State) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *State) GetEndpoints() []*EndpointStatus {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+type SetEndpointsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Endpoints     []string               `protobuf:"bytes,2,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetEndpointsRequest) Reset() {
+	*x = SetEndpointsRequest{}
+	mi := &file_control_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetEndpointsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetEndpointsRequest) ProtoMessage() {}
+
+func (x *SetEndpointsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetEndpointsRequest.ProtoReflect.Descriptor instead.
+func (*SetEndpointsRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetEndpointsRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *SetEndpointsRequest) GetEndpoints() []string {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+type SetEndpointsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetEndpointsResponse) Reset() {
+	*x = SetEndpointsResponse{}
+	mi := &file_control_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetEndpointsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetEndpointsResponse) ProtoMessage() {}
+
+func (x *SetEndpointsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetEndpointsResponse.ProtoReflect.Descriptor instead.
+func (*SetEndpointsResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{8}
+}
+
+type RemoveServiceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveServiceRequest) Reset() {
+	*x = RemoveServiceRequest{}
+	mi := &file_control_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveServiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveServiceRequest) ProtoMessage() {}
+
+func (x *RemoveServiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveServiceRequest.ProtoReflect.Descriptor instead.
+func (*RemoveServiceRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RemoveServiceRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+type RemoveServiceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveServiceResponse) Reset() {
+	*x = RemoveServiceResponse{}
+	mi := &file_control_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveServiceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveServiceResponse) ProtoMessage() {}
+
+func (x *RemoveServiceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveServiceResponse.ProtoReflect.Descriptor instead.
+func (*RemoveServiceResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{10}
+}
+
+type AddEndpointRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddEndpointRequest) Reset() {
+	*x = AddEndpointRequest{}
+	mi := &file_control_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddEndpointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddEndpointRequest) ProtoMessage() {}
+
+func (x *AddEndpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddEndpointRequest.ProtoReflect.Descriptor instead.
+func (*AddEndpointRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AddEndpointRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *AddEndpointRequest) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+type AddEndpointResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddEndpointResponse) Reset() {
+	*x = AddEndpointResponse{}
+	mi := &file_control_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddEndpointResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddEndpointResponse) ProtoMessage() {}
+
+func (x *AddEndpointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddEndpointResponse.ProtoReflect.Descriptor instead.
+func (*AddEndpointResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{12}
+}
+
+type RemoveEndpointRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveEndpointRequest) Reset() {
+	*x = RemoveEndpointRequest{}
+	mi := &file_control_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveEndpointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveEndpointRequest) ProtoMessage() {}
+
+func (x *RemoveEndpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveEndpointRequest.ProtoReflect.Descriptor instead.
+func (*RemoveEndpointRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RemoveEndpointRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *RemoveEndpointRequest) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+type RemoveEndpointResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveEndpointResponse) Reset() {
+	*x = RemoveEndpointResponse{}
+	mi := &file_control_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveEndpointResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveEndpointResponse) ProtoMessage() {}
+
+func (x *RemoveEndpointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveEndpointResponse.ProtoReflect.Descriptor instead.
+func (*RemoveEndpointResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{14}
+}
+
+type SetEndpointStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	State         EndpointState          `protobuf:"varint,3,opt,name=state,proto3,enum=swarmroute.control.v1.EndpointState" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetEndpointStateRequest) Reset() {
+	*x = SetEndpointStateRequest{}
+	mi := &file_control_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetEndpointStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetEndpointStateRequest) ProtoMessage() {}
+
+func (x *SetEndpointStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetEndpointStateRequest.ProtoReflect.Descriptor instead.
+func (*SetEndpointStateRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SetEndpointStateRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *SetEndpointStateRequest) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *SetEndpointStateRequest) GetState() EndpointState {
+	if x != nil {
+		return x.State
+	}
+	return EndpointState_ENDPOINT_STATE_ACTIVE
+}
+
+type SetEndpointStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetEndpointStateResponse) Reset() {
+	*x = SetEndpointStateResponse{}
+	mi := &file_control_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetEndpointStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetEndpointStateResponse) ProtoMessage() {}
+
+func (x *SetEndpointStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetEndpointStateResponse.ProtoReflect.Descriptor instead.
+func (*SetEndpointStateResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{16}
+}
+
+type GetConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_control_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{17}
+}
+
+// Config mirrors swarmroute.Config.
+type Config struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	EvaporationRate       float64                `protobuf:"fixed64,1,opt,name=evaporation_rate,json=evaporationRate,proto3" json:"evaporation_rate,omitempty"`
+	PosReinforce          float64                `protobuf:"fixed64,2,opt,name=pos_reinforce,json=posReinforce,proto3" json:"pos_reinforce,omitempty"`
+	NegReinforce          float64                `protobuf:"fixed64,3,opt,name=neg_reinforce,json=negReinforce,proto3" json:"neg_reinforce,omitempty"`
+	ReqEvapRate           float64                `protobuf:"fixed64,4,opt,name=req_evap_rate,json=reqEvapRate,proto3" json:"req_evap_rate,omitempty"`
+	BaseWeight            float64                `protobuf:"fixed64,5,opt,name=base_weight,json=baseWeight,proto3" json:"base_weight,omitempty"`
+	ExploreEveryN         int64                  `protobuf:"varint,6,opt,name=explore_every_n,json=exploreEveryN,proto3" json:"explore_every_n,omitempty"`
+	ExploreNegThreshold   float64                `protobuf:"fixed64,7,opt,name=explore_neg_threshold,json=exploreNegThreshold,proto3" json:"explore_neg_threshold,omitempty"`
+	SlowThresholdSec      float64                `protobuf:"fixed64,8,opt,name=slow_threshold_sec,json=slowThresholdSec,proto3" json:"slow_threshold_sec,omitempty"`
+	BadPosDecay           float64                `protobuf:"fixed64,9,opt,name=bad_pos_decay,json=badPosDecay,proto3" json:"bad_pos_decay,omitempty"`
+	ConnLifetimeTargetSec float64                `protobuf:"fixed64,10,opt,name=conn_lifetime_target_sec,json=connLifetimeTargetSec,proto3" json:"conn_lifetime_target_sec,omitempty"`
+	ReuseBonus            float64                `protobuf:"fixed64,11,opt,name=reuse_bonus,json=reuseBonus,proto3" json:"reuse_bonus,omitempty"`
+	WarmIdleTimeoutSec    float64                `protobuf:"fixed64,12,opt,name=warm_idle_timeout_sec,json=warmIdleTimeoutSec,proto3" json:"warm_idle_timeout_sec,omitempty"`
+	EndpointTtlSec        float64                `protobuf:"fixed64,13,opt,name=endpoint_ttl_sec,json=endpointTtlSec,proto3" json:"endpoint_ttl_sec,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_control_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Config) GetEvaporationRate() float64 {
+	if x != nil {
+		return x.EvaporationRate
+	}
+	return 0
+}
+
+func (x *Config) GetPosReinforce() float64 {
+	if x != nil {
+		return x.PosReinforce
+	}
+	return 0
+}
+
+func (x *Config) GetNegReinforce() float64 {
+	if x != nil {
+		return x.NegReinforce
+	}
+	return 0
+}
+
+func (x *Config) GetReqEvapRate() float64 {
+	if x != nil {
+		return x.ReqEvapRate
+	}
+	return 0
+}
+
+func (x *Config) GetBaseWeight() float64 {
+	if x != nil {
+		return x.BaseWeight
+	}
+	return 0
+}
+
+func (x *Config) GetExploreEveryN() int64 {
+	if x != nil {
+		return x.ExploreEveryN
+	}
+	return 0
+}
+
+func (x *Config) GetExploreNegThreshold() float64 {
+	if x != nil {
+		return x.ExploreNegThreshold
+	}
+	return 0
+}
+
+func (x *Config) GetSlowThresholdSec() float64 {
+	if x != nil {
+		return x.SlowThresholdSec
+	}
+	return 0
+}
+
+func (x *Config) GetBadPosDecay() float64 {
+	if x != nil {
+		return x.BadPosDecay
+	}
+	return 0
+}
+
+func (x *Config) GetConnLifetimeTargetSec() float64 {
+	if x != nil {
+		return x.ConnLifetimeTargetSec
+	}
+	return 0
+}
+
+func (x *Config) GetReuseBonus() float64 {
+	if x != nil {
+		return x.ReuseBonus
+	}
+	return 0
+}
+
+func (x *Config) GetWarmIdleTimeoutSec() float64 {
+	if x != nil {
+		return x.WarmIdleTimeoutSec
+	}
+	return 0
+}
+
+func (x *Config) GetEndpointTtlSec() float64 {
+	if x != nil {
+		return x.EndpointTtlSec
+	}
+	return 0
+}
+
+// UpdateConfigRequest is a partial Config; unset fields are kept.
+type UpdateConfigRequest struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	EvaporationRate       *float64               `protobuf:"fixed64,1,opt,name=evaporation_rate,json=evaporationRate,proto3,oneof" json:"evaporation_rate,omitempty"`
+	PosReinforce          *float64               `protobuf:"fixed64,2,opt,name=pos_reinforce,json=posReinforce,proto3,oneof" json:"pos_reinforce,omitempty"`
+	NegReinforce          *float64               `protobuf:"fixed64,3,opt,name=neg_reinforce,json=negReinforce,proto3,oneof" json:"neg_reinforce,omitempty"`
+	ReqEvapRate           *float64               `protobuf:"fixed64,4,opt,name=req_evap_rate,json=reqEvapRate,proto3,oneof" json:"req_evap_rate,omitempty"`
+	BaseWeight            *float64               `protobuf:"fixed64,5,opt,name=base_weight,json=baseWeight,proto3,oneof" json:"base_weight,omitempty"`
+	ExploreEveryN         *int64                 `protobuf:"varint,6,opt,name=explore_every_n,json=exploreEveryN,proto3,oneof" json:"explore_every_n,omitempty"`
+	ExploreNegThreshold   *float64               `protobuf:"fixed64,7,opt,name=explore_neg_threshold,json=exploreNegThreshold,proto3,oneof" json:"explore_neg_threshold,omitempty"`
+	SlowThresholdSec      *float64               `protobuf:"fixed64,8,opt,name=slow_threshold_sec,json=slowThresholdSec,proto3,oneof" json:"slow_threshold_sec,omitempty"`
+	BadPosDecay           *float64               `protobuf:"fixed64,9,opt,name=bad_pos_decay,json=badPosDecay,proto3,oneof" json:"bad_pos_decay,omitempty"`
+	ConnLifetimeTargetSec *float64               `protobuf:"fixed64,10,opt,name=conn_lifetime_target_sec,json=connLifetimeTargetSec,proto3,oneof" json:"conn_lifetime_target_sec,omitempty"`
+	ReuseBonus            *float64               `protobuf:"fixed64,11,opt,name=reuse_bonus,json=reuseBonus,proto3,oneof" json:"reuse_bonus,omitempty"`
+	WarmIdleTimeoutSec    *float64               `protobuf:"fixed64,12,opt,name=warm_idle_timeout_sec,json=warmIdleTimeoutSec,proto3,oneof" json:"warm_idle_timeout_sec,omitempty"`
+	EndpointTtlSec        *float64               `protobuf:"fixed64,13,opt,name=endpoint_ttl_sec,json=endpointTtlSec,proto3,oneof" json:"endpoint_ttl_sec,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *UpdateConfigRequest) Reset() {
+	*x = UpdateConfigRequest{}
+	mi := &file_control_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateConfigRequest) ProtoMessage() {}
+
+func (x *UpdateConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateConfigRequest.ProtoReflect.Descriptor instead.
+func (*UpdateConfigRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdateConfigRequest) GetEvaporationRate() float64 {
+	if x != nil && x.EvaporationRate != nil {
+		return *x.EvaporationRate
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetPosReinforce() float64 {
+	if x != nil && x.PosReinforce != nil {
+		return *x.PosReinforce
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetNegReinforce() float64 {
+	if x != nil && x.NegReinforce != nil {
+		return *x.NegReinforce
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetReqEvapRate() float64 {
+	if x != nil && x.ReqEvapRate != nil {
+		return *x.ReqEvapRate
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetBaseWeight() float64 {
+	if x != nil && x.BaseWeight != nil {
+		return *x.BaseWeight
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetExploreEveryN() int64 {
+	if x != nil && x.ExploreEveryN != nil {
+		return *x.ExploreEveryN
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetExploreNegThreshold() float64 {
+	if x != nil && x.ExploreNegThreshold != nil {
+		return *x.ExploreNegThreshold
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetSlowThresholdSec() float64 {
+	if x != nil && x.SlowThresholdSec != nil {
+		return *x.SlowThresholdSec
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetBadPosDecay() float64 {
+	if x != nil && x.BadPosDecay != nil {
+		return *x.BadPosDecay
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetConnLifetimeTargetSec() float64 {
+	if x != nil && x.ConnLifetimeTargetSec != nil {
+		return *x.ConnLifetimeTargetSec
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetReuseBonus() float64 {
+	if x != nil && x.ReuseBonus != nil {
+		return *x.ReuseBonus
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetWarmIdleTimeoutSec() float64 {
+	if x != nil && x.WarmIdleTimeoutSec != nil {
+		return *x.WarmIdleTimeoutSec
+	}
+	return 0
+}
+
+func (x *UpdateConfigRequest) GetEndpointTtlSec() float64 {
+	if x != nil && x.EndpointTtlSec != nil {
+		return *x.EndpointTtlSec
+	}
+	return 0
+}
+
+type WatchStateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// interval_ms between snapshots; values <= 0 mean one second.
+	IntervalMs    int64 `protobuf:"varint,1,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStateRequest) Reset() {
+	*x = WatchStateRequest{}
+	mi := &file_control_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStateRequest) ProtoMessage() {}
+
+func (x *WatchStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStateRequest.ProtoReflect.Descriptor instead.
+func (*WatchStateRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WatchStateRequest) GetIntervalMs() int64 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+var File_control_proto protoreflect.FileDescriptor
+
+const file_control_proto_rawDesc = "" +
+	"\n" +
+	"\rcontrol.proto\x12\x15swarmroute.control.v1\"Z\n" +
+	"\bEndpoint\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12:\n" +
+	"\x05state\x18\x02 \x01(\x0e2$.swarmroute.control.v1.EndpointStateR\x05state\"\\\n" +
+	"\aService\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12=\n" +
+	"\tendpoints\x18\x02 \x03(\v2\x1f.swarmroute.control.v1.EndpointR\tendpoints\"\x15\n" +
+	"\x13ListServicesRequest\"R\n" +
+	"\x14ListServicesResponse\x12:\n" +
+	"\bservices\x18\x01 \x03(\v2\x1e.swarmroute.control.v1.ServiceR\bservices\"\x16\n" +
+	"\x14GetPheromonesRequest\"\xcf\x01\n" +
+	"\x0eEndpointStatus\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12\x10\n" +
+	"\x03pos\x18\x03 \x01(\x01R\x03pos\x12\x10\n" +
+	"\x03neg\x18\x04 \x01(\x01R\x03neg\x12\x14\n" +
+	"\x05picks\x18\x05 \x01(\x04R\x05picks\x12\x1c\n" +
+	"\tsuccesses\x18\x06 \x01(\x04R\tsuccesses\x12\x1a\n" +
+	"\bfailures\x18\a \x01(\x04R\bfailures\x12\x1b\n" +
+	"\tin_flight\x18\b \x01(\x03R\binFlight\"L\n" +
+	"\x05State\x12C\n" +
+	"\tendpoints\x18\x01 \x03(\v2%.swarmroute.control.v1.EndpointStatusR\tendpoints\"M\n" +
+	"\x13SetEndpointsRequest\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x1c\n" +
+	"\tendpoints\x18\x02 \x03(\tR\tendpoints\"\x16\n" +
+	"\x14SetEndpointsResponse\"0\n" +
+	"\x14RemoveServiceRequest\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\"\x17\n" +
+	"\x15RemoveServiceResponse\"B\n" +
+	"\x12AddEndpointRequest\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\"\x15\n" +
+	"\x13AddEndpointResponse\"E\n" +
+	"\x15RemoveEndpointRequest\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\"\x18\n" +
+	"\x16RemoveEndpointResponse\"\x83\x01\n" +
+	"\x17SetEndpointStateRequest\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12:\n" +
+	"\x05state\x18\x03 \x01(\x0e2$.swarmroute.control.v1.EndpointStateR\x05state\"\x1a\n" +
+	"\x18SetEndpointStateResponse\"\x12\n" +
+	"\x10GetConfigRequest\"\xa7\x04\n" +
+	"\x06Config\x12)\n" +
+	"\x10evaporation_rate\x18\x01 \x01(\x01R\x0fevaporationRate\x12#\n" +
+	"\rpos_reinforce\x18\x02 \x01(\x01R\fposReinforce\x12#\n" +
+	"\rneg_reinforce\x18\x03 \x01(\x01R\fnegReinforce\x12\"\n" +
+	"\rreq_evap_rate\x18\x04 \x01(\x01R\vreqEvapRate\x12\x1f\n" +
+	"\vbase_weight\x18\x05 \x01(\x01R\n" +
+	"baseWeight\x12&\n" +
+	"\x0fexplore_every_n\x18\x06 \x01(\x03R\rexploreEveryN\x122\n" +
+	"\x15explore_neg_threshold\x18\a \x01(\x01R\x13exploreNegThreshold\x12,\n" +
+	"\x12slow_threshold_sec\x18\b \x01(\x01R\x10slowThresholdSec\x12\"\n" +
+	"\rbad_pos_decay\x18\t \x01(\x01R\vbadPosDecay\x127\n" +
+	"\x18conn_lifetime_target_sec\x18\n" +
+	" \x01(\x01R\x15connLifetimeTargetSec\x12\x1f\n" +
+	"\vreuse_bonus\x18\v \x01(\x01R\n" +
+	"reuseBonus\x121\n" +
+	"\x15warm_idle_timeout_sec\x18\f \x01(\x01R\x12warmIdleTimeoutSec\x12(\n" +
+	"\x10endpoint_ttl_sec\x18\r \x01(\x01R\x0eendpointTtlSec\"\x83\a\n" +
+	"\x13UpdateConfigRequest\x12.\n" +
+	"\x10evaporation_rate\x18\x01 \x01(\x01H\x00R\x0fevaporationRate\x88\x01\x01\x12(\n" +
+	"\rpos_reinforce\x18\x02 \x01(\x01H\x01R\fposReinforce\x88\x01\x01\x12(\n" +
+	"\rneg_reinforce\x18\x03 \x01(\x01H\x02R\fnegReinforce\x88\x01\x01\x12'\n" +
+	"\rreq_evap_rate\x18\x04 \x01(\x01H\x03R\vreqEvapRate\x88\x01\x01\x12$\n" +
+	"\vbase_weight\x18\x05 \x01(\x01H\x04R\n" +
+	"baseWeight\x88\x01\x01\x12+\n" +
+	"\x0fexplore_every_n\x18\x06 \x01(\x03H\x05R\rexploreEveryN\x88\x01\x01\x127\n" +
+	"\x15explore_neg_threshold\x18\a \x01(\x01H\x06R\x13exploreNegThreshold\x88\x01\x01\x121\n" +
+	"\x12slow_threshold_sec\x18\b \x01(\x01H\aR\x10slowThresholdSec\x88\x01\x01\x12'\n" +
+	"\rbad_pos_decay\x18\t \x01(\x01H\bR\vbadPosDecay\x88\x01\x01\x12<\n" +
+	"\x18conn_lifetime_target_sec\x18\n" +
+	" \x01(\x01H\tR\x15connLifetimeTargetSec\x88\x01\x01\x12$\n" +
+	"\vreuse_bonus\x18\v \x01(\x01H\n" +
+	"R\n" +
+	"reuseBonus\x88\x01\x01\x126\n" +
+	"\x15warm_idle_timeout_sec\x18\f \x01(\x01H\vR\x12warmIdleTimeoutSec\x88\x01\x01\x12-\n" +
+	"\x10endpoint_ttl_sec\x18\r \x01(\x01H\fR\x0eendpointTtlSec\x88\x01\x01B\x13\n" +
+	"\x11_evaporation_rateB\x10\n" +
+	"\x0e_pos_reinforceB\x10\n" +
+	"\x0e_neg_reinforceB\x10\n" +
+	"\x0e_req_evap_rateB\x0e\n" +
+	"\f_base_weightB\x12\n" +
+	"\x10_explore_every_nB\x18\n" +
+	"\x16_explore_neg_thresholdB\x15\n" +
+	"\x13_slow_threshold_secB\x10\n" +
+	"\x0e_bad_pos_decayB\x1b\n" +
+	"\x19_conn_lifetime_target_secB\x0e\n" +
+	"\f_reuse_bonusB\x18\n" +
+	"\x16_warm_idle_timeout_secB\x13\n" +
+	"\x11_endpoint_ttl_sec\"4\n" +
+	"\x11WatchStateRequest\x12\x1f\n" +
+	"\vinterval_ms\x18\x01 \x01(\x03R\n" +
+	"intervalMs*a\n" +
+	"\rEndpointState\x12\x19\n" +
+	"\x15ENDPOINT_STATE_ACTIVE\x10\x00\x12\x1a\n" +
+	"\x16ENDPOINT_STATE_DRAINED\x10\x01\x12\x19\n" +
+	"\x15ENDPOINT_STATE_BANNED\x10\x022\xf5\a\n" +
+	"\aControl\x12g\n" +
+	"\fListServices\x12*.swarmroute.control.v1.ListServicesRequest\x1a+.swarmroute.control.v1.ListServicesResponse\x12Z\n" +
+	"\rGetPheromones\x12+.swarmroute.control.v1.GetPheromonesRequest\x1a\x1c.swarmroute.control.v1.State\x12g\n" +
+	"\fSetEndpoints\x12*.swarmroute.control.v1.SetEndpointsRequest\x1a+.swarmroute.control.v1.SetEndpointsResponse\x12j\n" +
+	"\rRemoveService\x12+.swarmroute.control.v1.RemoveServiceRequest\x1a,.swarmroute.control.v1.RemoveServiceResponse\x12d\n" +
+	"\vAddEndpoint\x12).swarmroute.control.v1.AddEndpointRequest\x1a*.swarmroute.control.v1.AddEndpointResponse\x12m\n" +
+	"\x0eRemoveEndpoint\x12,.swarmroute.control.v1.RemoveEndpointRequest\x1a-.swarmroute.control.v1.RemoveEndpointResponse\x12s\n" +
+	"\x10SetEndpointState\x12..swarmroute.control.v1.SetEndpointStateRequest\x1a/.swarmroute.control.v1.SetEndpointStateResponse\x12S\n" +
+	"\tGetConfig\x12'.swarmroute.control.v1.GetConfigRequest\x1a\x1d.swarmroute.control.v1.Config\x12Y\n" +
+	"\fUpdateConfig\x12*.swarmroute.control.v1.UpdateConfigRequest\x1a\x1d.swarmroute.control.v1.Config\x12V\n" +
+	"\n" +
+	"WatchState\x12(.swarmroute.control.v1.WatchStateRequest\x1a\x1c.swarmroute.control.v1.State0\x01B\x1bZ\x19swarmroute/grpc/controlpbb\x06proto3"
+
+var (
+	file_control_proto_rawDescOnce sync.Once
+	file_control_proto_rawDescData []byte
+)
+
+func file_control_proto_rawDescGZIP() []byte {
+	file_control_proto_rawDescOnce.Do(func() {
+		file_control_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_control_proto_rawDesc), len(file_control_proto_rawDesc)))
+	})
+	return file_control_proto_rawDescData
+}
+
+var file_control_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_control_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_control_proto_goTypes = []any{
+	(EndpointState)(0),               // 0: swarmroute.control.v1.EndpointState
+	(*Endpoint)(nil),                 // 1: swarmroute.control.v1.Endpoint
+	(*Service)(nil),                  // 2: swarmroute.control.v1.Service
+	(*ListServicesRequest)(nil),      // 3: swarmroute.control.v1.ListServicesRequest
+	(*ListServicesResponse)(nil),     // 4: swarmroute.control.v1.ListServicesResponse
+	(*GetPheromonesRequest)(nil),     // 5: swarmroute.control.v1.GetPheromonesRequest
+	(*EndpointStatus)(nil),           // 6: swarmroute.control.v1.EndpointStatus
+	(*State)(nil),                    // 7: swarmroute.control.v1.State
+	(*SetEndpointsRequest)(nil),      // 8: swarmroute.control.v1.SetEndpointsRequest
+	(*SetEndpointsResponse)(nil),     // 9: swarmroute.control.v1.SetEndpointsResponse
+	(*RemoveServiceRequest)(nil),     // 10: swarmroute.control.v1.RemoveServiceRequest
+	(*RemoveServiceResponse)(nil),    // 11: swarmroute.control.v1.RemoveServiceResponse
+	(*AddEndpointRequest)(nil),       // 12: swarmroute.control.v1.AddEndpointRequest
+	(*AddEndpointResponse)(nil),      // 13: swarmroute.control.v1.AddEndpointResponse
+	(*RemoveEndpointRequest)(nil),    // 14: swarmroute.control.v1.RemoveEndpointRequest
+	(*RemoveEndpointResponse)(nil),   // 15: swarmroute.control.v1.RemoveEndpointResponse
+	(*SetEndpointStateRequest)(nil),  // 16: swarmroute.control.v1.SetEndpointStateRequest
+	(*SetEndpointStateResponse)(nil), // 17: swarmroute.control.v1.SetEndpointStateResponse
+	(*GetConfigRequest)(nil),         // 18: swarmroute.control.v1.GetConfigRequest
+	(*Config)(nil),                   // 19: swarmroute.control.v1.Config
+	(*UpdateConfigRequest)(nil),      // 20: swarmroute.control.v1.UpdateConfigRequest
+	(*WatchStateRequest)(nil),        // 21: swarmroute.control.v1.WatchStateRequest
+}
+var file_control_proto_depIdxs = []int32{
+	0,  // 0: swarmroute.control.v1.Endpoint.state:type_name -> swarmroute.control.v1.EndpointState
+	1,  // 1: swarmroute.control.v1.Service.endpoints:type_name -> swarmroute.control.v1.Endpoint
+	2,  // 2: swarmroute.control.v1.ListServicesResponse.services:type_name -> swarmroute.control.v1.Service
+	6,  // 3: swarmroute.control.v1.State.endpoints:type_name -> swarmroute.control.v1.EndpointStatus
+	0,  // 4: swarmroute.control.v1.SetEndpointStateRequest.state:type_name -> swarmroute.control.v1.EndpointState
+	3,  // 5: swarmroute.control.v1.Control.ListServices:input_type -> swarmroute.control.v1.ListServicesRequest
+	5,  // 6: swarmroute.control.v1.Control.GetPheromones:input_type -> swarmroute.control.v1.GetPheromonesRequest
+	8,  // 7: swarmroute.control.v1.Control.SetEndpoints:input_type -> swarmroute.control.v1.SetEndpointsRequest
+	10, // 8: swarmroute.control.v1.Control.RemoveService:input_type -> swarmroute.control.v1.RemoveServiceRequest
+	12, // 9: swarmroute.control.v1.Control.AddEndpoint:input_type -> swarmroute.control.v1.AddEndpointRequest
+	14, // 10: swarmroute.control.v1.Control.RemoveEndpoint:input_type -> swarmroute.control.v1.RemoveEndpointRequest
+	16, // 11: swarmroute.control.v1.Control.SetEndpointState:input_type -> swarmroute.control.v1.SetEndpointStateRequest
+	18, // 12: swarmroute.control.v1.Control.GetConfig:input_type -> swarmroute.control.v1.GetConfigRequest
+	20, // 13: swarmroute.control.v1.Control.UpdateConfig:input_type -> swarmroute.control.v1.UpdateConfigRequest
+	21, // 14: swarmroute.control.v1.Control.WatchState:input_type -> swarmroute.control.v1.WatchStateRequest
+	4,  // 15: swarmroute.control.v1.Control.ListServices:output_type -> swarmroute.control.v1.ListServicesResponse
+	7,  // 16: swarmroute.control.v1.Control.GetPheromones:output_type -> swarmroute.control.v1.State
+	9,  // 17: swarmroute.control.v1.Control.SetEndpoints:output_type -> swarmroute.control.v1.SetEndpointsResponse
+	11, // 18: swarmroute.control.v1.Control.RemoveService:output_type -> swarmroute.control.v1.RemoveServiceResponse
+	13, // 19: swarmroute.control.v1.Control.AddEndpoint:output_type -> swarmroute.control.v1.AddEndpointResponse
+	15, // 20: swarmroute.control.v1.Control.RemoveEndpoint:output_type -> swarmroute.control.v1.RemoveEndpointResponse
+	17, // 21: swarmroute.control.v1.Control.SetEndpointState:output_type -> swarmroute.control.v1.SetEndpointStateResponse
+	19, // 22: swarmroute.control.v1.Control.GetConfig:output_type -> swarmroute.control.v1.Config
+	19, // 23: swarmroute.control.v1.Control.UpdateConfig:output_type -> swarmroute.control.v1.Config
+	7,  // 24: swarmroute.control.v1.Control.WatchState:output_type -> swarmroute.control.v1.State
+	15, // [15:25] is the sub-list for method output_type
+	5,  // [5:15] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_control_proto_init() }
+func file_control_proto_init() {
+	if File_control_proto != nil {
+		return
+	}
+	file_control_proto_msgTypes[19].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_control_proto_rawDesc), len(file_control_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_control_proto_goTypes,
+		DependencyIndexes: file_control_proto_depIdxs,
+		EnumInfos:         file_control_proto_enumTypes,
+		MessageInfos:      file_control_proto_msgTypes,
+	}.Build()
+	File_control_proto = out.File
+	file_control_proto_goTypes = nil
+	file_control_proto_depIdxs = nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpc exposes the SwarmRoute control plane as a gRPC service
+// (swarmroute.control.v1.Control, see controlpb/control.proto).  It offers
+// the same operations as SwarmRoute.ControlHandler plus a streaming state
+// watch.  It lives in its own module so the core library stays
+// dependency-free.
+//
+//	s := ggrpc.NewServer(grpc.AuthOptions(token)...)
+//	controlpb.RegisterControlServer(s, grpc.NewServer(sr))
+//
+// It also connects the harness to strategies served over gRPC
+// (swarmroute.strategy.v1.Strategy, see RemoteStrategy), and balances
+// gRPC clients with SwarmRoute or any harness strategy (see
+// NewBalancerBuilder; cmd/grpcdemo compares them end to end).
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"time"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"swarmroute"
+	"swarmroute/grpc/controlpb"
+)
+
+// Server implements controlpb.ControlServer for a SwarmRoute instance.
+type Server struct {
+	controlpb.UnimplementedControlServer
+	sr *swarmroute.SwarmRoute
+}
+
+// NewServer returns a control server operating sr.
+func NewServer(sr *swarmroute.SwarmRoute) *Server {
+	return &Server{sr: sr}
+}
+
+// AuthOptions returns server options that require every call to carry
+// "authorization: Bearer <token>" metadata, like the REST control plane.
+// An empty token rejects all calls.
+func AuthOptions(token string) []ggrpc.ServerOption {
+	want := []byte("Bearer " + token)
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, got := range md.Get("authorization") {
+			if token != "" && subtle.ConstantTimeCompare([]byte(got), want) == 1 {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+	return []ggrpc.ServerOption{
+		ggrpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *ggrpc.UnaryServerInfo, h ggrpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return h(ctx, req)
+		}),
+		ggrpc.ChainStreamInterceptor(func(srv any, ss ggrpc.ServerStream, _ *ggrpc.StreamServerInfo, h ggrpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return h(srv, ss)
+		}),
+	}
+}
+
+func (s *Server) ListServices(context.Context, *controlpb.ListServicesRequest) (*controlpb.ListServicesResponse, error) {
+	services := s.sr.Services()
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	resp := &controlpb.ListServicesResponse{}
+	for _, name := range names {
+		states := s.sr.EndpointStates(name)
+		svc := &controlpb.Service{Name: name}
+		for _, addr := range services[name] {
+			svc.Endpoints = append(svc.Endpoints, &controlpb.Endpoint{Addr: addr, State: controlpb.EndpointState(states[addr])})
+		}
+		resp.Services = append(resp.Services, svc)
+	}
+	return resp, nil
+}
+
+func (s *Server) GetPheromones(context.Context, *controlpb.GetPheromonesRequest) (*controlpb.State, error) {
+	return s.state(), nil
+}
+
+func (s *Server) SetEndpoints(_ context.Context, req *controlpb.SetEndpointsRequest) (*controlpb.SetEndpointsResponse, error) {
+	if req.GetService() == "" {
+		return nil, status.Error(codes.InvalidArgument, "service is required")
+	}
+	s.sr.SetEndpoints(req.GetService(), req.GetEndpoints())
+	return &controlpb.SetEndpointsResponse{}, nil
+}
+
+func (s *Server) RemoveService(_ context.Context, req *controlpb.RemoveServiceRequest) (*controlpb.RemoveServiceResponse, error) {
+	s.sr.RemoveService(req.GetService())
+	return &controlpb.RemoveServiceResponse{}, nil
+}
+
+func (s *Server) AddEndpoint(_ context.Context, req *controlpb.AddEndpointRequest) (*controlpb.AddEndpointResponse, error) {
+	if req.GetService() == "" || req.GetAddr() == "" {
+		return nil, status.Error(codes.InvalidArgument, "service and addr are required")
+	}
+	s.sr.AddEndpoint(req.GetService(), req.GetAddr())
+	return &controlpb.AddEndpointResponse{}, nil
+}
+
+func (s *Server) RemoveEndpoint(_ context.Context, req *controlpb.RemoveEndpointRequest) (*controlpb.RemoveEndpointResponse, error) {
+	if err := s.sr.RemoveEndpoint(req.GetService(), req.GetAddr()); err != nil {
+		return nil, statusError(err)
+	}
+	return &controlpb.RemoveEndpointResponse{}, nil
+}
+
+func (s *Server) SetEndpointState(_ context.Context, req *controlpb.SetEndpointStateRequest) (*controlpb.SetEndpointStateResponse, error) {
+	st := swarmroute.EndpointState(req.GetState())
+	if _, err := swarmroute.ParseEndpointState(st.String()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.sr.SetEndpointState(req.GetService(), req.GetAddr(), st); err != nil {
+		return nil, statusError(err)
+	}
+	return &controlpb.SetEndpointStateResponse{}, nil
+}
+
+func (s *Server) GetConfig(context.Context, *controlpb.GetConfigRequest) (*controlpb.Config, error) {
+	return configToProto(s.sr.Config()), nil
+}
+
+func (s *Server) UpdateConfig(_ context.Context, req *controlpb.UpdateConfigRequest) (*controlpb.Config, error) {
+	cfg, err := s.sr.UpdateConfig(func(c *swarmroute.Config) error {
+		set := func(dst *float64, v *float64) {
+			if v != nil {
+				*dst = *v
+			}
+		}
+		set(&c.EvaporationRate, req.EvaporationRate)
+		set(&c.PosReinforce, req.PosReinforce)
+		set(&c.NegReinforce, req.NegReinforce)
+		set(&c.ReqEvapRate, req.ReqEvapRate)
+		set(&c.BaseWeight, req.BaseWeight)
+		if req.ExploreEveryN != nil {
+			c.ExploreEveryN = int(*req.ExploreEveryN)
+		}
+		set(&c.ExploreNegThreshold, req.ExploreNegThreshold)
+		set(&c.SlowThresholdSec, req.SlowThresholdSec)
+		set(&c.BadPosDecay, req.BadPosDecay)
+		set(&c.ConnLifetimeTargetSec, req.ConnLifetimeTargetSec)
+		set(&c.ReuseBonus, req.ReuseBonus)
+		set(&c.WarmIdleTimeoutSec, req.WarmIdleTimeoutSec)
+		set(&c.EndpointTTLSec, req.EndpointTtlSec)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return configToProto(cfg), nil
+}
+
+func (s *Server) WatchState(req *controlpb.WatchStateRequest, stream ggrpc.ServerStreamingServer[controlpb.State]) error {
+	interval := time.Duration(req.GetIntervalMs()) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := stream.Send(s.state()); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// state builds a State from one pheromone snapshot and one counter snapshot.
+func (s *Server) state() *controlpb.State {
+	snap := s.sr.PheromoneSnapshot()
+	counters := s.sr.Counters()
+	out := &controlpb.State{}
+	for svc, eps := range snap {
+		for addr, p := range eps {
+			c := counters[svc][addr]
+			out.Endpoints = append(out.Endpoints, &controlpb.EndpointStatus{
+				Service: svc, Addr: addr, Pos: p.Pos, Neg: p.Neg,
+				Picks: c.Picks, Successes: c.Successes, Failures: c.Failures, InFlight: int64(c.InFlight),
+			})
+		}
+	}
+	sort.Slice(out.Endpoints, func(i, j int) bool {
+		a, b := out.Endpoints[i], out.Endpoints[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Addr < b.Addr
+	})
+	return out
+}
+
+func configToProto(c swarmroute.Config) *controlpb.Config {
+	return &controlpb.Config{
+		EvaporationRate:       c.EvaporationRate,
+		PosReinforce:          c.PosReinforce,
+		NegReinforce:          c.NegReinforce,
+		ReqEvapRate:           c.ReqEvapRate,
+		BaseWeight:            c.BaseWeight,
+		ExploreEveryN:         int64(c.ExploreEveryN),
+		ExploreNegThreshold:   c.ExploreNegThreshold,
+		SlowThresholdSec:      c.SlowThresholdSec,
+		BadPosDecay:           c.BadPosDecay,
+		ConnLifetimeTargetSec: c.ConnLifetimeTargetSec,
+		ReuseBonus:            c.ReuseBonus,
+		WarmIdleTimeoutSec:    c.WarmIdleTimeoutSec,
+		EndpointTtlSec:        c.EndpointTTLSec,
+	}
+}
+
+func statusError(err error) error {
+	if errors.Is(err, swarmroute.ErrUnknownEndpoint) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"swarmroute"
+	"swarmroute/grpc/controlpb"
+)
+
+func TestControlServer(t *testing.T) {
+	sr := swarmroute.NewSwarmRoute()
+	lis := bufconn.Listen(1 << 20)
+	s := ggrpc.NewServer(AuthOptions("s3cret")...)
+	controlpb.RegisterControlServer(s, NewServer(sr))
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := ggrpc.NewClient("passthrough:///bufnet",
+		ggrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		ggrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := controlpb.NewControlClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.ListServices(ctx, &controlpb.ListServicesRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without token, got %v", err)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cret")
+
+	if _, err := client.SetEndpoints(ctx, &controlpb.SetEndpointsRequest{Service: "api", Endpoints: []string{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SetEndpointState(ctx, &controlpb.SetEndpointStateRequest{Service: "api", Addr: "a", State: controlpb.EndpointState_ENDPOINT_STATE_DRAINED}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SetEndpointState(ctx, &controlpb.SetEndpointStateRequest{Service: "api", Addr: "zz"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for unknown endpoint, got %v", err)
+	}
+	list, err := client.ListServices(ctx, &controlpb.ListServicesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eps := list.Services[0].Endpoints; len(eps) != 2 || eps[0].State != controlpb.EndpointState_ENDPOINT_STATE_DRAINED {
+		t.Fatalf("unexpected services: %v", list)
+	}
+	cfg, err := client.UpdateConfig(ctx, &controlpb.UpdateConfigRequest{BaseWeight: proto.Float64(0.25)})
+	if err != nil || cfg.BaseWeight != 0.25 || sr.Config().BaseWeight != 0.25 {
+		t.Fatalf("UpdateConfig: %v %v", cfg, err)
+	}
+	if _, err := client.UpdateConfig(ctx, &controlpb.UpdateConfigRequest{BaseWeight: proto.Float64(-1)}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("UpdateConfig with an invalid value: %v", err)
+	}
+
+	sr.ReportResult("api", "b", 0.01, true)
+	stream, err := client.WatchState(ctx, &controlpb.WatchStateRequest{IntervalMs: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		st, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(st.Endpoints) != 2 || st.Endpoints[1].Addr != "b" || st.Endpoints[1].Successes != 1 {
+			t.Fatalf("unexpected state: %v", st)
+		}
+	}
+}
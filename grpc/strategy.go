@@ -0,0 +1,255 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"swarmroute/grpc/strategypb"
+	"swarmroute/harness"
+)
+
+// Importing this package registers the "grpc" harness strategy, e.g.
+// -strategies grpc:target=localhost:50051, which connects to an external
+// strategy server without transport security.
+func init() {
+	harness.Register("grpc", func(p harness.Params) (harness.Strategy, error) {
+		if err := p.Only("target"); err != nil {
+			return nil, err
+		}
+		target, err := p.String("target", "")
+		if err != nil {
+			return nil, err
+		}
+		if target == "" {
+			return nil, fmt.Errorf("parameter target is required")
+		}
+		return DialStrategy(target)
+	})
+}
+
+// RemoteStrategy is a harness strategy served by a
+// swarmroute.strategy.v1.Strategy server (see strategypb/strategy.proto),
+// so long-running strategies in other languages, such as a learning agent,
+// take part in simulations.  It forwards results and server feedback as
+// they happen over a single stream, which keeps the calls in simulation
+// order; only picks wait for the server.
+//
+// A broken stream fails every later pick with the first error.
+type RemoteStrategy struct {
+	mu     sync.Mutex
+	name   string
+	conn   *ggrpc.ClientConn
+	cancel context.CancelFunc
+	stream ggrpc.BidiStreamingClient[strategypb.Request, strategypb.Response]
+	err    error
+}
+
+// NewRemoteStrategy opens a session on cc and exchanges the hello.
+func NewRemoteStrategy(cc ggrpc.ClientConnInterface) (*RemoteStrategy, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := strategypb.NewStrategyClient(cc).Session(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	r := &RemoteStrategy{cancel: cancel, stream: stream}
+	resp, err := r.call(&strategypb.Request{Msg: &strategypb.Request_Hello{Hello: &strategypb.Hello{Version: harness.PluginProtocolVersion}}})
+	if err == nil && resp.GetHello().GetName() == "" {
+		err = errors.New("hello reply has no name")
+	}
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("strategy server: %w", err)
+	}
+	r.name = resp.GetHello().GetName()
+	return r, nil
+}
+
+// DialStrategy connects to the strategy server at target, without
+// transport security unless opts say otherwise, and opens a session.
+// Close also closes the connection.
+func DialStrategy(target string, opts ...ggrpc.DialOption) (*RemoteStrategy, error) {
+	opts = append([]ggrpc.DialOption{ggrpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := ggrpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRemoteStrategy(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %w", target, err)
+	}
+	r.conn = conn
+	return r, nil
+}
+
+func (r *RemoteStrategy) Name() string { return r.name }
+
+func (r *RemoteStrategy) AddService(name string, endpoints []string) {
+	r.send(&strategypb.Request{Msg: &strategypb.Request_AddService{AddService: &strategypb.AddService{Service: name, Endpoints: endpoints}}})
+}
+
+func (r *RemoteStrategy) Seed(seed int64) {
+	r.send(&strategypb.Request{Msg: &strategypb.Request_Seed{Seed: &strategypb.Seed{Seed: seed}}})
+}
+
+func (r *RemoteStrategy) PickEndpoint(service string) (string, error) {
+	return r.PickEndpointExcluding(service)
+}
+
+// PickEndpointExcluding asks the server for an endpoint avoiding exclude.
+func (r *RemoteStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	resp, err := r.call(&strategypb.Request{Msg: &strategypb.Request_Pick{Pick: &strategypb.Pick{Service: service, Exclude: exclude}}})
+	if err != nil {
+		return "", err
+	}
+	if msg := resp.GetPick().GetError(); msg != "" {
+		return "", errors.New(msg)
+	}
+	return resp.GetPick().GetEndpoint(), nil
+}
+
+func (r *RemoteStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	r.send(&strategypb.Request{Msg: &strategypb.Request_Report{Report: &strategypb.Report{
+		Service: service, Endpoint: endpoint, LatencySec: latencySec, Success: success,
+	}}})
+}
+
+func (r *RemoteStrategy) ReportFeedback(service, endpoint string, fb harness.ServerFeedback) {
+	r.send(&strategypb.Request{Msg: &strategypb.Request_Feedback{Feedback: &strategypb.Feedback{
+		Service: service, Endpoint: endpoint, QueueLen: int32(fb.QueueLen), ServiceTimeSec: fb.ServiceTimeSec,
+	}}})
+}
+
+// Close ends the session and, if the strategy was dialed, the connection.
+func (r *RemoteStrategy) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.stream.CloseSend()
+	r.cancel()
+	if r.conn != nil {
+		if cerr := r.conn.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// send writes a one-way request.
+func (r *RemoteStrategy) send(req *strategypb.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.stream.Send(req)
+	}
+}
+
+// call writes req and reads the response.
+func (r *RemoteStrategy) call(req *strategypb.Request) (*strategypb.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.stream.Send(req)
+	}
+	if r.err != nil {
+		// Send reports io.EOF when the server ended the stream; the
+		// reason is in Recv.
+		if errors.Is(r.err, io.EOF) {
+			if _, err := r.stream.Recv(); err != nil && err != io.EOF {
+				r.err = err
+			}
+		}
+		return nil, r.err
+	}
+	resp, err := r.stream.Recv()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		r.err = fmt.Errorf("strategy stream ended: %w", err)
+		return nil, r.err
+	}
+	return resp, nil
+}
+
+// StrategyService implements strategypb.StrategyServer with Go strategies,
+// e.g. to serve a harness strategy to simulations elsewhere or to test a
+// client.  Every session gets a fresh strategy from newStrategy.
+type StrategyService struct {
+	strategypb.UnimplementedStrategyServer
+	newStrategy func() harness.Strategy
+}
+
+// NewStrategyService returns a service creating a strategy per session.
+func NewStrategyService(newStrategy func() harness.Strategy) *StrategyService {
+	return &StrategyService{newStrategy: newStrategy}
+}
+
+func (s *StrategyService) Session(stream ggrpc.BidiStreamingServer[strategypb.Request, strategypb.Response]) error {
+	st := s.newStrategy()
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch m := req.Msg.(type) {
+		case *strategypb.Request_Hello:
+			err = stream.Send(&strategypb.Response{Msg: &strategypb.Response_Hello{Hello: &strategypb.HelloReply{Name: st.Name()}}})
+		case *strategypb.Request_AddService:
+			st.AddService(m.AddService.GetService(), m.AddService.GetEndpoints())
+		case *strategypb.Request_Seed:
+			if ss, ok := st.(harness.SeedableStrategy); ok {
+				ss.Seed(m.Seed.GetSeed())
+			}
+		case *strategypb.Request_Pick:
+			var ep string
+			var perr error
+			if es, ok := st.(harness.ExcludingStrategy); ok {
+				ep, perr = es.PickEndpointExcluding(m.Pick.GetService(), m.Pick.GetExclude()...)
+			} else {
+				ep, perr = st.PickEndpoint(m.Pick.GetService())
+			}
+			reply := &strategypb.PickReply{Endpoint: ep}
+			if perr != nil {
+				reply.Error = perr.Error()
+			}
+			err = stream.Send(&strategypb.Response{Msg: &strategypb.Response_Pick{Pick: reply}})
+		case *strategypb.Request_Report:
+			r := m.Report
+			st.ReportResult(r.GetService(), r.GetEndpoint(), r.GetLatencySec(), r.GetSuccess())
+		case *strategypb.Request_Feedback:
+			if fs, ok := st.(harness.FeedbackStrategy); ok {
+				f := m.Feedback
+				fs.ReportFeedback(f.GetService(), f.GetEndpoint(), harness.ServerFeedback{QueueLen: int(f.GetQueueLen()), ServiceTimeSec: f.GetServiceTimeSec()})
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
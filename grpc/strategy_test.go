@@ -0,0 +1,71 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"maps"
+	"net"
+	"testing"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"swarmroute/grpc/strategypb"
+	"swarmroute/harness"
+)
+
+func TestRemoteStrategy(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := ggrpc.NewServer()
+	// C3 uses server feedback, so matching it checks that feedback and
+	// results arrive in simulation order.
+	strategypb.RegisterStrategyServer(s, NewStrategyService(func() harness.Strategy { return harness.NewC3Strategy(0.1, 1) }))
+	go s.Serve(lis)
+	defer s.Stop()
+
+	r, err := DialStrategy("passthrough:///bufnet",
+		ggrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		ggrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := harness.Scenario{
+		Service:       "svc",
+		Endpoints:     []harness.EndpointSpec{{Addr: "a", MeanLatencySec: 0.02, ErrorRate: 0.1}, {Addr: "b", MeanLatencySec: 0.03}, {Addr: "c", MeanLatencySec: 0.05}},
+		TotalRequests: 500,
+		Retry:         &harness.RetryPolicy{MaxRetries: 1},
+		Seed:          5,
+	}
+	got, want := harness.RunScenario(sc, r), harness.RunScenario(sc, harness.NewC3Strategy(0.1, 1))
+	if got.Strategy != want.Strategy || got.Success != want.Success || !maps.Equal(got.Selection, want.Selection) {
+		t.Fatalf("expected the remote strategy to route like C3, got %+v want %+v", got, want)
+	}
+	if _, err := r.PickEndpoint("nope"); err == nil {
+		t.Fatal("expected the server's error for an unknown service")
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.PickEndpoint("svc"); err == nil {
+		t.Fatal("expected a pick after Close to fail")
+	}
+	if _, err := harness.NewStrategy("grpc"); err == nil {
+		t.Fatal("expected grpc without target to fail")
+	}
+}
@@ -0,0 +1,841 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Strategy lets a long-running external process (e.g. a learning agent)
+// act as a load-balancing strategy in the SwarmRoute harness.  It carries
+// the same messages as the harness's subprocess protocol (see
+// harness.ProcessStrategy) over one bidirectional stream per strategy instance.
+//
+// Regenerate with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative strategy.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: strategy.proto
+
+package strategypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Request struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Msg:
+	//
+	//	*Request_Hello
+	//	*Request_AddService
+	//	*Request_Seed
+	//	*Request_Pick
+	//	*Request_Report
+	//	*Request_Feedback
+	Msg           isRequest_Msg `protobuf_oneof:"msg"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Request) Reset() {
+	*x = Request{}
+	mi := &file_strategy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Request) ProtoMessage() {}
+
+func (x *Request) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Request.ProtoReflect.Descriptor instead.
+func (*Request) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Request) GetMsg() isRequest_Msg {
+	if x != nil {
+		return x.Msg
+	}
+	return nil
+}
+
+func (x *Request) GetHello() *Hello {
+	if x != nil {
+		if x, ok := x.Msg.(*Request_Hello); ok {
+			return x.Hello
+		}
+	}
+	return nil
+}
+
+func (x *Request) GetAddService() *AddService {
+	if x != nil {
+		if x, ok := x.Msg.(*Request_AddService); ok {
+			return x.AddService
+		}
+	}
+	return nil
+}
+
+func (x *Request) GetSeed() *Seed {
+	if x != nil {
+		if x, ok := x.Msg.(*Request_Seed); ok {
+			return x.Seed
+		}
+	}
+	return nil
+}
+
+func (x *Request) GetPick() *Pick {
+	if x != nil {
+		if x, ok := x.Msg.(*Request_Pick); ok {
+			return x.Pick
+		}
+	}
+	return nil
+}
+
+func (x *Request) GetReport() *Report {
+	if x != nil {
+		if x, ok := x.Msg.(*Request_Report); ok {
+			return x.Report
+		}
+	}
+	return nil
+}
+
+func (x *Request) GetFeedback() *Feedback {
+	if x != nil {
+		if x, ok := x.Msg.(*Request_Feedback); ok {
+			return x.Feedback
+		}
+	}
+	return nil
+}
+
+type isRequest_Msg interface {
+	isRequest_Msg()
+}
+
+type Request_Hello struct {
+	Hello *Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type Request_AddService struct {
+	AddService *AddService `protobuf:"bytes,2,opt,name=add_service,json=addService,proto3,oneof"`
+}
+
+type Request_Seed struct {
+	Seed *Seed `protobuf:"bytes,3,opt,name=seed,proto3,oneof"`
+}
+
+type Request_Pick struct {
+	Pick *Pick `protobuf:"bytes,4,opt,name=pick,proto3,oneof"`
+}
+
+type Request_Report struct {
+	Report *Report `protobuf:"bytes,5,opt,name=report,proto3,oneof"`
+}
+
+type Request_Feedback struct {
+	Feedback *Feedback `protobuf:"bytes,6,opt,name=feedback,proto3,oneof"`
+}
+
+func (*Request_Hello) isRequest_Msg() {}
+
+func (*Request_AddService) isRequest_Msg() {}
+
+func (*Request_Seed) isRequest_Msg() {}
+
+func (*Request_Pick) isRequest_Msg() {}
+
+func (*Request_Report) isRequest_Msg() {}
+
+func (*Request_Feedback) isRequest_Msg() {}
+
+type Response struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Msg:
+	//
+	//	*Response_Hello
+	//	*Response_Pick
+	Msg           isResponse_Msg `protobuf_oneof:"msg"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Response) Reset() {
+	*x = Response{}
+	mi := &file_strategy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Response) ProtoMessage() {}
+
+func (x *Response) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Response.ProtoReflect.Descriptor instead.
+func (*Response) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Response) GetMsg() isResponse_Msg {
+	if x != nil {
+		return x.Msg
+	}
+	return nil
+}
+
+func (x *Response) GetHello() *HelloReply {
+	if x != nil {
+		if x, ok := x.Msg.(*Response_Hello); ok {
+			return x.Hello
+		}
+	}
+	return nil
+}
+
+func (x *Response) GetPick() *PickReply {
+	if x != nil {
+		if x, ok := x.Msg.(*Response_Pick); ok {
+			return x.Pick
+		}
+	}
+	return nil
+}
+
+type isResponse_Msg interface {
+	isResponse_Msg()
+}
+
+type Response_Hello struct {
+	Hello *HelloReply `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type Response_Pick struct {
+	Pick *PickReply `protobuf:"bytes,2,opt,name=pick,proto3,oneof"`
+}
+
+func (*Response_Hello) isResponse_Msg() {}
+
+func (*Response_Pick) isResponse_Msg() {}
+
+// Hello is the first request of a session.
+type Hello struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hello) Reset() {
+	*x = Hello{}
+	mi := &file_strategy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hello) ProtoMessage() {}
+
+func (x *Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hello.ProtoReflect.Descriptor instead.
+func (*Hello) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Hello) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type HelloReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name labels the strategy in reports.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HelloReply) Reset() {
+	*x = HelloReply{}
+	mi := &file_strategy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HelloReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HelloReply) ProtoMessage() {}
+
+func (x *HelloReply) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HelloReply.ProtoReflect.Descriptor instead.
+func (*HelloReply) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HelloReply) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// AddService sets the endpoints of a service.  It is sent again with the
+// full list when endpoints join mid-run; learned state of endpoints
+// already known should be kept.
+type AddService struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Endpoints     []string               `protobuf:"bytes,2,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddService) Reset() {
+	*x = AddService{}
+	mi := &file_strategy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddService) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddService) ProtoMessage() {}
+
+func (x *AddService) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddService.ProtoReflect.Descriptor instead.
+func (*AddService) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AddService) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *AddService) GetEndpoints() []string {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+// Seed is sent before every run; strategies with internal randomness
+// should reseed from it so runs are reproducible.
+type Seed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seed          int64                  `protobuf:"varint,1,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Seed) Reset() {
+	*x = Seed{}
+	mi := &file_strategy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Seed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Seed) ProtoMessage() {}
+
+func (x *Seed) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Seed.ProtoReflect.Descriptor instead.
+func (*Seed) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Seed) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// Pick asks for an endpoint, avoiding exclude when a retry is sent
+// elsewhere.  exclude should be ignored if it excludes every endpoint.
+type Pick struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Exclude       []string               `protobuf:"bytes,2,rep,name=exclude,proto3" json:"exclude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pick) Reset() {
+	*x = Pick{}
+	mi := &file_strategy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pick) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pick) ProtoMessage() {}
+
+func (x *Pick) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pick.ProtoReflect.Descriptor instead.
+func (*Pick) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Pick) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Pick) GetExclude() []string {
+	if x != nil {
+		return x.Exclude
+	}
+	return nil
+}
+
+type PickReply struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Endpoint string                 `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	// error, if set, fails the pick, e.g. for an unknown service.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PickReply) Reset() {
+	*x = PickReply{}
+	mi := &file_strategy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PickReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PickReply) ProtoMessage() {}
+
+func (x *PickReply) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PickReply.ProtoReflect.Descriptor instead.
+func (*PickReply) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PickReply) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *PickReply) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Report is the outcome of an attempt on an endpoint.
+type Report struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	LatencySec    float64                `protobuf:"fixed64,3,opt,name=latency_sec,json=latencySec,proto3" json:"latency_sec,omitempty"`
+	Success       bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Report) Reset() {
+	*x = Report{}
+	mi := &file_strategy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Report) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Report) ProtoMessage() {}
+
+func (x *Report) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Report) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Report) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Report) GetLatencySec() float64 {
+	if x != nil {
+		return x.LatencySec
+	}
+	return 0
+}
+
+func (x *Report) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Feedback is what the endpoint piggybacked on its response, sent right
+// before the Report of every attempt the endpoint answered.
+type Feedback struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Service        string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Endpoint       string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	QueueLen       int32                  `protobuf:"varint,3,opt,name=queue_len,json=queueLen,proto3" json:"queue_len,omitempty"`
+	ServiceTimeSec float64                `protobuf:"fixed64,4,opt,name=service_time_sec,json=serviceTimeSec,proto3" json:"service_time_sec,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Feedback) Reset() {
+	*x = Feedback{}
+	mi := &file_strategy_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feedback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feedback) ProtoMessage() {}
+
+func (x *Feedback) ProtoReflect() protoreflect.Message {
+	mi := &file_strategy_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feedback.ProtoReflect.Descriptor instead.
+func (*Feedback) Descriptor() ([]byte, []int) {
+	return file_strategy_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Feedback) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Feedback) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Feedback) GetQueueLen() int32 {
+	if x != nil {
+		return x.QueueLen
+	}
+	return 0
+}
+
+func (x *Feedback) GetServiceTimeSec() float64 {
+	if x != nil {
+		return x.ServiceTimeSec
+	}
+	return 0
+}
+
+var File_strategy_proto protoreflect.FileDescriptor
+
+const file_strategy_proto_rawDesc = "" +
+	"\n" +
+	"\x0estrategy.proto\x12\x16swarmroute.strategy.v1\"\xf0\x02\n" +
+	"\aRequest\x125\n" +
+	"\x05hello\x18\x01 \x01(\v2\x1d.swarmroute.strategy.v1.HelloH\x00R\x05hello\x12E\n" +
+	"\vadd_service\x18\x02 \x01(\v2\".swarmroute.strategy.v1.AddServiceH\x00R\n" +
+	"addService\x122\n" +
+	"\x04seed\x18\x03 \x01(\v2\x1c.swarmroute.strategy.v1.SeedH\x00R\x04seed\x122\n" +
+	"\x04pick\x18\x04 \x01(\v2\x1c.swarmroute.strategy.v1.PickH\x00R\x04pick\x128\n" +
+	"\x06report\x18\x05 \x01(\v2\x1e.swarmroute.strategy.v1.ReportH\x00R\x06report\x12>\n" +
+	"\bfeedback\x18\x06 \x01(\v2 .swarmroute.strategy.v1.FeedbackH\x00R\bfeedbackB\x05\n" +
+	"\x03msg\"\x86\x01\n" +
+	"\bResponse\x12:\n" +
+	"\x05hello\x18\x01 \x01(\v2\".swarmroute.strategy.v1.HelloReplyH\x00R\x05hello\x127\n" +
+	"\x04pick\x18\x02 \x01(\v2!.swarmroute.strategy.v1.PickReplyH\x00R\x04pickB\x05\n" +
+	"\x03msg\"!\n" +
+	"\x05Hello\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\" \n" +
+	"\n" +
+	"HelloReply\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"D\n" +
+	"\n" +
+	"AddService\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x1c\n" +
+	"\tendpoints\x18\x02 \x03(\tR\tendpoints\"\x1a\n" +
+	"\x04Seed\x12\x12\n" +
+	"\x04seed\x18\x01 \x01(\x03R\x04seed\":\n" +
+	"\x04Pick\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x18\n" +
+	"\aexclude\x18\x02 \x03(\tR\aexclude\"=\n" +
+	"\tPickReply\x12\x1a\n" +
+	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"y\n" +
+	"\x06Report\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12\x1f\n" +
+	"\vlatency_sec\x18\x03 \x01(\x01R\n" +
+	"latencySec\x12\x18\n" +
+	"\asuccess\x18\x04 \x01(\bR\asuccess\"\x87\x01\n" +
+	"\bFeedback\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12\x1b\n" +
+	"\tqueue_len\x18\x03 \x01(\x05R\bqueueLen\x12(\n" +
+	"\x10service_time_sec\x18\x04 \x01(\x01R\x0eserviceTimeSec2\\\n" +
+	"\bStrategy\x12P\n" +
+	"\aSession\x12\x1f.swarmroute.strategy.v1.Request\x1a .swarmroute.strategy.v1.Response(\x010\x01B\x1cZ\x1aswarmroute/grpc/strategypbb\x06proto3"
+
+var (
+	file_strategy_proto_rawDescOnce sync.Once
+	file_strategy_proto_rawDescData []byte
+)
+
+func file_strategy_proto_rawDescGZIP() []byte {
+	file_strategy_proto_rawDescOnce.Do(func() {
+		file_strategy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_strategy_proto_rawDesc), len(file_strategy_proto_rawDesc)))
+	})
+	return file_strategy_proto_rawDescData
+}
+
+var file_strategy_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_strategy_proto_goTypes = []any{
+	(*Request)(nil),    // 0: swarmroute.strategy.v1.Request
+	(*Response)(nil),   // 1: swarmroute.strategy.v1.Response
+	(*Hello)(nil),      // 2: swarmroute.strategy.v1.Hello
+	(*HelloReply)(nil), // 3: swarmroute.strategy.v1.HelloReply
+	(*AddService)(nil), // 4: swarmroute.strategy.v1.AddService
+	(*Seed)(nil),       // 5: swarmroute.strategy.v1.Seed
+	(*Pick)(nil),       // 6: swarmroute.strategy.v1.Pick
+	(*PickReply)(nil),  // 7: swarmroute.strategy.v1.PickReply
+	(*Report)(nil),     // 8: swarmroute.strategy.v1.Report
+	(*Feedback)(nil),   // 9: swarmroute.strategy.v1.Feedback
+}
+var file_strategy_proto_depIdxs = []int32{
+	2, // 0: swarmroute.strategy.v1.Request.hello:type_name -> swarmroute.strategy.v1.Hello
+	4, // 1: swarmroute.strategy.v1.Request.add_service:type_name -> swarmroute.strategy.v1.AddService
+	5, // 2: swarmroute.strategy.v1.Request.seed:type_name -> swarmroute.strategy.v1.Seed
+	6, // 3: swarmroute.strategy.v1.Request.pick:type_name -> swarmroute.strategy.v1.Pick
+	8, // 4: swarmroute.strategy.v1.Request.report:type_name -> swarmroute.strategy.v1.Report
+	9, // 5: swarmroute.strategy.v1.Request.feedback:type_name -> swarmroute.strategy.v1.Feedback
+	3, // 6: swarmroute.strategy.v1.Response.hello:type_name -> swarmroute.strategy.v1.HelloReply
+	7, // 7: swarmroute.strategy.v1.Response.pick:type_name -> swarmroute.strategy.v1.PickReply
+	0, // 8: swarmroute.strategy.v1.Strategy.Session:input_type -> swarmroute.strategy.v1.Request
+	1, // 9: swarmroute.strategy.v1.Strategy.Session:output_type -> swarmroute.strategy.v1.Response
+	9, // [9:10] is the sub-list for method output_type
+	8, // [8:9] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_strategy_proto_init() }
+func file_strategy_proto_init() {
+	if File_strategy_proto != nil {
+		return
+	}
+	file_strategy_proto_msgTypes[0].OneofWrappers = []any{
+		(*Request_Hello)(nil),
+		(*Request_AddService)(nil),
+		(*Request_Seed)(nil),
+		(*Request_Pick)(nil),
+		(*Request_Report)(nil),
+		(*Request_Feedback)(nil),
+	}
+	file_strategy_proto_msgTypes[1].OneofWrappers = []any{
+		(*Response_Hello)(nil),
+		(*Response_Pick)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_strategy_proto_rawDesc), len(file_strategy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_strategy_proto_goTypes,
+		DependencyIndexes: file_strategy_proto_depIdxs,
+		MessageInfos:      file_strategy_proto_msgTypes,
+	}.Build()
+	File_strategy_proto = out.File
+	file_strategy_proto_goTypes = nil
+	file_strategy_proto_depIdxs = nil
+}
@@ -19,25 +19,96 @@ package harness
 import (
 	"fmt"
 	"math"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
 )
 
 // MultiSeedAggregation holds per-strategy aggregated metrics across seeds.
+// The per-seed slices are in the order of Seeds, and Runs keeps the full
+// results of every seed so outliers can be investigated and reproduced.
 type MultiSeedAggregation struct {
 	Strategy       string
+	Scenario       string // Scenario.Name
+	Seeds          []int64
+	Runs           []Results
 	SuccessPct     []float64
 	P95ms          []float64
 	BadShare       []float64
+	ShedSteps      []float64
+	ReincludeSteps []float64
 	MeanSuccessPct float64
 	StdSuccessPct  float64
 	MeanP95ms      float64
 	StdP95ms       float64
 	MeanBadShare   float64
 	StdBadShare    float64
+	MeanShedSteps  float64
+	StdShedSteps   float64
+	// Mean time to re-include recovered endpoints, which penalizes
+	// strategies that keep avoiding them.
+	MeanReincludeSteps float64
+	StdReincludeSteps  float64
+	// Distribution of every metric across seeds, with a 95% confidence
+	// interval of the mean.
+	SuccessStats   SeedStats
+	P95Stats       SeedStats
+	BadShareStats  SeedStats
+	ShedStats      SeedStats
+	ReincludeStats SeedStats
+}
+
+// SeedStats summarizes a metric across seeds.  CILow..CIHigh is the 95%
+// confidence interval of the mean from Student's t distribution, which
+// accounts for the handful of seeds usually run; it is empty (the mean)
+// with a single seed.
+type SeedStats struct {
+	Mean, Std        float64
+	CILow, CIHigh    float64
+	Median, Min, Max float64
+}
+
+// tCrit95 holds the two-sided 95% critical values of Student's t
+// distribution for 1..30 degrees of freedom.
+var tCrit95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// seedStats summarizes xs.  Std is the population standard deviation, as
+// in meanStd; the confidence interval uses the sample standard deviation.
+func seedStats(xs []float64) SeedStats {
+	if len(xs) == 0 {
+		return SeedStats{}
+	}
+	st := SeedStats{}
+	st.Mean, st.Std = meanStd(xs)
+	sorted := slices.Clone(xs)
+	slices.Sort(sorted)
+	st.Min, st.Max = sorted[0], sorted[len(sorted)-1]
+	if n := len(sorted); n%2 == 1 {
+		st.Median = sorted[n/2]
+	} else {
+		st.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	st.CILow, st.CIHigh = st.Mean, st.Mean
+	if n := len(xs); n > 1 {
+		t := 1.96 // normal approximation beyond the table
+		if n-1 <= len(tCrit95) {
+			t = tCrit95[n-2]
+		}
+		half := t * st.Std / math.Sqrt(float64(n-1)) // sample std / √n
+		st.CILow, st.CIHigh = st.Mean-half, st.Mean+half
+	}
+	return st
 }
 
 // AggregateMultiSeed runs the given scenario across multiple seeds for all strategies
-// and aggregates the required metrics (overall success%, overall p95 latency, and
-// bad-window share to the degraded endpoint).
+// and aggregates the required metrics (overall success%, overall p95 latency,
+// bad-window share to the degraded endpoint and mean times to shed it and
+// to re-include it after it recovers).
 func AggregateMultiSeed(sc Scenario, strategies []Strategy, seeds []int64) []MultiSeedAggregation {
 	// results by strategy name
 	agg := make(map[string]*MultiSeedAggregation)
@@ -47,16 +118,20 @@ func AggregateMultiSeed(sc Scenario, strategies []Strategy, seeds []int64) []Mul
 		for _, r := range rs {
 			a, ok := agg[r.Strategy]
 			if !ok {
-				a = &MultiSeedAggregation{Strategy: r.Strategy}
+				a = &MultiSeedAggregation{Strategy: r.Strategy, Scenario: sc.Name}
 				agg[r.Strategy] = a
 			}
 			succPct := 0.0
 			if r.Total > 0 {
 				succPct = 100.0 * float64(r.Success) / float64(r.Total)
 			}
+			a.Seeds = append(a.Seeds, seed)
+			a.Runs = append(a.Runs, r)
 			a.SuccessPct = append(a.SuccessPct, succPct)
 			a.P95ms = append(a.P95ms, r.P95LatMS)
 			a.BadShare = append(a.BadShare, 100.0*r.BadWindowDegradedShare) // percent
+			a.ShedSteps = append(a.ShedSteps, r.MeanShedSteps)
+			a.ReincludeSteps = append(a.ReincludeSteps, r.MeanReincludeSteps)
 		}
 	}
 
@@ -65,6 +140,10 @@ func AggregateMultiSeed(sc Scenario, strategies []Strategy, seeds []int64) []Mul
 		a.MeanSuccessPct, a.StdSuccessPct = meanStd(a.SuccessPct)
 		a.MeanP95ms, a.StdP95ms = meanStd(a.P95ms)
 		a.MeanBadShare, a.StdBadShare = meanStd(a.BadShare)
+		a.MeanShedSteps, a.StdShedSteps = meanStd(a.ShedSteps)
+		a.MeanReincludeSteps, a.StdReincludeSteps = meanStd(a.ReincludeSteps)
+		a.SuccessStats, a.P95Stats, a.BadShareStats = seedStats(a.SuccessPct), seedStats(a.P95ms), seedStats(a.BadShare)
+		a.ShedStats, a.ReincludeStats = seedStats(a.ShedSteps), seedStats(a.ReincludeSteps)
 		out = append(out, *a)
 	}
 	return out
@@ -91,12 +170,159 @@ func meanStd(xs []float64) (mean, std float64) {
 	return
 }
 
-// FormatAggregatedResults renders mean±stddev for the chosen metrics per strategy.
+// FormatAggregatedResults renders mean±stddev for the chosen metrics per
+// strategy, followed by their 95% confidence intervals, medians and ranges.
 func FormatAggregatedResults(aggs []MultiSeedAggregation) string {
 	s := ""
 	for _, a := range aggs {
-		s += fmt.Sprintf("%s: success=%.2f%% ± %.2f, p95=%.2fms ± %.2f, bad-window share=%.2f%% ± %.2f\n",
-			a.Strategy, a.MeanSuccessPct, a.StdSuccessPct, a.MeanP95ms, a.StdP95ms, a.MeanBadShare, a.StdBadShare)
+		s += fmt.Sprintf("%s: success=%.2f%% ± %.2f, p95=%.2fms ± %.2f, bad-window share=%.2f%% ± %.2f, time-to-shed=%.0f ± %.0f steps, time-to-reinclude=%.0f ± %.0f steps\n",
+			a.Strategy, a.MeanSuccessPct, a.StdSuccessPct, a.MeanP95ms, a.StdP95ms, a.MeanBadShare, a.StdBadShare,
+			a.MeanShedSteps, a.StdShedSteps, a.MeanReincludeSteps, a.StdReincludeSteps)
+		s += formatSeedStats("success %", a.SuccessStats, 2)
+		s += formatSeedStats("p95 ms", a.P95Stats, 2)
+		s += formatSeedStats("bad-window %", a.BadShareStats, 2)
+		s += formatSeedStats("time-to-shed", a.ShedStats, 0)
+		s += formatSeedStats("time-to-reinclude", a.ReincludeStats, 0)
+	}
+	return s
+}
+
+// formatSeedStats renders the confidence interval, median and range of st
+// with prec decimals.
+func formatSeedStats(name string, st SeedStats, prec int) string {
+	return fmt.Sprintf("  %-18s 95%% CI [%.*f, %.*f]  median=%.*f  min=%.*f  max=%.*f\n",
+		name+":", prec, st.CILow, prec, st.CIHigh, prec, st.Median, prec, st.Min, prec, st.Max)
+}
+
+// FormatPerSeedResults renders the per-seed values behind every aggregate,
+// one row per seed, to spot outliers and the seed to rerun them with.
+func FormatPerSeedResults(aggs []MultiSeedAggregation) string {
+	s := ""
+	for _, a := range aggs {
+		s += a.Strategy + ":\n"
+		for i, seed := range a.Seeds {
+			s += fmt.Sprintf("  seed=%d: success=%.2f%%, p95=%.2fms, bad-window share=%.2f%%, time-to-shed=%.0f steps, time-to-reinclude=%.0f steps\n",
+				seed, a.SuccessPct[i], a.P95ms[i], a.BadShare[i], a.ShedSteps[i], a.ReincludeSteps[i])
+		}
 	}
 	return s
 }
+
+// rankMetrics are the metrics FormatRankTable ranks strategies by, with
+// whether higher is better.
+var rankMetrics = []struct {
+	name   string
+	value  func(a MultiSeedAggregation) float64
+	higher bool
+}{
+	{"success", func(a MultiSeedAggregation) float64 { return a.MeanSuccessPct }, true},
+	{"p95", func(a MultiSeedAggregation) float64 { return a.MeanP95ms }, false},
+	{"bad-share", func(a MultiSeedAggregation) float64 { return a.MeanBadShare }, false},
+	{"shed", func(a MultiSeedAggregation) float64 { return a.MeanShedSteps }, false},
+	{"reinclude", func(a MultiSeedAggregation) float64 { return a.MeanReincludeSteps }, false},
+}
+
+// FormatRankTable renders a table ranking the strategies on every
+// aggregated metric (1 is best; ties share a rank) and overall by their
+// mean rank, best first.
+func FormatRankTable(aggs []MultiSeedAggregation) string {
+	ranks := make([][]int, len(aggs))
+	for i := range aggs {
+		ranks[i] = make([]int, len(rankMetrics))
+	}
+	for m, metric := range rankMetrics {
+		for i, a := range aggs {
+			v := metric.value(a)
+			rank := 1
+			for _, b := range aggs {
+				w := metric.value(b)
+				if (metric.higher && w > v) || (!metric.higher && w < v) {
+					rank++
+				}
+			}
+			ranks[i][m] = rank
+		}
+	}
+	overall := make([]float64, len(aggs))
+	for i := range aggs {
+		for _, r := range ranks[i] {
+			overall[i] += float64(r)
+		}
+		overall[i] /= float64(len(rankMetrics))
+	}
+	order := make([]int, len(aggs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(x, y int) bool {
+		i, j := order[x], order[y]
+		if overall[i] != overall[j] {
+			return overall[i] < overall[j]
+		}
+		return aggs[i].Strategy < aggs[j].Strategy
+	})
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "rank\tstrategy")
+	for _, metric := range rankMetrics {
+		fmt.Fprint(w, "\t"+metric.name)
+	}
+	fmt.Fprintln(w, "\tmean rank")
+	for pos, i := range order {
+		fmt.Fprintf(w, "%d\t%s", pos+1, aggs[i].Strategy)
+		for _, r := range ranks[i] {
+			fmt.Fprintf(w, "\t%d", r)
+		}
+		fmt.Fprintf(w, "\t%.1f\n", overall[i])
+	}
+	w.Flush()
+	return b.String()
+}
+
+// FormatScoreTable renders the strategies' objective scores, best first.
+// If baseline names one of the strategies, it adds the difference from
+// its score, which is the weighted sum of the metric differences, e.g.
+// 0.5·Δsuccess − 0.3·Δp95 − 0.2·Δbad-share.
+func FormatScoreTable(aggs []MultiSeedAggregation, o Objective, baseline string) string {
+	scores, base, hasBase := objectiveScores(aggs, o, baseline)
+	order := make([]int, len(aggs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(x, y int) bool { return scores[order[x]] > scores[order[y]] })
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "rank\tstrategy\tscore")
+	if hasBase {
+		fmt.Fprint(w, "\tvs "+baseline)
+	}
+	fmt.Fprintln(w)
+	for pos, i := range order {
+		fmt.Fprintf(w, "%d\t%s\t%.2f", pos+1, aggs[i].Strategy, scores[i])
+		if hasBase {
+			d := scores[i] - base
+			if math.Abs(d) < 0.005 {
+				d = 0 // not -0.00
+			}
+			fmt.Fprintf(w, "\t%+.2f", d)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// objectiveScores returns the score of every aggregation and, if baseline
+// is among them, the baseline's.
+func objectiveScores(aggs []MultiSeedAggregation, o Objective, baseline string) (scores []float64, base float64, hasBase bool) {
+	scores = make([]float64, len(aggs))
+	for i, a := range aggs {
+		scores[i] = o.ScoreAggregation(a)
+		if a.Strategy == baseline {
+			base, hasBase = scores[i], true
+		}
+	}
+	return scores, base, hasBase
+}
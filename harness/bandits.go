@@ -0,0 +1,477 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+)
+
+// ThompsonStrategy treats endpoints as arms of a Beta-Bernoulli bandit: it
+// draws a success rate for each from the Beta posterior of its successes
+// and failures and picks the highest draw.  With the latency model, it
+// also draws a mean latency from a Gaussian posterior and picks the highest
+// success rate per second instead.  A discount below 1 fades old
+// observations by that factor per report to the service, so the posterior
+// follows drift instead of hardening.
+type ThompsonStrategy struct {
+	rng      *rand.Rand
+	services map[string][]string
+	latency  bool
+	discount float64
+	arms     map[string]map[string]*thompsonArm
+}
+
+// thompsonArm holds the (discounted) observations of an endpoint.
+type thompsonArm struct {
+	Successes, Failures float64
+	N, Sum, SumSq       float64 // latency observations, in seconds
+}
+
+// NewThompsonStrategy returns a Thompson sampling strategy; a discount
+// outside (0, 1] means 1, no discounting.
+func NewThompsonStrategy(seed int64, latency bool, discount float64) *ThompsonStrategy {
+	if discount <= 0 || discount > 1 {
+		discount = 1
+	}
+	return &ThompsonStrategy{rng: rand.New(rand.NewSource(seed)), services: make(map[string][]string), latency: latency, discount: discount, arms: make(map[string]map[string]*thompsonArm)}
+}
+
+// Name tells the latency model apart so both variants can be compared.
+func (s *ThompsonStrategy) Name() string {
+	if s.latency {
+		return "ThompsonLatency"
+	}
+	return "Thompson"
+}
+
+func (s *ThompsonStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
+
+// MarshalState and UnmarshalState checkpoint the observations.
+func (s *ThompsonStrategy) MarshalState() ([]byte, error)    { return json.Marshal(s.arms) }
+func (s *ThompsonStrategy) UnmarshalState(data []byte) error { return json.Unmarshal(data, &s.arms) }
+
+func (s *ThompsonStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	if _, ok := s.arms[name]; !ok {
+		s.arms[name] = make(map[string]*thompsonArm)
+	}
+	for _, e := range endpoints {
+		if _, ok := s.arms[name][e]; !ok {
+			s.arms[name][e] = &thompsonArm{}
+		}
+	}
+}
+
+func (s *ThompsonStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *ThompsonStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	best, bestScore := "", math.Inf(-1)
+	for _, e := range eps {
+		if score := s.sample(s.arms[service][e]); score > bestScore {
+			best, bestScore = e, score
+		}
+	}
+	return best, nil
+}
+
+// sample draws the score of arm: a success rate, or with the latency
+// model a success rate per second of mean latency.  An arm without a
+// latency observation scores +Inf, so every endpoint gets tried.
+func (s *ThompsonStrategy) sample(arm *thompsonArm) float64 {
+	if arm == nil {
+		arm = &thompsonArm{}
+	}
+	p := betaSample(s.rng, 1+arm.Successes, 1+arm.Failures)
+	if !s.latency {
+		return p
+	}
+	if arm.N < 1e-9 {
+		return math.Inf(1)
+	}
+	// The mean's posterior under a flat prior has the sample variance over
+	// n; with fewer than two observations the mean squared stands in for
+	// the unknown variance.
+	mean := arm.Sum / arm.N
+	variance := mean * mean
+	if arm.N >= 2 {
+		variance = max(arm.SumSq/arm.N-mean*mean, 0)
+	}
+	mu := max(mean+s.rng.NormFloat64()*math.Sqrt(variance/arm.N), 1e-6)
+	return p / mu
+}
+
+func (s *ThompsonStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	arms := s.arms[service]
+	if arms == nil {
+		arms = make(map[string]*thompsonArm)
+		s.arms[service] = arms
+	}
+	if s.discount < 1 {
+		for _, arm := range arms {
+			arm.Successes *= s.discount
+			arm.Failures *= s.discount
+			arm.N *= s.discount
+			arm.Sum *= s.discount
+			arm.SumSq *= s.discount
+		}
+	}
+	arm := arms[endpoint]
+	if arm == nil {
+		arm = &thompsonArm{}
+		arms[endpoint] = arm
+	}
+	if success {
+		arm.Successes++
+	} else {
+		arm.Failures++
+	}
+	arm.N++
+	arm.Sum += latencySec
+	arm.SumSq += latencySec * latencySec
+}
+
+// betaSample draws from Beta(a, b) as X/(X+Y) with X ~ Gamma(a) and
+// Y ~ Gamma(b).
+func betaSample(rng *rand.Rand, a, b float64) float64 {
+	x := gammaSample(rng, a)
+	return x / (x + gammaSample(rng, b))
+}
+
+// gammaSample draws from Gamma(shape, 1) with Marsaglia and Tsang's method,
+// boosting shapes below 1 by U^(1/shape).
+func gammaSample(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		return gammaSample(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// UCBStrategy is UCB1 over a sliding window (Garivier and Moulines'
+// SW-UCB): it picks the endpoint with the highest mean reward plus
+// c·sqrt(2 ln n / n_i), where n counts the service's last window reports
+// and n_i those of the endpoint.  The reward of a request mixes success
+// and latency, (1-w)·success + w·success·(1 - latency/max) with max the
+// slowest latency in the window, so a failure is worth 0.  An endpoint
+// without a report in the window scores +Inf, so shed endpoints get
+// retried once they fall out of it.
+type UCBStrategy struct {
+	services      map[string][]string
+	window        int
+	c             float64 // exploration weight
+	latencyWeight float64 // w
+	reports       map[string][]ucbReport
+}
+
+type ucbReport struct {
+	Endpoint   string
+	LatencySec float64
+	Success    bool
+}
+
+// NewUCBStrategy returns a sliding-window UCB1 strategy; a window below 1
+// means 200, a negative c means 1 and a latency weight outside [0, 1]
+// means 0.5.
+func NewUCBStrategy(window int, c, latencyWeight float64) *UCBStrategy {
+	if window < 1 {
+		window = 200
+	}
+	if c < 0 {
+		c = 1
+	}
+	if latencyWeight < 0 || latencyWeight > 1 {
+		latencyWeight = 0.5
+	}
+	return &UCBStrategy{services: make(map[string][]string), window: window, c: c, latencyWeight: latencyWeight, reports: make(map[string][]ucbReport)}
+}
+
+func (s *UCBStrategy) Name() string { return "UCB1" }
+
+// MarshalState and UnmarshalState checkpoint the window.
+func (s *UCBStrategy) MarshalState() ([]byte, error)    { return json.Marshal(s.reports) }
+func (s *UCBStrategy) UnmarshalState(data []byte) error { return json.Unmarshal(data, &s.reports) }
+
+func (s *UCBStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+}
+
+func (s *UCBStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *UCBStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	reports := s.reports[service]
+	maxLat := 0.0
+	for _, r := range reports {
+		maxLat = max(maxLat, r.LatencySec)
+	}
+	n, sum := make(map[string]float64), make(map[string]float64)
+	for _, r := range reports {
+		n[r.Endpoint]++
+		if r.Success {
+			reward := 1.0
+			if maxLat > 0 {
+				reward -= s.latencyWeight * r.LatencySec / maxLat
+			}
+			sum[r.Endpoint] += reward
+		}
+	}
+	logN := math.Log(float64(len(reports)))
+	best, bestScore := "", math.Inf(-1)
+	for _, e := range eps {
+		score := math.Inf(1)
+		if n[e] > 0 {
+			score = sum[e]/n[e] + s.c*math.Sqrt(2*logN/n[e])
+		}
+		if score > bestScore {
+			best, bestScore = e, score
+		}
+	}
+	return best, nil
+}
+
+func (s *UCBStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	reports := append(s.reports[service], ucbReport{endpoint, latencySec, success})
+	if len(reports) > s.window {
+		reports = append(reports[:0], reports[len(reports)-s.window:]...)
+	}
+	s.reports[service] = reports
+}
+
+// EpsilonGreedyStrategy picks the endpoint with the lowest latency average
+// (EWMA), except with probability epsilon, when it picks uniformly at
+// random.  Epsilon decays with the reports to the service, halving every
+// halfLife of them, so the strategy explores less as it learns; endpoints
+// without an average are picked first.
+type EpsilonGreedyStrategy struct {
+	slowThreshold
+	rng      *rand.Rand
+	services map[string][]string
+	alpha    float64
+	epsilon  float64 // before any report
+	halfLife float64 // reports per halving of epsilon
+	state    epsilonGreedyState
+}
+
+type epsilonGreedyState struct {
+	EWMA    map[string]map[string]float64 // service -> endpoint -> ewma seconds
+	Reports map[string]int                // service -> reports so far
+}
+
+// NewEpsilonGreedyStrategy returns an epsilon-greedy strategy; alpha outside
+// (0, 1) means 0.2, epsilon outside [0, 1] means 0.1 and a halfLife below 1
+// means 1000.
+func NewEpsilonGreedyStrategy(seed int64, alpha, epsilon float64, halfLife int) *EpsilonGreedyStrategy {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0.2
+	}
+	if epsilon < 0 || epsilon > 1 {
+		epsilon = 0.1
+	}
+	if halfLife < 1 {
+		halfLife = 1000
+	}
+	return &EpsilonGreedyStrategy{rng: rand.New(rand.NewSource(seed)), services: make(map[string][]string), alpha: alpha, epsilon: epsilon, halfLife: float64(halfLife),
+		state: epsilonGreedyState{EWMA: make(map[string]map[string]float64), Reports: make(map[string]int)}}
+}
+
+func (s *EpsilonGreedyStrategy) Name() string { return "EpsilonGreedy" }
+
+func (s *EpsilonGreedyStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
+
+// MarshalState and UnmarshalState checkpoint the latency averages and the
+// report counts epsilon decays with.
+func (s *EpsilonGreedyStrategy) MarshalState() ([]byte, error) { return json.Marshal(s.state) }
+func (s *EpsilonGreedyStrategy) UnmarshalState(data []byte) error {
+	return json.Unmarshal(data, &s.state)
+}
+
+func (s *EpsilonGreedyStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	if _, ok := s.state.EWMA[name]; !ok {
+		s.state.EWMA[name] = make(map[string]float64)
+	}
+}
+
+// Epsilon returns the current exploration probability for service.
+func (s *EpsilonGreedyStrategy) Epsilon(service string) float64 {
+	return s.epsilon * math.Exp2(-float64(s.state.Reports[service])/s.halfLife)
+}
+
+func (s *EpsilonGreedyStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *EpsilonGreedyStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	if s.rng.Float64() < s.Epsilon(service) {
+		return eps[s.rng.Intn(len(eps))], nil
+	}
+	best := eps[0]
+	for _, e := range eps[1:] {
+		if v := s.state.EWMA[service][e]; v < s.state.EWMA[service][best] {
+			best = e
+		}
+	}
+	return best, nil
+}
+
+func (s *EpsilonGreedyStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	latencySec, success = s.judge(latencySec, success)
+	if _, ok := s.state.EWMA[service]; !ok {
+		s.state.EWMA[service] = make(map[string]float64)
+	}
+	s.state.Reports[service]++
+	if cur := s.state.EWMA[service][endpoint]; cur == 0 {
+		s.state.EWMA[service][endpoint] = latencySec
+	} else {
+		s.state.EWMA[service][endpoint] = s.alpha*latencySec + (1-s.alpha)*cur
+	}
+}
+
+// SoftmaxStrategy picks endpoints with Boltzmann probabilities over their
+// negative latency averages (EWMA), p ∝ exp(-latency/temperature), so an
+// endpoint temperature seconds slower is e times less likely.  It is
+// SwarmRoute's weighted sampling with a single, latency-only signal: no
+// negative pheromone, so failures only count through the latency penalty
+// the harness reports them with.  Endpoints without an average weigh as
+// much as a zero latency, so they get tried early.
+type SoftmaxStrategy struct {
+	slowThreshold
+	rng         *rand.Rand
+	services    map[string][]string
+	alpha       float64
+	temperature float64 // seconds
+	ewma        map[string]map[string]float64
+}
+
+// NewSoftmaxStrategy returns a softmax strategy; alpha outside (0, 1) means
+// 0.2 and a temperature not above 0 means 0.01 (10ms).
+func NewSoftmaxStrategy(seed int64, alpha, temperature float64) *SoftmaxStrategy {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0.2
+	}
+	if temperature <= 0 {
+		temperature = 0.01
+	}
+	return &SoftmaxStrategy{rng: rand.New(rand.NewSource(seed)), services: make(map[string][]string), alpha: alpha, temperature: temperature, ewma: make(map[string]map[string]float64)}
+}
+
+func (s *SoftmaxStrategy) Name() string { return "Softmax" }
+
+func (s *SoftmaxStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
+
+// MarshalState and UnmarshalState checkpoint the latency averages.
+func (s *SoftmaxStrategy) MarshalState() ([]byte, error)    { return json.Marshal(s.ewma) }
+func (s *SoftmaxStrategy) UnmarshalState(data []byte) error { return json.Unmarshal(data, &s.ewma) }
+
+func (s *SoftmaxStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	if _, ok := s.ewma[name]; !ok {
+		s.ewma[name] = make(map[string]float64)
+	}
+}
+
+// probabilities returns the pick probability of each of eps.
+func (s *SoftmaxStrategy) probabilities(service string, eps []string) []float64 {
+	lo := math.Inf(1)
+	for _, e := range eps {
+		lo = min(lo, s.ewma[service][e])
+	}
+	ps, sum := make([]float64, len(eps)), 0.0
+	for i, e := range eps {
+		// Shifting by the lowest average keeps exp from underflowing.
+		ps[i] = math.Exp(-(s.ewma[service][e] - lo) / s.temperature)
+		sum += ps[i]
+	}
+	for i := range ps {
+		ps[i] /= sum
+	}
+	return ps
+}
+
+func (s *SoftmaxStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *SoftmaxStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	u := s.rng.Float64()
+	for i, p := range s.probabilities(service, eps) {
+		if u -= p; u < 0 {
+			return eps[i], nil
+		}
+	}
+	return eps[len(eps)-1], nil
+}
+
+// PickEndpointExplained scores candidates by their latency average in
+// seconds, with their softmax probabilities.
+func (s *SoftmaxStrategy) PickEndpointExplained(service string) (string, []CandidateScore, error) {
+	best, err := s.PickEndpoint(service)
+	if err != nil {
+		return "", nil, err
+	}
+	eps := s.services[service]
+	scores := make([]CandidateScore, len(eps))
+	for i, p := range s.probabilities(service, eps) {
+		scores[i] = CandidateScore{Endpoint: eps[i], Score: s.ewma[service][eps[i]], Probability: p}
+	}
+	return best, scores, nil
+}
+
+func (s *SoftmaxStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	latencySec, success = s.judge(latencySec, success)
+	if _, ok := s.ewma[service]; !ok {
+		s.ewma[service] = make(map[string]float64)
+	}
+	if cur := s.ewma[service][endpoint]; cur == 0 {
+		s.ewma[service][endpoint] = latencySec
+	} else {
+		s.ewma[service][endpoint] = s.alpha*latencySec + (1-s.alpha)*cur
+	}
+}
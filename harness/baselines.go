@@ -17,8 +17,15 @@
 package harness
 
 import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
 	"math"
 	"math/rand"
+	"slices"
+	"strconv"
 )
 
 // RandomStrategy selects uniformly at random among endpoints for a service.
@@ -33,12 +40,18 @@ func NewRandomStrategy(seed int64) *RandomStrategy {
 
 func (s *RandomStrategy) Name() string { return "Random" }
 
+func (s *RandomStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
+
 func (s *RandomStrategy) AddService(name string, endpoints []string) {
 	s.services[name] = append([]string{}, endpoints...)
 }
 
 func (s *RandomStrategy) PickEndpoint(service string) (string, error) {
-	eps := s.services[service]
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *RandomStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
 	if len(eps) == 0 {
 		return "", ErrNoEndpoints
 	}
@@ -59,16 +72,32 @@ func NewRoundRobinStrategy() *RoundRobinStrategy {
 
 func (s *RoundRobinStrategy) Name() string { return "RoundRobin" }
 
+// MarshalState and UnmarshalState checkpoint the rotation positions.
+func (s *RoundRobinStrategy) MarshalState() ([]byte, error)    { return json.Marshal(s.idx) }
+func (s *RoundRobinStrategy) UnmarshalState(data []byte) error { return json.Unmarshal(data, &s.idx) }
+
 func (s *RoundRobinStrategy) AddService(name string, endpoints []string) {
 	s.services[name] = append([]string{}, endpoints...)
 }
 
 func (s *RoundRobinStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+// PickEndpointExcluding continues the rotation at the next endpoint that is
+// not excluded.
+func (s *RoundRobinStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
 	eps := s.services[service]
 	if len(eps) == 0 {
 		return "", ErrNoEndpoints
 	}
 	i := s.idx[service]
+	for k := range eps {
+		if addr := eps[(i+k)%len(eps)]; !slices.Contains(exclude, addr) {
+			s.idx[service] = (i + k + 1) % len(eps)
+			return addr, nil
+		}
+	}
 	addr := eps[i%len(eps)]
 	s.idx[service] = (i + 1) % len(eps)
 	return addr, nil
@@ -77,84 +106,806 @@ func (s *RoundRobinStrategy) PickEndpoint(service string) (string, error) {
 func (s *RoundRobinStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
 }
 
-// PowerOfTwoChoicesStrategy samples two random endpoints and chooses the one
-// with lower observed average latency (EWMA). If no data, falls back to random.
-type PowerOfTwoChoicesStrategy struct {
+// WeightedRoundRobinStrategy cycles endpoints in proportion to static
+// weights with NGINX's smooth weighted round-robin, which interleaves the
+// picks instead of sending runs of requests to heavy endpoints.  Endpoints
+// without a weight have weight 1; those with weight 0 only get picked if
+// every candidate has weight 0.
+type WeightedRoundRobinStrategy struct {
+	weights  map[string]int
+	services map[string][]string
+	current  map[string]map[string]int
+}
+
+// NewWeightedRoundRobinStrategy returns a WRR strategy with weights by
+// endpoint address.
+func NewWeightedRoundRobinStrategy(weights map[string]int) *WeightedRoundRobinStrategy {
+	return &WeightedRoundRobinStrategy{weights: maps.Clone(weights), services: make(map[string][]string), current: make(map[string]map[string]int)}
+}
+
+func (s *WeightedRoundRobinStrategy) Name() string { return "WeightedRoundRobin" }
+
+// MarshalState and UnmarshalState checkpoint the current weights.
+func (s *WeightedRoundRobinStrategy) MarshalState() ([]byte, error) { return json.Marshal(s.current) }
+func (s *WeightedRoundRobinStrategy) UnmarshalState(data []byte) error {
+	return json.Unmarshal(data, &s.current)
+}
+
+func (s *WeightedRoundRobinStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	if _, ok := s.current[name]; !ok {
+		s.current[name] = make(map[string]int)
+	}
+}
+
+func (s *WeightedRoundRobinStrategy) weight(addr string) int {
+	if w, ok := s.weights[addr]; ok {
+		return max(w, 0)
+	}
+	return 1
+}
+
+func (s *WeightedRoundRobinStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+// PickEndpointExcluding runs the smooth WRR step over the endpoints not
+// excluded: each gains its weight, the one with the highest current weight
+// is picked and loses the total.
+func (s *WeightedRoundRobinStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	weight := s.weight
+	if !slices.ContainsFunc(eps, func(e string) bool { return s.weight(e) > 0 }) {
+		weight = func(string) int { return 1 }
+	}
+	cur := s.current[service]
+	best, total := "", 0
+	for _, e := range eps {
+		cur[e] += weight(e)
+		total += weight(e)
+		if best == "" || cur[e] > cur[best] {
+			best = e
+		}
+	}
+	cur[best] -= total
+	return best, nil
+}
+
+func (s *WeightedRoundRobinStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+}
+
+// OutlierEjectionStrategy round-robins over the endpoints that are not
+// ejected, like Envoy's outlier detection: an endpoint whose error rate
+// over its last window results (at least half a window of them) exceeds
+// threshold is ejected for cooldown picks to the service, times the
+// number of times it has been ejected in a row, then re-admitted with a
+// fresh window.  At most maxEjected of the endpoints are out at once, but
+// always allowing one.  Unlike a circuit breaker it needs no probe
+// traffic: ejection ends on the clock.
+type OutlierEjectionStrategy struct {
+	services   map[string][]string
+	window     int
+	threshold  float64
+	cooldown   int
+	maxEjected float64
+	state      outlierState
+}
+
+type outlierState struct {
+	Next         map[string]int               // rotation position
+	Picks        map[string]int               // picks to the service, the ejection clock
+	Results      map[string]map[string][]bool // the endpoint's last results, true for a failure
+	EjectedUntil map[string]map[string]int    // pick count at which the endpoint is re-admitted
+	Ejections    map[string]map[string]int    // ejections in a row
+}
+
+// NewOutlierEjectionStrategy returns an outlier ejection strategy; a
+// window below 2 means 20, a threshold outside (0, 1) means 0.5, a
+// cooldown below 1 means 200 picks and a maxEjected outside [0, 1] means
+// 0.5.
+func NewOutlierEjectionStrategy(window int, threshold float64, cooldown int, maxEjected float64) *OutlierEjectionStrategy {
+	if window < 2 {
+		window = 20
+	}
+	if threshold <= 0 || threshold >= 1 {
+		threshold = 0.5
+	}
+	if cooldown < 1 {
+		cooldown = 200
+	}
+	if maxEjected < 0 || maxEjected > 1 {
+		maxEjected = 0.5
+	}
+	return &OutlierEjectionStrategy{services: make(map[string][]string), window: window, threshold: threshold, cooldown: cooldown, maxEjected: maxEjected,
+		state: outlierState{Next: make(map[string]int), Picks: make(map[string]int), Results: make(map[string]map[string][]bool),
+			EjectedUntil: make(map[string]map[string]int), Ejections: make(map[string]map[string]int)}}
+}
+
+func (s *OutlierEjectionStrategy) Name() string { return "OutlierEjection" }
+
+// MarshalState and UnmarshalState checkpoint the windows and ejections.
+func (s *OutlierEjectionStrategy) MarshalState() ([]byte, error) { return json.Marshal(s.state) }
+func (s *OutlierEjectionStrategy) UnmarshalState(data []byte) error {
+	return json.Unmarshal(data, &s.state)
+}
+
+func (s *OutlierEjectionStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	if _, ok := s.state.Results[name]; !ok {
+		s.state.Results[name] = make(map[string][]bool)
+		s.state.EjectedUntil[name] = make(map[string]int)
+		s.state.Ejections[name] = make(map[string]int)
+	}
+}
+
+// Ejected returns the endpoints of service currently ejected, sorted.
+func (s *OutlierEjectionStrategy) Ejected(service string) []string {
+	return slices.Sorted(maps.Keys(s.state.EjectedUntil[service]))
+}
+
+func (s *OutlierEjectionStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *OutlierEjectionStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	s.state.Picks[service]++
+	until := s.state.EjectedUntil[service]
+	for e, t := range until {
+		if s.state.Picks[service] >= t {
+			delete(until, e)
+		}
+	}
+	eps := without(without(s.services[service], s.Ejected(service)), exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	i := s.state.Next[service] % len(eps)
+	s.state.Next[service] = i + 1
+	return eps[i], nil
+}
+
+func (s *OutlierEjectionStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	if _, ok := s.state.Results[service]; !ok {
+		s.AddService(service, nil)
+	}
+	if _, ejected := s.state.EjectedUntil[service][endpoint]; ejected {
+		// A late result of a request picked before the ejection.
+		return
+	}
+	results := append(s.state.Results[service][endpoint], !success)
+	if len(results) > s.window {
+		results = results[len(results)-s.window:]
+	}
+	s.state.Results[service][endpoint] = results
+	failures := 0
+	for _, failed := range results {
+		if failed {
+			failures++
+		}
+	}
+	if len(results) < (s.window+1)/2 || float64(failures) <= s.threshold*float64(len(results)) {
+		if len(results) == s.window {
+			s.state.Ejections[service][endpoint] = 0
+		}
+		return
+	}
+	if len(s.state.EjectedUntil[service])+1 > max(1, int(s.maxEjected*float64(len(s.services[service])))) {
+		return
+	}
+	s.state.Ejections[service][endpoint]++
+	s.state.EjectedUntil[service][endpoint] = s.state.Picks[service] + s.cooldown*s.state.Ejections[service][endpoint]
+	delete(s.state.Results[service], endpoint)
+}
+
+// LeastConnectionsStrategy picks the endpoint with the fewest outstanding
+// requests, counted from pick to report, like least_conn in NGINX and
+// LEAST_REQUEST in Envoy.  Ties go round-robin.  It only differs from
+// RoundRobin when requests overlap, i.e. in open-loop runs (Scenario.Load)
+// and populations.
+type LeastConnectionsStrategy struct {
+	services map[string][]string
+	state    leastConnState
+}
+
+type leastConnState struct {
+	InFlight map[string]map[string]int
+	Next     map[string]int // where the round-robin tie-break starts
+}
+
+func NewLeastConnectionsStrategy() *LeastConnectionsStrategy {
+	return &LeastConnectionsStrategy{services: make(map[string][]string),
+		state: leastConnState{InFlight: make(map[string]map[string]int), Next: make(map[string]int)}}
+}
+
+func (s *LeastConnectionsStrategy) Name() string { return "LeastConnections" }
+
+// MarshalState and UnmarshalState checkpoint the outstanding requests and
+// the tie-break position.
+func (s *LeastConnectionsStrategy) MarshalState() ([]byte, error) { return json.Marshal(s.state) }
+func (s *LeastConnectionsStrategy) UnmarshalState(data []byte) error {
+	return json.Unmarshal(data, &s.state)
+}
+
+func (s *LeastConnectionsStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	if _, ok := s.state.InFlight[name]; !ok {
+		s.state.InFlight[name] = make(map[string]int)
+	}
+}
+
+func (s *LeastConnectionsStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *LeastConnectionsStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	inFlight, start := s.state.InFlight[service], s.state.Next[service]
+	best := start % len(eps)
+	for k := 1; k < len(eps); k++ {
+		if i := (start + k) % len(eps); inFlight[eps[i]] < inFlight[eps[best]] {
+			best = i
+		}
+	}
+	s.state.Next[service] = best + 1
+	inFlight[eps[best]]++
+	return eps[best], nil
+}
+
+func (s *LeastConnectionsStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	if inFlight := s.state.InFlight[service]; inFlight[endpoint] > 0 {
+		inFlight[endpoint]--
+	}
+}
+
+// ConsistentHashStrategy maps request keys (Scenario.Keys) to endpoints on
+// a hash ring with replicas virtual nodes per endpoint, so a key sticks to
+// its endpoint and only the keys of an endpoint that leaves or joins move.
+// With bounded loads (Mirrokni et al., as in HAProxy and Envoy), an
+// endpoint with more than 1+epsilon times the mean outstanding requests
+// spills its keys to the next endpoint clockwise; like LeastConnections
+// this only binds when requests overlap.  Picks without a key hash a
+// counter, spreading them evenly.
+type ConsistentHashStrategy struct {
+	replicas int
+	epsilon  float64
+	services map[string][]string
+	rings    map[string][]ringNode
+	state    leastConnState // Next counts keyless picks
+}
+
+type ringNode struct {
+	hash     uint64
+	endpoint string
+}
+
+// NewConsistentHashStrategy returns a consistent hashing strategy; replicas
+// below 1 mean 100 and a negative epsilon means 0.25.  An epsilon of +Inf
+// turns bounded loads off.
+func NewConsistentHashStrategy(replicas int, epsilon float64) *ConsistentHashStrategy {
+	if replicas < 1 {
+		replicas = 100
+	}
+	if epsilon < 0 {
+		epsilon = 0.25
+	}
+	return &ConsistentHashStrategy{replicas: replicas, epsilon: epsilon, services: make(map[string][]string), rings: make(map[string][]ringNode),
+		state: leastConnState{InFlight: make(map[string]map[string]int), Next: make(map[string]int)}}
+}
+
+func (s *ConsistentHashStrategy) Name() string { return "ConsistentHash" }
+
+// MarshalState and UnmarshalState checkpoint the outstanding requests; the
+// ring follows from the endpoints.
+func (s *ConsistentHashStrategy) MarshalState() ([]byte, error) { return json.Marshal(s.state) }
+func (s *ConsistentHashStrategy) UnmarshalState(data []byte) error {
+	return json.Unmarshal(data, &s.state)
+}
+
+func (s *ConsistentHashStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	ring := make([]ringNode, 0, len(endpoints)*s.replicas)
+	for _, e := range endpoints {
+		for i := range s.replicas {
+			ring = append(ring, ringNode{hashKey(e + "#" + strconv.Itoa(i)), e})
+		}
+	}
+	slices.SortFunc(ring, func(a, b ringNode) int { return cmp.Compare(a.hash, b.hash) })
+	s.rings[name] = ring
+	if _, ok := s.state.InFlight[name]; !ok {
+		s.state.InFlight[name] = make(map[string]int)
+	}
+}
+
+func (s *ConsistentHashStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *ConsistentHashStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	s.state.Next[service]++
+	return s.PickEndpointForKey(service, "#"+strconv.Itoa(s.state.Next[service]), exclude...)
+}
+
+// PickEndpointForKey walks the ring clockwise from the key's hash to the
+// first endpoint that is neither excluded nor over its load bound.  If the
+// exclusions leave none, they are ignored; if every endpoint is over the
+// bound, the key's own endpoint is used.
+func (s *ConsistentHashStrategy) PickEndpointForKey(service, key string, exclude ...string) (string, error) {
+	ring := s.rings[service]
+	eps := without(s.services[service], exclude)
+	if len(ring) == 0 || len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	inFlight := s.state.InFlight[service]
+	total := 0
+	for _, e := range eps {
+		total += inFlight[e]
+	}
+	bound := math.Ceil((1 + s.epsilon) * float64(total+1) / float64(len(eps)))
+	h := hashKey(key)
+	start, _ := slices.BinarySearchFunc(ring, h, func(n ringNode, h uint64) int { return cmp.Compare(n.hash, h) })
+	owner := ""
+	for i := range ring {
+		e := ring[(start+i)%len(ring)].endpoint
+		if !slices.Contains(eps, e) {
+			continue
+		}
+		if owner == "" {
+			owner = e
+		}
+		if float64(inFlight[e]) < bound {
+			owner = e
+			break
+		}
+	}
+	inFlight[owner]++
+	return owner, nil
+}
+
+func (s *ConsistentHashStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	if inFlight := s.state.InFlight[service]; inFlight[endpoint] > 0 {
+		inFlight[endpoint]--
+	}
+}
+
+// hashKey hashes a ring key with FNV-1a, then mixes the bits with
+// SplitMix64's finalizer: FNV alone barely moves the high bits for short
+// keys that differ in the last character, which clusters the ring.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// PowerOfKChoicesStrategy samples k distinct random endpoints and picks the
+// one with the lowest cost: its latency average (EWMA) times one plus
+// penalty times its recent failure rate (also an EWMA).  Unsampled
+// endpoints are unseen until their first report and preferred, randomly
+// among themselves; cost ties go to the first sampled.  With k=2 and no
+// penalty it is the classic latency-only power of two choices.
+type PowerOfKChoicesStrategy struct {
+	slowThreshold
 	rng      *rand.Rand
 	services map[string][]string
-	ewma     map[string]map[string]float64 // service -> endpoint -> ewma seconds
-	alpha    float64                       // smoothing factor
+	k        int
+	alpha    float64 // smoothing factor
+	penalty  float64 // cost multiplier of a 100% failure rate
+	state    ewmaState
 }
 
-func NewPowerOfTwoChoicesStrategy(seed int64, alpha float64) *PowerOfTwoChoicesStrategy {
+// PowerOfTwoChoicesStrategy is the k=2 strategy of NewPowerOfTwoChoicesStrategy.
+type PowerOfTwoChoicesStrategy = PowerOfKChoicesStrategy
+
+// NewPowerOfKChoicesStrategy returns a power of k choices strategy; k below
+// 1 means 2, alpha outside (0, 1) means 0.2 and a negative penalty means 0.
+func NewPowerOfKChoicesStrategy(k int, seed int64, alpha, penalty float64) *PowerOfKChoicesStrategy {
+	if k < 1 {
+		k = 2
+	}
 	if alpha <= 0 || alpha >= 1 {
 		alpha = 0.2
 	}
-	return &PowerOfTwoChoicesStrategy{rng: rand.New(rand.NewSource(seed)), services: make(map[string][]string), ewma: make(map[string]map[string]float64), alpha: alpha}
+	return &PowerOfKChoicesStrategy{rng: rand.New(rand.NewSource(seed)), services: make(map[string][]string), k: k, alpha: alpha, penalty: max(penalty, 0),
+		state: ewmaState{Latency: make(map[string]map[string]float64), Failures: make(map[string]map[string]float64)}}
+}
+
+// NewPowerOfTwoChoicesStrategy returns the classic latency-only power of
+// two choices.
+func NewPowerOfTwoChoicesStrategy(seed int64, alpha float64) *PowerOfTwoChoicesStrategy {
+	return NewPowerOfKChoicesStrategy(2, seed, alpha, 0)
+}
+
+func (s *PowerOfKChoicesStrategy) Name() string {
+	if s.k == 2 {
+		return "PowerOfTwoChoices"
+	}
+	return fmt.Sprintf("PowerOf%dChoices", s.k)
 }
 
-func (s *PowerOfTwoChoicesStrategy) Name() string { return "PowerOfTwoChoices" }
+func (s *PowerOfKChoicesStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
 
-func (s *PowerOfTwoChoicesStrategy) AddService(name string, endpoints []string) {
+// MarshalState and UnmarshalState checkpoint the latency and failure
+// averages.
+func (s *PowerOfKChoicesStrategy) MarshalState() ([]byte, error) { return json.Marshal(s.state) }
+func (s *PowerOfKChoicesStrategy) UnmarshalState(data []byte) error {
+	return json.Unmarshal(data, &s.state)
+}
+
+func (s *PowerOfKChoicesStrategy) AddService(name string, endpoints []string) {
 	s.services[name] = append([]string{}, endpoints...)
-	if _, ok := s.ewma[name]; !ok {
-		s.ewma[name] = make(map[string]float64)
+	for _, m := range []map[string]map[string]float64{s.state.Latency, s.state.Failures} {
+		if _, ok := m[name]; !ok {
+			m[name] = make(map[string]float64)
+		}
 	}
 }
 
-func (s *PowerOfTwoChoicesStrategy) PickEndpoint(service string) (string, error) {
-	eps := s.services[service]
+func (s *PowerOfKChoicesStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *PowerOfKChoicesStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
 	if len(eps) == 0 {
 		return "", ErrNoEndpoints
 	}
 	if len(eps) == 1 {
 		return eps[0], nil
 	}
-	// sample two distinct indices
-	i := s.rng.Intn(len(eps))
-	j := s.rng.Intn(len(eps) - 1)
-	if j >= i {
-		j++
+	// Sample k distinct indices: each draw ranges over the indices not
+	// drawn yet, in ascending order.
+	var drawn, sorted []int
+	for range min(s.k, len(eps)) {
+		i := s.rng.Intn(len(eps) - len(drawn))
+		for _, d := range sorted {
+			if i >= d {
+				i++
+			}
+		}
+		drawn = append(drawn, i)
+		sorted = append(sorted, i)
+		slices.Sort(sorted)
 	}
-	a, b := eps[i], eps[j]
-	ma := s.ewma[service][a]
-	mb := s.ewma[service][b]
-	// zero means unseen; prefer the one with a smaller non-zero, otherwise break ties randomly
-	switch {
-	case ma == 0 && mb == 0:
-		if s.rng.Intn(2) == 0 {
-			return a, nil
-		} else {
-			return b, nil
-		}
-	case ma == 0:
-		return a, nil
-	case mb == 0:
-		return b, nil
+	var unseen []string
+	best, bestCost := "", math.Inf(1)
+	for _, i := range drawn {
+		e := eps[i]
+		lat := s.state.Latency[service][e]
+		if lat == 0 {
+			unseen = append(unseen, e)
+			continue
+		}
+		if cost := lat * (1 + s.penalty*s.state.Failures[service][e]); cost < bestCost {
+			best, bestCost = e, cost
+		}
+	}
+	switch len(unseen) {
+	case 0:
+		return best, nil
+	case 1:
+		return unseen[0], nil
 	default:
-		if ma <= mb {
-			return a, nil
-		} else {
-			return b, nil
+		return unseen[s.rng.Intn(len(unseen))], nil
+	}
+}
+
+func (s *PowerOfKChoicesStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	latencySec, success = s.judge(latencySec, success)
+	if _, ok := s.state.Latency[service]; !ok {
+		s.AddService(service, nil)
+	}
+	failed := 0.0
+	if !success {
+		failed = 1
+	}
+	if cur := s.state.Latency[service][endpoint]; cur == 0 {
+		s.state.Latency[service][endpoint] = latencySec
+	} else {
+		s.state.Latency[service][endpoint] = s.alpha*latencySec + (1-s.alpha)*cur
+	}
+	s.state.Failures[service][endpoint] = s.alpha*failed + (1-s.alpha)*s.state.Failures[service][endpoint]
+}
+
+// EWMAStrategy is Finagle's p2c EWMA balancer: it samples two endpoints and
+// picks the cheaper, where the cost of an endpoint is its latency average
+// times one plus its outstanding requests, inflated by its recent failure
+// rate: latency × (pending+1) × (1 + penalty × failure rate).  Endpoints
+// without a latency average cost 0 so they get tried first.
+type EWMAStrategy struct {
+	slowThreshold
+	rng      *rand.Rand
+	services map[string][]string
+	alpha    float64 // smoothing factor of the averages
+	penalty  float64 // cost multiplier of a 100% failure rate
+	state    ewmaState
+}
+
+type ewmaState struct {
+	Latency  map[string]map[string]float64 // service -> endpoint -> ewma seconds
+	Failures map[string]map[string]float64 // service -> endpoint -> ewma failure rate
+	Pending  map[string]map[string]int     // service -> endpoint -> outstanding requests
+}
+
+// NewEWMAStrategy returns an EWMA strategy; alpha outside (0, 1) means 0.2
+// and a negative penalty means 4.
+func NewEWMAStrategy(seed int64, alpha, penalty float64) *EWMAStrategy {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0.2
+	}
+	if penalty < 0 {
+		penalty = 4
+	}
+	return &EWMAStrategy{rng: rand.New(rand.NewSource(seed)), services: make(map[string][]string), alpha: alpha, penalty: penalty,
+		state: ewmaState{Latency: make(map[string]map[string]float64), Failures: make(map[string]map[string]float64), Pending: make(map[string]map[string]int)}}
+}
+
+func (s *EWMAStrategy) Name() string { return "EWMA" }
+
+func (s *EWMAStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
+
+// MarshalState and UnmarshalState checkpoint the averages and the
+// outstanding requests.
+func (s *EWMAStrategy) MarshalState() ([]byte, error)    { return json.Marshal(s.state) }
+func (s *EWMAStrategy) UnmarshalState(data []byte) error { return json.Unmarshal(data, &s.state) }
+
+func (s *EWMAStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	for _, m := range []map[string]map[string]float64{s.state.Latency, s.state.Failures} {
+		if _, ok := m[name]; !ok {
+			m[name] = make(map[string]float64)
 		}
 	}
+	if _, ok := s.state.Pending[name]; !ok {
+		s.state.Pending[name] = make(map[string]int)
+	}
 }
 
-func (s *PowerOfTwoChoicesStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
-	if _, ok := s.ewma[service]; !ok {
-		s.ewma[service] = make(map[string]float64)
+func (s *EWMAStrategy) cost(service, endpoint string) float64 {
+	return s.state.Latency[service][endpoint] * float64(s.state.Pending[service][endpoint]+1) * (1 + s.penalty*s.state.Failures[service][endpoint])
+}
+
+func (s *EWMAStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *EWMAStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
 	}
-	cur := s.ewma[service][endpoint]
-	if cur == 0 {
-		s.ewma[service][endpoint] = latencySec
+	best := pickTwo(s.rng, eps, func(e string) float64 { return s.cost(service, e) })
+	s.state.Pending[service][best]++
+	return best, nil
+}
+
+func (s *EWMAStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	latencySec, success = s.judge(latencySec, success)
+	if _, ok := s.state.Latency[service]; !ok {
+		s.AddService(service, nil)
+	}
+	if pending := s.state.Pending[service]; pending[endpoint] > 0 {
+		pending[endpoint]--
+	}
+	failed := 0.0
+	if !success {
+		failed = 1
+	}
+	if cur := s.state.Latency[service][endpoint]; cur == 0 {
+		s.state.Latency[service][endpoint] = latencySec
 	} else {
-		s.ewma[service][endpoint] = s.alpha*latencySec + (1-s.alpha)*cur
+		s.state.Latency[service][endpoint] = s.alpha*latencySec + (1-s.alpha)*cur
+	}
+	s.state.Failures[service][endpoint] = s.alpha*failed + (1-s.alpha)*s.state.Failures[service][endpoint]
+}
+
+// PeakEWMAStrategy is Linkerd's peak EWMA balancer: it samples two
+// endpoints and picks the cheaper, where the cost of an endpoint is its
+// peak-sensitive latency average times one plus its outstanding requests.
+// A latency above the average replaces it at once, so a spike sheds traffic
+// on the next pick, and only lower latencies pull it back down, at rate
+// alpha.  A failure counts as a latency of penalty times the average.
+// Endpoints without an average cost 0 so they get tried first.
+type PeakEWMAStrategy struct {
+	slowThreshold
+	rng      *rand.Rand
+	services map[string][]string
+	alpha    float64 // how fast lower latencies pull the average down
+	penalty  float64 // latency of a failure, in multiples of the average
+	state    peakEWMAState
+}
+
+type peakEWMAState struct {
+	Latency map[string]map[string]float64 // service -> endpoint -> peak ewma seconds
+	Pending map[string]map[string]int     // service -> endpoint -> outstanding requests
+}
+
+// NewPeakEWMAStrategy returns a peak EWMA strategy; alpha outside (0, 1)
+// means 0.2 and a penalty below 1 means 2.
+func NewPeakEWMAStrategy(seed int64, alpha, penalty float64) *PeakEWMAStrategy {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0.2
+	}
+	if penalty < 1 {
+		penalty = 2
 	}
+	return &PeakEWMAStrategy{rng: rand.New(rand.NewSource(seed)), services: make(map[string][]string), alpha: alpha, penalty: penalty,
+		state: peakEWMAState{Latency: make(map[string]map[string]float64), Pending: make(map[string]map[string]int)}}
+}
+
+func (s *PeakEWMAStrategy) Name() string { return "PeakEWMA" }
+
+func (s *PeakEWMAStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
+
+// MarshalState and UnmarshalState checkpoint the averages and the
+// outstanding requests.
+func (s *PeakEWMAStrategy) MarshalState() ([]byte, error)    { return json.Marshal(s.state) }
+func (s *PeakEWMAStrategy) UnmarshalState(data []byte) error { return json.Unmarshal(data, &s.state) }
+
+func (s *PeakEWMAStrategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	if _, ok := s.state.Latency[name]; !ok {
+		s.state.Latency[name] = make(map[string]float64)
+	}
+	if _, ok := s.state.Pending[name]; !ok {
+		s.state.Pending[name] = make(map[string]int)
+	}
+}
+
+func (s *PeakEWMAStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *PeakEWMAStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	best := pickTwo(s.rng, eps, func(e string) float64 {
+		return s.state.Latency[service][e] * float64(s.state.Pending[service][e]+1)
+	})
+	s.state.Pending[service][best]++
+	return best, nil
+}
+
+func (s *PeakEWMAStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	latencySec, success = s.judge(latencySec, success)
+	if _, ok := s.state.Latency[service]; !ok {
+		s.AddService(service, nil)
+	}
+	if pending := s.state.Pending[service]; pending[endpoint] > 0 {
+		pending[endpoint]--
+	}
+	cur := s.state.Latency[service][endpoint]
+	if !success {
+		latencySec = max(latencySec, s.penalty*cur)
+	}
+	if latencySec > cur {
+		s.state.Latency[service][endpoint] = latencySec
+	} else {
+		s.state.Latency[service][endpoint] = s.alpha*latencySec + (1-s.alpha)*cur
+	}
+}
+
+// C3Strategy ranks endpoints with the replica scoring of C3 (Suresh et
+// al., NSDI 2015): R - 1/µ + q̂³/µ, where R is the latency average, 1/µ
+// the average service time the endpoint reports (ServerFeedback) and
+// q̂ = 1 + os·clients + q its estimated queue, with os this client's
+// outstanding requests and q the average queue length it reports.  The
+// cube penalizes long queues far more than slow service, so load spreads
+// before queues build.  It picks the lowest score, endpoints without an
+// average first; C3's rate control is left out.
+type C3Strategy struct {
+	services map[string][]string
+	alpha    float64
+	clients  float64 // concurrent clients os is scaled by
+	state    c3State
+}
+
+type c3State struct {
+	Latency map[string]map[string]float64 // R
+	Service map[string]map[string]float64 // 1/µ
+	Queue   map[string]map[string]float64 // q
+	Pending map[string]map[string]int     // os
+}
+
+// NewC3Strategy returns a C3 strategy; alpha outside (0, 1) means 0.2 (C3
+// uses 0.9 for the weight of the old value, i.e. 0.1) and clients below
+// 1 mean 1.
+func NewC3Strategy(alpha float64, clients int) *C3Strategy {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0.2
+	}
+	return &C3Strategy{services: make(map[string][]string), alpha: alpha, clients: float64(max(clients, 1)),
+		state: c3State{Latency: make(map[string]map[string]float64), Service: make(map[string]map[string]float64),
+			Queue: make(map[string]map[string]float64), Pending: make(map[string]map[string]int)}}
+}
+
+func (s *C3Strategy) Name() string { return "C3" }
+
+// MarshalState and UnmarshalState checkpoint the averages and the
+// outstanding requests.
+func (s *C3Strategy) MarshalState() ([]byte, error)    { return json.Marshal(s.state) }
+func (s *C3Strategy) UnmarshalState(data []byte) error { return json.Unmarshal(data, &s.state) }
+
+func (s *C3Strategy) AddService(name string, endpoints []string) {
+	s.services[name] = append([]string{}, endpoints...)
+	for _, m := range []map[string]map[string]float64{s.state.Latency, s.state.Service, s.state.Queue} {
+		if _, ok := m[name]; !ok {
+			m[name] = make(map[string]float64)
+		}
+	}
+	if _, ok := s.state.Pending[name]; !ok {
+		s.state.Pending[name] = make(map[string]int)
+	}
+}
+
+// Score returns the C3 score of endpoint, lower being better.
+func (s *C3Strategy) Score(service, endpoint string) float64 {
+	st := s.state
+	q := 1 + float64(st.Pending[service][endpoint])*s.clients + st.Queue[service][endpoint]
+	svc := st.Service[service][endpoint]
+	return st.Latency[service][endpoint] - svc + q*q*q*svc
+}
+
+func (s *C3Strategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *C3Strategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
+	if len(eps) == 0 {
+		return "", ErrNoEndpoints
+	}
+	best, bestScore := "", math.Inf(1)
+	for _, e := range eps {
+		if score := s.Score(service, e); score < bestScore {
+			best, bestScore = e, score
+		}
+	}
+	s.state.Pending[service][best]++
+	return best, nil
+}
+
+// ReportFeedback updates the service time and queue averages.
+func (s *C3Strategy) ReportFeedback(service, endpoint string, fb ServerFeedback) {
+	if _, ok := s.state.Service[service]; !ok {
+		s.AddService(service, nil)
+	}
+	s.state.Service[service][endpoint] = s.ewma(s.state.Service[service][endpoint], fb.ServiceTimeSec)
+	// Queues start empty, so a zero average is a real one.
+	s.state.Queue[service][endpoint] = s.alpha*float64(fb.QueueLen) + (1-s.alpha)*s.state.Queue[service][endpoint]
+}
+
+func (s *C3Strategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	if _, ok := s.state.Latency[service]; !ok {
+		s.AddService(service, nil)
+	}
+	if pending := s.state.Pending[service]; pending[endpoint] > 0 {
+		pending[endpoint]--
+	}
+	s.state.Latency[service][endpoint] = s.ewma(s.state.Latency[service][endpoint], latencySec)
+}
+
+// ewma folds v into the average cur, which starts at the first value.
+func (s *C3Strategy) ewma(cur, v float64) float64 {
+	if cur == 0 {
+		return v
+	}
+	return s.alpha*v + (1-s.alpha)*cur
 }
 
 // LeastLatencyStrategy always chooses the endpoint with smallest observed average latency (EWMA).
 // If none observed, falls back to random choice.
 type LeastLatencyStrategy struct {
+	slowThreshold
 	rng      *rand.Rand
 	services map[string][]string
 	ewma     map[string]map[string]float64
@@ -170,6 +921,14 @@ func NewLeastLatencyStrategy(seed int64, alpha float64) *LeastLatencyStrategy {
 
 func (s *LeastLatencyStrategy) Name() string { return "LeastLatency" }
 
+func (s *LeastLatencyStrategy) Seed(seed int64) { s.rng = rand.New(rand.NewSource(seed)) }
+
+// MarshalState and UnmarshalState checkpoint the latency averages.
+func (s *LeastLatencyStrategy) MarshalState() ([]byte, error) { return json.Marshal(s.ewma) }
+func (s *LeastLatencyStrategy) UnmarshalState(data []byte) error {
+	return json.Unmarshal(data, &s.ewma)
+}
+
 func (s *LeastLatencyStrategy) AddService(name string, endpoints []string) {
 	s.services[name] = append([]string{}, endpoints...)
 	if _, ok := s.ewma[name]; !ok {
@@ -178,7 +937,11 @@ func (s *LeastLatencyStrategy) AddService(name string, endpoints []string) {
 }
 
 func (s *LeastLatencyStrategy) PickEndpoint(service string) (string, error) {
-	eps := s.services[service]
+	return s.PickEndpointExcluding(service)
+}
+
+func (s *LeastLatencyStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	eps := without(s.services[service], exclude)
 	if len(eps) == 0 {
 		return "", ErrNoEndpoints
 	}
@@ -200,7 +963,29 @@ func (s *LeastLatencyStrategy) PickEndpoint(service string) (string, error) {
 	return eps[s.rng.Intn(len(eps))], nil
 }
 
+// PickEndpointExplained scores candidates by their latency average in
+// seconds, 0 before the first report.  The pick is certain unless no
+// endpoint has an average yet and it is random.
+func (s *LeastLatencyStrategy) PickEndpointExplained(service string) (string, []CandidateScore, error) {
+	best, err := s.PickEndpoint(service)
+	if err != nil {
+		return "", nil, err
+	}
+	eps := s.services[service]
+	scores := make([]CandidateScore, len(eps))
+	for i, e := range eps {
+		scores[i] = CandidateScore{Endpoint: e, Score: s.ewma[service][e]}
+		if s.ewma[service][best] == 0 {
+			scores[i].Probability = 1 / float64(len(eps))
+		} else if e == best {
+			scores[i].Probability = 1
+		}
+	}
+	return best, scores, nil
+}
+
 func (s *LeastLatencyStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	latencySec, success = s.judge(latencySec, success)
 	if _, ok := s.ewma[service]; !ok {
 		s.ewma[service] = make(map[string]float64)
 	}
@@ -211,3 +996,65 @@ func (s *LeastLatencyStrategy) ReportResult(service, endpoint string, latencySec
 		s.ewma[service][endpoint] = s.alpha*latencySec + (1-s.alpha)*cur
 	}
 }
+
+// slowThreshold gives a baseline SwarmRoute's slow-success signal, so
+// comparisons can tell the algorithm from that feature: once set, a
+// success slower than the threshold is learned as a failure, with the
+// latency penalty the simulator reports failures with.
+type slowThreshold struct {
+	slowSec float64
+}
+
+// SetSlowThresholdSec sets the threshold; 0 (the default) disables it, as
+// in SwarmRoute.SetSlowThresholdSec.
+func (t *slowThreshold) SetSlowThresholdSec(sec float64) { t.slowSec = max(sec, 0) }
+
+// judge returns the latency and outcome to learn from.
+func (t *slowThreshold) judge(latencySec float64, success bool) (float64, bool) {
+	if success && t.slowSec > 0 && latencySec > t.slowSec {
+		return latencySec + failurePenaltySec, false
+	}
+	return latencySec, success
+}
+
+// pickTwo samples two distinct endpoints of eps and returns the one with
+// the lower cost, either on a tie.
+func pickTwo(rng *rand.Rand, eps []string, cost func(string) float64) string {
+	if len(eps) == 1 {
+		return eps[0]
+	}
+	i := rng.Intn(len(eps))
+	j := rng.Intn(len(eps) - 1)
+	if j >= i {
+		j++
+	}
+	a, b := eps[i], eps[j]
+	switch ca, cb := cost(a), cost(b); {
+	case ca < cb:
+		return a
+	case cb < ca:
+		return b
+	case rng.Intn(2) == 0:
+		return a
+	default:
+		return b
+	}
+}
+
+// without returns eps minus the endpoints in exclude, or eps itself if
+// nothing is excluded or that would leave none.
+func without(eps, exclude []string) []string {
+	if len(exclude) == 0 {
+		return eps
+	}
+	out := make([]string, 0, len(eps))
+	for _, e := range eps {
+		if !slices.Contains(exclude, e) {
+			out = append(out, e)
+		}
+	}
+	if len(out) == 0 {
+		return eps
+	}
+	return out
+}
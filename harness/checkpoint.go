@@ -0,0 +1,239 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// CheckpointPolicy makes a run hand out its state periodically, so a long
+// study can be resumed with ResumeScenario or ResumePopulation after an
+// interruption.
+type CheckpointPolicy struct {
+	// Every is the number of steps between checkpoints.
+	Every int
+	// Save receives every checkpoint, e.g. to write it with SaveFile.  If
+	// the state could not be captured because a strategy failed to marshal
+	// its state, cp is nil and err says why; the run continues either way.
+	Save func(cp *Checkpoint, err error)
+}
+
+// CheckpointStrategy is implemented by strategies whose learned state can
+// be saved in a checkpoint.  Other strategies resume with fresh state.
+// Randomness is not part of the state: a resumed SeedableStrategy is
+// reseeded from the scenario seed and the resume step, so a resumed run is
+// reproducible but not identical to an uninterrupted one.
+type CheckpointStrategy interface {
+	Strategy
+	MarshalState() ([]byte, error)
+	UnmarshalState(data []byte) error
+}
+
+// Checkpoint is the state of a run between two steps: the environment, its
+// random streams, requests in flight, metrics so far and the strategies'
+// state.  It holds no part of the Scenario itself, so resume with the same
+// scenario and clients.
+type Checkpoint struct {
+	// Step is the first step the resumed run executes.
+	Step int
+	st   checkpointState
+}
+
+type checkpointState struct {
+	Seed          int64
+	TotalRequests int
+	Endpoints     []string // offered to the strategies, in order
+	Env           map[string]envState
+	Served        map[string]int
+	Draws         map[string]uint64 // per endpoint stream
+	ClassDraws    uint64
+	KeyDraws      uint64
+	Open          *openLoopState `json:",omitempty"`
+	DegradedSince map[string]int
+	Windows       []DegradedWindow
+	All           *tally
+	Groups        map[string]*tally
+	Strategies    []json.RawMessage // per client; null if not checkpointable
+}
+
+// envState is the part of an EndpointSpec that events change.
+type envState struct {
+	MeanLatencySec, JitterSec, ErrorRate float64
+	Down, Blackhole                      bool
+	Classes                              map[string]ClassOverride `json:",omitempty"`
+}
+
+func envStateOf(e *EndpointSpec) envState {
+	return envState{e.MeanLatencySec, e.JitterSec, e.ErrorRate, e.Down, e.Blackhole, e.Classes}
+}
+
+func (s envState) apply(e *EndpointSpec) {
+	e.MeanLatencySec, e.JitterSec, e.ErrorRate, e.Down, e.Blackhole = s.MeanLatencySec, s.JitterSec, s.ErrorRate, s.Down, s.Blackhole
+	e.Classes = s.Classes
+}
+
+type openLoopState struct {
+	Arrival, Start float64
+	MaxInFlight    int
+	Draws          uint64
+	Pending        []completion
+}
+
+func (o *openLoop) state() *openLoopState {
+	return &openLoopState{Arrival: o.arrival, Start: o.start, MaxInFlight: o.maxInFlight, Draws: o.src.n, Pending: slices.Clone(o.pending)}
+}
+
+func (o *openLoop) restore(s *openLoopState) {
+	o.arrival, o.start = s.Arrival, s.Start
+	o.src.skip(s.Draws)
+	for _, c := range s.Pending {
+		o.add(c)
+	}
+	o.maxInFlight = s.MaxInFlight
+}
+
+// MarshalJSON encodes the checkpoint.
+func (cp *Checkpoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Step  int
+		State checkpointState
+	}{cp.Step, cp.st})
+}
+
+// UnmarshalJSON decodes a checkpoint written by MarshalJSON.
+func (cp *Checkpoint) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Step  int
+		State checkpointState
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	cp.Step, cp.st = v.Step, v.State
+	return nil
+}
+
+// clone returns a deep copy of the checkpoint.
+func (cp *Checkpoint) clone() (*Checkpoint, error) {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, err
+	}
+	out := &Checkpoint{}
+	return out, json.Unmarshal(data, out)
+}
+
+// SaveFile writes the checkpoint to path atomically: it writes a temporary
+// file in the same directory and renames it over path.
+func (cp *Checkpoint) SaveFile(path string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after a successful rename
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadCheckpointFile reads a checkpoint written by SaveFile.
+func LoadCheckpointFile(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// ResumeScenario continues the run of sc that produced cp and returns the
+// results of the whole run, as RunScenario would.
+func ResumeScenario(sc Scenario, s Strategy, cp *Checkpoint) (Results, error) {
+	_, groups, err := simulate(sc, []Strategy{s}, cp)
+	if err != nil {
+		return Results{}, err
+	}
+	return groups[0], nil
+}
+
+// ResumePopulation continues the population run that produced cp.
+func ResumePopulation(sc Scenario, clients []Strategy, cp *Checkpoint) (PopulationResults, error) {
+	if len(clients) == 0 {
+		return PopulationResults{}, nil
+	}
+	all, groups, err := simulate(sc, clients, cp)
+	if err != nil {
+		return PopulationResults{}, err
+	}
+	return PopulationResults{All: all, Groups: groups}, nil
+}
+
+// check reports whether cp was taken from a run of sc with n clients.
+func (cp *Checkpoint) check(sc Scenario, n int) error {
+	switch {
+	case cp.st.Seed != sc.Seed || cp.st.TotalRequests != sc.TotalRequests:
+		return fmt.Errorf("checkpoint is from a different scenario (seed %d, %d requests)", cp.st.Seed, cp.st.TotalRequests)
+	case len(cp.st.Strategies) != n:
+		return fmt.Errorf("checkpoint has %d clients, resuming with %d", len(cp.st.Strategies), n)
+	case cp.Step < 0 || cp.Step > sc.TotalRequests:
+		return fmt.Errorf("checkpoint step %d out of range", cp.Step)
+	}
+	return nil
+}
+
+// countingSource is a random source that counts its draws, so a stream can
+// be checkpointed as (seed, draws) and restored by replaying the draws.
+type countingSource struct {
+	src rand.Source64
+	n   uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+// Int63 and Uint64 advance the underlying source by one step each.
+func (s *countingSource) Int63() int64   { s.n++; return s.src.Int63() }
+func (s *countingSource) Uint64() uint64 { s.n++; return s.src.Uint64() }
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.n = 0
+}
+
+// skip advances the source to n draws.
+func (s *countingSource) skip(n uint64) {
+	for s.n < n {
+		s.Uint64()
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import "math/rand"
+
+// RequestClass is a kind of request in a scenario's traffic mix, e.g.
+// reads and writes.  Endpoints can behave differently per class (see
+// EndpointSpec.Classes), modeling partial brownouts where one route of a
+// service degrades while the others stay fine.
+type RequestClass struct {
+	Name string
+	// Weight is the class's relative share of requests.
+	Weight float64
+}
+
+// ClassOverride replaces an endpoint's mean latency or error rate for one
+// request class.  Nil fields keep the endpoint's own value.
+type ClassOverride struct {
+	MeanLatencySec *float64
+	ErrorRate      *float64
+}
+
+// ClassMetrics summarizes the requests of one class.
+type ClassMetrics struct {
+	Name      string
+	Total     int
+	Success   int
+	MeanLatMS float64
+	P95LatMS  float64
+}
+
+// forClass returns the spec with the overrides of class applied.
+func (e EndpointSpec) forClass(class string) EndpointSpec {
+	ov, ok := e.Classes[class]
+	if !ok {
+		return e
+	}
+	if ov.MeanLatencySec != nil {
+		e.MeanLatencySec = *ov.MeanLatencySec
+	}
+	if ov.ErrorRate != nil {
+		e.ErrorRate = *ov.ErrorRate
+	}
+	return e
+}
+
+// setClass updates the override of class with the non-nil values.
+func (e *EndpointSpec) setClass(class string, latencySec, errorRate *float64) {
+	ov := e.Classes[class]
+	if latencySec != nil {
+		v := *latencySec
+		ov.MeanLatencySec = &v
+	}
+	if errorRate != nil {
+		v := clamp01(*errorRate)
+		ov.ErrorRate = &v
+	}
+	if e.Classes == nil {
+		e.Classes = make(map[string]ClassOverride)
+	}
+	e.Classes[class] = ov
+}
+
+// degradedForAny reports whether cur is degraded relative to initial for
+// the endpoint as a whole or for any of classes.
+func degradedForAny(initial, cur EndpointSpec, classes []RequestClass) bool {
+	if degraded(initial, cur) {
+		return true
+	}
+	for _, c := range classes {
+		if degraded(initial.forClass(c.Name), cur.forClass(c.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// classService returns the service a request of class is routed through:
+// "service/class" when routing by class, service otherwise.
+func (sc Scenario) classService(class string) string {
+	if !sc.RouteByClass || class == "" {
+		return sc.Service
+	}
+	return sc.Service + "/" + class
+}
+
+// classPicker draws request classes by weight.
+type classPicker struct {
+	src     *countingSource
+	rng     *rand.Rand
+	classes []RequestClass
+	total   float64
+}
+
+// newClassPicker returns a picker for classes, or nil if there are none.
+// It draws from its own random stream so adding classes leaves the
+// latency and error samples of a scenario unchanged.
+func newClassPicker(classes []RequestClass, seed int64) *classPicker {
+	if len(classes) == 0 {
+		return nil
+	}
+	src := newCountingSource(seed + 2)
+	p := &classPicker{src: src, rng: rand.New(src), classes: classes}
+	for _, c := range classes {
+		p.total += max(c.Weight, 0)
+	}
+	return p
+}
+
+// next returns the index of the next request's class.
+func (p *classPicker) next() int {
+	if p.total <= 0 {
+		return p.rng.Intn(len(p.classes))
+	}
+	r := p.rng.Float64() * p.total
+	for i, c := range p.classes {
+		if r -= max(c.Weight, 0); r < 0 {
+			return i
+		}
+	}
+	return len(p.classes) - 1
+}
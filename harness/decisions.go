@@ -0,0 +1,174 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"swarmroute/harness/parquet"
+)
+
+// Explainer is implemented by strategies that can explain a pick: the
+// score they gave every candidate.  Runs with Scenario.Decisions pick
+// through it, so it must choose exactly like PickEndpoint.
+type Explainer interface {
+	Strategy
+	PickEndpointExplained(service string) (string, []CandidateScore, error)
+}
+
+// CandidateScore is a strategy's view of one endpoint at a pick.  Score is
+// strategy-specific: SwarmRoute's sampling weight, or LeastLatency's
+// latency average in seconds.  Probability is the chance the endpoint had
+// of being chosen.
+type CandidateScore struct {
+	Endpoint    string  `json:"endpoint"`
+	Score       float64 `json:"score"`
+	Probability float64 `json:"probability"`
+	// Excluded is empty for eligible endpoints, otherwise the reason the
+	// endpoint could not be chosen.
+	Excluded string `json:"excluded,omitempty"`
+}
+
+// Decision is one pick of a run.  Candidates is nil unless the strategy is
+// an Explainer; retries that exclude endpoints are not explained.
+type Decision struct {
+	Step       int              `json:"step"`
+	Client     int              `json:"client"`
+	Strategy   string           `json:"strategy"`
+	Service    string           `json:"service"`
+	Attempt    int              `json:"attempt"` // 0, then 1.. for retries
+	Endpoint   string           `json:"endpoint"`
+	Candidates []CandidateScore `json:"candidates,omitempty"`
+	// Degraded lists the endpoints degraded at the step, sorted.
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// DecisionWriter writes the decisions of one or more runs as NDJSON, one
+// Decision per line, or as CSV or Parquet with a row per candidate and the
+// columns step, client, strategy, service, attempt, endpoint, candidate,
+// score, probability, excluded and degraded (whether the candidate was
+// degraded).  Decisions without candidates have a single row with empty
+// candidate columns (zero score and probability in Parquet, whose columns
+// are not nullable).
+type DecisionWriter struct {
+	w       *bufio.Writer
+	enc     *json.Encoder
+	csv     *csv.Writer
+	parquet *parquet.Writer
+	header  bool
+	err     error
+}
+
+// decisionColumns are the columns of the CSV and Parquet formats.
+var decisionColumns = []parquet.Column{
+	{Name: "step", Type: parquet.Int64}, {Name: "client", Type: parquet.Int64},
+	{Name: "strategy", Type: parquet.String}, {Name: "service", Type: parquet.String},
+	{Name: "attempt", Type: parquet.Int64}, {Name: "endpoint", Type: parquet.String},
+	{Name: "candidate", Type: parquet.String}, {Name: "score", Type: parquet.Double},
+	{Name: "probability", Type: parquet.Double}, {Name: "excluded", Type: parquet.String},
+	{Name: "degraded", Type: parquet.Bool},
+}
+
+// NewDecisionWriter returns a DecisionWriter writing format, "ndjson",
+// "csv" or "parquet", to w.  Call Flush when done; a Parquet file is only
+// complete, and can't be written to anymore, after Flush.
+func NewDecisionWriter(w io.Writer, format string) (*DecisionWriter, error) {
+	d := &DecisionWriter{w: bufio.NewWriter(w)}
+	switch format {
+	case "ndjson":
+		d.enc = json.NewEncoder(d.w)
+	case "csv":
+		d.csv = csv.NewWriter(d.w)
+	case "parquet":
+		d.parquet, _ = parquet.NewWriter(d.w, decisionColumns)
+	default:
+		return nil, fmt.Errorf("unknown decision format %q", format)
+	}
+	return d, nil
+}
+
+// Write writes d.  Errors are kept and returned by Flush.
+func (w *DecisionWriter) Write(d Decision) {
+	if w.err != nil {
+		return
+	}
+	if w.enc != nil {
+		w.err = w.enc.Encode(d)
+		return
+	}
+	if w.parquet != nil {
+		if len(d.Candidates) == 0 {
+			w.err = w.parquet.Write(d.Step, d.Client, d.Strategy, d.Service, d.Attempt, d.Endpoint, "", 0.0, 0.0, "", false)
+		}
+		for _, c := range d.Candidates {
+			if w.err == nil {
+				w.err = w.parquet.Write(d.Step, d.Client, d.Strategy, d.Service, d.Attempt, d.Endpoint,
+					c.Endpoint, c.Score, c.Probability, c.Excluded, slices.Contains(d.Degraded, c.Endpoint))
+			}
+		}
+		return
+	}
+	if !w.header {
+		w.header = true
+		header := make([]string, len(decisionColumns))
+		for i, c := range decisionColumns {
+			header[i] = c.Name
+		}
+		w.err = w.csv.Write(header)
+	}
+	row := []string{strconv.Itoa(d.Step), strconv.Itoa(d.Client), d.Strategy, d.Service,
+		strconv.Itoa(d.Attempt), d.Endpoint, "", "", "", "", ""}
+	if len(d.Candidates) == 0 {
+		if w.err == nil {
+			w.err = w.csv.Write(row)
+		}
+		return
+	}
+	for _, c := range d.Candidates {
+		row[6] = c.Endpoint
+		row[7] = strconv.FormatFloat(c.Score, 'g', -1, 64)
+		row[8] = strconv.FormatFloat(c.Probability, 'g', -1, 64)
+		row[9] = c.Excluded
+		row[10] = strconv.FormatBool(slices.Contains(d.Degraded, c.Endpoint))
+		if w.err == nil {
+			w.err = w.csv.Write(row)
+		}
+	}
+}
+
+// Flush writes out buffered decisions and returns the first error.
+func (w *DecisionWriter) Flush() error {
+	if w.parquet != nil && w.err == nil {
+		w.err = w.parquet.Close()
+	}
+	if w.csv != nil {
+		w.csv.Flush()
+		if w.err == nil {
+			w.err = w.csv.Error()
+		}
+	}
+	if err := w.w.Flush(); w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
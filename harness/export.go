@@ -0,0 +1,275 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// CSVWriter writes results and aggregations as the rows of one CSV table in
+// long format, one row per value, with the columns scenario, strategy,
+// seed, metric, stat and value.  Results rows have the stat "value";
+// aggregations add the full results of every seed followed by summary rows
+// without a seed whose stat is mean, std, ci95_low, ci95_high, median, min
+// or max.
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter returns a CSVWriter writing to w.  Call Flush when done.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Results writes a row per metric of every result.
+func (c *CSVWriter) Results(results []Results) error {
+	for _, r := range results {
+		for _, m := range resultMetrics(r) {
+			if err := c.row(r.Scenario, r.Strategy, strconv.FormatInt(r.Seed, 10), m.name, "value", m.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Aggregations writes the per-seed results behind every aggregation and
+// the summary statistics of its metrics.
+func (c *CSVWriter) Aggregations(aggs []MultiSeedAggregation) error {
+	for _, a := range aggs {
+		if err := c.Results(a.Runs); err != nil {
+			return err
+		}
+		for _, m := range []struct {
+			name string
+			st   SeedStats
+		}{
+			{"success_pct", a.SuccessStats},
+			{"p95_ms", a.P95Stats},
+			{"bad_window_share_pct", a.BadShareStats},
+			{"mean_shed_steps", a.ShedStats},
+			{"mean_reinclude_steps", a.ReincludeStats},
+		} {
+			for _, s := range []struct {
+				stat  string
+				value float64
+			}{
+				{"mean", m.st.Mean}, {"std", m.st.Std}, {"ci95_low", m.st.CILow}, {"ci95_high", m.st.CIHigh},
+				{"median", m.st.Median}, {"min", m.st.Min}, {"max", m.st.Max},
+			} {
+				if err := c.row(a.Scenario, a.Strategy, "", m.name, s.stat, s.value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Scores writes the objective score of every aggregation as metric
+// "score" with stat "mean" and, if baseline names one of the strategies,
+// the difference from its score as "score_delta".
+func (c *CSVWriter) Scores(aggs []MultiSeedAggregation, o Objective, baseline string) error {
+	scores, base, hasBase := objectiveScores(aggs, o, baseline)
+	for i, a := range aggs {
+		if err := c.row(a.Scenario, a.Strategy, "", "score", "mean", scores[i]); err != nil {
+			return err
+		}
+		if hasBase {
+			if err := c.row(a.Scenario, a.Strategy, "", "score_delta", "mean", scores[i]-base); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered rows and reports any write error.
+func (c *CSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *CSVWriter) row(scenario, strategy, seed, metric, stat string, value float64) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write([]string{"scenario", "strategy", "seed", "metric", "stat", "value"}); err != nil {
+			return err
+		}
+	}
+	return c.w.Write([]string{scenario, strategy, seed, metric, stat, strconv.FormatFloat(value, 'g', -1, 64)})
+}
+
+// WriteResultsCSV writes results as CSV; see CSVWriter.
+func WriteResultsCSV(w io.Writer, results []Results) error {
+	c := NewCSVWriter(w)
+	if err := c.Results(results); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+// WriteAggregationsCSV writes aggregations as CSV; see CSVWriter.
+func WriteAggregationsCSV(w io.Writer, aggs []MultiSeedAggregation) error {
+	c := NewCSVWriter(w)
+	if err := c.Aggregations(aggs); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+type metric struct {
+	name  string
+	value float64
+}
+
+// resultMetrics flattens the scalar metrics of r, followed by the
+// selections per endpoint and the per-phase and per-class metrics.
+func resultMetrics(r Results) []metric {
+	ms := []metric{
+		{"total", float64(r.Total)},
+		{"success", float64(r.Success)},
+		{"failure", float64(r.Failure)},
+		{"success_pct", pct(r.Success, r.Total)},
+		{"mean_ms", r.MeanLatMS},
+		{"p95_ms", r.P95LatMS},
+	}
+	for _, lp := range r.Percentiles {
+		ms = append(ms, metric{"p" + strconv.FormatFloat(lp.P, 'f', -1, 64) + "_ms", lp.LatMS})
+	}
+	ms = append(ms,
+		metric{"attempts", float64(r.Attempts)},
+		metric{"retry_amplification", r.RetryAmplification},
+		metric{"timeouts", float64(r.Timeouts)},
+		metric{"timed_out", float64(r.TimedOut)},
+		metric{"dropped", float64(r.Dropped)},
+		metric{"duration_sec", r.DurationSec},
+		metric{"goodput_rps", r.GoodputRPS},
+		metric{"max_in_flight", float64(r.MaxInFlight)},
+		metric{"mean_queue_wait_ms", r.MeanQueueWaitMS},
+		metric{"overload_share", r.OverloadShare},
+		metric{"key_affinity", r.KeyAffinity},
+		metric{"bad_window_share", r.BadWindowDegradedShare},
+		metric{"peak_share", r.PeakShare},
+		metric{"share_swing", r.ShareSwing},
+		metric{"oscillation", r.Oscillation},
+		metric{"mean_shed_steps", r.MeanShedSteps},
+		metric{"mean_reinclude_steps", r.MeanReincludeSteps},
+	)
+	addrs := make([]string, 0, len(r.Selection))
+	for addr := range r.Selection {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	for _, addr := range addrs {
+		ms = append(ms, metric{"selection/" + addr, float64(r.Selection[addr])})
+	}
+	for _, p := range r.Phases {
+		prefix := fmt.Sprintf("phase/%d/", p.Start)
+		ms = append(ms,
+			metric{prefix + "success_pct", pct(p.Success, p.Total)},
+			metric{prefix + "mean_ms", p.MeanLatMS},
+			metric{prefix + "p95_ms", p.P95LatMS},
+			metric{prefix + "fairness", p.Fairness},
+		)
+	}
+	for _, cl := range r.Classes {
+		prefix := "class/" + cl.Name + "/"
+		ms = append(ms,
+			metric{prefix + "success_pct", pct(cl.Success, cl.Total)},
+			metric{prefix + "mean_ms", cl.MeanLatMS},
+			metric{prefix + "p95_ms", cl.P95LatMS},
+		)
+	}
+	return ms
+}
+
+// WriteHistogramsCSV writes the latency histograms of results as CSV with
+// the columns strategy, endpoint, le_ms and count, one row per bucket.  The
+// endpoint column is empty for a strategy's overall histogram and le_ms is
+// +Inf for the overflow bucket.  Counts are per bucket, not cumulative.
+func WriteHistogramsCSV(w io.Writer, results []Results) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"strategy", "endpoint", "le_ms", "count"}); err != nil {
+		return err
+	}
+	write := func(strategy, endpoint string, h *Histogram) error {
+		for i, c := range h.Counts {
+			le := math.Inf(1)
+			if i < len(h.BoundsMS) {
+				le = h.BoundsMS[i]
+			}
+			row := []string{strategy, endpoint, strconv.FormatFloat(le, 'g', -1, 64), strconv.Itoa(c)}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, r := range results {
+		if r.Histogram != nil {
+			if err := write(r.Strategy, "", r.Histogram); err != nil {
+				return err
+			}
+		}
+		addrs := make([]string, 0, len(r.EndpointHistograms))
+		for addr := range r.EndpointHistograms {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+		for _, addr := range addrs {
+			if err := write(r.Strategy, addr, r.EndpointHistograms[addr]); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteShareSeriesCSV writes the selection share time series of results as
+// CSV with the columns strategy, step, endpoint and share, one row per
+// endpoint and sample.
+func WriteShareSeriesCSV(w io.Writer, results []Results) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"strategy", "step", "endpoint", "share"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		for _, s := range r.ShareSeries {
+			addrs := make([]string, 0, len(s.Shares))
+			for addr := range s.Shares {
+				addrs = append(addrs, addr)
+			}
+			sort.Strings(addrs)
+			for _, addr := range addrs {
+				row := []string{r.Strategy, strconv.Itoa(s.Step), addr, strconv.FormatFloat(s.Shares[addr], 'f', 4, 64)}
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
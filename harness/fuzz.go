@@ -0,0 +1,243 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RandomScenario draws a scenario from the fuzzing distribution, the same
+// one for the same seed:
+//
+//   - 2-10 endpoints around a median latency of 10-100ms (log-uniform),
+//     spread by a log-normal factor of σ 0-0.7, with 10-50% jitter and a
+//     Gaussian, log-normal, Pareto or bimodal shape shared by all
+//   - error rates of 0-2%, one endpoint flaky at 5-30% one time in five
+//   - 4000-12000 requests
+//   - 1-3 of: a degradation (latency ×2-8, +0-30% errors) that recovers,
+//     a latency ramp ×2-6 that snaps back, a correlated outage of up to
+//     half the endpoints, a partition of one, GC-like spikes, or a
+//     diurnal latency oscillation, each over 10-40% of the run
+//
+// Its name is "fuzz-<seed>".
+func RandomScenario(seed int64) Scenario {
+	sc, _ := randomScenario(seed)
+	return sc
+}
+
+// randomScenario implements RandomScenario and also describes the drift
+// patterns it drew.
+func randomScenario(seed int64) (Scenario, []string) {
+	rng := rand.New(rand.NewSource(seed))
+	uniform := func(lo, hi float64) float64 { return lo + rng.Float64()*(hi-lo) }
+	logUniform := func(lo, hi float64) float64 { return math.Exp(uniform(math.Log(lo), math.Log(hi))) }
+
+	n := 2 + rng.Intn(9)
+	median, spread := logUniform(0.010, 0.100), uniform(0, 0.7)
+	jitter := uniform(0.1, 0.5)
+	var dist LatencyDistribution
+	var shape string
+	switch r := rng.Float64(); {
+	case r < 0.6:
+		shape = "gaussian"
+	case r < 0.8:
+		dist, shape = LogNormal{Sigma: uniform(0.3, 0.8)}, "lognormal"
+	case r < 0.9:
+		dist, shape = Pareto{Alpha: uniform(1.5, 3)}, "pareto"
+	default:
+		dist, shape = Bimodal{SlowProb: uniform(0.02, 0.1), SlowFactor: uniform(3, 10)}, "bimodal"
+	}
+	sc := Scenario{
+		Name:          fmt.Sprintf("fuzz-%d", seed),
+		Service:       "svc",
+		TotalRequests: 1000 * (4 + rng.Intn(9)),
+	}
+	errRate := uniform(0, 0.02)
+	for i := range n {
+		mean := median * math.Exp(spread*rng.NormFloat64())
+		sc.Endpoints = append(sc.Endpoints, EndpointSpec{
+			Addr:           fmt.Sprintf("e%d", i),
+			MeanLatencySec: mean,
+			JitterSec:      jitter * mean,
+			ErrorRate:      errRate,
+			Distribution:   dist,
+		})
+	}
+	patterns := []string{fmt.Sprintf("%d endpoints, %s, median %.0fms", n, shape, 1000*median)}
+	if rng.Intn(5) == 0 {
+		e := &sc.Endpoints[rng.Intn(n)]
+		e.ErrorRate = uniform(0.05, 0.3)
+		patterns = append(patterns, fmt.Sprintf("%s flaky at %.0f%%", e.Addr, 100*e.ErrorRate))
+	}
+
+	for range 1 + rng.Intn(3) {
+		total := float64(sc.TotalRequests)
+		start := int(total * uniform(0.1, 0.7))
+		end := min(start+int(total*uniform(0.1, 0.4)), sc.TotalRequests-1)
+		e := sc.Endpoints[rng.Intn(n)]
+		switch rng.Intn(6) {
+		case 0:
+			lat, errs := e.MeanLatencySec*uniform(2, 8), e.ErrorRate+uniform(0, 0.3)
+			sc.Events = append(sc.Events,
+				EnvironmentEvent{Step: start, Endpoint: e.Addr, NewMeanLatency: &lat, NewErrorRate: &errs},
+				EnvironmentEvent{Step: end, Endpoint: e.Addr, NewMeanLatency: &e.MeanLatencySec, NewErrorRate: &e.ErrorRate})
+			patterns = append(patterns, fmt.Sprintf("%s degrades %d-%d", e.Addr, start, end))
+		case 1:
+			sc.Generators = append(sc.Generators,
+				Ramp{Endpoint: e.Addr, Param: ParamLatency, From: e.MeanLatencySec, To: e.MeanLatencySec * uniform(2, 6), StartStep: start, EndStep: end, Every: 50},
+				Events{ParamLatency.event(end+1, e.Addr, e.MeanLatencySec)})
+			patterns = append(patterns, fmt.Sprintf("%s ramps %d-%d", e.Addr, start, end))
+		case 2:
+			k := 1 + rng.Intn(max(1, n/2))
+			var addrs []string
+			for _, i := range rng.Perm(n)[:k] {
+				addrs = append(addrs, sc.Endpoints[i].Addr)
+			}
+			slices.Sort(addrs)
+			sc.Generators = append(sc.Generators, Outage{Endpoints: addrs, StartStep: start, EndStep: end})
+			patterns = append(patterns, fmt.Sprintf("%s down %d-%d", strings.Join(addrs, "+"), start, end))
+		case 3:
+			sc.Generators = append(sc.Generators, Partition{Endpoints: []string{e.Addr}, StartStep: start, EndStep: end})
+			sc.TimeoutSec = 0.5
+			patterns = append(patterns, fmt.Sprintf("%s partitioned %d-%d", e.Addr, start, end))
+		case 4:
+			sc.Generators = append(sc.Generators, Spikes{Endpoint: e.Addr, BaseSec: e.MeanLatencySec, SpikeSec: 10 * e.MeanLatencySec,
+				Every: 200 + rng.Intn(800), Length: 10 + rng.Intn(40), StartStep: start, EndStep: end})
+			patterns = append(patterns, fmt.Sprintf("%s spikes %d-%d", e.Addr, start, end))
+		default:
+			sc.Generators = append(sc.Generators, Sine{Endpoint: e.Addr, Param: ParamLatency, Base: e.MeanLatencySec,
+				Amplitude: e.MeanLatencySec * uniform(0.3, 0.9), Period: 500 + rng.Intn(2500), Every: 50})
+			patterns = append(patterns, fmt.Sprintf("%s oscillates", e.Addr))
+		}
+	}
+	return sc, patterns
+}
+
+// Fuzz runs strategies on random scenarios (see RandomScenario) to find
+// where one of them, by default SwarmRoute, falls furthest behind the
+// best of the others by an Objective.
+type Fuzz struct {
+	Seed       int64 // seeds the scenario draws
+	Count      int   // scenarios to draw
+	Strategies []StrategySpec
+	Target     string // the strategy name to compare; "" means "SwarmRoute"
+	Seeds      []int64
+	Objective  Objective // nil means DefaultObjective
+	Parallel   int       // scenarios at a time; 0 means GOMAXPROCS
+	// OnDone, if set, is called after each scenario.  Calls are serialized.
+	OnDone func(done, total int)
+}
+
+// FuzzCase is one scenario of a Fuzz.  Seed recreates the scenario with
+// RandomScenario and Patterns describes it.  Margin is how much better
+// the best other strategy, Best, scored than the target; negative if the
+// target won.
+type FuzzCase struct {
+	Seed         int64
+	Scenario     Scenario
+	Patterns     []string
+	Aggregations []MultiSeedAggregation // sorted by strategy name
+	TargetScore  float64
+	Best         string
+	BestScore    float64
+	Margin       float64
+}
+
+// Run runs the fuzzer and returns the cases by decreasing margin.  It
+// fails before running anything if a strategy doesn't build or the
+// target isn't among them.
+func (f Fuzz) Run() ([]FuzzCase, error) {
+	target := cmp.Or(f.Target, "SwarmRoute")
+	objective := f.Objective
+	if objective == nil {
+		objective = DefaultObjective
+	}
+	newStrategies := func() ([]Strategy, error) {
+		out := make([]Strategy, len(f.Strategies))
+		for i, spec := range f.Strategies {
+			s, err := spec.New()
+			if err != nil {
+				return nil, err
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	strategies, err := newStrategies()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(strategies, func(s Strategy) bool { return s.Name() == target }) || len(strategies) < 2 {
+		return nil, fmt.Errorf("fuzz: want %s and at least one other strategy", target)
+	}
+
+	cases := make([]FuzzCase, f.Count)
+	jobs := make(chan int)
+	parallel := f.Parallel
+	if parallel <= 0 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				seed := streamSeed(f.Seed, fmt.Sprintf("fuzz/%d", i))
+				sc, patterns := randomScenario(seed)
+				strategies, _ := newStrategies()
+				c := FuzzCase{Seed: seed, Scenario: sc, Patterns: patterns, Aggregations: AggregateMultiSeed(sc, strategies, f.Seeds)}
+				slices.SortFunc(c.Aggregations, func(a, b MultiSeedAggregation) int { return cmp.Compare(a.Strategy, b.Strategy) })
+				c.BestScore = math.Inf(-1)
+				for _, a := range c.Aggregations {
+					score := objective.ScoreAggregation(a)
+					switch {
+					case a.Strategy == target:
+						c.TargetScore = score
+					case score > c.BestScore:
+						c.Best, c.BestScore = a.Strategy, score
+					}
+				}
+				c.Margin = c.BestScore - c.TargetScore
+				cases[i] = c
+				mu.Lock()
+				if done++; f.OnDone != nil {
+					f.OnDone(done, f.Count)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range f.Count {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	slices.SortStableFunc(cases, func(a, b FuzzCase) int { return cmp.Compare(b.Margin, a.Margin) })
+	return cases, nil
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import "math"
+
+// EventGenerator produces environment events declaratively, so drifting and
+// diurnal environments don't have to be written out event by event.  Put
+// generators in Scenario.Generators; RunScenario expands them alongside
+// Scenario.Events.
+type EventGenerator interface {
+	// Events returns the concrete events for a run of totalSteps requests.
+	Events(totalSteps int) []EnvironmentEvent
+}
+
+// Param selects the endpoint parameter a generator changes.
+type Param int
+
+const (
+	// ParamLatency changes MeanLatencySec.
+	ParamLatency Param = iota
+	// ParamJitter changes JitterSec.
+	ParamJitter
+	// ParamErrorRate changes ErrorRate.
+	ParamErrorRate
+)
+
+// event returns an EnvironmentEvent setting p to v.
+func (p Param) event(step int, endpoint string, v float64) EnvironmentEvent {
+	ev := EnvironmentEvent{Step: step, Endpoint: endpoint}
+	switch p {
+	case ParamLatency:
+		ev.NewMeanLatency = &v
+	case ParamJitter:
+		ev.NewJitterSec = &v
+	case ParamErrorRate:
+		ev.NewErrorRate = &v
+	}
+	return ev
+}
+
+// Outage takes a group of endpoints down together from StartStep until
+// EndStep (the end of the run if 0), modeling a shared dependency failing:
+// requests to them are refused immediately with zero latency.  Use a single
+// endpoint for a plain full outage.
+type Outage struct {
+	Endpoints          []string
+	StartStep, EndStep int
+}
+
+// Events expands the outage into down and up events.
+func (o Outage) Events(int) []EnvironmentEvent {
+	down, up := true, false
+	out := make([]EnvironmentEvent, 0, 2*len(o.Endpoints))
+	for _, ep := range o.Endpoints {
+		out = append(out, EnvironmentEvent{Step: o.StartStep, Endpoint: ep, NewDown: &down})
+		if o.EndStep > 0 {
+			out = append(out, EnvironmentEvent{Step: o.EndStep, Endpoint: ep, NewDown: &up})
+		}
+	}
+	return out
+}
+
+// Partition makes a group of endpoints unreachable from StartStep until
+// EndStep (the end of the run if 0): requests are blackholed and hang until
+// the scenario's timeout instead of failing fast as with Outage.
+type Partition struct {
+	Endpoints          []string
+	StartStep, EndStep int
+}
+
+// Events expands the partition into blackhole and restore events.
+func (p Partition) Events(int) []EnvironmentEvent {
+	on, off := true, false
+	out := make([]EnvironmentEvent, 0, 2*len(p.Endpoints))
+	for _, ep := range p.Endpoints {
+		out = append(out, EnvironmentEvent{Step: p.StartStep, Endpoint: ep, NewBlackhole: &on})
+		if p.EndStep > 0 {
+			out = append(out, EnvironmentEvent{Step: p.EndStep, Endpoint: ep, NewBlackhole: &off})
+		}
+	}
+	return out
+}
+
+// Events is a fixed list of events, e.g. to be repeated with Repeat.
+type Events []EnvironmentEvent
+
+// Events returns the list itself.
+func (e Events) Events(int) []EnvironmentEvent { return e }
+
+// Ramp moves a parameter of one endpoint linearly from From at StartStep to
+// To at EndStep, emitting an event every Every steps (1 if unset) and one at
+// EndStep.
+type Ramp struct {
+	Endpoint           string
+	Param              Param
+	From, To           float64
+	StartStep, EndStep int
+	Every              int
+}
+
+// Events expands the ramp.
+func (r Ramp) Events(int) []EnvironmentEvent {
+	every := max(r.Every, 1)
+	var out []EnvironmentEvent
+	for step := r.StartStep; step < r.EndStep; step += every {
+		frac := float64(step-r.StartStep) / float64(r.EndStep-r.StartStep)
+		out = append(out, r.Param.event(step, r.Endpoint, r.From+(r.To-r.From)*frac))
+	}
+	return append(out, r.Param.event(r.EndStep, r.Endpoint, r.To))
+}
+
+// Sine oscillates a parameter of one endpoint around Base:
+//
+//	value = Base + Amplitude*sin(2π*(step-StartStep)/Period)
+//
+// from StartStep to EndStep (the end of the run if 0), emitting an event
+// every Every steps (1 if unset).  Error rates are clamped to 0..1 by the
+// simulator; latencies below zero are clamped here.
+type Sine struct {
+	Endpoint           string
+	Param              Param
+	Base, Amplitude    float64
+	Period             int
+	StartStep, EndStep int
+	Every              int
+}
+
+// Events expands the oscillation.
+func (s Sine) Events(totalSteps int) []EnvironmentEvent {
+	if s.Period <= 0 {
+		return nil
+	}
+	end := s.EndStep
+	if end <= 0 {
+		end = totalSteps
+	}
+	every := max(s.Every, 1)
+	var out []EnvironmentEvent
+	for step := s.StartStep; step < end; step += every {
+		v := s.Base + s.Amplitude*math.Sin(2*math.Pi*float64(step-s.StartStep)/float64(s.Period))
+		if s.Param != ParamErrorRate {
+			v = max(v, 0)
+		}
+		out = append(out, s.Param.event(step, s.Endpoint, v))
+	}
+	return out
+}
+
+// Spikes injects short, recurring latency spikes on one endpoint, like
+// stop-the-world GC pauses: every Every steps from StartStep to EndStep
+// (the end of the run if 0) its mean latency jumps to SpikeSec for Length
+// steps (1 if unset) and then returns to BaseSec.  Use it to check that a
+// strategy rides out transient spikes instead of treating them like
+// sustained degradation.
+type Spikes struct {
+	Endpoint           string
+	BaseSec, SpikeSec  float64
+	Every, Length      int
+	StartStep, EndStep int
+}
+
+// Events expands the spikes into alternating spike and restore events.
+func (s Spikes) Events(totalSteps int) []EnvironmentEvent {
+	if s.Every <= 0 {
+		return nil
+	}
+	end := s.EndStep
+	if end <= 0 {
+		end = totalSteps
+	}
+	length := min(max(s.Length, 1), s.Every)
+	var out []EnvironmentEvent
+	for step := s.StartStep; step < end; step += s.Every {
+		out = append(out,
+			ParamLatency.event(step, s.Endpoint, s.SpikeSec),
+			ParamLatency.event(min(step+length, end), s.Endpoint, s.BaseSec))
+	}
+	return out
+}
+
+// Repeat replays the events of Of shifted by Period steps, Count times in
+// total, or until the end of the run if Count is 0.
+type Repeat struct {
+	Of     EventGenerator
+	Period int
+	Count  int
+}
+
+// Events expands the repetition.
+func (r Repeat) Events(totalSteps int) []EnvironmentEvent {
+	base := r.Of.Events(totalSteps)
+	if r.Period <= 0 {
+		return base
+	}
+	var out []EnvironmentEvent
+	for i := 0; r.Count <= 0 || i < r.Count; i++ {
+		shift := i * r.Period
+		if shift >= totalSteps {
+			break
+		}
+		for _, ev := range base {
+			ev.Step += shift
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// expandEvents returns the scenario's events followed by those of its
+// generators in order; of several events at the same step the last wins.
+func (sc Scenario) expandEvents() []EnvironmentEvent {
+	events := append([]EnvironmentEvent(nil), sc.Events...)
+	for _, g := range sc.Generators {
+		events = append(events, g.Events(sc.TotalRequests)...)
+	}
+	return events
+}
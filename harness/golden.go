@@ -0,0 +1,161 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Golden is a stored baseline of the aggregated metrics of a named
+// scenario suite.  Comparing a new run of the suite against it catches
+// code changes that make a strategy route worse.
+type Golden struct {
+	SchemaVersion int           `json:"schema_version"`
+	Suite         string        `json:"suite"`
+	Seeds         []int64       `json:"seeds"`
+	Entries       []GoldenEntry `json:"entries"`
+}
+
+// GoldenEntry holds the mean metrics of one strategy on one scenario.
+type GoldenEntry struct {
+	Scenario   string  `json:"scenario"`
+	Strategy   string  `json:"strategy"`
+	SuccessPct float64 `json:"success_pct"`
+	P95ms      float64 `json:"p95_ms"`
+	BadShare   float64 `json:"bad_window_share_pct"`
+}
+
+// Tolerances bound how much worse than the baseline a metric may get
+// before Compare reports a regression.
+type Tolerances struct {
+	// BadSharePts is the allowed increase of the bad-window share, in
+	// percentage points.
+	BadSharePts float64
+	// P95Pct is the allowed increase of the p95 latency, in percent of the
+	// baseline.
+	P95Pct float64
+	// SuccessPts is the allowed drop of the success rate, in percentage
+	// points.
+	SuccessPts float64
+}
+
+// DefaultTolerances absorb run-to-run noise: SwarmRoute also evaporates
+// pheromones on a wall-clock ticker, so a suite that runs for seconds does
+// not reproduce its baseline exactly even with the same seeds.
+var DefaultTolerances = Tolerances{BadSharePts: 1, P95Pct: 10, SuccessPts: 2}
+
+// Regression is a metric that got worse than its baseline by more than the
+// tolerance.  Limit is the worst value that would have passed.
+type Regression struct {
+	Scenario, Strategy, Metric string
+	Baseline, Got, Limit       float64
+}
+
+func (r Regression) String() string {
+	if r.Metric == "missing" {
+		return fmt.Sprintf("%s/%s: missing from the run", r.Scenario, r.Strategy)
+	}
+	return fmt.Sprintf("%s/%s: %s %.2f (baseline %.2f, limit %.2f)",
+		r.Scenario, r.Strategy, r.Metric, r.Got, r.Baseline, r.Limit)
+}
+
+// NewGolden records the mean metrics of aggs, the aggregations of every
+// scenario of suite run with seeds.
+func NewGolden(suite string, seeds []int64, aggs []MultiSeedAggregation) Golden {
+	g := Golden{SchemaVersion: JSONSchemaVersion, Suite: suite, Seeds: seeds}
+	for _, a := range aggs {
+		g.Entries = append(g.Entries, GoldenEntry{
+			Scenario:   a.Scenario,
+			Strategy:   a.Strategy,
+			SuccessPct: a.MeanSuccessPct,
+			P95ms:      a.MeanP95ms,
+			BadShare:   a.MeanBadShare,
+		})
+	}
+	sort.SliceStable(g.Entries, func(i, j int) bool {
+		if g.Entries[i].Scenario != g.Entries[j].Scenario {
+			return g.Entries[i].Scenario < g.Entries[j].Scenario
+		}
+		return g.Entries[i].Strategy < g.Entries[j].Strategy
+	})
+	return g
+}
+
+// ReadGolden reads a baseline written by WriteFile.
+func ReadGolden(path string) (Golden, error) {
+	var g Golden
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return g, err
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return g, fmt.Errorf("golden %s: %w", path, err)
+	}
+	if g.SchemaVersion != JSONSchemaVersion {
+		return g, fmt.Errorf("golden %s: schema version %d, want %d", path, g.SchemaVersion, JSONSchemaVersion)
+	}
+	return g, nil
+}
+
+// WriteFile stores the baseline at path as indented JSON, so updates
+// review well as diffs.
+func (g Golden) WriteFile(path string) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Compare reports every metric of run that is worse than the baseline by
+// more than tol, and every baseline entry missing from run.  Entries new in
+// run are ignored.  Both must come from the same suite and seeds, or the
+// comparison is meaningless and Compare fails.
+func (g Golden) Compare(run Golden, tol Tolerances) ([]Regression, error) {
+	if run.Suite != g.Suite || fmt.Sprint(run.Seeds) != fmt.Sprint(g.Seeds) {
+		return nil, fmt.Errorf("golden: baseline is suite %q seeds %v, run is suite %q seeds %v",
+			g.Suite, g.Seeds, run.Suite, run.Seeds)
+	}
+	type key struct{ scenario, strategy string }
+	got := make(map[key]GoldenEntry, len(run.Entries))
+	for _, e := range run.Entries {
+		got[key{e.Scenario, e.Strategy}] = e
+	}
+	var regs []Regression
+	for _, b := range g.Entries {
+		e, ok := got[key{b.Scenario, b.Strategy}]
+		if !ok {
+			regs = append(regs, Regression{Scenario: b.Scenario, Strategy: b.Strategy, Metric: "missing"})
+			continue
+		}
+		check := func(metric string, baseline, value, limit float64, worse bool) {
+			if worse {
+				regs = append(regs, Regression{b.Scenario, b.Strategy, metric, baseline, value, limit})
+			}
+		}
+		limit := b.BadShare + tol.BadSharePts
+		check("bad-window share %", b.BadShare, e.BadShare, limit, e.BadShare > limit)
+		limit = b.P95ms * (1 + tol.P95Pct/100)
+		check("p95 ms", b.P95ms, e.P95ms, limit, e.P95ms > limit)
+		limit = b.SuccessPct - tol.SuccessPts
+		check("success %", b.SuccessPct, e.SuccessPct, limit, e.SuccessPct < limit)
+	}
+	return regs, nil
+}
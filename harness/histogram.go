@@ -0,0 +1,59 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import "sort"
+
+// DefaultHistogramBucketsMS are the upper bounds, in milliseconds, of the
+// latency histogram buckets when Scenario.HistogramBucketsMS is nil.
+var DefaultHistogramBucketsMS = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000}
+
+// Histogram counts latencies into buckets.  Counts[i] is the number of
+// samples at most BoundsMS[i] and above the previous bound; the last count,
+// Counts[len(BoundsMS)], holds the samples above every bound.
+type Histogram struct {
+	BoundsMS []float64
+	Counts   []int
+}
+
+func newHistogram(bounds []float64) *Histogram {
+	return &Histogram{BoundsMS: bounds, Counts: make([]int, len(bounds)+1)}
+}
+
+// add records a latency in seconds.
+func (h *Histogram) add(latSec float64) {
+	h.Counts[sort.SearchFloat64s(h.BoundsMS, latSec*1000)]++
+}
+
+// Total returns the number of samples in h.
+func (h *Histogram) Total() int {
+	n := 0
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// histogramBuckets returns the sorted bucket bounds of sc.
+func (sc Scenario) histogramBuckets() []float64 {
+	if sc.HistogramBucketsMS == nil {
+		return DefaultHistogramBucketsMS
+	}
+	bounds := append([]float64(nil), sc.HistogramBucketsMS...)
+	sort.Float64s(bounds)
+	return bounds
+}
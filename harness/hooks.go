@@ -0,0 +1,39 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+// Hooks are optional callbacks into a run, so experiments can record custom
+// data (pheromone values per step, entropy of the selections, ...) without
+// changing the simulator.  Nil hooks are skipped.  Hooks run synchronously
+// in simulation order and must not call back into the run.
+type Hooks struct {
+	// OnStart is called when a run starts, before its first step, with
+	// its seed and the name of every client's strategy.
+	OnStart func(seed int64, strategies []string)
+	// OnEvent is called for every environment event as it is applied,
+	// before the request of its step.
+	OnEvent func(step int, ev EnvironmentEvent)
+	// OnPick is called for every attempt, retries included, once the
+	// client picked endpoint.
+	OnPick func(step, client int, service, endpoint string)
+	// OnReport is called whenever a result is reported to a client, which
+	// in open-loop runs happens when the attempt completes.
+	OnReport func(client int, service, endpoint string, latencySec float64, success bool)
+	// OnStep is called at the end of every step, after its request was
+	// issued and, in closed-loop runs, reported.
+	OnStep func(step int)
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// KeySpace gives every request a synthetic key, such as a user or cache
+// key, for strategies with affinity (KeyedStrategy) and for
+// Results.KeyAffinity.
+type KeySpace struct {
+	// Count is the number of distinct keys, "k0" to "k<Count-1>".
+	Count int `json:"count"`
+	// Skew above 1 draws keys from a Zipf distribution with that
+	// exponent, "k0" being the most popular; otherwise keys are uniform.
+	Skew float64 `json:"skew,omitempty"`
+}
+
+// KeyedStrategy is implemented by strategies that route by request key.
+// The simulator calls PickEndpointForKey instead of PickEndpoint and
+// PickEndpointExcluding when the scenario has a KeySpace.
+type KeyedStrategy interface {
+	Strategy
+	PickEndpointForKey(service, key string, exclude ...string) (string, error)
+}
+
+// keyPicker draws request keys.
+type keyPicker struct {
+	src  *countingSource
+	rng  *rand.Rand
+	zipf *rand.Zipf
+	n    int
+}
+
+// newKeyPicker returns a picker for ks, or nil if there is none.  Like
+// classes, keys come from their own random stream.
+func newKeyPicker(ks *KeySpace, seed int64) *keyPicker {
+	if ks == nil || ks.Count <= 0 {
+		return nil
+	}
+	src := newCountingSource(streamSeed(seed, "keys"))
+	p := &keyPicker{src: src, rng: rand.New(src), n: ks.Count}
+	if ks.Skew > 1 {
+		p.zipf = rand.NewZipf(p.rng, ks.Skew, 1, uint64(ks.Count-1))
+	}
+	return p
+}
+
+// next returns the next request's key.
+func (p *keyPicker) next() string {
+	if p.zipf != nil {
+		return "k" + strconv.FormatUint(p.zipf.Uint64(), 10)
+	}
+	return "k" + strconv.Itoa(p.rng.Intn(p.n))
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"math"
+	"math/rand"
+)
+
+// LatencyDistribution is the shape of an endpoint's latency.  Samples are
+// scaled to the endpoint's current MeanLatencySec, so events that change
+// the mean keep the shape.  The zero EndpointSpec uses Gaussian.
+type LatencyDistribution interface {
+	// Sample draws a latency in seconds with the given mean and standard
+	// deviation (JitterSec, already defaulted).
+	Sample(rng *rand.Rand, mean, jitter float64) float64
+}
+
+// Gaussian is a normal distribution truncated to 0.2x..5x the mean.  It has
+// a thin tail, so p95/p99 stay close to the mean.
+type Gaussian struct{}
+
+// Sample implements LatencyDistribution.
+func (Gaussian) Sample(rng *rand.Rand, mean, jitter float64) float64 {
+	lat := mean + rng.NormFloat64()*jitter
+	minLat := 0.2 * mean
+	maxLat := 5.0 * mean
+	if mean == 0 {
+		minLat = 0.001
+		maxLat = 0.050
+	}
+	if lat < minLat {
+		lat = minLat
+	}
+	if lat > maxLat {
+		lat = maxLat
+	}
+	return lat
+}
+
+// LogNormal is a log-normal distribution with shape Sigma, the usual model
+// of service latencies: skewed with a moderately long tail.  If Sigma is
+// zero it is derived from the jitter.
+type LogNormal struct {
+	Sigma float64
+}
+
+// Sample implements LatencyDistribution.
+func (d LogNormal) Sample(rng *rand.Rand, mean, jitter float64) float64 {
+	if mean <= 0 {
+		return 0
+	}
+	sigma := d.Sigma
+	if sigma <= 0 {
+		cv := jitter / mean
+		sigma = math.Sqrt(math.Log1p(cv * cv))
+	}
+	mu := math.Log(mean) - sigma*sigma/2
+	return math.Exp(mu + sigma*rng.NormFloat64())
+}
+
+// Pareto is a heavy-tailed Pareto distribution with tail index Alpha: the
+// smaller Alpha, the heavier the tail.  Alpha must exceed 1 for the mean to
+// exist; values <= 1 use 2.  The jitter is ignored.
+type Pareto struct {
+	Alpha float64
+}
+
+// Sample implements LatencyDistribution.
+func (d Pareto) Sample(rng *rand.Rand, mean, _ float64) float64 {
+	alpha := d.Alpha
+	if alpha <= 1 {
+		alpha = 2
+	}
+	xm := mean * (alpha - 1) / alpha // scale giving the requested mean
+	return xm / math.Pow(1-rng.Float64(), 1/alpha)
+}
+
+// Bimodal models a fast path and a slow path (cache miss, GC pause, cold
+// shard): with probability SlowProb a request takes SlowFactor times as
+// long as a fast one.  The mean is preserved and each mode is Gaussian
+// with the jitter scaled to the mode.
+type Bimodal struct {
+	SlowProb   float64
+	SlowFactor float64
+}
+
+// Sample implements LatencyDistribution.
+func (d Bimodal) Sample(rng *rand.Rand, mean, jitter float64) float64 {
+	p, factor := clamp01(d.SlowProb), max(d.SlowFactor, 1)
+	fast := mean / (1 - p + p*factor)
+	mode := fast
+	if rng.Float64() < p {
+		mode = fast * factor
+	}
+	if mean > 0 {
+		jitter *= mode / mean
+	}
+	return Gaussian{}.Sample(rng, mode, jitter)
+}
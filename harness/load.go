@@ -0,0 +1,132 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"container/heap"
+	"math/rand"
+)
+
+// Load configures open-loop traffic: requests arrive on their own schedule
+// instead of one after the other, so a slow endpoint builds up in-flight
+// requests and, with a concurrency limit, a client-side queue.  Results are
+// reported to the strategy when a request completes, not when it starts.
+type Load struct {
+	// RPS is the mean arrival rate in requests per second.
+	RPS float64
+	// Poisson draws exponentially distributed inter-arrival times;
+	// otherwise arrivals are evenly spaced at 1/RPS.
+	Poisson bool
+	// Concurrency caps the requests in flight; arrivals beyond the cap
+	// wait in FIFO order for a slot.  Zero means unlimited.
+	Concurrency int
+}
+
+// completion is a request in flight, reported when the clock passes Done.
+type completion struct {
+	Done       float64
+	Client     int
+	Service    string
+	Endpoint   string
+	LatencySec float64
+	Success    bool
+	Feedback   *ServerFeedback `json:",omitempty"`
+}
+
+type completionHeap []completion
+
+func (h completionHeap) Len() int           { return len(h) }
+func (h completionHeap) Less(i, j int) bool { return h[i].Done < h[j].Done }
+func (h completionHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *completionHeap) Push(x any)        { *h = append(*h, x.(completion)) }
+func (h *completionHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// openLoop runs the simulated clock of an open-loop run.
+type openLoop struct {
+	load    Load
+	src     *countingSource
+	rng     *rand.Rand
+	report  func(completion)
+	pending completionHeap
+
+	arrival, start float64 // latest arrival and start times
+	inFlight       map[string]int
+	maxInFlight    int
+	end            float64 // last completion, set by drain
+}
+
+// newOpenLoop returns the clock for load.  Arrivals use their own random
+// stream, so outcomes stay comparable with closed-loop runs of the same seed.
+func newOpenLoop(load Load, seed int64, report func(completion)) *openLoop {
+	src := newCountingSource(seed + 1)
+	return &openLoop{load: load, src: src, rng: rand.New(src), report: report, inFlight: make(map[string]int)}
+}
+
+// next advances to the next arrival and returns when the request starts
+// and how long it waited for a concurrency slot.  Completions up to the
+// start time are reported first.
+func (o *openLoop) next() (start, wait float64) {
+	gap := 1 / o.load.RPS
+	if o.load.Poisson {
+		gap = o.rng.ExpFloat64() / o.load.RPS
+	}
+	o.arrival += gap
+	o.start = max(o.start, o.arrival) // FIFO: never overtake a queued request
+	o.deliver(o.start)
+	if c := o.load.Concurrency; c > 0 && len(o.pending) >= c {
+		o.start = max(o.start, o.pending[0].Done)
+		o.deliver(o.start)
+	}
+	wait = o.start - o.arrival
+	return o.start, wait
+}
+
+// add puts a started request in flight.
+func (o *openLoop) add(c completion) {
+	heap.Push(&o.pending, c)
+	o.inFlight[c.Endpoint]++
+	o.maxInFlight = max(o.maxInFlight, len(o.pending))
+}
+
+// deliver reports every request completed by t.
+func (o *openLoop) deliver(t float64) {
+	for len(o.pending) > 0 && o.pending[0].Done <= t {
+		o.complete(heap.Pop(&o.pending).(completion))
+	}
+}
+
+// complete takes c out of flight and reports it.
+func (o *openLoop) complete(c completion) {
+	o.inFlight[c.Endpoint]--
+	o.report(c)
+}
+
+// drain reports all remaining requests and records when the last one
+// completed in end.
+func (o *openLoop) drain() {
+	o.end = o.start
+	for len(o.pending) > 0 {
+		c := heap.Pop(&o.pending).(completion)
+		o.end = max(o.end, c.Done)
+		o.complete(c)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+// Thrift compact protocol types.
+const (
+	typeI32    = 5
+	typeI64    = 6
+	typeBinary = 8
+	typeList   = 9
+	typeStruct = 12
+)
+
+// compact encodes the Thrift structs of the Parquet metadata with the
+// compact protocol.  Fields of a struct are written in increasing id order.
+type compact struct {
+	buf  []byte
+	last []int16 // id of the previous field of every open struct
+	prev int16
+}
+
+func zigzag(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+
+func (c *compact) varint(v uint64) {
+	for v >= 0x80 {
+		c.buf = append(c.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	c.buf = append(c.buf, byte(v))
+}
+
+func (c *compact) field(id int16, typ byte) {
+	if d := id - c.prev; d > 0 && d <= 15 {
+		c.buf = append(c.buf, byte(d)<<4|typ)
+	} else {
+		c.buf = append(c.buf, typ)
+		c.varint(zigzag(int64(id)))
+	}
+	c.prev = id
+}
+
+func (c *compact) i32(id int16, v int32)  { c.field(id, typeI32); c.varint(zigzag(int64(v))) }
+func (c *compact) i64(id int16, v int64)  { c.field(id, typeI64); c.varint(zigzag(v)) }
+func (c *compact) str(id int16, s string) { c.field(id, typeBinary); c.bytes(s) }
+
+func (c *compact) bytes(s string) {
+	c.varint(uint64(len(s)))
+	c.buf = append(c.buf, s...)
+}
+
+// open starts a struct value: the message, an element of a list or, after
+// the field header written by begin, a field.  close ends it.
+func (c *compact) open() {
+	c.last = append(c.last, c.prev)
+	c.prev = 0
+}
+
+func (c *compact) close() {
+	c.buf = append(c.buf, 0) // stop
+	c.prev, c.last = c.last[len(c.last)-1], c.last[:len(c.last)-1]
+}
+
+// begin starts a struct field, ended by close.
+func (c *compact) begin(id int16) {
+	c.field(id, typeStruct)
+	c.open()
+}
+
+// list starts a list field of n elements of typ, written next: structs
+// between open and close, others with varint or bytes.
+func (c *compact) list(id int16, typ byte, n int) {
+	c.field(id, typeList)
+	if n < 15 {
+		c.buf = append(c.buf, byte(n)<<4|typ)
+	} else {
+		c.buf = append(c.buf, 0xf0|typ)
+		c.varint(uint64(n))
+	}
+}
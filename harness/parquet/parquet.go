@@ -0,0 +1,287 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parquet writes tables as Apache Parquet files, so large traces
+// load directly into DuckDB, Spark or pandas.  It only uses the standard
+// library and writes the simplest valid layout: required (non-null)
+// columns, PLAIN encoded and uncompressed, in row groups of RowGroupRows
+// rows with one data page per column.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Type is the type of a column.
+type Type int
+
+const (
+	Bool   Type = iota // Go bool
+	Int64              // Go int64 or int
+	Double             // Go float64
+	String             // Go string, stored as UTF-8 byte arrays
+)
+
+// Column describes a column of the table.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// RowGroupRows is the number of rows buffered before a row group is
+// written, which bounds the memory used by large tables.
+const RowGroupRows = 1 << 16
+
+// Physical types, encodings and other enums of the Parquet format.
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	repetitionRequired = 0
+	convertedUTF8      = 0
+	pageData           = 0
+	codecUncompressed  = 0
+)
+
+var physical = map[Type]int32{Bool: typeBoolean, Int64: typeInt64, Double: typeDouble, String: typeByteArray}
+
+var magic = []byte("PAR1")
+
+// Writer writes a table row by row.  Close completes the file.
+type Writer struct {
+	w       io.Writer
+	off     int64
+	cols    []Column
+	pages   []page
+	rows    int
+	total   int64
+	groups  []rowGroup
+	started bool
+	err     error
+}
+
+// page buffers the PLAIN-encoded values of a column.
+type page struct {
+	data []byte
+	bits int // booleans packed into the last byte of data
+}
+
+type rowGroup struct {
+	rows   int
+	size   int64
+	chunks []chunk
+}
+
+type chunk struct {
+	offset, size int64
+	values       int
+}
+
+// NewWriter returns a Writer of a table with cols to w.
+func NewWriter(w io.Writer, cols []Column) (*Writer, error) {
+	if len(cols) == 0 {
+		return nil, errors.New("parquet: no columns")
+	}
+	for _, c := range cols {
+		if _, ok := physical[c.Type]; !ok || c.Name == "" {
+			return nil, fmt.Errorf("parquet: invalid column %+v", c)
+		}
+	}
+	return &Writer{w: w, cols: cols, pages: make([]page, len(cols))}, nil
+}
+
+// Write appends a row with a value of the column's Go type per column.
+func (w *Writer) Write(row ...any) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(row) != len(w.cols) {
+		return fmt.Errorf("parquet: row has %d values, want %d", len(row), len(w.cols))
+	}
+	for i, v := range row {
+		p := &w.pages[i]
+		switch x := v.(type) {
+		case bool:
+			if w.cols[i].Type != Bool {
+				return w.typeErr(i, v)
+			}
+			if p.bits%8 == 0 {
+				p.data = append(p.data, 0)
+			}
+			if x {
+				p.data[len(p.data)-1] |= 1 << (p.bits % 8)
+			}
+			p.bits++
+		case int:
+			if w.cols[i].Type != Int64 {
+				return w.typeErr(i, v)
+			}
+			p.data = binary.LittleEndian.AppendUint64(p.data, uint64(x))
+		case int64:
+			if w.cols[i].Type != Int64 {
+				return w.typeErr(i, v)
+			}
+			p.data = binary.LittleEndian.AppendUint64(p.data, uint64(x))
+		case float64:
+			if w.cols[i].Type != Double {
+				return w.typeErr(i, v)
+			}
+			p.data = binary.LittleEndian.AppendUint64(p.data, math.Float64bits(x))
+		case string:
+			if w.cols[i].Type != String {
+				return w.typeErr(i, v)
+			}
+			p.data = binary.LittleEndian.AppendUint32(p.data, uint32(len(x)))
+			p.data = append(p.data, x...)
+		default:
+			return w.typeErr(i, v)
+		}
+	}
+	w.rows++
+	if w.rows == RowGroupRows {
+		return w.flushGroup()
+	}
+	return nil
+}
+
+func (w *Writer) typeErr(i int, v any) error {
+	// The row is partly buffered, so the table can't be completed.
+	w.err = fmt.Errorf("parquet: column %s: unexpected value %T", w.cols[i].Name, v)
+	return w.err
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(b)
+	w.off += int64(n)
+	w.err = err
+}
+
+// flushGroup writes the buffered rows as a row group.
+func (w *Writer) flushGroup() error {
+	if !w.started {
+		w.started = true
+		w.write(magic)
+	}
+	g := rowGroup{rows: w.rows}
+	for i := range w.pages {
+		p := &w.pages[i]
+		var h compact
+		h.open()
+		h.i32(1, pageData)
+		h.i32(2, int32(len(p.data)))
+		h.i32(3, int32(len(p.data)))
+		h.begin(5) // DataPageHeader
+		h.i32(1, int32(w.rows))
+		h.i32(2, encodingPlain)
+		h.i32(3, encodingRLE)
+		h.i32(4, encodingRLE)
+		h.close()
+		h.close()
+		c := chunk{offset: w.off, size: int64(len(h.buf) + len(p.data)), values: w.rows}
+		w.write(h.buf)
+		w.write(p.data)
+		g.chunks = append(g.chunks, c)
+		g.size += c.size
+		*p = page{data: p.data[:0]}
+	}
+	w.groups = append(w.groups, g)
+	w.total += int64(w.rows)
+	w.rows = 0
+	return w.err
+}
+
+// Close writes the buffered rows and the file footer.  It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.rows > 0 {
+		if err := w.flushGroup(); err != nil {
+			return err
+		}
+	} else if !w.started {
+		w.started = true
+		w.write(magic) // an empty table has no row groups
+	}
+	var m compact
+	m.open()
+	m.i32(1, 1) // version
+	m.list(2, typeStruct, len(w.cols)+1)
+	m.open()
+	m.str(4, "schema")
+	m.i32(5, int32(len(w.cols)))
+	m.close()
+	for _, c := range w.cols {
+		m.open()
+		m.i32(1, physical[c.Type])
+		m.i32(3, repetitionRequired)
+		m.str(4, c.Name)
+		if c.Type == String {
+			m.i32(6, convertedUTF8)
+		}
+		m.close()
+	}
+	m.i64(3, w.total)
+	m.list(4, typeStruct, len(w.groups))
+	for _, g := range w.groups {
+		m.open()
+		m.list(1, typeStruct, len(g.chunks))
+		for i, c := range g.chunks {
+			m.open()
+			m.i64(2, c.offset)
+			m.begin(3) // ColumnMetaData
+			m.i32(1, physical[w.cols[i].Type])
+			m.list(2, typeI32, 2)
+			m.varint(zigzag(encodingPlain))
+			m.varint(zigzag(encodingRLE))
+			m.list(3, typeBinary, 1)
+			m.bytes(w.cols[i].Name)
+			m.i32(4, codecUncompressed)
+			m.i64(5, int64(c.values))
+			m.i64(6, c.size)
+			m.i64(7, c.size)
+			m.i64(9, c.offset)
+			m.close()
+			m.close()
+		}
+		m.i64(2, g.size)
+		m.i64(3, int64(g.rows))
+		m.close()
+	}
+	m.str(6, "swarmroute harness")
+	m.close()
+	w.write(m.buf)
+	w.write(binary.LittleEndian.AppendUint32(nil, uint32(len(m.buf))))
+	w.write(magic)
+	if w.err == nil {
+		w.err = errors.New("parquet: writer closed")
+		return nil
+	}
+	return w.err
+}
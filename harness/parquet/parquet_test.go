@@ -0,0 +1,193 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{{"step", Int64}, {"endpoint", String}, {"score", Double}, {"ok", Bool}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := RowGroupRows + 10 // two row groups
+	for i := 0; i < n; i++ {
+		if err := w.Write(i, fmt.Sprint("ep", i%3), float64(i)/2, i%3 == 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	names, rows := read(t, buf.Bytes())
+	if !reflect.DeepEqual(names, []string{"step", "endpoint", "score", "ok"}) || len(rows) != n {
+		t.Fatalf("unexpected table: %v, %d rows", names, len(rows))
+	}
+	for _, i := range []int{0, 1, RowGroupRows - 1, RowGroupRows, n - 1} {
+		want := []any{int64(i), fmt.Sprint("ep", i%3), float64(i) / 2, i%3 == 0}
+		if !reflect.DeepEqual(rows[i], want) {
+			t.Fatalf("row %d: expected %v, got %v", i, want, rows[i])
+		}
+	}
+
+	if err := w.Write(1, "a", 1.0, true); err == nil {
+		t.Fatal("expected writes after Close to fail")
+	}
+	w, _ = NewWriter(&buf, []Column{{"step", Int64}})
+	if err := w.Write("1"); err == nil {
+		t.Fatal("expected a value of the wrong type to fail")
+	}
+	if _, err := NewWriter(&buf, nil); err == nil {
+		t.Fatal("expected a table without columns to fail")
+	}
+}
+
+func TestEmptyTable(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, []Column{{"step", Int64}})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if names, rows := read(t, buf.Bytes()); len(names) != 1 || len(rows) != 0 {
+		t.Fatalf("unexpected table: %v, %v", names, rows)
+	}
+}
+
+// read decodes a file written by Writer, following the Parquet and Thrift
+// specifications rather than the writer's code.
+func read(t *testing.T, data []byte) (names []string, rows [][]any) {
+	t.Helper()
+	if !bytes.HasPrefix(data, magic) || !bytes.HasSuffix(data, magic) {
+		t.Fatal("missing magic")
+	}
+	n := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&decoder{data, len(data) - 8 - n}).structure()
+	schema := meta[2].([]any)
+	var types []int64
+	for _, e := range schema[1:] {
+		el := e.(map[int16]any)
+		names = append(names, string(el[4].([]byte)))
+		types = append(types, el[1].(int64))
+	}
+	for _, g := range meta[4].([]any) {
+		group := g.(map[int16]any)
+		var cols [][]any
+		for _, c := range group[1].([]any) {
+			md := c.(map[int16]any)[3].(map[int16]any)
+			d := &decoder{data, int(md[9].(int64))}
+			header := d.structure()
+			count := int(header[5].(map[int16]any)[1].(int64))
+			body := data[d.pos : d.pos+int(header[3].(int64))]
+			var col []any
+			for i := 0; i < count; i++ {
+				switch types[len(cols)] {
+				case typeBoolean:
+					col = append(col, body[i/8]>>(i%8)&1 == 1)
+				case typeInt64:
+					col = append(col, int64(binary.LittleEndian.Uint64(body[8*i:])))
+				case typeDouble:
+					col = append(col, math.Float64frombits(binary.LittleEndian.Uint64(body[8*i:])))
+				case typeByteArray:
+					l := binary.LittleEndian.Uint32(body)
+					col = append(col, string(body[4:4+l]))
+					body = body[4+l:]
+				}
+			}
+			cols = append(cols, col)
+		}
+		for i := 0; i < int(group[3].(int64)); i++ {
+			row := make([]any, len(cols))
+			for j := range cols {
+				row[j] = cols[j][i]
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) != int(meta[3].(int64)) {
+		t.Fatalf("expected %d rows, read %d", meta[3], len(rows))
+	}
+	return names, rows
+}
+
+// decoder reads the Thrift compact protocol.
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) varint() uint64 {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	d.pos += n
+	return v
+}
+
+func (d *decoder) zigzag() int64 {
+	v := d.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *decoder) value(typ byte) any {
+	switch typ {
+	case typeI32, typeI64:
+		return d.zigzag()
+	case typeBinary:
+		n := int(d.varint())
+		d.pos += n
+		return d.data[d.pos-n : d.pos]
+	case typeList:
+		h := d.data[d.pos]
+		d.pos++
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(d.varint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = d.value(h & 0x0f)
+		}
+		return list
+	case typeStruct:
+		return d.structure()
+	}
+	panic(fmt.Sprintf("unexpected type %d", typ))
+}
+
+func (d *decoder) structure() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for {
+		h := d.data[d.pos]
+		d.pos++
+		if h == 0 {
+			return fields
+		}
+		if delta := int16(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(d.zigzag())
+		}
+		fields[id] = d.value(h & 0x0f)
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plot renders harness results as SVG charts: the selection share
+// of every endpoint, the rolling p95 latency and SwarmRoute's pheromones
+// over the steps of a run, with the scenario's degraded windows shaded, and
+// the latency distribution of every endpoint.  These curves show how a
+// strategy adapts far better than end-of-run aggregates.  It only uses the
+// standard library.
+package plot
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"swarmroute/harness"
+)
+
+// Chart geometry in pixels.
+const (
+	width, height            = 800, 400
+	left, right, top, bottom = 60, 170, 40, 50
+)
+
+// palette colors the series in order.
+var palette = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"}
+
+type point struct{ x, y float64 }
+
+type series struct {
+	name   string
+	points []point
+}
+
+type chart struct {
+	title, yLabel string
+	xLabel        string  // "step" if empty
+	yMax          float64 // fixed upper bound of the y axis; 0 fits the data
+	yFormat       string  // tick label format of y times yScale
+	yScale        float64 // 0 means 1
+	xMax          float64
+	series        []series
+	shade         []harness.DegradedWindow
+}
+
+// SelectionShare renders the selection share of every endpoint over the
+// trailing 100 steps (Results.ShareSeries).
+func SelectionShare(w io.Writer, r harness.Results) error {
+	byAddr := make(map[string][]point)
+	for _, s := range r.ShareSeries {
+		for addr := range r.Selection {
+			byAddr[addr] = append(byAddr[addr], point{float64(s.Step), s.Shares[addr]})
+		}
+	}
+	c := chart{title: title(r, "selection share"), yLabel: "share", yMax: 1, yFormat: "%.0f%%", yScale: 100, shade: r.DegradedWindows}
+	for _, addr := range sortedKeys(byAddr) {
+		c.series = append(c.series, series{addr, byAddr[addr]})
+	}
+	c.xMax = lastStep(r)
+	return c.render(w)
+}
+
+// RollingP95 renders the p95 latency of the trailing 100 steps
+// (Results.P95Series).
+func RollingP95(w io.Writer, r harness.Results) error {
+	pts := make([]point, len(r.P95Series))
+	for i, s := range r.P95Series {
+		pts[i] = point{float64(s.Step), s.LatMS}
+	}
+	c := chart{title: title(r, "rolling p95 latency"), yLabel: "ms", yFormat: "%.0f", shade: r.DegradedWindows,
+		series: []series{{"p95", pts}}}
+	c.xMax = lastStep(r)
+	return c.render(w)
+}
+
+// LatencyCDF renders the cumulative latency distribution of every endpoint
+// and of all requests (Results.EndpointHistograms and Histogram) up to the
+// histogram bound that covers 99% of all requests, so that a long tail does
+// not squeeze the curves.
+func LatencyCDF(w io.Writer, r harness.Results) error {
+	c := chart{title: title(r, "latency distribution"), xLabel: "ms", yLabel: "requests", yMax: 1, yFormat: "%.0f%%", yScale: 100}
+	cdf := func(h *harness.Histogram) []point {
+		if h == nil || h.Total() == 0 {
+			return nil
+		}
+		total := float64(h.Total())
+		pts, n := []point{{0, 0}}, 0
+		for i, bound := range h.BoundsMS {
+			n += h.Counts[i]
+			pts = append(pts, point{bound, float64(n) / total})
+		}
+		return pts
+	}
+	all := cdf(r.Histogram)
+	for _, p := range all {
+		c.xMax = p.x
+		if p.y >= 0.99 {
+			break
+		}
+	}
+	for _, addr := range sortedKeys(r.EndpointHistograms) {
+		if pts := cdf(r.EndpointHistograms[addr]); pts != nil {
+			c.series = append(c.series, series{addr, pts})
+		}
+	}
+	if all != nil {
+		c.series = append(c.series, series{"all", all})
+	}
+	for i, s := range c.series {
+		n := 0
+		for n < len(s.points) && s.points[n].x <= c.xMax {
+			n++
+		}
+		c.series[i].points = s.points[:n]
+	}
+	return c.render(w)
+}
+
+// Pheromones renders the SwarmRoute pheromones of every endpoint in
+// samples: the latency channel's Pos if channel is "pos", the error
+// channel's Neg if it is "neg".
+func Pheromones(w io.Writer, r harness.Results, samples []harness.PheromoneSample, channel string) error {
+	var what string
+	switch channel {
+	case "pos":
+		what = "latency pheromone (pos)"
+	case "neg":
+		what = "error pheromone (neg)"
+	default:
+		return fmt.Errorf("plot: unknown pheromone channel %q", channel)
+	}
+	byAddr := make(map[string][]point)
+	for _, s := range samples {
+		values := s.Pos
+		if channel == "neg" {
+			values = s.Neg
+		}
+		for addr, v := range values {
+			byAddr[addr] = append(byAddr[addr], point{float64(s.Step), v})
+		}
+	}
+	c := chart{title: title(r, what), yLabel: channel, yFormat: "%.0f", shade: r.DegradedWindows}
+	for _, addr := range sortedKeys(byAddr) {
+		c.series = append(c.series, series{addr, byAddr[addr]})
+	}
+	c.xMax = lastStep(r)
+	if n := len(samples); n > 0 {
+		c.xMax = max(c.xMax, float64(samples[n-1].Step))
+	}
+	return c.render(w)
+}
+
+// WriteCharts writes both charts of every result to dir, creating it if
+// needed, as <scenario>-<strategy>-share.svg and -p95.svg, and returns the
+// paths written.
+func WriteCharts(dir string, results []harness.Results) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, r := range results {
+		scenario := r.Scenario
+		if scenario == "" {
+			scenario = "run"
+		}
+		base := fileName(scenario) + "-" + fileName(r.Strategy)
+		for _, ch := range []struct {
+			suffix string
+			render func(io.Writer, harness.Results) error
+		}{{"share", SelectionShare}, {"p95", RollingP95}} {
+			path := filepath.Join(dir, base+"-"+ch.suffix+".svg")
+			if err := writeFile(path, r, ch.render); err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func writeFile(path string, r harness.Results, render func(io.Writer, harness.Results) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// render writes the chart as a standalone SVG document.
+func (c chart) render(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	yMax := c.yMax
+	if yMax == 0 {
+		for _, s := range c.series {
+			for _, p := range s.points {
+				yMax = max(yMax, p.y)
+			}
+		}
+		yMax = niceCeil(yMax)
+	}
+	xMax := max(c.xMax, 1)
+	yScale := c.yScale
+	if yScale == 0 {
+		yScale = 1
+	}
+	plotW, plotH := float64(width-left-right), float64(height-top-bottom)
+	x := func(v float64) float64 { return left + v/xMax*plotW }
+	y := func(v float64) float64 { return top + plotH - v/yMax*plotH }
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="14" font-weight="bold">%s</text>`+"\n", left, top-15, escape(c.title))
+	for _, win := range c.shade {
+		fmt.Fprintf(bw, `<rect x="%.1f" y="%d" width="%.1f" height="%.0f" fill="#d62728" fill-opacity="0.08"><title>%s degraded %d-%d</title></rect>`+"\n",
+			x(float64(win.Start)), top, x(float64(win.End))-x(float64(win.Start)), plotH, escape(win.Endpoint), win.Start, win.End)
+	}
+	// Axes and ticks
+	fmt.Fprintf(bw, `<path d="M%d %d V%.0f H%.0f" fill="none" stroke="black"/>`+"\n", left, top, top+plotH, left+plotW)
+	for i := 0; i <= 5; i++ {
+		yv, xv := yMax*float64(i)/5, xMax*float64(i)/5
+		label := fmt.Sprintf(c.yFormat, yv*yScale)
+		fmt.Fprintf(bw, `<line x1="%d" y1="%.1f" x2="%.0f" y2="%.1f" stroke="#ddd"/>`+"\n", left, y(yv), left+plotW, y(yv))
+		fmt.Fprintf(bw, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`+"\n", left-6, y(yv)+4, label)
+		fmt.Fprintf(bw, `<text x="%.1f" y="%.0f" text-anchor="middle">%.0f</text>`+"\n", x(xv), top+plotH+18, xv)
+	}
+	xLabel := c.xLabel
+	if xLabel == "" {
+		xLabel = "step"
+	}
+	fmt.Fprintf(bw, `<text x="%.0f" y="%d" text-anchor="middle">%s</text>`+"\n", left+plotW/2, height-10, escape(xLabel))
+	fmt.Fprintf(bw, `<text x="15" y="%.0f" text-anchor="middle" transform="rotate(-90 15 %.0f)">%s</text>`+"\n", top+plotH/2, top+plotH/2, escape(c.yLabel))
+	// Series and legend
+	for i, s := range c.series {
+		color := palette[i%len(palette)]
+		var pts strings.Builder
+		for j, p := range s.points {
+			if j > 0 {
+				pts.WriteByte(' ')
+			}
+			fmt.Fprintf(&pts, "%.1f,%.1f", x(p.x), y(min(p.y, yMax)))
+		}
+		fmt.Fprintf(bw, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"><title>%s</title></polyline>`+"\n", pts.String(), color, escape(s.name))
+		ly := top + 10 + 18*i
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="3"/>`+"\n", width-right+15, ly, width-right+35, ly, color)
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", width-right+40, ly+4, escape(s.name))
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// niceCeil rounds v up to 1, 2 or 5 times a power of ten.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	p := 1.0
+	for p*10 <= v {
+		p *= 10
+	}
+	for p > v {
+		p /= 10
+	}
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*p >= v {
+			return m * p
+		}
+	}
+	return 10 * p
+}
+
+func title(r harness.Results, what string) string {
+	if r.Scenario != "" {
+		return fmt.Sprintf("%s: %s (%s, seed %d)", r.Strategy, what, r.Scenario, r.Seed)
+	}
+	return fmt.Sprintf("%s: %s (seed %d)", r.Strategy, what, r.Seed)
+}
+
+// lastStep returns the last step covered by r's series and windows.
+func lastStep(r harness.Results) float64 {
+	last := 0
+	if n := len(r.ShareSeries); n > 0 {
+		last = r.ShareSeries[n-1].Step
+	}
+	if n := len(r.P95Series); n > 0 {
+		last = max(last, r.P95Series[n-1].Step)
+	}
+	for _, w := range r.DegradedWindows {
+		last = max(last, w.End)
+	}
+	return float64(last)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fileName replaces the characters of s that are unsafe in file names.
+func fileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plot
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"swarmroute/harness"
+)
+
+func TestChartsRenderValidSVG(t *testing.T) {
+	slow := 0.200
+	sc := harness.Scenario{
+		Name:    "two/endpoints",
+		Service: "svc",
+		Endpoints: []harness.EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		Events:        []harness.EnvironmentEvent{{Step: 500, Endpoint: "b", NewMeanLatency: &slow}},
+		TotalRequests: 1000,
+		Seed:          1,
+	}
+	r := harness.RunScenario(sc, harness.NewRoundRobinStrategy())
+
+	var b strings.Builder
+	if err := SelectionShare(&b, r); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(t, b.String(), "polyline"); got != 2 {
+		t.Fatalf("expected a line per endpoint, got %d", got)
+	}
+	if !strings.Contains(b.String(), "<title>b degraded 500-1000</title>") {
+		t.Fatalf("expected the degraded window to be shaded:\n%s", b.String())
+	}
+	b.Reset()
+	if err := RollingP95(&b, r); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(t, b.String(), "polyline"); got != 1 {
+		t.Fatalf("expected one p95 line, got %d", got)
+	}
+
+	dir := t.TempDir()
+	paths, err := WriteCharts(dir, []harness.Results{r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "two_endpoints-RoundRobin-share.svg"), filepath.Join(dir, "two_endpoints-RoundRobin-p95.svg")}
+	if len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+	data, err := os.ReadFile(paths[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	count(t, string(data), "polyline")
+}
+
+func TestLatencyAndPheromoneCharts(t *testing.T) {
+	sc := harness.Scenario{
+		Name:    "pheromones",
+		Service: "svc",
+		Endpoints: []harness.EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.060, JitterSec: 0.002},
+		},
+		TotalRequests: 500,
+		Seed:          1,
+	}
+	adapter := harness.NewSwarmRouteAdapter()
+	var samples []harness.PheromoneSample
+	sc.Hooks = &harness.Hooks{OnStep: func(step int) {
+		if step%50 == 0 {
+			samples = append(samples, adapter.SamplePheromones(step, "svc"))
+		}
+	}}
+	r := harness.RunScenario(sc, adapter)
+	if len(samples) != 10 || samples[9].Pos["a"] <= samples[9].Pos["b"] {
+		t.Fatalf("expected the faster endpoint to gather more pheromone, got %+v", samples)
+	}
+
+	var b strings.Builder
+	sc.Hooks = nil
+	if err := LatencyCDF(&b, harness.RunScenario(sc, harness.NewRoundRobinStrategy())); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(t, b.String(), "polyline"); got != 3 {
+		t.Fatalf("expected a line per endpoint and one for all requests, got %d", got)
+	}
+	for _, channel := range []string{"pos", "neg"} {
+		b.Reset()
+		if err := Pheromones(&b, r, samples, channel); err != nil {
+			t.Fatal(err)
+		}
+		if got := count(t, b.String(), "polyline"); got != 2 {
+			t.Fatalf("%s: expected a line per endpoint, got %d", channel, got)
+		}
+	}
+	if err := Pheromones(&b, r, samples, "weight"); err == nil {
+		t.Fatal("expected an unknown channel to fail")
+	}
+}
+
+// count parses svg as XML and returns the number of name elements in it.
+func count(t *testing.T, svg, name string) int {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(svg))
+	n := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return n
+			}
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == name {
+			n++
+		}
+	}
+}
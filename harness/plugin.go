@@ -0,0 +1,203 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// ProcessStrategy runs a strategy implemented in any language as a
+// subprocess, so prototypes can be compared with the built-in strategies
+// in the same simulator.  The harness writes newline-delimited JSON
+// messages to the process's stdin, with "op" one of:
+//
+//	{"op":"hello","version":1}                             → {"name":"MyStrategy"}
+//	{"op":"add_service","service":"svc","endpoints":["a","b"]}
+//	{"op":"seed","seed":42}
+//	{"op":"pick","service":"svc","exclude":["a"]}          → {"endpoint":"b"} or {"error":"..."}
+//	{"op":"report","service":"svc","endpoint":"b","latency_sec":0.031,"success":true}
+//
+// Only hello and pick are answered, with one line on stdout each; the
+// other messages are one-way, so reports cost no round trip.  "exclude"
+// is omitted unless a retry avoids endpoints, and should be ignored if it
+// excludes every endpoint.  The process's stderr is passed through.  A
+// minimal round robin in Python:
+//
+//	import json, sys
+//	eps, i = {}, 0
+//	for line in sys.stdin:
+//	    m = json.loads(line)
+//	    if m["op"] == "hello":
+//	        print(json.dumps({"name": "PyRoundRobin"}), flush=True)
+//	    elif m["op"] == "add_service":
+//	        eps[m["service"]] = m["endpoints"]
+//	    elif m["op"] == "pick":
+//	        i += 1
+//	        e = eps[m["service"]]
+//	        print(json.dumps({"endpoint": e[i % len(e)]}), flush=True)
+//
+// A broken process fails every later pick with the first error.
+type ProcessStrategy struct {
+	mu   sync.Mutex
+	name string
+	cmd  *exec.Cmd
+	in   io.WriteCloser
+	w    *bufio.Writer
+	enc  *json.Encoder
+	out  *bufio.Scanner
+	err  error
+}
+
+// PluginProtocolVersion is the version of the ProcessStrategy protocol
+// sent in the hello message.
+const PluginProtocolVersion = 1
+
+// pluginMessage is a message to a ProcessStrategy process.
+type pluginMessage struct {
+	Op         string   `json:"op"`
+	Version    int      `json:"version,omitempty"`
+	Service    string   `json:"service,omitempty"`
+	Endpoints  []string `json:"endpoints,omitempty"`
+	Exclude    []string `json:"exclude,omitempty"`
+	Endpoint   string   `json:"endpoint,omitempty"`
+	LatencySec *float64 `json:"latency_sec,omitempty"`
+	Success    *bool    `json:"success,omitempty"`
+	Seed       *int64   `json:"seed,omitempty"`
+}
+
+// pluginReply is a reply of a ProcessStrategy process.
+type pluginReply struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Error    string `json:"error"`
+}
+
+// NewProcessStrategy starts name with args and exchanges the hello.  The
+// process runs until Close, or until the harness exits and its stdin
+// closes.
+func NewProcessStrategy(name string, args ...string) (*ProcessStrategy, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &ProcessStrategy{cmd: cmd, in: in, w: bufio.NewWriter(in), out: bufio.NewScanner(out)}
+	p.enc = json.NewEncoder(p.w)
+	p.out.Buffer(nil, 1<<20)
+	reply, err := p.call(pluginMessage{Op: "hello", Version: PluginProtocolVersion})
+	if err == nil && reply.Name == "" {
+		err = errors.New("hello reply has no name")
+	}
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("strategy process %s: %w", name, err)
+	}
+	p.name = reply.Name
+	return p, nil
+}
+
+func (p *ProcessStrategy) Name() string { return p.name }
+
+func (p *ProcessStrategy) AddService(name string, endpoints []string) {
+	p.send(pluginMessage{Op: "add_service", Service: name, Endpoints: endpoints})
+}
+
+func (p *ProcessStrategy) Seed(seed int64) {
+	p.send(pluginMessage{Op: "seed", Seed: &seed})
+}
+
+func (p *ProcessStrategy) PickEndpoint(service string) (string, error) {
+	return p.PickEndpointExcluding(service)
+}
+
+// PickEndpointExcluding asks the process for an endpoint avoiding exclude.
+func (p *ProcessStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	reply, err := p.call(pluginMessage{Op: "pick", Service: service, Exclude: exclude})
+	if err != nil {
+		return "", err
+	}
+	if reply.Error != "" {
+		return "", errors.New(reply.Error)
+	}
+	return reply.Endpoint, nil
+}
+
+func (p *ProcessStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	p.send(pluginMessage{Op: "report", Service: service, Endpoint: endpoint, LatencySec: &latencySec, Success: &success})
+}
+
+// Close closes the process's stdin and waits for it to exit.
+func (p *ProcessStrategy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.w.Flush()
+	p.in.Close()
+	return p.cmd.Wait()
+}
+
+// send writes a one-way message; it is flushed with the next call.
+func (p *ProcessStrategy) send(m pluginMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = p.enc.Encode(m)
+	}
+}
+
+// call writes m and reads the reply.
+func (p *ProcessStrategy) call(m pluginMessage) (pluginReply, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = p.enc.Encode(m)
+	}
+	if p.err == nil {
+		p.err = p.w.Flush()
+	}
+	if p.err != nil {
+		return pluginReply{}, p.err
+	}
+	if !p.out.Scan() {
+		p.err = p.out.Err()
+		if p.err == nil {
+			p.err = io.ErrUnexpectedEOF
+		}
+		p.err = fmt.Errorf("strategy process exited: %w", p.err)
+		return pluginReply{}, p.err
+	}
+	var reply pluginReply
+	if err := json.Unmarshal(p.out.Bytes(), &reply); err != nil {
+		p.err = fmt.Errorf("strategy process reply %q: %w", p.out.Text(), err)
+		return pluginReply{}, p.err
+	}
+	return reply, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+// PopulationResults are the results of a population run.
+type PopulationResults struct {
+	// All covers every client's requests; its Strategy is "population".
+	All Results
+	// Groups covers the clients of each strategy name, in order of first
+	// appearance among the clients.
+	Groups []Results
+}
+
+// RunPopulation runs the scenario with many independent clients, each a
+// separate strategy instance with its own learned state, driving traffic
+// into one shared environment; request i is issued by client i mod
+// len(clients).  With endpoint capacities and open-loop load this reveals
+// herding and oscillation a single client can't: clients that all chase the
+// same endpoint overload it together.  Metrics come from the environment's
+// point of view, so Selection and the herding metrics of All describe the
+// load the endpoints saw.
+func RunPopulation(sc Scenario, clients []Strategy) PopulationResults {
+	if len(clients) == 0 {
+		return PopulationResults{}
+	}
+	all, groups, _ := simulate(sc, clients, nil)
+	return PopulationResults{All: all, Groups: groups}
+}
+
+// Clients returns n strategy instances made by newStrategy, which gets the
+// client index, e.g. to derive a seed.
+func Clients(n int, newStrategy func(i int) Strategy) []Strategy {
+	out := make([]Strategy, n)
+	for i := range out {
+		out[i] = newStrategy(i)
+	}
+	return out
+}
+
+// ClientGroup is one part of a mixed population (see MixedClients).
+type ClientGroup struct {
+	// Share is the group's relative share of the clients.
+	Share float64
+	// New makes the group's i-th client.
+	New func(i int) Strategy
+}
+
+// MixedClients returns n clients split among groups by share, e.g. 70%
+// RoundRobin and 30% SwarmRoute to model an incremental rollout.  Counts
+// are rounded by largest remainder so they add up to n; the clients of a
+// group are contiguous and in group order.
+func MixedClients(n int, groups ...ClientGroup) []Strategy {
+	total := 0.0
+	for _, g := range groups {
+		total += max(g.Share, 0)
+	}
+	if total <= 0 || n <= 0 {
+		return nil
+	}
+	counts := make([]int, len(groups))
+	rem := make([]float64, len(groups))
+	left := n
+	for i, g := range groups {
+		exact := float64(n) * max(g.Share, 0) / total
+		counts[i] = int(exact)
+		rem[i] = exact - float64(counts[i])
+		left -= counts[i]
+	}
+	for ; left > 0; left-- {
+		best := 0
+		for i := range rem {
+			if rem[i] > rem[best] {
+				best = i
+			}
+		}
+		counts[best]++
+		rem[best] = -1
+	}
+	out := make([]Strategy, 0, n)
+	for gi, g := range groups {
+		for i := 0; i < counts[gi]; i++ {
+			out = append(out, g.New(i))
+		}
+	}
+	return out
+}
@@ -0,0 +1,575 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	lib "swarmroute"
+)
+
+// StrategyFactory returns a new strategy configured by params.  It should
+// reject parameters it does not know (see Params.Only).
+type StrategyFactory func(params Params) (Strategy, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]StrategyFactory)
+)
+
+// Register makes a strategy available by name to NewStrategy, e.g. from a
+// -strategies flag or a config file.  It panics if name is already
+// registered, like database/sql.Register.
+func Register(name string, factory StrategyFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("harness: Register called twice for strategy " + name)
+	}
+	registry[name] = factory
+}
+
+// RegisteredStrategies returns the registered names, sorted.
+func RegisteredStrategies() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Params are the parameters of a StrategySpec: strings when parsed from a
+// flag, JSON values when decoded from a config file.
+type Params map[string]any
+
+// Float returns parameter name as a float64, or def if it is not set.
+func (p Params) Float(name string, def float64) (float64, error) {
+	switch v := p[name].(type) {
+	case nil:
+		return def, nil
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parameter %s: %w", name, err)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("parameter %s: not a number: %v", name, v)
+	}
+}
+
+// Int returns parameter name as an int64, or def if it is not set.
+func (p Params) Int(name string, def int64) (int64, error) {
+	f, err := p.Float(name, float64(def))
+	if err != nil {
+		return 0, err
+	}
+	if f != float64(int64(f)) {
+		return 0, fmt.Errorf("parameter %s: not an integer: %v", name, f)
+	}
+	return int64(f), nil
+}
+
+// String returns parameter name as a string, or def if it is not set.
+func (p Params) String(name, def string) (string, error) {
+	switch v := p[name].(type) {
+	case nil:
+		return def, nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("parameter %s: not a string: %v", name, v)
+	}
+}
+
+// Bool returns parameter name as a bool, or def if it is not set.
+func (p Params) Bool(name string, def bool) (bool, error) {
+	switch v := p[name].(type) {
+	case nil:
+		return def, nil
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("parameter %s: %w", name, err)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("parameter %s: not a bool: %v", name, v)
+	}
+}
+
+// Only fails if p has a parameter not in names, which catches typos.
+func (p Params) Only(names ...string) error {
+	for name := range p {
+		if !slices.Contains(names, name) {
+			return fmt.Errorf("unknown parameter %s (want one of %v)", name, names)
+		}
+	}
+	return nil
+}
+
+// StrategySpec names a registered strategy and its parameters.  Its JSON
+// form is {"name": "p2c", "params": {"alpha": 0.3}}, its flag form
+// "p2c:alpha=0.3" (see ParseStrategySpec).
+type StrategySpec struct {
+	Name   string `json:"name"`
+	Params Params `json:"params,omitempty"`
+}
+
+// ParseStrategySpec parses "name" or "name:key=value,key=value".
+func ParseStrategySpec(s string) (StrategySpec, error) {
+	name, params, _ := strings.Cut(s, ":")
+	spec := StrategySpec{Name: name}
+	if name == "" {
+		return spec, fmt.Errorf("strategy spec %q: missing name", s)
+	}
+	if params == "" {
+		return spec, nil
+	}
+	spec.Params = make(Params)
+	for _, kv := range strings.Split(params, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return spec, fmt.Errorf("strategy spec %q: want key=value, got %q", s, kv)
+		}
+		spec.Params[k] = v
+	}
+	return spec, nil
+}
+
+// ParseStrategySpecs parses a list of specs separated by spaces, as in
+// "random roundrobin p2c:alpha=0.3 swarmroute:preset=aggressive".
+func ParseStrategySpecs(s string) ([]StrategySpec, error) {
+	var specs []StrategySpec
+	for _, f := range strings.Fields(s) {
+		spec, err := ParseStrategySpec(f)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// ParseSwarmRouteVariant parses a SwarmRoute configuration in flag form,
+// "label[:key=value,...]", into a swarmroute spec named SwarmRoute-label,
+// e.g. "tight:preset=aggressive,base_weight=0.05" for SwarmRoute-tight.
+func ParseSwarmRouteVariant(s string) (StrategySpec, error) {
+	spec, err := ParseStrategySpec(s)
+	if err != nil {
+		return spec, err
+	}
+	if _, ok := spec.Params["label"]; ok {
+		return spec, fmt.Errorf("SwarmRoute variant %q: the label goes before the colon", s)
+	}
+	if spec.Params == nil {
+		spec.Params = make(Params)
+	}
+	spec.Params["label"] = spec.Name
+	spec.Name = "swarmroute"
+	return spec, nil
+}
+
+// New returns a new strategy of the spec's registered factory.
+func (s StrategySpec) New() (Strategy, error) {
+	registryMu.RLock()
+	factory, ok := registry[s.Name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (registered: %s)", s.Name, strings.Join(RegisteredStrategies(), ", "))
+	}
+	st, err := factory(s.Params)
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s: %w", s.Name, err)
+	}
+	return st, nil
+}
+
+// NewStrategy returns a new strategy from a spec in flag form.
+func NewStrategy(spec string) (Strategy, error) {
+	s, err := ParseStrategySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return s.New()
+}
+
+// NewStrategies returns new strategies from specs in flag form separated by
+// spaces (see ParseStrategySpecs).
+func NewStrategies(specs string) ([]Strategy, error) {
+	parsed, err := ParseStrategySpecs(specs)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Strategy, len(parsed))
+	for i, s := range parsed {
+		if out[i], err = s.New(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// UnmarshalJSON accepts a spec object or a string in flag form.
+func (s *StrategySpec) UnmarshalJSON(data []byte) error {
+	var flag string
+	if json.Unmarshal(data, &flag) == nil {
+		spec, err := ParseStrategySpec(flag)
+		*s = spec
+		return err
+	}
+	type plain StrategySpec
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// DefaultStrategies are the strategies the harness commands compare by
+// default.
+const DefaultStrategies = "random roundrobin p2c leastlatency swarmroute"
+
+// The built-in strategies.  Seeds only matter outside the simulator, which
+// reseeds strategies for every run.
+func init() {
+	Register("random", func(p Params) (Strategy, error) {
+		if err := p.Only("seed"); err != nil {
+			return nil, err
+		}
+		seed, err := p.Int("seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewRandomStrategy(seed), nil
+	})
+	Register("roundrobin", func(p Params) (Strategy, error) {
+		if err := p.Only(); err != nil {
+			return nil, err
+		}
+		return NewRoundRobinStrategy(), nil
+	})
+	Register("wrr", func(p Params) (Strategy, error) {
+		if err := p.Only("weights"); err != nil {
+			return nil, err
+		}
+		weights, err := weightsParam(p["weights"])
+		if err != nil {
+			return nil, err
+		}
+		return NewWeightedRoundRobinStrategy(weights), nil
+	})
+	Register("outlier", func(p Params) (Strategy, error) {
+		if err := p.Only("window", "threshold", "cooldown", "max_ejected"); err != nil {
+			return nil, err
+		}
+		window, err := p.Int("window", 20)
+		if err != nil {
+			return nil, err
+		}
+		threshold, err := p.Float("threshold", 0.5)
+		if err != nil {
+			return nil, err
+		}
+		cooldown, err := p.Int("cooldown", 200)
+		if err != nil {
+			return nil, err
+		}
+		maxEjected, err := p.Float("max_ejected", 0.5)
+		if err != nil {
+			return nil, err
+		}
+		return NewOutlierEjectionStrategy(int(window), threshold, int(cooldown), maxEjected), nil
+	})
+	Register("leastconn", func(p Params) (Strategy, error) {
+		if err := p.Only(); err != nil {
+			return nil, err
+		}
+		return NewLeastConnectionsStrategy(), nil
+	})
+	Register("chash", func(p Params) (Strategy, error) {
+		if err := p.Only("replicas", "epsilon"); err != nil {
+			return nil, err
+		}
+		replicas, err := p.Int("replicas", 100)
+		if err != nil {
+			return nil, err
+		}
+		epsilon, err := p.Float("epsilon", 0.25)
+		if err != nil {
+			return nil, err
+		}
+		return NewConsistentHashStrategy(int(replicas), epsilon), nil
+	})
+	Register("p2c", func(p Params) (Strategy, error) {
+		k, err := p.Int("k", 2)
+		if err != nil {
+			return nil, err
+		}
+		penalty, err := p.Float("penalty", 0)
+		if err != nil {
+			return nil, err
+		}
+		return ewmaParams(p, 2, func(seed int64, alpha float64) Strategy {
+			return NewPowerOfKChoicesStrategy(int(k), seed, alpha, penalty)
+		}, "k", "penalty")
+	})
+	Register("leastlatency", func(p Params) (Strategy, error) {
+		return ewmaParams(p, 3, func(seed int64, alpha float64) Strategy { return NewLeastLatencyStrategy(seed, alpha) })
+	})
+	Register("ewma", func(p Params) (Strategy, error) {
+		penalty, err := p.Float("penalty", 4)
+		if err != nil {
+			return nil, err
+		}
+		return ewmaParams(p, 4, func(seed int64, alpha float64) Strategy { return NewEWMAStrategy(seed, alpha, penalty) }, "penalty")
+	})
+	Register("peakewma", func(p Params) (Strategy, error) {
+		penalty, err := p.Float("penalty", 2)
+		if err != nil {
+			return nil, err
+		}
+		return ewmaParams(p, 5, func(seed int64, alpha float64) Strategy { return NewPeakEWMAStrategy(seed, alpha, penalty) }, "penalty")
+	})
+	Register("thompson", func(p Params) (Strategy, error) {
+		if err := p.Only("seed", "latency", "discount"); err != nil {
+			return nil, err
+		}
+		seed, err := p.Int("seed", 6)
+		if err != nil {
+			return nil, err
+		}
+		latency, err := p.Bool("latency", false)
+		if err != nil {
+			return nil, err
+		}
+		discount, err := p.Float("discount", 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewThompsonStrategy(seed, latency, discount), nil
+	})
+	Register("ucb", func(p Params) (Strategy, error) {
+		if err := p.Only("window", "c", "latency_weight"); err != nil {
+			return nil, err
+		}
+		window, err := p.Int("window", 200)
+		if err != nil {
+			return nil, err
+		}
+		c, err := p.Float("c", 1)
+		if err != nil {
+			return nil, err
+		}
+		w, err := p.Float("latency_weight", 0.5)
+		if err != nil {
+			return nil, err
+		}
+		return NewUCBStrategy(int(window), c, w), nil
+	})
+	Register("egreedy", func(p Params) (Strategy, error) {
+		epsilon, err := p.Float("epsilon", 0.1)
+		if err != nil {
+			return nil, err
+		}
+		halfLife, err := p.Int("half_life", 1000)
+		if err != nil {
+			return nil, err
+		}
+		return ewmaParams(p, 7, func(seed int64, alpha float64) Strategy {
+			return NewEpsilonGreedyStrategy(seed, alpha, epsilon, int(halfLife))
+		}, "epsilon", "half_life")
+	})
+	Register("softmax", func(p Params) (Strategy, error) {
+		temperature, err := p.Float("temperature", 0.01)
+		if err != nil {
+			return nil, err
+		}
+		return ewmaParams(p, 8, func(seed int64, alpha float64) Strategy { return NewSoftmaxStrategy(seed, alpha, temperature) }, "temperature")
+	})
+	Register("c3", func(p Params) (Strategy, error) {
+		if err := p.Only("alpha", "clients"); err != nil {
+			return nil, err
+		}
+		alpha, err := p.Float("alpha", 0.2)
+		if err != nil {
+			return nil, err
+		}
+		clients, err := p.Int("clients", 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewC3Strategy(alpha, int(clients)), nil
+	})
+	Register("exec", func(p Params) (Strategy, error) {
+		if err := p.Only("cmd", "args"); err != nil {
+			return nil, err
+		}
+		cmd, err := p.String("cmd", "")
+		if err != nil {
+			return nil, err
+		}
+		if cmd == "" {
+			return nil, fmt.Errorf("parameter cmd is required")
+		}
+		// args are separated by | since the flag form splits at commas.
+		args, err := p.String("args", "")
+		if err != nil {
+			return nil, err
+		}
+		var argv []string
+		if args != "" {
+			argv = strings.Split(args, "|")
+		}
+		return NewProcessStrategy(cmd, argv...)
+	})
+	Register("swarmroute", func(p Params) (Strategy, error) {
+		// Besides these, every swarmroute.Config field can be set by its
+		// JSON name, e.g. base_weight=0.05.
+		names := []string{"preset", "config", "autotune", "label"}
+		fields := configFields()
+		if err := p.Only(append(names, fields...)...); err != nil {
+			return nil, err
+		}
+		preset, err := p.String("preset", "default")
+		if err != nil {
+			return nil, err
+		}
+		cfg, ok := lib.Presets()[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q", preset)
+		}
+		label := ""
+		if preset != "default" {
+			label = preset
+		}
+		// config is a swarmroute.LoadConfig file, e.g. one written by a
+		// Tune, and replaces the preset.
+		path, err := p.String("config", "")
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			s, err := lib.LoadConfig(path)
+			if err != nil {
+				return nil, err
+			}
+			cfg = s.Config
+		}
+		opts := []AdapterOption{WithConfig(cfg)}
+		for _, name := range fields {
+			if _, ok := p[name]; !ok {
+				continue
+			}
+			v, err := p.Float(name, 0)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, WithParam(name, v))
+		}
+		autoTune, err := p.Bool("autotune", false)
+		if err != nil {
+			return nil, err
+		}
+		if autoTune {
+			opts = append(opts, WithAutoTune(lib.AutoTunePolicy{}))
+			if label != "" {
+				label += "-autotune"
+			}
+		}
+		if label, err = p.String("label", label); err != nil {
+			return nil, err
+		}
+		return NewSwarmRouteAdapterWithOptions(append(opts, WithLabel(label))...)
+	})
+}
+
+// configFields returns the JSON names of the swarmroute.Config fields.
+func configFields() []string {
+	t := reflect.TypeFor[lib.Config]()
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+	}
+	return names
+}
+
+// ewmaParams builds a strategy with a seed, an EWMA alpha and a slow
+// threshold ("slow", in seconds; see SetSlowThresholdSec).  extra names the
+// other parameters the strategy takes.
+func ewmaParams(p Params, seed int64, build func(seed int64, alpha float64) Strategy, extra ...string) (Strategy, error) {
+	if err := p.Only(append([]string{"seed", "alpha", "slow"}, extra...)...); err != nil {
+		return nil, err
+	}
+	seed, err := p.Int("seed", seed)
+	if err != nil {
+		return nil, err
+	}
+	alpha, err := p.Float("alpha", 0.2)
+	if err != nil {
+		return nil, err
+	}
+	slow, err := p.Float("slow", 0)
+	if err != nil {
+		return nil, err
+	}
+	s := build(seed, alpha)
+	s.(interface{ SetSlowThresholdSec(float64) }).SetSlowThresholdSec(slow)
+	return s, nil
+}
+
+// weightsParam parses endpoint weights: a JSON object of address to weight,
+// or "addr=weight|addr=weight" in flag form.
+func weightsParam(v any) (map[string]int, error) {
+	weights := make(map[string]int)
+	switch v := v.(type) {
+	case nil:
+	case map[string]any:
+		for addr, w := range v {
+			n, err := Params{addr: w}.Int(addr, 0)
+			if err != nil {
+				return nil, fmt.Errorf("weights: %w", err)
+			}
+			weights[addr] = int(n)
+		}
+	case string:
+		for _, kv := range strings.Split(v, "|") {
+			i := strings.LastIndex(kv, "=")
+			if i <= 0 {
+				return nil, fmt.Errorf("weights: want addr=weight, got %q", kv)
+			}
+			n, err := strconv.Atoi(kv[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("weights: %w", err)
+			}
+			weights[kv[:i]] = n
+		}
+	default:
+		return nil, fmt.Errorf("weights: want an object or addr=weight|..., got %v", v)
+	}
+	return weights, nil
+}
@@ -0,0 +1,343 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSONSchemaVersion is the version of the JSON documents written by the
+// MarshalJSON methods of Report, Scenario, Results and
+// MultiSeedAggregation.  Fields may be added within a version; it changes
+// when a field is renamed, removed or changes meaning.
+const JSONSchemaVersion = 1
+
+// Report is a self-describing experiment output: the scenario, the seeds it
+// ran with, and its results or multi-seed aggregations.
+type Report struct {
+	Scenario     Scenario
+	Seeds        []int64
+	Results      []Results
+	Aggregations []MultiSeedAggregation
+	// Scores are objective scores by strategy, e.g. from
+	// Objective.ScoreAggregation.
+	Scores map[string]float64
+}
+
+// MarshalJSON encodes the report with its schema version.
+func (r Report) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		SchemaVersion int                    `json:"schema_version"`
+		Scenario      Scenario               `json:"scenario"`
+		Seeds         []int64                `json:"seeds"`
+		Results       []Results              `json:"results,omitempty"`
+		Aggregations  []MultiSeedAggregation `json:"aggregations,omitempty"`
+		Scores        map[string]float64     `json:"scores,omitempty"`
+	}{JSONSchemaVersion, r.Scenario, r.Seeds, r.Results, r.Aggregations, r.Scores})
+}
+
+type endpointJSON struct {
+	Addr           string  `json:"addr"`
+	MeanLatencySec float64 `json:"mean_latency_sec"`
+	JitterSec      float64 `json:"jitter_sec,omitempty"`
+	ErrorRate      float64 `json:"error_rate"`
+	Distribution   string  `json:"distribution,omitempty"`
+	Down           bool    `json:"down,omitempty"`
+	Blackhole      bool    `json:"blackhole,omitempty"`
+	Capacity       int     `json:"capacity,omitempty"`
+	JoinStep       int     `json:"join_step,omitempty"`
+}
+
+type eventJSON struct {
+	Step           int      `json:"step"`
+	Endpoint       string   `json:"endpoint"`
+	Class          string   `json:"class,omitempty"`
+	MeanLatencySec *float64 `json:"mean_latency_sec,omitempty"`
+	JitterSec      *float64 `json:"jitter_sec,omitempty"`
+	ErrorRate      *float64 `json:"error_rate,omitempty"`
+	Down           *bool    `json:"down,omitempty"`
+	Blackhole      *bool    `json:"blackhole,omitempty"`
+}
+
+type classJSON struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// MarshalJSON describes the scenario: its settings, endpoints and every
+// event, including those added by Generators.  Hooks, checkpointing and
+// the step and decision logs are left out, and a replayed Trace is only
+// counted.
+func (sc Scenario) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Name          string         `json:"name,omitempty"`
+		Service       string         `json:"service"`
+		TotalRequests int            `json:"total_requests"`
+		Seed          int64          `json:"seed"`
+		WarmupSteps   int            `json:"warmup_steps,omitempty"`
+		TimeoutSec    float64        `json:"timeout_sec,omitempty"`
+		MaxRetries    int            `json:"max_retries,omitempty"`
+		BackoffSec    float64        `json:"backoff_sec,omitempty"`
+		RPS           float64        `json:"rps,omitempty"`
+		Poisson       bool           `json:"poisson,omitempty"`
+		Concurrency   int            `json:"concurrency,omitempty"`
+		Phases        []int          `json:"phases"`
+		Classes       []classJSON    `json:"classes,omitempty"`
+		RouteByClass  bool           `json:"route_by_class,omitempty"`
+		Keys          *KeySpace      `json:"keys,omitempty"`
+		TraceRecords  int            `json:"trace_records,omitempty"`
+		Endpoints     []endpointJSON `json:"endpoints"`
+		Events        []eventJSON    `json:"events"`
+	}{
+		Name: sc.Name, Service: sc.Service, TotalRequests: sc.TotalRequests, Seed: sc.Seed,
+		WarmupSteps: sc.WarmupSteps, TimeoutSec: sc.TimeoutSec, Phases: sc.phaseStarts(), RouteByClass: sc.RouteByClass,
+		Keys: sc.Keys, Endpoints: []endpointJSON{}, Events: []eventJSON{},
+	}
+	if sc.Trace != nil {
+		v.TraceRecords = sc.Trace.Len()
+	}
+	if sc.Retry != nil {
+		v.MaxRetries, v.BackoffSec = sc.Retry.MaxRetries, sc.Retry.BackoffSec
+	}
+	if sc.Load != nil {
+		v.RPS, v.Poisson, v.Concurrency = sc.Load.RPS, sc.Load.Poisson, sc.Load.Concurrency
+	}
+	for _, c := range sc.Classes {
+		v.Classes = append(v.Classes, classJSON{c.Name, c.Weight})
+	}
+	for _, e := range sc.Endpoints {
+		ej := endpointJSON{Addr: e.Addr, MeanLatencySec: e.MeanLatencySec, JitterSec: e.JitterSec, ErrorRate: e.ErrorRate,
+			Down: e.Down, Blackhole: e.Blackhole, Capacity: e.Capacity, JoinStep: e.JoinStep}
+		if e.Distribution != nil {
+			ej.Distribution = fmt.Sprintf("%s%+v", reflect.TypeOf(e.Distribution).Name(), e.Distribution)
+		}
+		v.Endpoints = append(v.Endpoints, ej)
+	}
+	for _, ev := range sc.expandEvents() {
+		v.Events = append(v.Events, eventJSON{Step: ev.Step, Endpoint: ev.Endpoint, Class: ev.Class,
+			MeanLatencySec: ev.NewMeanLatency, JitterSec: ev.NewJitterSec, ErrorRate: ev.NewErrorRate, Down: ev.NewDown, Blackhole: ev.NewBlackhole})
+	}
+	return json.Marshal(v)
+}
+
+type percentileJSON struct {
+	P  float64 `json:"p"`
+	MS float64 `json:"ms"`
+}
+
+type phaseJSON struct {
+	Start     int     `json:"start"`
+	End       int     `json:"end"`
+	Total     int     `json:"total"`
+	Success   int     `json:"success"`
+	MeanLatMS float64 `json:"mean_ms"`
+	P95LatMS  float64 `json:"p95_ms"`
+	Fairness  float64 `json:"fairness"`
+}
+
+type classMetricsJSON struct {
+	Name      string  `json:"name"`
+	Total     int     `json:"total"`
+	Success   int     `json:"success"`
+	MeanLatMS float64 `json:"mean_ms"`
+	P95LatMS  float64 `json:"p95_ms"`
+}
+
+type windowJSON struct {
+	Endpoint string `json:"endpoint"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+type reactionJSON struct {
+	Endpoint       string `json:"endpoint"`
+	Start          int    `json:"start"`
+	End            int    `json:"end"`
+	ShedSteps      int    `json:"shed_steps"`
+	ReincludeSteps int    `json:"reinclude_steps"`
+	Recovered      bool   `json:"recovered"`
+}
+
+type histogramJSON struct {
+	BoundsMS []float64 `json:"bounds_ms"`
+	Counts   []int     `json:"counts"`
+}
+
+type shareSampleJSON struct {
+	Step   int                `json:"step"`
+	Shares map[string]float64 `json:"shares"`
+}
+
+type latencySampleJSON struct {
+	Step int     `json:"step"`
+	MS   float64 `json:"ms"`
+}
+
+type inFlightJSON struct {
+	Step     int     `json:"step"`
+	TimeSec  float64 `json:"time_sec"`
+	InFlight int     `json:"in_flight"`
+}
+
+func histogramToJSON(h *Histogram) *histogramJSON {
+	if h == nil {
+		return nil
+	}
+	return &histogramJSON{h.BoundsMS, h.Counts}
+}
+
+// MarshalJSON encodes the results with stable snake_case field names.
+func (r Results) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Strategy           string                    `json:"strategy"`
+		Scenario           string                    `json:"scenario,omitempty"`
+		Seed               int64                     `json:"seed"`
+		Total              int                       `json:"total"`
+		Success            int                       `json:"success"`
+		Failure            int                       `json:"failure"`
+		MeanLatMS          float64                   `json:"mean_ms"`
+		P95LatMS           float64                   `json:"p95_ms"`
+		Percentiles        []percentileJSON          `json:"percentiles"`
+		Selection          map[string]int            `json:"selection"`
+		Attempts           int                       `json:"attempts"`
+		RetryAmplification float64                   `json:"retry_amplification"`
+		Timeouts           int                       `json:"timeouts"`
+		TimedOut           int                       `json:"timed_out"`
+		Dropped            int                       `json:"dropped"`
+		DurationSec        float64                   `json:"duration_sec,omitempty"`
+		GoodputRPS         float64                   `json:"goodput_rps,omitempty"`
+		MaxInFlight        int                       `json:"max_in_flight,omitempty"`
+		MeanQueueWaitMS    float64                   `json:"mean_queue_wait_ms,omitempty"`
+		OverloadShare      float64                   `json:"overload_share"`
+		KeyAffinity        float64                   `json:"key_affinity,omitempty"`
+		BadWindowShare     float64                   `json:"bad_window_share"`
+		PeakShare          float64                   `json:"peak_share"`
+		ShareSwing         float64                   `json:"share_swing"`
+		Oscillation        float64                   `json:"oscillation"`
+		MeanShedSteps      float64                   `json:"mean_shed_steps"`
+		MeanReincludeSteps float64                   `json:"mean_reinclude_steps"`
+		Phases             []phaseJSON               `json:"phases"`
+		Classes            []classMetricsJSON        `json:"classes,omitempty"`
+		DegradedWindows    []windowJSON              `json:"degraded_windows"`
+		Reactions          []reactionJSON            `json:"reactions"`
+		Histogram          *histogramJSON            `json:"histogram,omitempty"`
+		EndpointHistograms map[string]*histogramJSON `json:"endpoint_histograms,omitempty"`
+		ShareSeries        []shareSampleJSON         `json:"share_series,omitempty"`
+		InFlightSeries     []inFlightJSON            `json:"in_flight_series,omitempty"`
+		P95Series          []latencySampleJSON       `json:"p95_series,omitempty"`
+	}{
+		Strategy: r.Strategy, Scenario: r.Scenario, Seed: r.Seed,
+		Total: r.Total, Success: r.Success, Failure: r.Failure, MeanLatMS: r.MeanLatMS, P95LatMS: r.P95LatMS,
+		Percentiles: []percentileJSON{}, Selection: r.Selection,
+		Attempts: r.Attempts, RetryAmplification: r.RetryAmplification, Timeouts: r.Timeouts, TimedOut: r.TimedOut, Dropped: r.Dropped,
+		DurationSec: r.DurationSec, GoodputRPS: r.GoodputRPS, MaxInFlight: r.MaxInFlight, MeanQueueWaitMS: r.MeanQueueWaitMS,
+		OverloadShare: r.OverloadShare, KeyAffinity: r.KeyAffinity, BadWindowShare: r.BadWindowDegradedShare,
+		PeakShare: r.PeakShare, ShareSwing: r.ShareSwing, Oscillation: r.Oscillation,
+		MeanShedSteps: r.MeanShedSteps, MeanReincludeSteps: r.MeanReincludeSteps,
+		Phases: []phaseJSON{}, DegradedWindows: []windowJSON{}, Reactions: []reactionJSON{},
+		Histogram: histogramToJSON(r.Histogram),
+	}
+	if v.Selection == nil {
+		v.Selection = map[string]int{}
+	}
+	for _, lp := range r.Percentiles {
+		v.Percentiles = append(v.Percentiles, percentileJSON{lp.P, lp.LatMS})
+	}
+	for _, p := range r.Phases {
+		v.Phases = append(v.Phases, phaseJSON{p.Start, p.End, p.Total, p.Success, p.MeanLatMS, p.P95LatMS, p.Fairness})
+	}
+	for _, c := range r.Classes {
+		v.Classes = append(v.Classes, classMetricsJSON{c.Name, c.Total, c.Success, c.MeanLatMS, c.P95LatMS})
+	}
+	for _, w := range r.DegradedWindows {
+		v.DegradedWindows = append(v.DegradedWindows, windowJSON{w.Endpoint, w.Start, w.End})
+	}
+	for _, rc := range r.Reactions {
+		v.Reactions = append(v.Reactions, reactionJSON{rc.Endpoint, rc.Start, rc.End, rc.ShedSteps, rc.ReincludeSteps, rc.Recovered})
+	}
+	if len(r.EndpointHistograms) > 0 {
+		v.EndpointHistograms = make(map[string]*histogramJSON, len(r.EndpointHistograms))
+		for addr, h := range r.EndpointHistograms {
+			v.EndpointHistograms[addr] = histogramToJSON(h)
+		}
+	}
+	for _, s := range r.ShareSeries {
+		v.ShareSeries = append(v.ShareSeries, shareSampleJSON{s.Step, s.Shares})
+	}
+	for _, s := range r.InFlightSeries {
+		v.InFlightSeries = append(v.InFlightSeries, inFlightJSON{s.Step, s.TimeSec, s.InFlight})
+	}
+	for _, s := range r.P95Series {
+		v.P95Series = append(v.P95Series, latencySampleJSON{s.Step, s.LatMS})
+	}
+	return json.Marshal(v)
+}
+
+type seedStatsJSON struct {
+	Values []float64 `json:"values"`
+	Mean   float64   `json:"mean"`
+	Std    float64   `json:"std"`
+	CILow  float64   `json:"ci95_low"`
+	CIHigh float64   `json:"ci95_high"`
+	Median float64   `json:"median"`
+	Min    float64   `json:"min"`
+	Max    float64   `json:"max"`
+}
+
+func seedStatsToJSON(values []float64, st SeedStats) seedStatsJSON {
+	if values == nil {
+		values = []float64{}
+	}
+	return seedStatsJSON{values, st.Mean, st.Std, st.CILow, st.CIHigh, st.Median, st.Min, st.Max}
+}
+
+// MarshalJSON encodes the aggregation: the per-seed runs and, per metric,
+// the per-seed values (in the order of seeds) and their statistics.
+func (a MultiSeedAggregation) MarshalJSON() ([]byte, error) {
+	type metrics struct {
+		SuccessPct     seedStatsJSON `json:"success_pct"`
+		P95ms          seedStatsJSON `json:"p95_ms"`
+		BadSharePct    seedStatsJSON `json:"bad_window_share_pct"`
+		ShedSteps      seedStatsJSON `json:"mean_shed_steps"`
+		ReincludeSteps seedStatsJSON `json:"mean_reinclude_steps"`
+	}
+	v := struct {
+		Strategy string    `json:"strategy"`
+		Scenario string    `json:"scenario,omitempty"`
+		Seeds    []int64   `json:"seeds"`
+		Metrics  metrics   `json:"metrics"`
+		Runs     []Results `json:"runs"`
+	}{
+		Strategy: a.Strategy, Scenario: a.Scenario, Seeds: a.Seeds, Runs: a.Runs,
+		Metrics: metrics{
+			seedStatsToJSON(a.SuccessPct, a.SuccessStats),
+			seedStatsToJSON(a.P95ms, a.P95Stats),
+			seedStatsToJSON(a.BadShare, a.BadShareStats),
+			seedStatsToJSON(a.ShedSteps, a.ShedStats),
+			seedStatsToJSON(a.ReincludeSteps, a.ReincludeStats),
+		},
+	}
+	if v.Seeds == nil {
+		v.Seeds = []int64{}
+	}
+	if v.Runs == nil {
+		v.Runs = []Results{}
+	}
+	return json.Marshal(v)
+}
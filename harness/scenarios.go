@@ -0,0 +1,53 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import "fmt"
+
+// RetryStormScenario returns a canned metastable-failure scenario: a fleet
+// of four endpoints (20ms, four concurrent requests each at full speed, so
+// a nominal 800 RPS) under open-loop Poisson load of 300 RPS, with clients
+// that time out after 100ms and retry up to three times without backoff.
+//
+// From step 6000 to 12000 one endpoint slows down fourfold.  Its timeouts
+// trigger retries that push the remaining endpoints past capacity, whose
+// own timeouts trigger more retries.  A strategy dampens the storm if
+// success recovers in the last phase, after the trigger is gone, and
+// amplifies it if the overload outlives the trigger.  Phases are split at
+// the trigger's start and end.
+func RetryStormScenario(seed int64) Scenario {
+	eps := make([]EndpointSpec, 4)
+	for i := range eps {
+		eps[i] = EndpointSpec{Addr: fmt.Sprintf("http://s%d:8080", i+1), MeanLatencySec: 0.020, JitterSec: 0.006, ErrorRate: 0.001, Capacity: 4}
+	}
+	slow, norm := 0.080, 0.020
+	return Scenario{
+		Name:      "retry-storm",
+		Service:   "api",
+		Endpoints: eps,
+		Events: []EnvironmentEvent{
+			{Step: 6000, Endpoint: eps[0].Addr, NewMeanLatency: &slow},
+			{Step: 12000, Endpoint: eps[0].Addr, NewMeanLatency: &norm},
+		},
+		Phases:        []int{6000, 12000},
+		TotalRequests: 30000,
+		Seed:          seed,
+		TimeoutSec:    0.100,
+		Retry:         &RetryPolicy{MaxRetries: 3},
+		Load:          &Load{RPS: 300, Poisson: true},
+	}
+}
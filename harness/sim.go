@@ -17,10 +17,17 @@
 package harness
 
 import (
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
 	"math"
 	"math/rand"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 // EndpointSpec defines the initial environment for an endpoint.
@@ -31,6 +38,51 @@ type EndpointSpec struct {
 	// If zero, a default jitter of 30% of MeanLatencySec is used.
 	JitterSec float64
 	ErrorRate float64 // 0.0..1.0
+	// Distribution is the latency shape (Gaussian, LogNormal, Pareto,
+	// Bimodal); nil means Gaussian.
+	Distribution LatencyDistribution
+	// Down makes the endpoint refuse connections: every request fails
+	// immediately with zero latency, regardless of ErrorRate.
+	Down bool
+	// Blackhole makes the endpoint stop responding: every request hangs
+	// until the scenario's timeout and then fails.
+	Blackhole bool
+	// Capacity is how many concurrent requests the endpoint serves at full
+	// speed.  Beyond it, requests share the endpoint (processor sharing):
+	// a request arriving with n requests in flight is slowed by
+	// (n+1)/Capacity.  Zero means unlimited.  Only open-loop runs have
+	// concurrent requests (see Scenario.Load).
+	Capacity int
+	// JoinStep is the step at which the endpoint is added to the service,
+	// modeling a new instance coming up mid-run; 0 means present from the
+	// start.  Strategies learn of it through another AddService call.
+	JoinStep int
+	// WarmUp models a cold cache (see WarmUp); nil means always warm.
+	WarmUp *WarmUp
+	// Classes overrides latency or error rate per request class (see
+	// Scenario.Classes), keyed by class name.
+	Classes map[string]ClassOverride
+}
+
+// WarmUp slows a new endpoint down until its caches are warm: its latency
+// starts at Factor times the mean and decays linearly to the mean over the
+// first Requests attempts it serves.
+type WarmUp struct {
+	// Factor is the initial latency multiplier; 3 if zero.
+	Factor   float64
+	Requests int
+}
+
+// multiplier returns the latency multiplier after served attempts.
+func (w *WarmUp) multiplier(served int) float64 {
+	if w == nil || served >= w.Requests {
+		return 1
+	}
+	f := w.Factor
+	if f == 0 {
+		f = 3
+	}
+	return f + (1-f)*float64(served)/float64(w.Requests)
 }
 
 // EnvironmentEvent changes an endpoint's environment at a specific request index (step).
@@ -42,220 +94,836 @@ type EnvironmentEvent struct {
 	// Optional: update jitter (stddev) for the endpoint at this step.
 	NewJitterSec *float64
 	NewErrorRate *float64
+	// Optional: take the endpoint down (connection refused) or back up.
+	NewDown *bool
+	// Optional: blackhole the endpoint (requests time out) or restore it.
+	NewBlackhole *bool
+	// Optional: apply NewMeanLatency and NewErrorRate to this request
+	// class only, as an EndpointSpec.Classes override.
+	Class string
 }
 
 // Scenario is the full simulation definition.
 type Scenario struct {
+	// Name labels the scenario in Results and exports.
+	Name          string
 	Service       string
 	Endpoints     []EndpointSpec
 	Events        []EnvironmentEvent
 	TotalRequests int
-	Seed          int64
+	// Seed keys the environment's random streams.  Every endpoint draws
+	// its outcomes from its own stream, so for a given seed the n-th
+	// request an endpoint serves has the same outcome whichever strategy
+	// sent it there.
+	Seed int64
+	// Generators add declaratively described events (Ramp, Sine, Repeat,
+	// Outage, Partition) on top of Events.
+	Generators []EventGenerator
+	// TimeoutSec is the client timeout: requests whose latency would exceed
+	// it fail after TimeoutSec.  If zero, only blackholed endpoints time
+	// out, after defaultTimeoutSec.
+	TimeoutSec float64
+	// Retry makes the simulated client retry failed requests.  If nil,
+	// every request makes a single attempt.
+	Retry *RetryPolicy
+	// Load switches to open-loop traffic (see Load).  If nil, or its RPS
+	// is not positive, requests run closed-loop: each one completes and is
+	// reported before the next starts.
+	Load *Load
+	// Phases are the start steps of the reporting phases in Results; each
+	// phase lasts until the next one starts, and a phase starting at 0 (or
+	// at WarmupSteps) is implied.  If nil, phases start at the distinct
+	// steps of Events and the endpoints' JoinSteps (generated events are
+	// not considered).
+	Phases []int
+	// Classes is the request mix; each request draws a class by weight.
+	// If empty, all requests are alike.
+	Classes []RequestClass
+	// Hooks are optional instrumentation callbacks (see Hooks).
+	Hooks *Hooks
+	// StepLog, if set, receives the run as NDJSON, one object per line for
+	// every request and environment change, for analysis outside the
+	// harness.  Objects of "type": "env" give an endpoint's "env" state
+	// (mean_latency_sec, jitter_sec, error_rate, down, blackhole,
+	// degraded) at the start of the run and after events change it.
+	// Objects of "type": "request" give the step, client, strategy,
+	// class, the last endpoint attempted (empty if none could be picked)
+	// with its env state and in_flight count, the number of attempts,
+	// the end-to-end latency_ms (including queueing wait_ms) and success.
+	// Records are buffered and flushed when the run ends; the caller
+	// closes the writer and checks it for write errors.
+	StepLog io.Writer
+	// Decisions, if set, records every pick with the score the strategy
+	// gave each candidate if it is an Explainer.  The caller flushes it.
+	Decisions *DecisionWriter
+	// Checkpoints makes the run save its state periodically (see
+	// CheckpointPolicy).
+	Checkpoints *CheckpointPolicy
+	// Percentiles lists the latency percentiles (0..100, 100 being the
+	// maximum) reported in Results.Percentiles; nil means
+	// DefaultPercentiles.
+	Percentiles []float64
+	// ShedShare is the selection share below which a degraded endpoint
+	// counts as shed for Results.Reactions; zero means DefaultShedShare.
+	ShedShare float64
+	// ReincludeFraction is the fraction of the fair share (one over the
+	// number of endpoints) a recovered endpoint must reach again to count
+	// as re-included; zero means DefaultReincludeFraction.
+	ReincludeFraction float64
+	// HistogramBucketsMS are the upper bounds, in milliseconds, of the
+	// latency histogram buckets; nil means DefaultHistogramBucketsMS.
+	HistogramBucketsMS []float64
+	// WarmupSteps excludes the first requests from all metrics: they still
+	// drive learning, but convergence noise doesn't pollute steady-state
+	// comparisons.  Total counts the measured requests only; DurationSec
+	// and DegradedWindows still cover the whole run.
+	WarmupSteps int
+	// RouteByClass registers every class as its own service,
+	// "service/class", so strategies learn each route separately.
+	// Otherwise strategies see a single service and can't tell classes
+	// apart.
+	RouteByClass bool
+	// Keys, if set, gives every request a key: KeyedStrategy
+	// implementations route by it and Results.KeyAffinity measures how
+	// sticky the routing is.
+	Keys *KeySpace
+	// Trace, if set, replays recorded outcomes instead of drawing them
+	// from the endpoint specs; see Trace.Scenario.
+	Trace *Trace
 }
 
 // Results are aggregated per strategy after a run.
 type Results struct {
 	Strategy  string
+	Scenario  string // Scenario.Name
+	Seed      int64  // Scenario.Seed of the run
 	Total     int
 	Success   int
 	Failure   int
 	MeanLatMS float64
 	P95LatMS  float64
 	Selection map[string]int
-	// Phase-aware metrics: [0]=0..1999, [1]=2000..5999, [2]=6000..
-	Phases [3]PhaseMetrics
-	// Heuristically detected degraded endpoint at step 2000 (if any)
-	DegradedEndpoint string
-	// Share of selections to the degraded endpoint during bad window [2000,6000)
+	// Percentiles holds the latency percentiles requested by
+	// Scenario.Percentiles, in that order.
+	Percentiles []LatencyPercentile
+	// Histogram buckets the end-to-end latency of successful requests and
+	// EndpointHistograms the latency of successful attempts per endpoint,
+	// using Scenario.HistogramBucketsMS.  See WriteHistogramsCSV.
+	Histogram          *Histogram
+	EndpointHistograms map[string]*Histogram
+	// Timeouts counts attempts that failed by hitting the timeout,
+	// TimedOut the requests whose last attempt did, and Dropped the
+	// requests no attempt could be made for because the strategy had no
+	// endpoint to pick.
+	Timeouts int
+	TimedOut int
+	Dropped  int
+	// Attempts counts every attempt including retries; Selection counts
+	// attempts too.  RetryAmplification is Attempts per request.
+	Attempts           int
+	RetryAmplification float64
+	// Open-loop metrics (zero for closed-loop runs): simulated duration
+	// until the last completion, peak requests in flight and mean
+	// client-side queueing delay.  Latency metrics include the queueing
+	// delay.
+	DurationSec     float64
+	MaxInFlight     int
+	MeanQueueWaitMS float64
+	// GoodputRPS is the rate of successful requests over the measured
+	// part of the run, and InFlightSeries samples the requests in flight
+	// (of the whole run, in a population) every 10 steps.
+	GoodputRPS     float64
+	InFlightSeries []InFlightSample
+	// OverloadShare is the share of attempts sent to an endpoint that was
+	// already at its Capacity, the cost of shifting traffic faster than
+	// endpoints can absorb it.  Zero without capacity limits.
+	OverloadShare float64
+	// Phase-aware metrics, one per phase of the scenario (see
+	// Scenario.Phases).
+	Phases []PhaseMetrics
+	// Classes holds per-class metrics in the order of Scenario.Classes.
+	Classes []ClassMetrics
+	// DegradedWindows lists when each endpoint was degraded, ordered by
+	// start step.  An endpoint counts as degraded while it is down or
+	// blackholed, or its
+	// mean latency is at least 1.5x, or its error rate at least 5 points
+	// above, its initial spec.
+	DegradedWindows []DegradedWindow
+	// Share of the selections made while any endpoint was degraded that
+	// went to a currently degraded endpoint
 	BadWindowDegradedShare float64
+	// Herding metrics over windows of 100 steps: PeakShare is the mean
+	// share of the busiest endpoint per window (1 if all traffic always
+	// lands on one endpoint), ShareSwing the mean total variation distance
+	// between the endpoint shares of consecutive windows (0 for a stable
+	// split, 1 if traffic flips wholesale from one window to the next).
+	PeakShare, ShareSwing float64
+	// ShareSeries is the selection share of every endpoint over the
+	// trailing 100 steps, sampled every 10 steps; see
+	// WriteShareSeriesCSV.  Oscillation is the standard deviation of each
+	// endpoint's share within a phase, skipping its first 100 steps so the
+	// reaction to the phase's event doesn't count, averaged over endpoints
+	// and phases: near 0 for a steady split, large when traffic
+	// ping-pongs between endpoints.
+	ShareSeries []ShareSample
+	// KeyAffinity is the share of requests whose key (Scenario.Keys) was
+	// served by the same endpoint as that key's previous request, e.g. a
+	// warm cache hit; zero without keys.  The first request of every key
+	// isn't counted.
+	KeyAffinity float64
+	Oscillation float64
+	// P95Series is the p95 latency of the requests that succeeded in the
+	// trailing 100 steps, sampled every 10 steps.
+	P95Series []LatencySample
+	// Reactions measures, per degraded window, how fast the strategy shed
+	// the degraded endpoint and took it back after it recovered.
+	// MeanShedSteps averages their ShedSteps, counting windows never shed
+	// at their full length; MeanReincludeSteps averages the ReincludeSteps
+	// of the windows that recovered, counting endpoints never re-included
+	// at the steps left until they degraded again or the run ended.
+	Reactions          []Reaction
+	MeanShedSteps      float64
+	MeanReincludeSteps float64
+}
+
+// InFlightSample is the number of requests in flight when the request of
+// step Step-1 started, at TimeSec on the simulated clock.
+type InFlightSample struct {
+	Step     int
+	TimeSec  float64
+	InFlight int
+}
+
+// LatencySample is a latency, in milliseconds, over the 100 steps before
+// Step.
+type LatencySample struct {
+	Step  int
+	LatMS float64
+}
+
+// ShareSample is the selection share of every selected endpoint over the
+// 100 steps before Step.
+type ShareSample struct {
+	Step   int
+	Shares map[string]float64
+}
+
+// Reaction measures a strategy's reaction to the degraded window of
+// Endpoint over steps [Start, End).
+type Reaction struct {
+	Endpoint   string
+	Start, End int
+	// ShedSteps is how many steps after Start the endpoint's share of the
+	// selections over the trailing 100 steps (none before Start) first
+	// fell below Scenario.ShedShare, checked every 10 steps; -1 if it
+	// never did before End.
+	ShedSteps int
+	// ReincludeSteps is how many steps after End the endpoint's trailing
+	// share (none before End) first reached Scenario.ReincludeFraction of
+	// the fair share again, checked the same way; -1 if it never did
+	// before the endpoint degraded again or the run ended.  Recovered is
+	// false, and ReincludeSteps -1, if the window lasted until the end of
+	// the run.
+	ReincludeSteps int
+	Recovered      bool
+}
+
+// DegradedWindow is a span of steps [Start, End) during which Endpoint was
+// degraded.
+type DegradedWindow struct {
+	Endpoint   string
+	Start, End int
+}
+
+// defaultTimeoutSec is how long requests to a blackholed endpoint hang when
+// the scenario sets no TimeoutSec.
+const defaultTimeoutSec = 1.0
+
+// failurePenaltySec is added to the latency reported for a failed request
+// so latency-only strategies learn from failures too.
+const failurePenaltySec = 0.250
+
+// Degradation thresholds relative to an endpoint's initial spec.
+const (
+	degradeLatencyFactor = 1.5
+	degradeErrorDelta    = 0.05
+)
+
+// degraded reports whether cur is markedly worse than initial.
+func degraded(initial, cur EndpointSpec) bool {
+	if (cur.Down && !initial.Down) || (cur.Blackhole && !initial.Blackhole) {
+		return true
+	}
+	if cur.ErrorRate >= initial.ErrorRate+degradeErrorDelta {
+		return true
+	}
+	if initial.MeanLatencySec <= 0 {
+		return cur.MeanLatencySec > 0
+	}
+	return cur.MeanLatencySec >= degradeLatencyFactor*initial.MeanLatencySec
+}
+
+// RetryPolicy describes how the simulated client retries failed attempts.
+// Retries avoid the endpoints the request already failed on if the strategy
+// implements ExcludingStrategy.  A retry is issued right after the failed
+// attempt in simulation order, so in open-loop runs the strategy has not
+// yet received the failure report when it picks the retry's endpoint.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// BackoffSec is the delay before each retry.  It adds to the
+	// request's latency and, in open-loop runs, delays the retry.
+	BackoffSec float64
 }
 
 // PhaseMetrics summarizes a time window inside the run.
 type PhaseMetrics struct {
-	Total     int
-	Success   int
-	MeanLatMS float64
-	P95LatMS  float64
+	// Steps [Start, End) covered by the phase.
+	Start, End int
+	Total      int
+	Success    int
+	MeanLatMS  float64
+	P95LatMS   float64
+	// Fairness is Jain's index of the phase's selections across the
+	// endpoints healthy throughout it: 1 for an even split, 1/n when one
+	// of n endpoints takes everything.  High success and low latency with
+	// low fairness means the strategy fixates on a hotspot.
+	Fairness float64
 }
 
 // RunScenario executes the scenario for a single strategy and returns aggregated results.
 func RunScenario(sc Scenario, s Strategy) Results {
+	_, groups, _ := simulate(sc, []Strategy{s}, nil)
+	return groups[0]
+}
+
+// simulate runs the scenario with every client driving traffic into the
+// shared environment; request i is issued by client i mod len(clients).
+// It returns the results of all requests, named "population", and of every
+// group of clients sharing a strategy name, in order of first appearance.
+// A non-nil resume continues the run that checkpoint was taken from; only
+// resuming can fail.
+func simulate(sc Scenario, clients []Strategy, resume *Checkpoint) (total Results, groups []Results, err error) {
+	if resume != nil {
+		if err := resume.check(sc, len(clients)); err != nil {
+			return Results{}, nil, err
+		}
+	}
+	for i, s := range clients {
+		if r, ok := s.(ResettableStrategy); ok {
+			r.Reset()
+		}
+		if seedable, ok := s.(SeedableStrategy); ok {
+			key := fmt.Sprintf("client/%d", i)
+			if resume != nil {
+				key += fmt.Sprintf("@%d", resume.Step)
+			}
+			seedable.Seed(streamSeed(sc.Seed, key))
+		}
+	}
+
 	// Copy environment into a map for quick updates
 	env := make(map[string]*EndpointSpec)
 	eps := make([]string, 0, len(sc.Endpoints))
+	joins := make(map[int][]string)
 	for _, e := range sc.Endpoints {
 		v := e // copy
+		v.Classes = maps.Clone(e.Classes)
 		env[e.Addr] = &v
+		if e.JoinStep > 0 {
+			joins[e.JoinStep] = append(joins[e.JoinStep], e.Addr)
+			continue
+		}
 		eps = append(eps, e.Addr)
 	}
-	s.AddService(sc.Service, eps)
+	register := func() {
+		for _, s := range clients {
+			if !sc.RouteByClass || len(sc.Classes) == 0 {
+				s.AddService(sc.Service, slices.Clone(eps))
+				continue
+			}
+			for _, c := range sc.Classes {
+				s.AddService(sc.classService(c.Name), slices.Clone(eps))
+			}
+		}
+	}
+	register()
+	served := make(map[string]int)
 
 	// Index events by step for O(1) lookup
 	byStep := make(map[int][]EnvironmentEvent)
-	for _, ev := range sc.Events {
+	for _, ev := range sc.expandEvents() {
 		byStep[ev.Step] = append(byStep[ev.Step], ev)
 	}
 
-	rng := rand.New(rand.NewSource(sc.Seed))
+	// Common random numbers: every endpoint draws its outcomes from its own
+	// stream, so every strategy meets the same sequence of outcomes on it
+	// and differences between strategies come from their choices alone.
+	srcs := make(map[string]*countingSource, len(sc.Endpoints))
+	rngs := make(map[string]*rand.Rand, len(sc.Endpoints))
+	for _, e := range sc.Endpoints {
+		srcs[e.Addr] = newCountingSource(streamSeed(sc.Seed, e.Addr))
+		rngs[e.Addr] = rand.New(srcs[e.Addr])
+	}
+	timeout := sc.TimeoutSec
+	if timeout <= 0 {
+		timeout = defaultTimeoutSec
+	}
+	hooks := sc.Hooks
+	if hooks == nil {
+		hooks = &Hooks{}
+	}
+	report := func(client int, service, endpoint string, latencySec float64, success bool, fb *ServerFeedback) {
+		if fs, ok := clients[client].(FeedbackStrategy); ok && fb != nil {
+			fs.ReportFeedback(service, endpoint, *fb)
+		}
+		clients[client].ReportResult(service, endpoint, latencySec, success)
+		if hooks.OnReport != nil {
+			hooks.OnReport(client, service, endpoint, latencySec, success)
+		}
+	}
+	var ol *openLoop
+	if sc.Load != nil && sc.Load.RPS > 0 {
+		ol = newOpenLoop(*sc.Load, sc.Seed, func(c completion) {
+			report(c.Client, c.Service, c.Endpoint, c.LatencySec, c.Success, c.Feedback)
+		})
+	}
+	maxRetries, backoff := 0, 0.0
+	if sc.Retry != nil {
+		maxRetries, backoff = max(sc.Retry.MaxRetries, 0), max(sc.Retry.BackoffSec, 0)
+	}
+	pick := func(s Strategy, service, key string, exclude []string) (string, []CandidateScore, error) {
+		if keyed, ok := s.(KeyedStrategy); ok && key != "" {
+			addr, err := keyed.PickEndpointForKey(service, key, exclude...)
+			return addr, nil, err
+		}
+		if excluder, ok := s.(ExcludingStrategy); ok && len(exclude) > 0 {
+			addr, err := excluder.PickEndpointExcluding(service, exclude...)
+			return addr, nil, err
+		}
+		if explainer, ok := s.(Explainer); ok && sc.Decisions != nil {
+			return explainer.PickEndpointExplained(service)
+		}
+		addr, err := s.PickEndpoint(service)
+		return addr, nil, err
+	}
+	classes := newClassPicker(sc.Classes, sc.Seed)
+	keys := newKeyPicker(sc.Keys, sc.Seed)
+	starts := sc.phaseStarts()
+	phase := 0
+
+	// Metrics of all requests and of every group of clients
+	buckets := sc.histogramBuckets()
+	all := newTally(len(starts), len(sc.Classes), buckets)
+	var names []string
+	groupOf := make([]*tally, len(clients))
+	byName := make(map[string]*tally)
+	for i, s := range clients {
+		if byName[s.Name()] == nil {
+			byName[s.Name()] = newTally(len(starts), len(sc.Classes), buckets)
+			names = append(names, s.Name())
+		}
+		groupOf[i] = byName[s.Name()]
+	}
+
+	// Degradation tracking: endpoints currently worse than their initial
+	// spec, the start step of their open window, and the selections made
+	// while any endpoint was degraded.
+	initial := make(map[string]EndpointSpec, len(sc.Endpoints))
+	for _, e := range sc.Endpoints {
+		initial[e.Addr] = e
+	}
+	degradedSince := make(map[string]int)
+	var windows []DegradedWindow
 
-	selections := make(map[string]int)
-	latencies := make([]float64, 0, sc.TotalRequests)
-	success := 0
+	// checkpoint captures the state before step next.
+	checkpoint := func(next int) (*Checkpoint, error) {
+		st := checkpointState{
+			Seed:          sc.Seed,
+			TotalRequests: sc.TotalRequests,
+			Endpoints:     slices.Clone(eps),
+			Env:           make(map[string]envState, len(env)),
+			Served:        maps.Clone(served),
+			Draws:         make(map[string]uint64, len(srcs)),
+			DegradedSince: maps.Clone(degradedSince),
+			Windows:       slices.Clone(windows),
+			All:           all,
+			Groups:        byName,
+			Strategies:    make([]json.RawMessage, len(clients)),
+		}
+		for addr, e := range env {
+			st.Env[addr] = envStateOf(e)
+		}
+		for addr, src := range srcs {
+			st.Draws[addr] = src.n
+		}
+		if classes != nil {
+			st.ClassDraws = classes.src.n
+		}
+		if keys != nil {
+			st.KeyDraws = keys.src.n
+		}
+		if ol != nil {
+			st.Open = ol.state()
+		}
+		for i, s := range clients {
+			cs, ok := s.(CheckpointStrategy)
+			if !ok {
+				continue
+			}
+			data, err := cs.MarshalState()
+			if err != nil {
+				return nil, fmt.Errorf("client %d (%s): %w", i, s.Name(), err)
+			}
+			st.Strategies[i] = data
+		}
+		// The checkpoint must not alias live state.
+		return (&Checkpoint{Step: next, st: st}).clone()
+	}
 
-	// Per-phase tracking
-	perPhaseLat := [3][]float64{}
-	perPhaseSel := [3]map[string]int{make(map[string]int), make(map[string]int), make(map[string]int)}
-	perPhaseTotal := [3]int{}
-	perPhaseSuccess := [3]int{}
+	first := 0
+	if resume != nil {
+		// Copy, so resuming doesn't modify the checkpoint.
+		cp, err := resume.clone()
+		if err != nil {
+			return Results{}, nil, err
+		}
+		st := cp.st
+		first = resume.Step
+		eps = slices.Clone(st.Endpoints)
+		register()
+		for addr, e := range st.Env {
+			if cur, ok := env[addr]; ok {
+				e.apply(cur)
+			}
+		}
+		served = st.Served
+		for addr, n := range st.Draws {
+			if src, ok := srcs[addr]; ok {
+				src.skip(n)
+			}
+		}
+		if classes != nil {
+			classes.src.skip(st.ClassDraws)
+		}
+		if keys != nil {
+			keys.src.skip(st.KeyDraws)
+		}
+		if ol != nil && st.Open != nil {
+			ol.restore(st.Open)
+		}
+		degradedSince, windows = st.DegradedSince, st.Windows
+		all = st.All
+		for i, s := range clients {
+			if g := st.Groups[s.Name()]; g != nil {
+				byName[s.Name()], groupOf[i] = g, g
+			}
+		}
+		for i, s := range clients {
+			cs, ok := s.(CheckpointStrategy)
+			if !ok || st.Strategies[i] == nil {
+				continue
+			}
+			if err := cs.UnmarshalState(st.Strategies[i]); err != nil {
+				return Results{}, nil, fmt.Errorf("client %d (%s): %w", i, s.Name(), err)
+			}
+		}
+	}
 
-	// Detect degraded endpoint at step 2000 by looking at events applied at that step
-	degradedEndpoint := ""
+	if hooks.OnStart != nil {
+		names := make([]string, len(clients))
+		for i, s := range clients {
+			names[i] = s.Name()
+		}
+		hooks.OnStart(sc.Seed, names)
+	}
+	steplog := newStepLog(sc.StepLog)
+	for _, e := range sc.Endpoints {
+		_, degraded := degradedSince[e.Addr]
+		steplog.env(first, env[e.Addr], degraded)
+	}
+	for step := first; step < sc.TotalRequests; step++ {
+		if joined := joins[step]; len(joined) > 0 {
+			eps = append(eps, joined...)
+			register()
+		}
 
-	for step := 0; step < sc.TotalRequests; step++ {
 		// Apply events
 		if arr := byStep[step]; len(arr) > 0 {
-			// For degrade detection, inspect values before applying
-			if step == 2000 {
-				bestScore := 0.0
-				for _, ev := range arr {
-					st, ok := env[ev.Endpoint]
-					if !ok {
-						continue
-					}
-					oldMean := st.MeanLatencySec
-					oldErr := st.ErrorRate
-					// Compute score for worsening
-					score := 0.0
-					if ev.NewMeanLatency != nil {
-						if oldMean > 0 {
-							score += (*ev.NewMeanLatency/oldMean - 1.0)
-						} else {
-							if *ev.NewMeanLatency > 0 {
-								score += 1.0
-							}
+			for _, ev := range arr {
+				if hooks.OnEvent != nil {
+					hooks.OnEvent(step, ev)
+				}
+				if st, ok := env[ev.Endpoint]; ok {
+					switch {
+					case ev.Class != "":
+						st.setClass(ev.Class, ev.NewMeanLatency, ev.NewErrorRate)
+					default:
+						if ev.NewMeanLatency != nil {
+							st.MeanLatencySec = *ev.NewMeanLatency
+						}
+						if ev.NewErrorRate != nil {
+							st.ErrorRate = clamp01(*ev.NewErrorRate)
 						}
 					}
-					if ev.NewErrorRate != nil {
-						score += (*ev.NewErrorRate - oldErr)
+					if ev.NewJitterSec != nil {
+						st.JitterSec = *ev.NewJitterSec
+					}
+					if ev.NewDown != nil {
+						st.Down = *ev.NewDown
 					}
-					if score > 0 && score > bestScore {
-						bestScore = score
-						degradedEndpoint = ev.Endpoint
+					if ev.NewBlackhole != nil {
+						st.Blackhole = *ev.NewBlackhole
 					}
 				}
 			}
 			for _, ev := range arr {
-				if st, ok := env[ev.Endpoint]; ok {
-					if ev.NewMeanLatency != nil {
-						st.MeanLatencySec = *ev.NewMeanLatency
-					}
-					if ev.NewJitterSec != nil {
-						st.JitterSec = *ev.NewJitterSec
-					}
-					if ev.NewErrorRate != nil {
-						st.ErrorRate = clamp01(*ev.NewErrorRate)
+				st, ok := env[ev.Endpoint]
+				if !ok {
+					continue
+				}
+				start, was := degradedSince[ev.Endpoint]
+				switch is := degradedForAny(initial[ev.Endpoint], *st, sc.Classes); {
+				case is && !was:
+					degradedSince[ev.Endpoint] = step
+				case !is && was:
+					windows = append(windows, DegradedWindow{Endpoint: ev.Endpoint, Start: start, End: step})
+					delete(degradedSince, ev.Endpoint)
+				}
+			}
+			if steplog != nil {
+				logged := make(map[string]bool)
+				for _, ev := range arr {
+					if st, ok := env[ev.Endpoint]; ok && !logged[ev.Endpoint] {
+						logged[ev.Endpoint] = true
+						_, degraded := degradedSince[ev.Endpoint]
+						steplog.env(step, st, degraded)
 					}
 				}
 			}
 		}
 
-		// Choose endpoint
-		addr, err := s.PickEndpoint(sc.Service)
-		if err != nil {
-			// If strategy cannot pick, skip this request
-			continue
-		}
-		selections[addr]++
-		st := env[addr]
-		if st == nil {
-			// unknown endpoint (shouldn't happen), skip
-			continue
+		start, wait := 0.0, 0.0
+		if ol != nil {
+			start, wait = ol.next()
 		}
 
 		// Phase index by step
-		phase := 0
-		switch {
-		case step >= 6000:
-			phase = 2
-		case step >= 2000:
-			phase = 1
-		default:
-			phase = 0
-		}
-		perPhaseSel[phase][addr]++
-		perPhaseTotal[phase]++
-
-		// Sample outcome from environment
-		fail := rng.Float64() < st.ErrorRate
-		// Sample latency around mean with per-endpoint jitter (stddev), truncated to 0.2x..5x
-		jitter := st.JitterSec
-		if jitter <= 0 {
-			// Default to 30% coefficient of variation if not provided
-			jitter = 0.3 * st.MeanLatencySec
-		}
-		lat := st.MeanLatencySec + rng.NormFloat64()*jitter
-		minLat := 0.2 * st.MeanLatencySec
-		maxLat := 5.0 * st.MeanLatencySec
-		if st.MeanLatencySec == 0 {
-			minLat = 0.001
-			maxLat = 0.050
-		}
-		if lat < minLat {
-			lat = minLat
-		}
-		if lat > maxLat {
-			lat = maxLat
-		}
-
-		// Penalize failures by adding a fixed overhead so strategies can learn from them
-		reportLat := lat
-		if fail {
-			reportLat += 0.250
-		}
-
-		s.ReportResult(sc.Service, addr, reportLat, !fail)
-
-		if !fail {
-			success++
-			latencies = append(latencies, lat)
-			perPhaseSuccess[phase]++
-			perPhaseLat[phase] = append(perPhaseLat[phase], lat)
-		}
-	}
-
-	mean, p95 := summarizeLatency(latencies)
-	// Build phase metrics
-	phases := [3]PhaseMetrics{}
-	for i := 0; i < 3; i++ {
-		pm := PhaseMetrics{Total: perPhaseTotal[i], Success: perPhaseSuccess[i]}
-		m, p := summarizeLatency(perPhaseLat[i])
-		pm.MeanLatMS = m * 1000
-		pm.P95LatMS = p * 1000
-		phases[i] = pm
-	}
-	// Compute share to degraded endpoint in bad window
-	badShare := 0.0
-	if degradedEndpoint != "" {
-		totalBad := 0
-		for _, c := range perPhaseSel[1] {
-			totalBad += c
-		}
-		if totalBad > 0 {
-			badShare = float64(perPhaseSel[1][degradedEndpoint]) / float64(totalBad)
-		}
-	}
-	return Results{
-		Strategy:               s.Name(),
-		Total:                  sc.TotalRequests,
-		Success:                success,
-		Failure:                sc.TotalRequests - success,
-		MeanLatMS:              mean * 1000,
-		P95LatMS:               p95 * 1000,
-		Selection:              selections,
-		Phases:                 phases,
-		DegradedEndpoint:       degradedEndpoint,
-		BadWindowDegradedShare: badShare,
+		for phase+1 < len(starts) && step >= starts[phase+1] {
+			phase++
+		}
+
+		// Draw the request's class
+		ci, class := -1, ""
+		if classes != nil {
+			ci = classes.next()
+			class = sc.Classes[ci].Name
+		}
+		service := sc.classService(class)
+		key := ""
+		if keys != nil {
+			key = keys.next()
+		}
+		client := step % len(clients)
+		s := clients[client]
+		// Requests in the warm-up window drive learning but aren't measured
+		var tallies []*tally
+		if step >= sc.WarmupSteps {
+			tallies = []*tally{all, groupOf[client]}
+		}
+
+		// Attempt the request, retrying failures per the retry policy
+		var exclude []string
+		elapsed, fail, attempted, timedOut := 0.0, true, false, false
+		last, attempts := "", 0
+		for try := 0; ; try++ {
+			// Choose endpoint
+			addr, scores, err := pick(s, service, key, exclude)
+			if err != nil {
+				// If strategy cannot pick, give up on this request
+				break
+			}
+			st := env[addr]
+			if st == nil {
+				// unknown endpoint (shouldn't happen), give up
+				break
+			}
+			attempted, timedOut = true, false
+			last = addr
+			attempts++
+			if hooks.OnPick != nil {
+				hooks.OnPick(step, client, service, addr)
+			}
+			if sc.Decisions != nil {
+				degraded := slices.Sorted(maps.Keys(degradedSince))
+				sc.Decisions.Write(Decision{step, client, s.Name(), service, try, addr, scores, degraded})
+			}
+			for _, t := range tallies {
+				t.attempt(step, phase, addr, degradedSince)
+			}
+
+			// Sample outcome from environment, with the class's overrides
+			spec := st.forClass(class)
+			rng := rngs[addr]
+			fail = rng.Float64() < spec.ErrorRate
+			// Sample latency around mean with per-endpoint jitter (stddev)
+			jitter := spec.JitterSec
+			if jitter <= 0 {
+				// Default to 30% coefficient of variation if not provided
+				jitter = 0.3 * spec.MeanLatencySec
+			}
+			dist := spec.Distribution
+			if dist == nil {
+				dist = Gaussian{}
+			}
+			lat := dist.Sample(rng, spec.MeanLatencySec, jitter) * spec.WarmUp.multiplier(served[addr])
+			if sc.Trace != nil {
+				lat, fail = sc.Trace.outcome(addr, step, rng)
+			}
+			served[addr]++
+			fb := &ServerFeedback{ServiceTimeSec: lat}
+			if ol != nil {
+				fb.QueueLen = ol.inFlight[addr]
+			}
+			if ol != nil && spec.Capacity > 0 {
+				if n := ol.inFlight[addr] + 1; n > spec.Capacity {
+					lat *= float64(n) / float64(spec.Capacity)
+					for _, t := range tallies {
+						t.Overloaded++
+					}
+				}
+			}
+
+			// Penalize failures by adding a fixed overhead so strategies can learn
+			// from them.  The penalty is only reported; spent is the time the
+			// attempt actually took.
+			spent, reportLat := lat, lat
+			if fail {
+				reportLat += failurePenaltySec
+			}
+			// A down endpoint refuses the connection right away; requests to a
+			// blackholed endpoint, or slower than the timeout, hang until the
+			// timeout.  The samples above are still drawn so the endpoint's
+			// random stream stays aligned.
+			switch {
+			case spec.Down:
+				fail, spent, reportLat, fb = true, 0, 0, nil
+			case spec.Blackhole || (sc.TimeoutSec > 0 && lat > sc.TimeoutSec):
+				fail, spent, reportLat, timedOut, fb = true, timeout, timeout, true, nil
+				for _, t := range tallies {
+					t.Timeouts++
+				}
+			}
+
+			if !fail {
+				for _, t := range tallies {
+					t.served(addr, reportLat)
+				}
+			}
+
+			if ol != nil {
+				ol.add(completion{Done: start + elapsed + spent, Client: client, Service: service, Endpoint: addr, LatencySec: reportLat, Success: !fail, Feedback: fb})
+			} else {
+				report(client, service, addr, reportLat, !fail, fb)
+			}
+			elapsed += spent
+			if !fail || try >= maxRetries {
+				break
+			}
+			exclude = append(exclude, addr)
+			elapsed += backoff
+		}
+		for _, t := range tallies {
+			if t.Requests == 0 {
+				t.StartSec = start
+			}
+		}
+		if steplog != nil {
+			var st *EndpointSpec
+			inFlight := 0
+			if last != "" {
+				st = env[last]
+				if ol != nil {
+					inFlight = ol.inFlight[last]
+				}
+			}
+			_, degraded := degradedSince[last]
+			steplog.request(step, client, s.Name(), class, last, attempts, wait+elapsed, wait, attempted && !fail, st, degraded, inFlight)
+		}
+		if attempted {
+			// End-to-end latency: queueing, failed attempts, backoff
+			for _, t := range tallies {
+				t.request(step, phase, ci, !fail, wait+elapsed, wait)
+				if key != "" {
+					t.keyed(key, last)
+				}
+				if timedOut {
+					t.TimedOut++
+				}
+			}
+		} else {
+			for _, t := range tallies {
+				t.skipped(wait)
+			}
+		}
+		if ol != nil && (step+1)%shareBucketSteps == 0 {
+			for _, t := range tallies {
+				t.InFlight = append(t.InFlight, InFlightSample{Step: step + 1, TimeSec: start, InFlight: len(ol.pending)})
+			}
+		}
+		if hooks.OnStep != nil {
+			hooks.OnStep(step)
+		}
+		if cp := sc.Checkpoints; cp != nil && cp.Every > 0 && cp.Save != nil && (step+1)%cp.Every == 0 && step+1 < sc.TotalRequests {
+			cp.Save(checkpoint(step + 1))
+		}
+	}
+
+	if ol != nil {
+		ol.drain()
+	}
+	steplog.flush()
+	// Close windows still open at the end of the run
+	for addr, start := range degradedSince {
+		windows = append(windows, DegradedWindow{Endpoint: addr, Start: start, End: sc.TotalRequests})
+	}
+	sortWindows(windows)
+	total = all.results("population", sc, starts, windows, ol)
+	for _, name := range names {
+		groups = append(groups, byName[name].results(name, sc, starts, windows, ol))
+	}
+	return total, groups, nil
+}
+
+// streamSeed derives the seed of the random stream named key, such as an
+// endpoint's outcomes or a client's choices, from the scenario seed.
+func streamSeed(seed int64, key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return seed ^ int64(h.Sum64())
+}
+
+// phaseStarts returns the sorted, distinct start steps of the reporting
+// phases within the measured part of the run, starting with WarmupSteps.
+func (sc Scenario) phaseStarts() []int {
+	starts := sc.Phases
+	if starts == nil {
+		for _, ev := range sc.Events {
+			starts = append(starts, ev.Step)
+		}
+		for _, e := range sc.Endpoints {
+			starts = append(starts, e.JoinStep)
+		}
 	}
+	warmup := max(sc.WarmupSteps, 0)
+	out := []int{warmup}
+	for _, s := range starts {
+		if s > warmup && s < sc.TotalRequests {
+			out = append(out, s)
+		}
+	}
+	sort.Ints(out)
+	return slices.Compact(out)
 }
 
 func clamp01(v float64) float64 {
@@ -279,15 +947,50 @@ func summarizeLatency(samples []float64) (mean, p95 float64) {
 	mean = sum / float64(len(samples))
 	cp := append([]float64(nil), samples...)
 	sort.Float64s(cp)
-	idx := int(math.Ceil(0.95*float64(len(cp)))) - 1
-	if idx < 0 {
-		idx = 0
+	return mean, percentile(cp, 95)
+}
+
+// percentile returns the nearest-rank p-th percentile (0..100) of sorted,
+// which must not be empty; 100 is the maximum.
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	idx = min(max(idx, 0), len(sorted)-1)
+	return sorted[idx]
+}
+
+// DefaultPercentiles are the latency percentiles reported when
+// Scenario.Percentiles is nil: the median and the tail up to the maximum.
+var DefaultPercentiles = []float64{50, 95, 99, 99.9, 100}
+
+// LatencyPercentile is one reported latency percentile.
+type LatencyPercentile struct {
+	// P is the percentile, 0..100; 100 is the maximum.
+	P     float64
+	LatMS float64
+}
+
+// Percentile returns the latency at percentile p if it was reported.
+func (r Results) Percentile(p float64) (ms float64, ok bool) {
+	for _, lp := range r.Percentiles {
+		if lp.P == p {
+			return lp.LatMS, true
+		}
 	}
-	if idx >= len(cp) {
-		idx = len(cp) - 1
+	return 0, false
+}
+
+// latencyPercentiles returns the percentiles ps of samples.
+func latencyPercentiles(samples, ps []float64) []LatencyPercentile {
+	if len(samples) == 0 || len(ps) == 0 {
+		return nil
 	}
-	p95 = cp[idx]
-	return
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	out := make([]LatencyPercentile, len(ps))
+	for i, p := range ps {
+		out[i] = LatencyPercentile{P: p, LatMS: percentile(sorted, p) * 1000}
+	}
+	return out
 }
 
 // RunAll runs the scenario for all provided strategies and returns their results in order.
@@ -313,23 +1016,87 @@ func FormatResults(results []Results) string {
 		for _, k := range keys {
 			s += fmt.Sprintf("  %s: %d\n", k, r.Selection[k])
 		}
+		if len(r.Percentiles) > 0 {
+			parts := make([]string, len(r.Percentiles))
+			for i, lp := range r.Percentiles {
+				name := "p" + strconv.FormatFloat(lp.P, 'f', -1, 64)
+				if lp.P >= 100 {
+					name = "max"
+				}
+				parts[i] = fmt.Sprintf("%s=%.1fms", name, lp.LatMS)
+			}
+			s += "  latency: " + strings.Join(parts, " ") + "\n"
+		}
+		if r.Timeouts > 0 {
+			s += fmt.Sprintf("  timeouts: %d (requests timed out: %d)\n", r.Timeouts, r.TimedOut)
+		}
+		if r.Dropped > 0 {
+			s += fmt.Sprintf("  dropped: %d\n", r.Dropped)
+		}
+		if r.Attempts > r.Total {
+			s += fmt.Sprintf("  retries: attempts=%d amplification=%.2fx\n", r.Attempts, r.RetryAmplification)
+		}
+		if len(r.Selection) > 1 {
+			s += fmt.Sprintf("  herding: peak-share=%.1f%% swing=%.1f%% oscillation=%.1f%%\n", 100*r.PeakShare, 100*r.ShareSwing, 100*r.Oscillation)
+		}
+		if r.DurationSec > 0 {
+			s += fmt.Sprintf("  open-loop: duration=%.1fs goodput=%.1f/s max-in-flight=%d mean-queue-wait=%.1fms\n", r.DurationSec, r.GoodputRPS, r.MaxInFlight, r.MeanQueueWaitMS)
+		}
+		if r.OverloadShare > 0 {
+			s += fmt.Sprintf("  overload exposure: %.1f%% of attempts\n", 100*r.OverloadShare)
+		}
+		if r.KeyAffinity > 0 {
+			s += fmt.Sprintf("  key affinity: %.1f%% of repeat keys on the same endpoint\n", 100*r.KeyAffinity)
+		}
 		// Per-phase stats
-		s += fmt.Sprintf("  phase[0-1999]: success=%d/%d (%.1f%%), mean=%.1fms p95=%.1fms\n",
-			r.Phases[0].Success, r.Phases[0].Total,
-			pct(r.Phases[0].Success, r.Phases[0].Total), r.Phases[0].MeanLatMS, r.Phases[0].P95LatMS)
-		s += fmt.Sprintf("  phase[2000-5999]: success=%d/%d (%.1f%%), mean=%.1fms p95=%.1fms\n",
-			r.Phases[1].Success, r.Phases[1].Total,
-			pct(r.Phases[1].Success, r.Phases[1].Total), r.Phases[1].MeanLatMS, r.Phases[1].P95LatMS)
-		s += fmt.Sprintf("  phase[6000-...]: success=%d/%d (%.1f%%), mean=%.1fms p95=%.1fms\n",
-			r.Phases[2].Success, r.Phases[2].Total,
-			pct(r.Phases[2].Success, r.Phases[2].Total), r.Phases[2].MeanLatMS, r.Phases[2].P95LatMS)
-		if r.DegradedEndpoint != "" && r.Phases[1].Total > 0 {
-			s += fmt.Sprintf("  bad-window share to degraded (%s): %.1f%%\n", r.DegradedEndpoint, 100.0*r.BadWindowDegradedShare)
+		for i, p := range r.Phases {
+			span := fmt.Sprintf("%d-%d", p.Start, p.End-1)
+			if i == len(r.Phases)-1 {
+				span = fmt.Sprintf("%d-...", p.Start)
+			}
+			s += fmt.Sprintf("  phase[%s]: success=%d/%d (%.1f%%), mean=%.1fms p95=%.1fms fairness=%.2f\n",
+				span, p.Success, p.Total, pct(p.Success, p.Total), p.MeanLatMS, p.P95LatMS, p.Fairness)
+		}
+		for _, c := range r.Classes {
+			s += fmt.Sprintf("  class[%s]: success=%d/%d (%.1f%%), mean=%.1fms p95=%.1fms\n",
+				c.Name, c.Success, c.Total, pct(c.Success, c.Total), c.MeanLatMS, c.P95LatMS)
+		}
+		if len(r.DegradedWindows) > 0 {
+			spans := make([]string, len(r.DegradedWindows))
+			for i, w := range r.DegradedWindows {
+				spans[i] = fmt.Sprintf("%s %d-%d", w.Endpoint, w.Start, w.End-1)
+			}
+			s += fmt.Sprintf("  bad-window share to degraded (%s): %.1f%%\n", strings.Join(spans, ", "), 100.0*r.BadWindowDegradedShare)
+		}
+		if len(r.Reactions) > 0 {
+			parts := make([]string, len(r.Reactions))
+			for i, rc := range r.Reactions {
+				parts[i] = fmt.Sprintf("%s@%d %s", rc.Endpoint, rc.Start, formatSteps(rc.ShedSteps))
+			}
+			s += fmt.Sprintf("  time-to-shed: %s (mean %.0f steps)\n", strings.Join(parts, ", "), r.MeanShedSteps)
+			parts = parts[:0]
+			for _, rc := range r.Reactions {
+				if !rc.Recovered {
+					continue
+				}
+				parts = append(parts, fmt.Sprintf("%s@%d %s", rc.Endpoint, rc.End, formatSteps(rc.ReincludeSteps)))
+			}
+			if len(parts) > 0 {
+				s += fmt.Sprintf("  time-to-reinclude: %s (mean %.0f steps)\n", strings.Join(parts, ", "), r.MeanReincludeSteps)
+			}
 		}
 	}
 	return s
 }
 
+// formatSteps renders a reaction time in steps, -1 meaning never.
+func formatSteps(n int) string {
+	if n < 0 {
+		return "never"
+	}
+	return fmt.Sprintf("%d steps", n)
+}
+
 func pct(n, d int) float64 {
 	if d == 0 {
 		return 0
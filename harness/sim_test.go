@@ -17,7 +17,24 @@
 package harness
 
 import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	lib "swarmroute"
 )
 
 // TestAlwaysBadEndpoint ensures SwarmRoute rapidly avoids a 100% failing endpoint
@@ -63,3 +80,2231 @@ func TestAlwaysSlowEndpoint(t *testing.T) {
 		t.Fatalf("slow endpoint share too high: got %.2f%% (sel=%d/%d)", 100*share, slowSel, total)
 	}
 }
+
+// TestEventGeneratorsExpand checks the declarative generators against
+// hand-written expectations.
+func TestEventGeneratorsExpand(t *testing.T) {
+	ramp := Ramp{Endpoint: "b", Param: ParamLatency, From: 0.030, To: 0.130, StartStep: 100, EndStep: 200, Every: 50}.Events(1000)
+	if len(ramp) != 3 || ramp[1].Step != 150 || math.Abs(*ramp[1].NewMeanLatency-0.080) > 1e-12 || *ramp[2].NewMeanLatency != 0.130 {
+		t.Fatalf("unexpected ramp: %+v", ramp)
+	}
+
+	sine := Sine{Endpoint: "b", Param: ParamErrorRate, Base: 0.1, Amplitude: 0.1, Period: 40, Every: 10}.Events(40)
+	want := []float64{0.1, 0.2, 0.1, 0}
+	if len(sine) != len(want) {
+		t.Fatalf("expected %d sine events, got %d", len(want), len(sine))
+	}
+	for i, ev := range sine {
+		if ev.Step != 10*i || math.Abs(*ev.NewErrorRate-want[i]) > 1e-12 {
+			t.Fatalf("sine event %d: step %d value %v, want %v", i, ev.Step, *ev.NewErrorRate, want[i])
+		}
+	}
+
+	spikes := Spikes{Endpoint: "a", BaseSec: 0.030, SpikeSec: 0.300, Every: 100, Length: 5, StartStep: 50}.Events(260)
+	if len(spikes) != 6 || spikes[2].Step != 150 || *spikes[2].NewMeanLatency != 0.300 || spikes[3].Step != 155 || *spikes[3].NewMeanLatency != 0.030 {
+		t.Fatalf("unexpected spikes: %+v", spikes)
+	}
+	if last := spikes[5]; last.Step != 255 {
+		t.Fatalf("expected the last spike to end at step 255, got %d", last.Step)
+	}
+
+	slow := 0.5
+	rep := Repeat{Of: Events{{Step: 10, Endpoint: "a", NewMeanLatency: &slow}}, Period: 100}.Events(350)
+	if len(rep) != 4 || rep[3].Step != 310 {
+		t.Fatalf("unexpected repetition until the end of the run: %+v", rep)
+	}
+	if rep := (Repeat{Of: Events{{Step: 10}}, Period: 100, Count: 2}).Events(1000); len(rep) != 2 {
+		t.Fatalf("expected 2 repetitions, got %d", len(rep))
+	}
+}
+
+// TestPhasesFollowScenario checks that phase windows come from the
+// scenario's events or its explicit Phases rather than fixed steps.
+func TestPhasesFollowScenario(t *testing.T) {
+	slow := 0.150
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.030}, {Addr: "b", MeanLatencySec: 0.030}},
+		Events:        []EnvironmentEvent{{Step: 500, Endpoint: "b", NewMeanLatency: &slow}},
+		TotalRequests: 1500,
+		Seed:          7,
+	}
+	r := RunScenario(sc, NewRoundRobinStrategy())
+	if len(r.Phases) != 2 || r.Phases[1].Start != 500 || r.Phases[1].End != 1500 || r.Phases[1].Total != 1000 {
+		t.Fatalf("expected phases split at the event step, got %+v", r.Phases)
+	}
+	if len(r.DegradedWindows) != 1 || r.DegradedWindows[0] != (DegradedWindow{Endpoint: "b", Start: 500, End: 1500}) || math.Abs(r.BadWindowDegradedShare-0.5) > 1e-9 {
+		t.Fatalf("expected b degraded with half the bad-window traffic, got %+v %.3f", r.DegradedWindows, r.BadWindowDegradedShare)
+	}
+
+	sc.Phases = []int{1000, 250, 250}
+	r = RunScenario(sc, NewRoundRobinStrategy())
+	if len(r.Phases) != 3 || r.Phases[1].Start != 250 || r.Phases[2].Start != 1000 || r.Phases[0].Total != 250 {
+		t.Fatalf("expected explicit phases 0/250/1000, got %+v", r.Phases)
+	}
+}
+
+// TestDegradedShareTracksEveryEndpoint checks that overlapping degradations
+// of several endpoints are all measured, each in its own window.
+func TestDegradedShareTracksEveryEndpoint(t *testing.T) {
+	slow, fast := 0.150, 0.030
+	sc := Scenario{
+		Service:   "svc",
+		Endpoints: []EndpointSpec{{Addr: "a", MeanLatencySec: fast}, {Addr: "b", MeanLatencySec: fast}, {Addr: "c", MeanLatencySec: fast}, {Addr: "d", MeanLatencySec: fast}},
+		Events: []EnvironmentEvent{
+			{Step: 400, Endpoint: "b", NewMeanLatency: &slow},
+			{Step: 800, Endpoint: "c", NewMeanLatency: &slow},
+			{Step: 1200, Endpoint: "b", NewMeanLatency: &fast},
+			{Step: 1600, Endpoint: "c", NewMeanLatency: &fast},
+		},
+		TotalRequests: 2000,
+	}
+	r := RunScenario(sc, NewRoundRobinStrategy())
+	want := []DegradedWindow{{Endpoint: "b", Start: 400, End: 1200}, {Endpoint: "c", Start: 800, End: 1600}}
+	if len(r.DegradedWindows) != 2 || r.DegradedWindows[0] != want[0] || r.DegradedWindows[1] != want[1] {
+		t.Fatalf("unexpected windows: %+v", r.DegradedWindows)
+	}
+	// Round robin sends 1/4 of the traffic to b alone (400..799), 2/4 to b
+	// or c (800..1199) and 1/4 to c alone (1200..1599): (100+200+100)/1200.
+	if math.Abs(r.BadWindowDegradedShare-1.0/3) > 0.01 {
+		t.Fatalf("expected a third of bad-window selections to degraded endpoints, got %.3f", r.BadWindowDegradedShare)
+	}
+}
+
+// TestOutageRefusesGroup checks that a correlated outage fails every
+// request to the group with zero reported latency and that SwarmRoute
+// steers around it.
+func TestOutageRefusesGroup(t *testing.T) {
+	eps := []EndpointSpec{
+		{Addr: "a1", MeanLatencySec: 0.030}, {Addr: "a2", MeanLatencySec: 0.030},
+		{Addr: "b1", MeanLatencySec: 0.030}, {Addr: "b2", MeanLatencySec: 0.030},
+	}
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     eps,
+		Generators:    []EventGenerator{Outage{Endpoints: []string{"a1", "a2"}, StartStep: 1000, EndStep: 2000}},
+		TotalRequests: 3000,
+		Seed:          5,
+	}
+	refused := 0
+	r := RunScenario(sc, &reportHook{Strategy: NewRoundRobinStrategy(), fn: func(_ string, lat float64, ok bool) {
+		if !ok && lat == 0 {
+			refused++
+		}
+	}})
+	if refused != 500 {
+		t.Fatalf("expected the 500 requests to the group during the outage refused, got %d", refused)
+	}
+	if len(r.DegradedWindows) != 2 || r.DegradedWindows[0] != (DegradedWindow{Endpoint: "a1", Start: 1000, End: 2000}) {
+		t.Fatalf("unexpected degraded windows: %+v", r.DegradedWindows)
+	}
+
+	r = RunScenario(sc, NewSwarmRouteAdapter())
+	if r.BadWindowDegradedShare > 0.05 {
+		t.Fatalf("SwarmRoute sent %.1f%% of outage traffic to the down group", 100*r.BadWindowDegradedShare)
+	}
+}
+
+// TestPartitionTimesOut checks that requests to a partitioned endpoint
+// fail only after the timeout, and that the timeout also cuts off slow
+// responses of reachable endpoints.
+func TestPartitionTimesOut(t *testing.T) {
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.030}, {Addr: "b", MeanLatencySec: 0.030}},
+		Generators:    []EventGenerator{Partition{Endpoints: []string{"a"}, StartStep: 100, EndStep: 300}},
+		TotalRequests: 400,
+		TimeoutSec:    0.5,
+	}
+	atTimeout := 0
+	r := RunScenario(sc, &reportHook{Strategy: NewRoundRobinStrategy(), fn: func(_ string, lat float64, ok bool) {
+		if !ok && lat == 0.5 {
+			atTimeout++
+		}
+	}})
+	if r.Timeouts != 100 || atTimeout != 100 || r.Failure != 100 {
+		t.Fatalf("expected 100 requests to time out after 0.5s, got timeouts=%d reported=%d failures=%d", r.Timeouts, atTimeout, r.Failure)
+	}
+
+	sc.Generators = nil
+	sc.TimeoutSec = 0.040 // below 5x the mean, so the slowest samples time out
+	if r := RunScenario(sc, NewRoundRobinStrategy()); r.Timeouts == 0 || r.Timeouts > 200 {
+		t.Fatalf("expected some slow responses to time out, got %d", r.Timeouts)
+	}
+}
+
+// TestOpenLoopQueuesUnderLoad checks that open-loop arrivals overlap in
+// flight, that a concurrency cap queues them, and that every result is
+// still reported.
+func TestOpenLoopQueuesUnderLoad(t *testing.T) {
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.030, JitterSec: 0.001}},
+		TotalRequests: 1000,
+		Seed:          3,
+		Load:          &Load{RPS: 100},
+	}
+	reports := 0
+	r := RunScenario(sc, &reportHook{Strategy: NewRoundRobinStrategy(), fn: func(string, float64, bool) { reports++ }})
+	if reports != 1000 {
+		t.Fatalf("expected every request reported, got %d", reports)
+	}
+	if r.MaxInFlight < 3 || r.MaxInFlight > 5 || r.MeanQueueWaitMS != 0 || math.Abs(r.DurationSec-10.03) > 0.01 {
+		t.Fatalf("unexpected unlimited open loop: in-flight=%d wait=%.1fms duration=%.2fs", r.MaxInFlight, r.MeanQueueWaitMS, r.DurationSec)
+	}
+
+	sc.Load = &Load{RPS: 100, Poisson: true, Concurrency: 1}
+	r = RunScenario(sc, NewRoundRobinStrategy())
+	if r.MaxInFlight != 1 || r.MeanQueueWaitMS < 1000 || r.P95LatMS < r.MeanQueueWaitMS {
+		t.Fatalf("expected a growing queue behind one slot: in-flight=%d wait=%.1fms p95=%.1fms", r.MaxInFlight, r.MeanQueueWaitMS, r.P95LatMS)
+	}
+}
+
+func TestOpenLoopGoodputAndLosses(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.030, JitterSec: 0.001},
+			{Addr: "b", MeanLatencySec: 0.030, JitterSec: 0.001, Blackhole: true},
+		},
+		TotalRequests: 1000,
+		Seed:          3,
+		TimeoutSec:    0.2,
+		Load:          &Load{RPS: 100},
+	}
+	r := RunScenario(sc, &dropStrategy{Strategy: NewRoundRobinStrategy(), every: 10})
+	if r.Dropped != 100 || r.TimedOut != 450 || r.Success != 450 {
+		t.Fatalf("expected 100 dropped and 450 timed-out requests, got dropped=%d timed-out=%d success=%d", r.Dropped, r.TimedOut, r.Success)
+	}
+	if math.Abs(r.GoodputRPS-45) > 1 {
+		t.Fatalf("expected a goodput of about 45/s, got %.1f", r.GoodputRPS)
+	}
+	if len(r.InFlightSeries) != 100 || r.InFlightSeries[99].Step != 1000 || r.InFlightSeries[99].InFlight < 9 {
+		t.Fatalf("expected an in-flight sample every 10 steps with the hanging requests, got %d samples, last %+v", len(r.InFlightSeries), r.InFlightSeries[len(r.InFlightSeries)-1])
+	}
+}
+
+// dropStrategy fails every every-th pick.
+type dropStrategy struct {
+	Strategy
+	every, n int
+}
+
+func (d *dropStrategy) PickEndpoint(service string) (string, error) {
+	d.n++
+	if d.n%d.every == 0 {
+		return "", fmt.Errorf("no endpoint")
+	}
+	return d.Strategy.PickEndpoint(service)
+}
+
+// TestRetriesAvoidFailedEndpoint checks retry accounting and that retries
+// skip the endpoint that failed when the strategy supports exclusion.
+func TestRetriesAvoidFailedEndpoint(t *testing.T) {
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "bad", MeanLatencySec: 0.030, ErrorRate: 1}, {Addr: "b", MeanLatencySec: 0.030}, {Addr: "c", MeanLatencySec: 0.030}},
+		TotalRequests: 900,
+		Seed:          1,
+	}
+	r := RunScenario(sc, NewRoundRobinStrategy())
+	if r.Attempts != 900 || r.RetryAmplification != 1 || r.Success != 600 {
+		t.Fatalf("expected single attempts without a retry policy: %+v", r)
+	}
+
+	sc.Retry = &RetryPolicy{MaxRetries: 2, BackoffSec: 0.1}
+	r = RunScenario(sc, NewRoundRobinStrategy())
+	if r.Success != 900 || r.Selection["bad"] != r.Attempts-900 || r.RetryAmplification <= 1 || r.RetryAmplification > 1.5 {
+		t.Fatalf("expected every failure retried once elsewhere: success=%d attempts=%d selection=%v", r.Success, r.Attempts, r.Selection)
+	}
+	// A retried request takes the failed attempt, the backoff and the
+	// retry, about 160ms; the failure penalty is reported to the strategy
+	// but not spent.
+	if r.P95LatMS < 130 || r.P95LatMS > 200 {
+		t.Fatalf("expected retried requests to take the failed attempt, backoff and retry, p95=%.1fms", r.P95LatMS)
+	}
+}
+
+func TestPercentilesAreConfigurable(t *testing.T) {
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.020, Distribution: LogNormal{Sigma: 1}}},
+		TotalRequests: 2000,
+		Seed:          1,
+	}
+	res := RunScenario(sc, NewRoundRobinStrategy())
+	if len(res.Percentiles) != len(DefaultPercentiles) {
+		t.Fatalf("expected the default percentiles, got %+v", res.Percentiles)
+	}
+	for i := 1; i < len(res.Percentiles); i++ {
+		if res.Percentiles[i].LatMS < res.Percentiles[i-1].LatMS {
+			t.Fatalf("expected percentiles to be non-decreasing: %+v", res.Percentiles)
+		}
+	}
+	if p95, _ := res.Percentile(95); p95 != res.P95LatMS {
+		t.Fatalf("expected p95 %.3f to match P95LatMS %.3f", p95, res.P95LatMS)
+	}
+	if p999, _ := res.Percentile(99.9); p999 < 3*res.MeanLatMS {
+		t.Fatalf("expected a heavy p99.9 on a log-normal endpoint, got %.1fms (mean %.1fms)", p999, res.MeanLatMS)
+	}
+	sc.Percentiles = []float64{75}
+	res = RunScenario(sc, NewRoundRobinStrategy())
+	if _, ok := res.Percentile(99); ok || len(res.Percentiles) != 1 || res.Percentiles[0].P != 75 {
+		t.Fatalf("expected only p75, got %+v", res.Percentiles)
+	}
+}
+
+func TestLatencyHistogramsAndCSV(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.004, JitterSec: 0.0005},
+			{Addr: "b", MeanLatencySec: 0.030, JitterSec: 0.003, ErrorRate: 0.2},
+		},
+		TotalRequests:      1000,
+		Seed:               1,
+		HistogramBucketsMS: []float64{50, 10},
+	}
+	res := RunScenario(sc, NewRoundRobinStrategy())
+	h := res.Histogram
+	if !slices.Equal(h.BoundsMS, []float64{10, 50}) || h.Total() != res.Success {
+		t.Fatalf("expected sorted bounds and one sample per success, got %+v (success %d)", h, res.Success)
+	}
+	a, b := res.EndpointHistograms["a"], res.EndpointHistograms["b"]
+	if a.Counts[0] != res.Selection["a"] || b.Counts[1] == 0 || b.Counts[0]+b.Counts[2] != 0 {
+		t.Fatalf("expected a under 10ms and b within 10-50ms: a=%v b=%v", a.Counts, b.Counts)
+	}
+	if b.Total() >= res.Selection["b"] {
+		t.Fatalf("expected failed attempts on b to be left out, got %d of %d", b.Total(), res.Selection["b"])
+	}
+
+	var buf strings.Builder
+	if err := WriteHistogramsCSV(&buf, []Results{res}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+3*3 || lines[0] != "strategy,endpoint,le_ms,count" {
+		t.Fatalf("unexpected CSV:\n%s", buf.String())
+	}
+	if want := fmt.Sprintf("RoundRobin,a,10,%d", a.Counts[0]); lines[4] != want || lines[6] != "RoundRobin,a,+Inf,0" {
+		t.Fatalf("expected rows %q and the overflow bucket, got:\n%s", want, buf.String())
+	}
+}
+
+func TestTimeToShedDegradedEndpoint(t *testing.T) {
+	slow := 0.200
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		Events:        []EnvironmentEvent{{Step: 1000, Endpoint: "b", NewMeanLatency: &slow}},
+		TotalRequests: 3000,
+		Seed:          1,
+	}
+	rr := RunScenario(sc, NewRoundRobinStrategy())
+	if len(rr.Reactions) != 1 || rr.Reactions[0].ShedSteps != -1 || rr.MeanShedSteps != 2000 {
+		t.Fatalf("expected round robin never to shed b, got %+v (mean %.0f)", rr.Reactions, rr.MeanShedSteps)
+	}
+	p2c := RunScenario(sc, NewPowerOfTwoChoicesStrategy(2, 0.2))
+	r := p2c.Reactions[0]
+	if r.Endpoint != "b" || r.Start != 1000 || r.ShedSteps < 10 || r.ShedSteps > 200 || r.ShedSteps%10 != 0 {
+		t.Fatalf("expected P2C to shed b within 200 steps, got %+v", r)
+	}
+	sc.ShedShare = 0.6
+	if r := RunScenario(sc, NewRoundRobinStrategy()); r.Reactions[0].ShedSteps != 10 {
+		t.Fatalf("expected a 60%% threshold to count round robin's half share as shed, got %+v", r.Reactions)
+	}
+}
+
+func TestTimeToReincludeRecoveredEndpoint(t *testing.T) {
+	slow, normal := 0.200, 0.020
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		Events: []EnvironmentEvent{
+			{Step: 500, Endpoint: "b", NewMeanLatency: &slow},
+			{Step: 1000, Endpoint: "b", NewMeanLatency: &normal},
+			{Step: 2500, Endpoint: "a", NewMeanLatency: &slow},
+		},
+		TotalRequests: 3000,
+		Seed:          1,
+	}
+	rr := RunScenario(sc, NewRoundRobinStrategy())
+	if len(rr.Reactions) != 2 || !rr.Reactions[0].Recovered || rr.Reactions[0].ReincludeSteps != 10 {
+		t.Fatalf("expected round robin to take b back right away, got %+v", rr.Reactions)
+	}
+	if r := rr.Reactions[1]; r.Recovered || r.ReincludeSteps != -1 || rr.MeanReincludeSteps != 10 {
+		t.Fatalf("expected a's window to run until the end, got %+v (mean %.0f)", r, rr.MeanReincludeSteps)
+	}
+	// LeastLatency never explores, so it keeps avoiding the recovered b
+	ll := RunScenario(sc, NewLeastLatencyStrategy(3, 0.2))
+	if r := ll.Reactions[0]; r.ReincludeSteps != -1 || ll.MeanReincludeSteps != 2000 {
+		t.Fatalf("expected LeastLatency never to re-include b, got %+v (mean %.0f)", r, ll.MeanReincludeSteps)
+	}
+}
+
+func TestPhaseFairnessOverHealthyEndpoints(t *testing.T) {
+	slow := 0.200
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "c", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		Events:        []EnvironmentEvent{{Step: 900, Endpoint: "c", NewMeanLatency: &slow}},
+		TotalRequests: 1800,
+		Seed:          1,
+	}
+	rr := RunScenario(sc, NewRoundRobinStrategy())
+	for _, p := range rr.Phases {
+		if p.Fairness < 0.999 {
+			t.Fatalf("expected round robin to be fair in every phase, got %+v", rr.Phases)
+		}
+	}
+	// LeastLatency settles on one endpoint
+	ll := RunScenario(sc, NewLeastLatencyStrategy(3, 0.2))
+	if f := ll.Phases[0].Fairness; math.Abs(f-1.0/3) > 0.02 {
+		t.Fatalf("expected a fixated strategy to score about 1/3, got %.3f", f)
+	}
+	if got := jain([]float64{4, 0}); got != 0.5 {
+		t.Fatalf("jain(4, 0) = %v, want 0.5", got)
+	}
+}
+
+func TestShareSeriesAndOscillation(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		TotalRequests: 2000,
+		Seed:          1,
+	}
+	rr := RunScenario(sc, NewRoundRobinStrategy())
+	if len(rr.ShareSeries) != 200 || rr.ShareSeries[0].Step != 10 || rr.ShareSeries[199].Shares["a"] != 0.5 {
+		t.Fatalf("expected a sample every 10 steps, got %d samples starting %+v", len(rr.ShareSeries), rr.ShareSeries[0])
+	}
+	if len(rr.P95Series) != 200 || rr.P95Series[199].Step != 2000 || rr.P95Series[199].LatMS < 20 {
+		t.Fatalf("expected a rolling p95 sample every 10 steps, got %d samples", len(rr.P95Series))
+	}
+	if rr.Oscillation != 0 {
+		t.Fatalf("expected a steady split not to oscillate, got %.3f", rr.Oscillation)
+	}
+	flap := RunScenario(sc, &flapStrategy{Strategy: NewRoundRobinStrategy(), every: 150})
+	if flap.Oscillation < 0.2 {
+		t.Fatalf("expected flapping to oscillate, got %.3f", flap.Oscillation)
+	}
+
+	var buf strings.Builder
+	if err := WriteShareSeriesCSV(&buf, []Results{rr}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+2*200 || lines[0] != "strategy,step,endpoint,share" || lines[len(lines)-1] != "RoundRobin,2000,b,0.5000" {
+		t.Fatalf("unexpected CSV with %d lines, ending %q", len(lines), lines[len(lines)-1])
+	}
+}
+
+// flapStrategy sends all traffic to a, then to b, switching every every
+// picks.
+type flapStrategy struct {
+	Strategy
+	every, n int
+}
+
+func (f *flapStrategy) PickEndpoint(string) (string, error) {
+	f.n++
+	if f.n/f.every%2 == 0 {
+		return "a", nil
+	}
+	return "b", nil
+}
+
+func TestAggregationKeepsPerSeedRuns(t *testing.T) {
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.020, ErrorRate: 0.1}, {Addr: "b", MeanLatencySec: 0.020}},
+		TotalRequests: 500,
+	}
+	seeds := []int64{7, 3}
+	aggs := AggregateMultiSeed(sc, []Strategy{NewRandomStrategy(1)}, seeds)
+	a := aggs[0]
+	if !slices.Equal(a.Seeds, seeds) || len(a.Runs) != 2 {
+		t.Fatalf("expected a run per seed in seed order, got seeds %v and %d runs", a.Seeds, len(a.Runs))
+	}
+	for i, r := range a.Runs {
+		sc.Seed = seeds[i]
+		if r.Seed != seeds[i] || !reflect.DeepEqual(r, RunScenario(sc, NewRandomStrategy(1))) {
+			t.Fatalf("expected run %d to be reproducible from seed %d", i, seeds[i])
+		}
+		if got := pct(r.Success, r.Total); got != a.SuccessPct[i] {
+			t.Fatalf("expected success %.2f%% for seed %d, got %.2f%%", got, seeds[i], a.SuccessPct[i])
+		}
+	}
+	if rows := FormatPerSeedResults(aggs); !strings.Contains(rows, "  seed=7: success=") || !strings.Contains(rows, "  seed=3: success=") {
+		t.Fatalf("expected a row per seed, got:\n%s", rows)
+	}
+}
+
+func TestSeedStats(t *testing.T) {
+	st := seedStats([]float64{4, 1, 3, 2})
+	if st.Mean != 2.5 || st.Median != 2.5 || st.Min != 1 || st.Max != 4 {
+		t.Fatalf("unexpected summary %+v", st)
+	}
+	// Sample standard deviation 1.291, t(3) = 3.182
+	if half := st.CIHigh - st.Mean; math.Abs(half-2.054) > 0.001 || math.Abs(st.Mean-st.CILow-half) > 1e-9 {
+		t.Fatalf("expected a symmetric half-width of 2.054, got [%.3f, %.3f]", st.CILow, st.CIHigh)
+	}
+	if st := seedStats([]float64{5}); st.CILow != 5 || st.CIHigh != 5 || st.Median != 5 {
+		t.Fatalf("expected a single seed to collapse to its value, got %+v", st)
+	}
+}
+
+func TestRankTable(t *testing.T) {
+	aggs := []MultiSeedAggregation{
+		{Strategy: "slow", MeanSuccessPct: 99, MeanP95ms: 80, MeanBadShare: 5, MeanShedSteps: 100, MeanReincludeSteps: 10},
+		{Strategy: "fast", MeanSuccessPct: 99, MeanP95ms: 40, MeanBadShare: 1, MeanShedSteps: 20, MeanReincludeSteps: 50},
+	}
+	got := FormatRankTable(aggs)
+	want := "rank  strategy  success  p95  bad-share  shed  reinclude  mean rank\n" +
+		"1     fast      1        1    1          1     2          1.2\n" +
+		"2     slow      1        2    2          2     1          1.6\n"
+	if got != want {
+		t.Fatalf("unexpected table:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestScoreTable(t *testing.T) {
+	aggs := []MultiSeedAggregation{
+		{Strategy: "slow", MeanSuccessPct: 99, MeanP95ms: 80, MeanBadShare: 5},
+		{Strategy: "fast", MeanSuccessPct: 99, MeanP95ms: 40, MeanBadShare: 1},
+	}
+	o := Objective{"success_pct": 1, "p95_ms": -0.5, "bad_share": -0.25}
+	got := FormatScoreTable(aggs, o, "slow")
+	want := "rank  strategy  score  vs slow\n" +
+		"1     fast      78.75  +21.00\n" +
+		"2     slow      57.75  +0.00\n"
+	if got != want {
+		t.Fatalf("unexpected table:\n%s\nwant:\n%s", got, want)
+	}
+	if got := FormatScoreTable(aggs, o, "missing"); strings.Contains(got, "vs") {
+		t.Fatalf("expected no difference column without the baseline, got:\n%s", got)
+	}
+	var buf strings.Builder
+	c := NewCSVWriter(&buf)
+	if err := c.Scores(aggs, o, "slow"); err != nil {
+		t.Fatal(err)
+	}
+	c.Flush()
+	if !strings.Contains(buf.String(), ",fast,,score_delta,mean,21\n") {
+		t.Fatalf("expected a score_delta row, got:\n%s", buf.String())
+	}
+}
+
+func TestResultsAndAggregationsCSV(t *testing.T) {
+	sc := Scenario{
+		Name:          "two",
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.020}, {Addr: "b", MeanLatencySec: 0.020}},
+		TotalRequests: 100,
+		Seed:          5,
+	}
+	var buf strings.Builder
+	if err := WriteResultsCSV(&buf, []Results{RunScenario(sc, NewRoundRobinStrategy())}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "scenario,strategy,seed,metric,stat,value" ||
+		!slices.Contains(lines, "two,RoundRobin,5,success_pct,value,100") ||
+		!slices.Contains(lines, "two,RoundRobin,5,selection/b,value,50") {
+		t.Fatalf("unexpected results CSV:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	aggs := AggregateMultiSeed(sc, []Strategy{NewRoundRobinStrategy()}, []int64{1, 2})
+	if err := WriteAggregationsCSV(&buf, aggs); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Count(buf.String(), "scenario,strategy") != 1 ||
+		!slices.Contains(lines, "two,RoundRobin,2,total,value,100") ||
+		!slices.Contains(lines, "two,RoundRobin,,success_pct,ci95_low,100") {
+		t.Fatalf("unexpected aggregations CSV:\n%s", buf.String())
+	}
+}
+
+func TestReportJSONSchema(t *testing.T) {
+	slow := 0.100
+	sc := Scenario{
+		Name:          "json",
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.020, Distribution: LogNormal{Sigma: 0.5}}, {Addr: "b", MeanLatencySec: 0.020}},
+		Events:        []EnvironmentEvent{{Step: 50, Endpoint: "b", NewMeanLatency: &slow}},
+		Generators:    []EventGenerator{Outage{Endpoints: []string{"a"}, StartStep: 80, EndStep: 90}},
+		TotalRequests: 100,
+		Seed:          9,
+		Hooks:         &Hooks{OnStep: func(int) {}},
+	}
+	aggs := AggregateMultiSeed(sc, []Strategy{NewRoundRobinStrategy()}, []int64{9})
+	data, err := json.Marshal(Report{Scenario: sc, Seeds: []int64{9}, Aggregations: aggs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		SchemaVersion int `json:"schema_version"`
+		Scenario      struct {
+			Name      string
+			Endpoints []map[string]any
+			Events    []map[string]any
+		}
+		Seeds        []int64
+		Aggregations []struct {
+			Strategy string
+			Metrics  map[string]struct{ Values []float64 }
+			Runs     []map[string]any
+		}
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SchemaVersion != JSONSchemaVersion || doc.Scenario.Name != "json" || !slices.Equal(doc.Seeds, []int64{9}) {
+		t.Fatalf("unexpected header: %s", data)
+	}
+	if d := doc.Scenario.Endpoints[0]["distribution"]; d != "LogNormal{Sigma:0.5}" {
+		t.Fatalf("expected the distribution to be described, got %v", d)
+	}
+	if len(doc.Scenario.Events) != 3 {
+		t.Fatalf("expected the event and the generated outage, got %v", doc.Scenario.Events)
+	}
+	a := doc.Aggregations[0]
+	if a.Strategy != "RoundRobin" || len(a.Metrics["success_pct"].Values) != 1 || len(a.Runs) != 1 {
+		t.Fatalf("unexpected aggregation: %+v", a)
+	}
+	for _, key := range []string{"strategy", "seed", "p95_ms", "percentiles", "phases", "reactions", "histogram"} {
+		if _, ok := a.Runs[0][key]; !ok {
+			t.Fatalf("expected results to have %q: %v", key, a.Runs[0])
+		}
+	}
+}
+
+func TestStepLogStreamsRequestsAndEnvironment(t *testing.T) {
+	down := true
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		Events:        []EnvironmentEvent{{Step: 10, Endpoint: "b", NewDown: &down}},
+		TotalRequests: 20,
+		Seed:          1,
+	}
+	var buf strings.Builder
+	sc.StepLog = &buf
+	res := RunScenario(sc, NewRoundRobinStrategy())
+
+	type record struct {
+		Type      string
+		Step      int
+		Strategy  string
+		Endpoint  string
+		Attempts  int
+		LatencyMS float64 `json:"latency_ms"`
+		Success   bool
+		Env       *struct{ Down, Degraded bool }
+	}
+	var envs, requests []record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		if r.Type == "env" {
+			envs = append(envs, r)
+		} else {
+			requests = append(requests, r)
+		}
+	}
+	if len(envs) != 3 || envs[2].Step != 10 || envs[2].Endpoint != "b" || !envs[2].Env.Down || !envs[2].Env.Degraded {
+		t.Fatalf("expected the initial state of both endpoints and b going down, got %+v", envs)
+	}
+	if len(requests) != 20 {
+		t.Fatalf("expected a record per request, got %d", len(requests))
+	}
+	ok := 0
+	for _, r := range requests {
+		if r.Strategy != "RoundRobin" || r.Attempts != 1 || r.Env == nil {
+			t.Fatalf("unexpected record %+v", r)
+		}
+		if r.Success {
+			ok++
+		} else if r.Step < 10 || r.Endpoint != "b" || !r.Env.Down {
+			t.Fatalf("expected failures only on b while down, got %+v", r)
+		}
+	}
+	if ok != res.Success {
+		t.Fatalf("expected %d successes in the log, got %d", res.Success, ok)
+	}
+}
+
+func TestGoldenBaselineCatchesRegressions(t *testing.T) {
+	sc := Scenario{
+		Name:    "golden",
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.030, JitterSec: 0.002},
+		},
+		TotalRequests: 600,
+	}
+	slow, fast := 0.120, 0.030
+	sc.Events = []EnvironmentEvent{{Step: 200, Endpoint: "b", NewMeanLatency: &slow}, {Step: 400, Endpoint: "b", NewMeanLatency: &fast}}
+	seeds := []int64{1, 2}
+	// Strategies keep state between runs, so every run of the suite
+	// starts from new ones, as every process running it does.
+	strategies := func() []Strategy { return []Strategy{NewRoundRobinStrategy(), NewSwarmRouteAdapter()} }
+	path := filepath.Join(t.TempDir(), "golden.json")
+	if err := NewGolden("suite", seeds, AggregateMultiSeed(sc, strategies(), seeds)).WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	base, err := ReadGolden(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := NewGolden("suite", seeds, AggregateMultiSeed(sc, strategies(), seeds))
+	if regs, err := base.Compare(run, Tolerances{}); err != nil || len(regs) != 0 {
+		t.Fatalf("expected a rerun to reproduce the baseline, got %v %v", regs, err)
+	}
+
+	for i := range run.Entries {
+		if e := &run.Entries[i]; e.Strategy == "SwarmRoute" {
+			e.BadShare += 5
+			e.P95ms *= 1.2
+			e.SuccessPct += 1 // improvements never fail
+		}
+	}
+	regs, err := base.Compare(run, DefaultTolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regs) != 2 || regs[0].Metric != "bad-window share %" || regs[1].Metric != "p95 ms" || regs[0].Strategy != "SwarmRoute" {
+		t.Fatalf("expected bad-share and p95 regressions of SwarmRoute, got %v", regs)
+	}
+	if regs, _ := base.Compare(run, Tolerances{BadSharePts: 10, P95Pct: 50}); len(regs) != 0 {
+		t.Fatalf("expected wider tolerances to pass, got %v", regs)
+	}
+
+	run.Entries = run.Entries[:1]
+	if regs, _ := base.Compare(run, DefaultTolerances); len(regs) != 1 || regs[0].Metric != "missing" {
+		t.Fatalf("expected the missing entry to fail, got %v", regs)
+	}
+	if _, err := base.Compare(NewGolden("suite", []int64{1}, nil), DefaultTolerances); err == nil {
+		t.Fatal("expected different seeds to be rejected")
+	}
+}
+
+func TestDecisionTraceExplainsPicks(t *testing.T) {
+	slow := 0.200
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.030, JitterSec: 0.002},
+			{Addr: "c", MeanLatencySec: 0.040, JitterSec: 0.002},
+		},
+		Events:        []EnvironmentEvent{{Step: 100, Endpoint: "a", NewMeanLatency: &slow}},
+		TotalRequests: 300,
+		Seed:          3,
+	}
+	for _, newStrategy := range []func() Strategy{
+		func() Strategy { return NewSwarmRouteAdapter() },
+		func() Strategy { return NewLeastLatencyStrategy(1, 0.2) },
+	} {
+		want := RunScenario(sc, newStrategy())
+
+		var buf strings.Builder
+		traced := sc
+		traced.Decisions, _ = NewDecisionWriter(&buf, "ndjson")
+		got := RunScenario(traced, newStrategy())
+		if err := traced.Decisions.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got.Success != want.Success || !reflect.DeepEqual(got.Selection, want.Selection) {
+			t.Fatalf("%s: tracing changed the run: %v vs %v", want.Strategy, got.Selection, want.Selection)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != sc.TotalRequests {
+			t.Fatalf("%s: expected a decision per pick, got %d", want.Strategy, len(lines))
+		}
+		for _, line := range lines {
+			var d Decision
+			if err := json.Unmarshal([]byte(line), &d); err != nil {
+				t.Fatal(err)
+			}
+			if d.Strategy != want.Strategy || len(d.Candidates) != 3 {
+				t.Fatalf("unexpected decision %+v", d)
+			}
+			if (d.Step >= 100) != slices.Equal(d.Degraded, []string{"a"}) {
+				t.Fatalf("expected a to be degraded from step 100, got %+v", d)
+			}
+			total, chosen := 0.0, 0.0
+			for _, c := range d.Candidates {
+				total += c.Probability
+				if c.Endpoint == d.Endpoint {
+					chosen = c.Probability
+				}
+			}
+			if math.Abs(total-1) > 1e-9 || chosen == 0 {
+				t.Fatalf("expected probabilities summing to 1 with the chosen endpoint possible, got %+v", d)
+			}
+		}
+	}
+
+	var buf strings.Builder
+	w, _ := NewDecisionWriter(&buf, "csv")
+	w.Write(Decision{Step: 1, Strategy: "S", Service: "svc", Endpoint: "a", Degraded: []string{"b"},
+		Candidates: []CandidateScore{{Endpoint: "a", Score: 2, Probability: 1}, {Endpoint: "b", Excluded: "drained"}}})
+	w.Write(Decision{Step: 2, Strategy: "R", Service: "svc", Attempt: 1, Endpoint: "b"})
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := `step,client,strategy,service,attempt,endpoint,candidate,score,probability,excluded,degraded
+1,0,S,svc,0,a,a,2,1,,false
+1,0,S,svc,0,a,b,0,0,drained,true
+2,0,R,svc,1,b,,,,,
+`
+	if buf.String() != wantCSV {
+		t.Fatalf("unexpected CSV:\n%s", buf.String())
+	}
+	buf.Reset()
+	w, _ = NewDecisionWriter(&buf, "parquet")
+	w.Write(Decision{Step: 1, Strategy: "S", Service: "svc", Endpoint: "a", Candidates: []CandidateScore{{Endpoint: "a", Score: 2, Probability: 1}}})
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.HasPrefix(out, "PAR1") || !strings.HasSuffix(out, "PAR1") || !strings.Contains(out, "candidate") {
+		t.Fatalf("expected a Parquet file, got %q", out)
+	}
+	if _, err := NewDecisionWriter(&buf, "xml"); err == nil {
+		t.Fatal("expected an unknown format to fail")
+	}
+}
+
+func TestStrategyRegistry(t *testing.T) {
+	strategies, err := NewStrategies(DefaultStrategies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range strategies {
+		names = append(names, s.Name())
+	}
+	if want := []string{"Random", "RoundRobin", "PowerOfTwoChoices", "LeastLatency", "SwarmRoute"}; !slices.Equal(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+
+	s, err := NewStrategy("p2c:alpha=0.5,seed=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p2c := s.(*PowerOfTwoChoicesStrategy); p2c.alpha != 0.5 {
+		t.Fatalf("expected alpha 0.5, got %v", p2c.alpha)
+	}
+	var spec StrategySpec
+	if err := json.Unmarshal([]byte(`{"name": "leastlatency", "params": {"alpha": 0.3}}`), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := spec.New(); err != nil || s.(*LeastLatencyStrategy).alpha != 0.3 {
+		t.Fatalf("expected alpha 0.3 from JSON, got %v %v", s, err)
+	}
+	var specs []StrategySpec
+	if err := json.Unmarshal([]byte(`["roundrobin", "swarmroute:preset=aggressive"]`), &specs); err != nil || len(specs) != 2 || specs[1].Params["preset"] != "aggressive" {
+		t.Fatalf("expected flag-form specs in JSON, got %+v %v", specs, err)
+	}
+
+	Register("test-flap", func(p Params) (Strategy, error) {
+		every, err := p.Int("every", 10)
+		return &flapStrategy{Strategy: NewRoundRobinStrategy(), every: int(every)}, err
+	})
+	if !slices.Contains(RegisteredStrategies(), "test-flap") {
+		t.Fatal("expected the registered strategy to be listed")
+	}
+	if s, err := NewStrategy("test-flap:every=5"); err != nil || s.(*flapStrategy).every != 5 {
+		t.Fatalf("expected a flap strategy every 5 steps, got %v %v", s, err)
+	}
+
+	for _, bad := range []string{"nope", "p2c:alpah=0.5", "p2c:alpha=x", "random:seed=1.5", "swarmroute:preset=wild", "p2c:alpha", ":alpha=1"} {
+		if _, err := NewStrategy(bad); err == nil {
+			t.Fatalf("expected %q to fail", bad)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering a name twice to panic")
+		}
+	}()
+	Register("p2c", nil)
+}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	s, err := NewStrategy("wrr:weights=a=3|b=1|c=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddService("svc", []string{"a", "b", "c"})
+	var picks []string
+	for range 8 {
+		addr, _ := s.PickEndpoint("svc")
+		picks = append(picks, addr)
+	}
+	if want := []string{"a", "a", "b", "a", "a", "a", "b", "a"}; !slices.Equal(picks, want) {
+		t.Fatalf("expected the smooth 3:1 sequence %v, got %v", want, picks)
+	}
+	wrr := s.(*WeightedRoundRobinStrategy)
+	if addr, _ := wrr.PickEndpointExcluding("svc", "a", "b"); addr != "c" {
+		t.Fatalf("expected the zero-weight endpoint when it is the only one left, got %s", addr)
+	}
+
+	var spec StrategySpec
+	if err := json.Unmarshal([]byte(`{"name": "wrr", "params": {"weights": {"a": 2}}}`), &spec); err != nil {
+		t.Fatal(err)
+	}
+	s, err = spec.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddService("svc", []string{"a", "b"})
+	counts := map[string]int{}
+	for range 300 {
+		addr, _ := s.PickEndpoint("svc")
+		counts[addr]++
+	}
+	if counts["a"] != 200 || counts["b"] != 100 {
+		t.Fatalf("expected a 2:1 split with b at the default weight, got %v", counts)
+	}
+	if _, err := NewStrategy("wrr:weights=a"); err == nil {
+		t.Fatal("expected a weight without value to fail")
+	}
+}
+
+func TestLeastConnections(t *testing.T) {
+	s := NewLeastConnectionsStrategy()
+	s.AddService("svc", []string{"a", "b", "c"})
+	var picks []string
+	for range 4 {
+		addr, _ := s.PickEndpoint("svc")
+		picks = append(picks, addr)
+	}
+	s.ReportResult("svc", "b", 0.01, true)
+	s.ReportResult("svc", "c", 0.01, true)
+	for range 2 {
+		addr, _ := s.PickEndpoint("svc")
+		picks = append(picks, addr)
+	}
+	if want := []string{"a", "b", "c", "a", "b", "c"}; !slices.Equal(picks, want) {
+		t.Fatalf("expected ties round-robin and the least loaded next, got %v", picks)
+	}
+	if addr, _ := s.PickEndpointExcluding("svc", "b"); addr != "c" {
+		t.Fatalf("expected c with b excluded, got %s", addr)
+	}
+
+	// With overlapping requests, the slow endpoint holds more of them and
+	// gets fewer new ones.
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "fast", MeanLatencySec: 0.010, JitterSec: 0.001},
+			{Addr: "slow", MeanLatencySec: 0.100, JitterSec: 0.001},
+		},
+		TotalRequests: 2000,
+		Seed:          1,
+		Load:          &Load{RPS: 100},
+	}
+	res := RunScenario(sc, NewLeastConnectionsStrategy())
+	if res.Selection["slow"] >= res.Selection["fast"]/2 {
+		t.Fatalf("expected the slow endpoint to get far fewer requests, got %v", res.Selection)
+	}
+	if rr := RunScenario(sc, NewRoundRobinStrategy()); res.P95LatMS >= rr.P95LatMS {
+		t.Fatalf("expected a lower p95 than RoundRobin: %.1fms vs %.1fms", res.P95LatMS, rr.P95LatMS)
+	}
+}
+
+func TestEWMAStrategy(t *testing.T) {
+	s := NewEWMAStrategy(1, 0.5, 5)
+	s.AddService("svc", []string{"a", "b"})
+	s.ReportResult("svc", "a", 0.010, true)
+	s.ReportResult("svc", "b", 0.010, false)
+	for range 10 {
+		if addr, _ := s.PickEndpoint("svc"); addr != "a" {
+			t.Fatalf("expected the failing endpoint to cost more, got %s", addr)
+		}
+		s.ReportResult("svc", "a", 0.010, true)
+	}
+	// Three requests outstanding quadruple a's cost, past b's failure
+	// penalty of 1 + 5×0.5.
+	for range 3 {
+		s.PickEndpoint("svc")
+	}
+	if addr, _ := s.PickEndpoint("svc"); addr != "b" {
+		t.Fatalf("expected pending requests to inflate the cost, got %s", addr)
+	}
+
+	beatsRoundRobin(t, NewEWMAStrategy(1, 0.2, 4))
+}
+
+// beatsRoundRobin runs s against a steady and a flaky endpoint and checks
+// that it sends the flaky one under a quarter of the traffic and succeeds
+// more often than RoundRobin.  It returns s's results for further checks.
+func beatsRoundRobin(t *testing.T, s Strategy) Results {
+	t.Helper()
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "steady", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+			{Addr: "flaky", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.3},
+		},
+		TotalRequests: 2000,
+		Seed:          1,
+	}
+	res, rr := RunScenario(sc, s), RunScenario(sc, NewRoundRobinStrategy())
+	if res.Selection["flaky"] >= res.Total/4 || res.Success <= rr.Success {
+		t.Fatalf("expected %s to avoid the flaky endpoint: %v, %d successes vs %d for RoundRobin", res.Strategy, res.Selection, res.Success, rr.Success)
+	}
+	return res
+}
+
+func TestPeakEWMAStrategy(t *testing.T) {
+	s := NewPeakEWMAStrategy(1, 0.5, 2)
+	s.AddService("svc", []string{"a", "b"})
+	s.ReportResult("svc", "a", 0.010, true)
+	s.ReportResult("svc", "b", 0.012, true)
+	pick := func() string {
+		addr, _ := s.PickEndpoint("svc")
+		s.ReportResult("svc", addr, s.state.Latency["svc"][addr], true)
+		return addr
+	}
+	if addr := pick(); addr != "a" {
+		t.Fatalf("expected the faster endpoint, got %s", addr)
+	}
+	// A spike takes effect at once; halving its distance to 10ms per
+	// success takes six successes to get a below b again.
+	s.ReportResult("svc", "a", 0.100, true)
+	for i := range 6 {
+		if addr := pick(); addr != "b" {
+			t.Fatalf("expected the spike to shed a after %d successes, got %s", i, addr)
+		}
+		s.ReportResult("svc", "a", 0.010, true)
+	}
+	if addr := pick(); addr != "a" {
+		t.Fatalf("expected successes to decay the spike, got %s", addr)
+	}
+	// A failure counts as twice the average, 22.8ms.
+	s.ReportResult("svc", "a", 0.010, false)
+	if got := s.state.Latency["svc"]["a"]; math.Abs(got-0.0228) > 1e-4 {
+		t.Fatalf("expected a failure to double the average, got %.4f", got)
+	}
+
+	beatsRoundRobin(t, NewPeakEWMAStrategy(1, 0.2, 2))
+}
+
+func TestThompsonStrategy(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sum := 0.0
+	for range 20000 {
+		sum += betaSample(rng, 3, 7)
+	}
+	if mean := sum / 20000; math.Abs(mean-0.3) > 0.01 {
+		t.Fatalf("expected Beta(3, 7) draws to average 0.3, got %.3f", mean)
+	}
+
+	if res := beatsRoundRobin(t, NewThompsonStrategy(1, false, 1)); res.Selection["flaky"] >= res.Total/10 {
+		t.Fatalf("expected the flaky endpoint mostly avoided, got %v", res.Selection)
+	}
+	// Success rates alone cannot tell steady from fast; the latency model
+	// can.
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "steady", MeanLatencySec: 0.030, JitterSec: 0.003, ErrorRate: 0.01},
+			{Addr: "fast", MeanLatencySec: 0.010, JitterSec: 0.001, ErrorRate: 0.01},
+		},
+		TotalRequests: 2000,
+		Seed:          1,
+	}
+	res := RunScenario(sc, NewThompsonStrategy(1, true, 1))
+	if res.Selection["fast"] < res.Total*9/10 {
+		t.Fatalf("expected the latency model to favor the fast endpoint, got %v", res.Selection)
+	}
+
+	s, err := NewStrategy("thompson:latency=true,discount=0.99")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts := s.(*ThompsonStrategy); !ts.latency || ts.discount != 0.99 {
+		t.Fatalf("expected the spec's parameters, got latency=%v discount=%v", ts.latency, ts.discount)
+	}
+}
+
+func TestUCBStrategy(t *testing.T) {
+	s := NewUCBStrategy(4, 1, 0.5)
+	s.AddService("svc", []string{"a", "b", "c"})
+	var picks []string
+	for _, ok := range []bool{true, false, true} {
+		addr, _ := s.PickEndpoint("svc")
+		picks = append(picks, addr)
+		s.ReportResult("svc", addr, 0.010, ok)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(picks, want) {
+		t.Fatalf("expected every endpoint tried first, got %v", picks)
+	}
+	if addr, _ := s.PickEndpoint("svc"); addr == "b" {
+		t.Fatal("expected the failed endpoint to score lowest")
+	}
+	// The window holds four reports, so b's failure falls out of it.
+	for _, addr := range []string{"a", "c", "a"} {
+		s.ReportResult("svc", addr, 0.010, true)
+	}
+	if addr, _ := s.PickEndpoint("svc"); addr != "b" {
+		t.Fatalf("expected b retried once out of the window, got %s", addr)
+	}
+	beatsRoundRobin(t, NewUCBStrategy(200, 1, 0.5))
+
+	// The flaky endpoint gets shed, then its share comes back after it
+	// recovers at step 1000.
+	recovered := 0.01
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "steady", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+			{Addr: "flaky", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.5},
+		},
+		Events:        []EnvironmentEvent{{Step: 1000, Endpoint: "flaky", NewErrorRate: &recovered}},
+		TotalRequests: 3000,
+		Seed:          1,
+		Phases:        []int{1000},
+	}
+	res := RunScenario(sc, NewUCBStrategy(200, 1, 0.5))
+	if bad, good := res.Phases[0], res.Phases[1]; bad.Success*100 <= bad.Total*85 || good.Success*100 <= good.Total*97 {
+		t.Fatalf("expected the flaky endpoint shed while failing: %+v", res.Phases)
+	}
+	if res.Selection["flaky"] < res.Total/4 {
+		t.Fatalf("expected the flaky endpoint back after recovering, got %v", res.Selection)
+	}
+}
+
+func TestEpsilonGreedyStrategy(t *testing.T) {
+	s := NewEpsilonGreedyStrategy(1, 0.2, 0, 10)
+	s.AddService("svc", []string{"a", "b"})
+	if addr, _ := s.PickEndpoint("svc"); addr != "a" {
+		t.Fatalf("expected unseen endpoints first, got %s", addr)
+	}
+	s.ReportResult("svc", "a", 0.030, true)
+	s.ReportResult("svc", "b", 0.010, true)
+	if addr, _ := s.PickEndpoint("svc"); addr != "b" {
+		t.Fatalf("expected the lowest average without exploration, got %s", addr)
+	}
+
+	s = NewEpsilonGreedyStrategy(1, 0.2, 0.4, 10)
+	s.AddService("svc", nil)
+	for range 20 {
+		s.ReportResult("svc", "a", 0.010, true)
+	}
+	if got := s.Epsilon("svc"); math.Abs(got-0.1) > 1e-12 {
+		t.Fatalf("expected epsilon halved every 10 reports, got %v", got)
+	}
+	beatsRoundRobin(t, NewEpsilonGreedyStrategy(1, 0.2, 0.2, 200))
+
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "fast", MeanLatencySec: 0.010, JitterSec: 0.001},
+			{Addr: "slow", MeanLatencySec: 0.050, JitterSec: 0.005},
+		},
+		TotalRequests: 2000,
+		Seed:          1,
+	}
+	res := RunScenario(sc, NewEpsilonGreedyStrategy(1, 0.2, 0.2, 200))
+	if slow := res.Selection["slow"]; slow == 0 || slow >= res.Total/20 {
+		t.Fatalf("expected a little, decaying exploration of the slow endpoint, got %v", res.Selection)
+	}
+}
+
+func TestSoftmaxStrategy(t *testing.T) {
+	s := NewSoftmaxStrategy(1, 0.2, 0.01)
+	s.AddService("svc", []string{"a", "b"})
+	s.ReportResult("svc", "a", 0.010, true)
+	s.ReportResult("svc", "b", 0.020, true)
+	_, scores, err := s.PickEndpointExplained("svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 1 / (1 + math.Exp(-1)); math.Abs(scores[0].Probability-want) > 1e-9 || math.Abs(scores[0].Probability+scores[1].Probability-1) > 1e-9 {
+		t.Fatalf("expected a 10ms slower endpoint e times less likely at 10ms, got %+v", scores)
+	}
+	counts := make(map[string]int)
+	for range 10000 {
+		addr, _ := s.PickEndpoint("svc")
+		counts[addr]++
+	}
+	if got := float64(counts["a"]) / 10000; math.Abs(got-scores[0].Probability) > 0.02 {
+		t.Fatalf("expected picks to follow the probabilities, got %.3f for a", got)
+	}
+	beatsRoundRobin(t, NewSoftmaxStrategy(1, 0.2, 0.01))
+
+	// 40ms slower is four temperatures: e^-4 ≈ 2% of the picks.
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "fast", MeanLatencySec: 0.010, JitterSec: 0.001},
+			{Addr: "slow", MeanLatencySec: 0.050, JitterSec: 0.001},
+		},
+		TotalRequests: 2000,
+		Seed:          1,
+	}
+	res := RunScenario(sc, NewSoftmaxStrategy(1, 0.2, 0.01))
+	if slow := res.Selection["slow"]; slow == 0 || slow >= res.Total/20 {
+		t.Fatalf("expected the slow endpoint to keep a sliver of traffic, got %v", res.Selection)
+	}
+}
+
+func TestOutlierEjection(t *testing.T) {
+	s := NewOutlierEjectionStrategy(4, 0.5, 6, 0.5)
+	s.AddService("svc", []string{"a", "b", "c", "d"})
+	s.ReportResult("svc", "b", 0.010, false)
+	s.ReportResult("svc", "b", 0.010, false)
+	s.ReportResult("svc", "c", 0.010, false)
+	s.ReportResult("svc", "c", 0.010, false)
+	if got := s.Ejected("svc"); !slices.Equal(got, []string{"b", "c"}) {
+		t.Fatalf("expected b and c ejected, got %v", got)
+	}
+	// Half of the endpoints are out, so d is not ejected.
+	s.ReportResult("svc", "d", 0.010, false)
+	s.ReportResult("svc", "d", 0.010, false)
+	var picks []string
+	for range 6 {
+		addr, _ := s.PickEndpoint("svc")
+		picks = append(picks, addr)
+	}
+	if want := []string{"a", "d", "a", "d", "a", "b"}; !slices.Equal(picks, want) {
+		t.Fatalf("expected b and c skipped for 6 picks, got %v", picks)
+	}
+	// A second ejection in a row lasts twice as long.
+	s.ReportResult("svc", "b", 0.010, false)
+	s.ReportResult("svc", "b", 0.010, false)
+	if until := s.state.EjectedUntil["svc"]["b"]; until != 6+12 {
+		t.Fatalf("expected b out until pick 18, got %d", until)
+	}
+
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+			{Addr: "flaky", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.8},
+		},
+		TotalRequests: 3000,
+		Seed:          1,
+	}
+	res, rr := RunScenario(sc, NewOutlierEjectionStrategy(20, 0.5, 200, 0.5)), RunScenario(sc, NewRoundRobinStrategy())
+	if res.Selection["flaky"] >= res.Total/10 || res.Success <= rr.Success {
+		t.Fatalf("expected the flaky endpoint ejected: %v, %d successes vs %d for RoundRobin", res.Selection, res.Success, rr.Success)
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	s := NewConsistentHashStrategy(100, math.Inf(1))
+	s.AddService("svc", []string{"a", "b", "c"})
+	owners := make(map[string]string)
+	for i := range 1000 {
+		key := fmt.Sprint("k", i)
+		owners[key], _ = s.PickEndpointForKey("svc", key)
+		s.ReportResult("svc", owners[key], 0.01, true)
+	}
+	// A joining endpoint only takes keys, about a quarter of them.
+	s.AddService("svc", []string{"a", "b", "c", "d"})
+	moved := 0
+	for key, owner := range owners {
+		addr, _ := s.PickEndpointForKey("svc", key)
+		s.ReportResult("svc", addr, 0.01, true)
+		if addr != owner {
+			if addr != "d" {
+				t.Fatalf("expected %s to stay on %s or move to d, got %s", key, owner, addr)
+			}
+			moved++
+		}
+	}
+	if moved < 150 || moved > 350 {
+		t.Fatalf("expected about 250 of 1000 keys to move, got %d", moved)
+	}
+
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "c", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		TotalRequests: 3000,
+		Seed:          1,
+		Keys:          &KeySpace{Count: 50},
+	}
+	if res := RunScenario(sc, NewConsistentHashStrategy(100, 0.25)); res.KeyAffinity != 1 {
+		t.Fatalf("expected every repeat key on the same endpoint, got %.3f", res.KeyAffinity)
+	}
+	if res := RunScenario(sc, NewRoundRobinStrategy()); res.KeyAffinity > 0.5 {
+		t.Fatalf("expected round-robin to ignore keys, got %.3f", res.KeyAffinity)
+	}
+
+	// A hot key overloads its endpoint unless loads are bounded.
+	sc.Keys = &KeySpace{Count: 100, Skew: 1.5}
+	sc.Load = &Load{RPS: 200}
+	peak := func(r Results) int { return slices.Max(slices.Collect(maps.Values(r.Selection))) }
+	unbounded := RunScenario(sc, NewConsistentHashStrategy(100, math.Inf(1)))
+	bounded := RunScenario(sc, NewConsistentHashStrategy(100, 0.25))
+	if peak(bounded) >= peak(unbounded) || bounded.KeyAffinity >= unbounded.KeyAffinity {
+		t.Fatalf("expected bounded loads to trade affinity for balance: peak %d vs %d, affinity %.3f vs %.3f",
+			peak(bounded), peak(unbounded), bounded.KeyAffinity, unbounded.KeyAffinity)
+	}
+}
+
+func TestC3Strategy(t *testing.T) {
+	s := NewC3Strategy(0.5, 1)
+	s.AddService("svc", []string{"a", "b"})
+	s.ReportFeedback("svc", "a", ServerFeedback{QueueLen: 0, ServiceTimeSec: 0.010})
+	s.ReportResult("svc", "a", 0.010, true)
+	s.ReportFeedback("svc", "b", ServerFeedback{QueueLen: 0, ServiceTimeSec: 0.015})
+	s.ReportResult("svc", "b", 0.015, true)
+	// a scores 10ms idle, 10ms - 10ms + 2³·10ms = 80ms with a request
+	// outstanding, past b's 15ms.
+	var picks []string
+	for range 2 {
+		addr, _ := s.PickEndpoint("svc")
+		picks = append(picks, addr)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(picks, want) {
+		t.Fatalf("expected the outstanding request to push the next pick to b, got %v", picks)
+	}
+	if got := s.Score("svc", "a"); math.Abs(got-0.080) > 1e-12 {
+		t.Fatalf("expected a cubic queue penalty of 80ms, got %v", got)
+	}
+
+	// Under load, latency alone herds onto the fastest endpoint past its
+	// capacity; the queue term spreads the load first.
+	sc := Scenario{
+		Service:       "svc",
+		TotalRequests: 5000,
+		Seed:          1,
+		Load:          &Load{RPS: 600, Poisson: true},
+	}
+	for i, lat := range []float64{0.020, 0.025, 0.030, 0.035} {
+		sc.Endpoints = append(sc.Endpoints, EndpointSpec{Addr: fmt.Sprint("p", i), MeanLatencySec: lat, JitterSec: 0.2 * lat, Capacity: 8})
+	}
+	c3, ll := RunScenario(sc, NewC3Strategy(0.2, 1)), RunScenario(sc, NewLeastLatencyStrategy(1, 0.2))
+	if c3.OverloadShare >= ll.OverloadShare || c3.P95LatMS >= ll.P95LatMS {
+		t.Fatalf("expected less overload and a lower p95 than LeastLatency: %.3f vs %.3f, %.1fms vs %.1fms",
+			c3.OverloadShare, ll.OverloadShare, c3.P95LatMS, ll.P95LatMS)
+	}
+}
+
+func TestPowerOfKChoices(t *testing.T) {
+	s := NewPowerOfKChoicesStrategy(3, 1, 0.5, 4)
+	s.AddService("svc", []string{"a", "b", "c"})
+	if s.Name() != "PowerOf3Choices" {
+		t.Fatalf("unexpected name %s", s.Name())
+	}
+	s.ReportResult("svc", "a", 0.010, false)
+	s.ReportResult("svc", "b", 0.020, true)
+	s.ReportResult("svc", "c", 0.030, true)
+	// Sampling all three, a's failure triples its 10ms cost, past b's.
+	for range 10 {
+		if addr, _ := s.PickEndpoint("svc"); addr != "b" {
+			t.Fatalf("expected the failure penalty to make b cheapest, got %s", addr)
+		}
+	}
+
+	// Latency alone keeps picking the fast but failing endpoint.
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.030, JitterSec: 0.003, ErrorRate: 0.01},
+			{Addr: "b", MeanLatencySec: 0.030, JitterSec: 0.003, ErrorRate: 0.01},
+			{Addr: "flaky", MeanLatencySec: 0.010, JitterSec: 0.001, ErrorRate: 0.3},
+		},
+		TotalRequests: 2000,
+		Seed:          1,
+	}
+	p2c, aware := RunScenario(sc, NewPowerOfTwoChoicesStrategy(1, 0.2)), RunScenario(sc, NewPowerOfKChoicesStrategy(3, 1, 0.2, 10))
+	if aware.Selection["flaky"] >= p2c.Selection["flaky"] || aware.Success <= p2c.Success {
+		t.Fatalf("expected failures to steer picks away from flaky: %v vs %v, %d vs %d successes", aware.Selection, p2c.Selection, aware.Success, p2c.Success)
+	}
+	if _, err := NewStrategy("p2c:k=3,penalty=10"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSlowThresholdParity(t *testing.T) {
+	ll := NewLeastLatencyStrategy(1, 0.2)
+	ll.SetSlowThresholdSec(0.050)
+	ll.AddService("svc", []string{"a", "b"})
+	ll.ReportResult("svc", "a", 0.060, true)
+	ll.ReportResult("svc", "b", 0.040, true)
+	if got := ll.ewma["svc"]["a"]; math.Abs(got-(0.060+failurePenaltySec)) > 1e-12 {
+		t.Fatalf("expected a slow success to carry the failure penalty, got %v", got)
+	}
+	if got := ll.ewma["svc"]["b"]; got != 0.040 {
+		t.Fatalf("expected a fast success unchanged, got %v", got)
+	}
+
+	s, err := NewStrategy("ewma:slow=0.05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := s.(*EWMAStrategy)
+	e.AddService("svc", []string{"a"})
+	e.ReportResult("svc", "a", 0.060, true)
+	if got := e.state.Failures["svc"]["a"]; got != e.alpha {
+		t.Fatalf("expected a slow success to count as a failure, got rate %v", got)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	grid, err := ParseParamGrid("base_weight=0.01|1000 req_evap_rate=0.001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ParamGrid{"base_weight": {0.01, 1000}, "req_evap_rate": {0.001}}); !reflect.DeepEqual(grid, want) {
+		t.Fatalf("unexpected grid %v", grid)
+	}
+	slow, bad := 0.2, 0.3
+	sc := Scenario{
+		Name:    "degrade",
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+		},
+		Events:        []EnvironmentEvent{{Step: 500, Endpoint: "b", NewMeanLatency: &slow, NewErrorRate: &bad}},
+		TotalRequests: 2000,
+	}
+	var progress []int
+	res, err := Sweep{Grid: grid, Scenarios: []Scenario{sc}, Seeds: []int64{1, 2}, Parallel: 2,
+		OnDone: func(done, total int) { progress = append(progress, done) }}.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Points) != 2 || !slices.Equal(progress, []int{1, 2}) {
+		t.Fatalf("expected both configurations run, got %d points, progress %v", len(res.Points), progress)
+	}
+	// A huge base weight drowns the pheromones, so picks stay uniform.
+	if best := res.Best["bad_share"]; best.Params["base_weight"] != 0.01 || best.Config.ReqEvapRate != 0.001 {
+		t.Fatalf("expected the pheromones to shed b, got %v", best.Params)
+	}
+	if len(res.Pareto) == 0 || res.Pareto[0].Params["base_weight"] != 0.01 {
+		t.Fatalf("expected the low base weight on the Pareto front, got %+v", res.Pareto)
+	}
+	if _, err := (Sweep{Grid: ParamGrid{"no_such": {1}}, Scenarios: []Scenario{sc}, Seeds: []int64{1}}).Run(); err == nil {
+		t.Fatal("expected an unknown parameter to fail")
+	}
+}
+
+func TestTune(t *testing.T) {
+	space, err := ParseParamSpace("base_weight=0.01:1000:log explore_every_n=50:500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ParamSpace{"base_weight": {0.01, 1000, true}, "explore_every_n": {50, 500, false}}); !reflect.DeepEqual(space, want) {
+		t.Fatalf("unexpected space %v", space)
+	}
+	obj, err := ParseObjective("bad_share=-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, bad := 0.2, 0.3
+	sc := Scenario{
+		Name:    "degrade",
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.01},
+		},
+		Events:        []EnvironmentEvent{{Step: 500, Endpoint: "b", NewMeanLatency: &slow, NewErrorRate: &bad}},
+		TotalRequests: 2000,
+	}
+	for _, tpe := range []bool{false, true} {
+		var progress []int
+		res, err := Tune{Space: space, Objective: obj, Scenarios: []Scenario{sc}, Seeds: []int64{1}, Budget: 14, Seed: 3, TPE: tpe,
+			OnDone: func(done, total int, best TuneTrial) { progress = append(progress, done) }}.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Trials) != 14 || len(progress) != 14 || progress[13] != 14 {
+			t.Fatalf("tpe=%v: expected the whole budget run, got %d trials, progress %v", tpe, len(res.Trials), progress)
+		}
+		for _, tr := range res.Trials {
+			if w, n := tr.Params["base_weight"], tr.Params["explore_every_n"]; w < 0.01 || w > 1000 || n < 50 || n > 500 || n != math.Round(n) {
+				t.Fatalf("tpe=%v: trial outside the space: %v", tpe, tr.Params)
+			}
+			if tr.Score != -tr.BadShare || tr.Score > res.Best.Score {
+				t.Fatalf("tpe=%v: trial %v scored %v, best %v", tpe, tr.Params, tr.Score, res.Best.Score)
+			}
+		}
+		// A huge base weight drowns the pheromones, so picks stay uniform.
+		if res.Best.Config.BaseWeight > 1 {
+			t.Fatalf("tpe=%v: expected a low base weight to win, got %v", tpe, res.Best.Params)
+		}
+	}
+
+	// The winner is a preset the "swarmroute" strategy can load.
+	res, err := Tune{Space: ParamSpace{"base_weight": {0.01, 0.01, false}}, Scenarios: []Scenario{sc}, Seeds: []int64{1}, Budget: 1}.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "preset.json")
+	var buf strings.Builder
+	if err := WritePreset(&buf, res.Best.Config); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(buf.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStrategy("swarmroute:config=" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.(*SwarmRouteAdapter).SwarmRoute().Config(); got != res.Best.Config {
+		t.Fatalf("preset loaded as %+v, want %+v", got, res.Best.Config)
+	}
+	if _, err := (Tune{Space: ParamSpace{"no_such": {0, 1, false}}, Scenarios: []Scenario{sc}, Seeds: []int64{1}, Budget: 1}).Run(); err == nil {
+		t.Fatal("expected an unknown parameter to fail")
+	}
+	if _, err := ParseObjective("latency=1"); err == nil {
+		t.Fatal("expected an unknown metric to fail")
+	}
+}
+
+func TestFuzz(t *testing.T) {
+	a, b := RandomScenario(7), RandomScenario(7)
+	if !reflect.DeepEqual(a, b) || a.Name != "fuzz-7" || len(a.Endpoints) < 2 || len(a.Endpoints) > 10 {
+		t.Fatalf("expected the same valid scenario for a seed, got %+v and %+v", a, b)
+	}
+	if reflect.DeepEqual(a.Endpoints, RandomScenario(8).Endpoints) {
+		t.Fatal("expected different seeds to draw different scenarios")
+	}
+	specs, err := ParseStrategySpecs("swarmroute random")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases, err := Fuzz{Seed: 1, Count: 4, Strategies: specs, Seeds: []int64{1}, Parallel: 2}.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 4 || !slices.IsSortedFunc(cases, func(a, b FuzzCase) int { return cmp.Compare(b.Margin, a.Margin) }) {
+		t.Fatalf("expected 4 cases by decreasing margin, got %+v", cases)
+	}
+	for _, c := range cases {
+		if c.Best != "Random" || c.Margin != c.BestScore-c.TargetScore || len(c.Aggregations) != 2 || len(c.Patterns) < 2 {
+			t.Fatalf("unexpected case %+v", c)
+		}
+		if sc := RandomScenario(c.Seed); sc.Name != c.Scenario.Name {
+			t.Fatalf("expected seed %d to recreate %s, got %s", c.Seed, c.Scenario.Name, sc.Name)
+		}
+	}
+	if _, err := (Fuzz{Count: 1, Strategies: specs[1:], Seeds: []int64{1}}).Run(); err == nil {
+		t.Fatal("expected a fuzz without SwarmRoute to fail")
+	}
+}
+
+func TestTraceReplay(t *testing.T) {
+	logfmt := `time="[14/Nov/2023:22:13:20 +0000]" upstream="10.0.0.9:80, b" rt=0.050 status=200
+time="[14/Nov/2023:22:13:21 +0000]" upstream=- rt=0.001 status=404`
+	recs, err := ReadAccessLog(strings.NewReader(logfmt), AccessLogFormat{Format: "logfmt", TimeField: "time", LatencyField: "rt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Upstream != "b" || recs[0].LatencySec != 0.05 || !recs[0].Success() || recs[0].Time.Unix() != 1700000000 {
+		t.Fatalf("unexpected logfmt records %+v", recs)
+	}
+
+	// a is steady; b serves 500s at 200ms in the second half.
+	var csvLog strings.Builder
+	csvLog.WriteString("status,upstream,latency,timestamp\n")
+	for i := range 400 {
+		up, lat, status := "a", "20ms", 200
+		if i%2 == 1 {
+			up = "b"
+			if i >= 200 {
+				lat, status = "200ms", 500
+			}
+		}
+		fmt.Fprintf(&csvLog, "%d,%s,%s,%.2f\n", status, up, lat, 1700000000+float64(i)*0.05)
+	}
+	recs, err = ReadAccessLog(strings.NewReader(csvLog.String()), AccessLogFormat{Format: "csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTrace(recs)
+	sc := tr.Scenario(5)
+	if sc.TotalRequests != 400 || len(sc.Endpoints) != 2 || math.Abs(sc.Endpoints[1].MeanLatencySec-0.02) > 1e-9 || sc.Endpoints[1].ErrorRate != 0 {
+		t.Fatalf("unexpected scenario %+v", sc)
+	}
+	var bad *EnvironmentEvent
+	for i, ev := range sc.Events {
+		if ev.Endpoint == "b" && *ev.NewErrorRate == 1 {
+			bad = &sc.Events[i]
+			break
+		}
+	}
+	if bad == nil || bad.Step != 201 {
+		t.Fatalf("expected b to fail from step 201, got events %+v", sc.Events)
+	}
+
+	sc.Trace = tr
+	r := RunScenario(sc, NewRoundRobinStrategy())
+	// Round robin sends every other request to b, whose replayed outcomes
+	// fail from the middle of the trace on, give or take the neighbors
+	// drawn around the switch.
+	if r.Failure < 80 || r.Failure > 120 {
+		t.Fatalf("expected about a quarter of requests to fail on replay, got %d", r.Failure)
+	}
+	if got := RunScenario(sc, NewRoundRobinStrategy()); got.Failure != r.Failure || got.P95LatMS != r.P95LatMS {
+		t.Fatal("expected replays to be reproducible")
+	}
+	for _, w := range []float64{0, -1} {
+		if whole := tr.Scenario(w); len(whole.Events) != 0 || whole.Endpoints[1].ErrorRate == 0 {
+			t.Fatalf("window %v: expected the trace in a single window, got %d events and %+v", w, len(whole.Events), whole.Endpoints)
+		}
+	}
+	if _, err := ReadAccessLog(strings.NewReader("x=1"), AccessLogFormat{Format: "logfmt"}); err == nil {
+		t.Fatal("expected a record without the fields to fail")
+	}
+}
+
+// TestPluginHelperProcess is the strategy process of TestProcessStrategy:
+// a round robin speaking the plugin protocol.
+func TestPluginHelperProcess(t *testing.T) {
+	if os.Getenv("SWARMROUTE_PLUGIN_HELPER") != "1" {
+		return
+	}
+	eps, next := make(map[string][]string), 0
+	in := bufio.NewScanner(os.Stdin)
+	for in.Scan() {
+		var m pluginMessage
+		if err := json.Unmarshal(in.Bytes(), &m); err != nil {
+			os.Exit(2)
+		}
+		switch m.Op {
+		case "hello":
+			fmt.Println(`{"name":"HelperRoundRobin"}`)
+		case "add_service":
+			eps[m.Service] = m.Endpoints
+		case "pick":
+			candidates := slices.DeleteFunc(slices.Clone(eps[m.Service]), func(e string) bool { return slices.Contains(m.Exclude, e) })
+			if len(candidates) == 0 {
+				candidates = eps[m.Service]
+			}
+			if len(candidates) == 0 {
+				fmt.Println(`{"error":"no endpoints"}`)
+				continue
+			}
+			b, _ := json.Marshal(pluginReply{Endpoint: candidates[next%len(candidates)]})
+			fmt.Println(string(b))
+			next++
+		}
+	}
+	os.Exit(0)
+}
+
+func TestProcessStrategy(t *testing.T) {
+	t.Setenv("SWARMROUTE_PLUGIN_HELPER", "1")
+	p, err := NewProcessStrategy(os.Args[0], "-test.run=^TestPluginHelperProcess$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := Scenario{
+		Service:       "svc",
+		Endpoints:     []EndpointSpec{{Addr: "a", MeanLatencySec: 0.02, ErrorRate: 0.1}, {Addr: "b", MeanLatencySec: 0.03}},
+		TotalRequests: 500,
+		Retry:         &RetryPolicy{MaxRetries: 1},
+		Seed:          5,
+	}
+	got, want := RunScenario(sc, p), RunScenario(sc, NewRoundRobinStrategy())
+	if got.Strategy != "HelperRoundRobin" || got.Success != want.Success || !maps.Equal(got.Selection, want.Selection) {
+		t.Fatalf("expected the process to route like round robin, got %+v want %+v", got, want)
+	}
+	if _, err := p.PickEndpoint("nope"); err == nil || err.Error() != "no endpoints" {
+		t.Fatalf("expected the process's error, got %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.PickEndpoint("svc"); err == nil {
+		t.Fatal("expected a pick after Close to fail")
+	}
+	if _, err := NewStrategy("exec"); err == nil {
+		t.Fatal("expected exec without cmd to fail")
+	}
+}
+
+func TestSwarmRouteAdapterOptions(t *testing.T) {
+	a, err := NewSwarmRouteAdapterWithOptions(WithParam("base_weight", 0.05), WithConfig(lib.AggressiveConfig()), WithLabel("tight"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := lib.AggressiveConfig()
+	want.BaseWeight = 0.05
+	if a.Name() != "SwarmRoute-tight" || a.SwarmRoute().Config() != want {
+		t.Fatalf("unexpected variant %s: %+v", a.Name(), a.SwarmRoute().Config())
+	}
+	if _, err := NewSwarmRouteAdapterWithOptions(WithParam("base_wieght", 0.05)); err == nil {
+		t.Fatal("expected an unknown parameter to fail")
+	}
+	if _, err := NewSwarmRouteAdapterWithOptions(WithParam("base_weight", -1)); err == nil {
+		t.Fatal("expected an invalid configuration to fail")
+	}
+	spec, err := ParseSwarmRouteVariant("cautious:preset=conservative,explore_every_n=100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		spec StrategySpec
+		name string
+	}{
+		{spec, "SwarmRoute-cautious"},
+		{StrategySpec{Name: "swarmroute"}, "SwarmRoute"},
+		{StrategySpec{Name: "swarmroute", Params: Params{"preset": "aggressive"}}, "SwarmRoute-aggressive"},
+		{StrategySpec{Name: "swarmroute", Params: Params{"autotune": true}}, "SwarmRoute-autotune"},
+	} {
+		s, err := c.spec.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Name() != c.name {
+			t.Fatalf("%+v: got %s, want %s", c.spec, s.Name(), c.name)
+		}
+	}
+	if _, err := ParseSwarmRouteVariant("x:label=y"); err == nil {
+		t.Fatal("expected a label parameter to fail")
+	}
+}
+
+// reportHook wraps a Strategy and calls fn for every reported result.
+type reportHook struct {
+	Strategy
+	fn func(endpoint string, latencySec float64, success bool)
+}
+
+func (h *reportHook) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	h.fn(endpoint, latencySec, success)
+	h.Strategy.ReportResult(service, endpoint, latencySec, success)
+}
+
+// TestCapacityPunishesHerding checks that with limited endpoint capacity,
+// sending everything to the fastest box backfires: its queueing delay
+// exceeds the latency of spreading the load.
+func TestCapacityPunishesHerding(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "fast", MeanLatencySec: 0.020, JitterSec: 0.002, Capacity: 2},
+			{Addr: "slow", MeanLatencySec: 0.040, JitterSec: 0.004, Capacity: 8},
+		},
+		TotalRequests: 4000,
+		Seed:          11,
+		Load:          &Load{RPS: 200, Poisson: true},
+	}
+	herd := RunScenario(sc, pinnedStrategy{NewRoundRobinStrategy(), "fast"})
+	spread := RunScenario(sc, NewRoundRobinStrategy())
+	if herd.P95LatMS <= spread.P95LatMS {
+		t.Fatalf("expected herding to cost latency under capacity limits: herd p95=%.1fms spread p95=%.1fms", herd.P95LatMS, spread.P95LatMS)
+	}
+	if herd.OverloadShare <= spread.OverloadShare || herd.OverloadShare < 0.5 {
+		t.Fatalf("expected herding to expose most attempts to overload: herd=%.2f spread=%.2f", herd.OverloadShare, spread.OverloadShare)
+	}
+
+	sc.Endpoints[0].Capacity, sc.Endpoints[1].Capacity = 0, 0
+	if r := RunScenario(sc, pinnedStrategy{NewRoundRobinStrategy(), "fast"}); r.P95LatMS >= spread.P95LatMS {
+		t.Fatalf("expected unlimited capacity to reward the fast endpoint: p95=%.1fms", r.P95LatMS)
+	} else if r.OverloadShare != 0 {
+		t.Fatalf("expected no overload without capacity limits, got %.2f", r.OverloadShare)
+	}
+}
+
+// pinnedStrategy always picks the same endpoint.
+type pinnedStrategy struct {
+	Strategy
+	endpoint string
+}
+
+func (p pinnedStrategy) PickEndpoint(string) (string, error) { return p.endpoint, nil }
+
+// TestLatencyDistributionsKeepMeanAndShapeTails checks that every
+// distribution honors the mean while the tails differ as advertised.
+func TestLatencyDistributionsKeepMeanAndShapeTails(t *testing.T) {
+	const mean, jitter, n = 0.040, 0.012, 200000
+	p99 := make(map[string]float64)
+	for name, d := range map[string]LatencyDistribution{
+		"gaussian":  Gaussian{},
+		"lognormal": LogNormal{Sigma: 0.8},
+		"pareto":    Pareto{Alpha: 1.8},
+		"bimodal":   Bimodal{SlowProb: 0.05, SlowFactor: 10},
+	} {
+		rng := rand.New(rand.NewSource(1))
+		xs := make([]float64, n)
+		sum := 0.0
+		for i := range xs {
+			xs[i] = d.Sample(rng, mean, jitter)
+			sum += xs[i]
+		}
+		tol := 0.02 // the heavy Pareto tail converges slowly
+		if name == "pareto" {
+			tol = 0.1
+		}
+		if got := sum / n; math.Abs(got/mean-1) > tol {
+			t.Fatalf("%s: mean %.4f, want %.4f", name, got, mean)
+		}
+		sort.Float64s(xs)
+		p99[name] = xs[n*99/100]
+	}
+	if !(p99["gaussian"] < p99["lognormal"] && p99["lognormal"] < p99["pareto"]) || p99["bimodal"] < 3*mean {
+		t.Fatalf("unexpected p99 ordering: %v", p99)
+	}
+}
+
+// TestRetryStormScenario checks that the canned retry storm separates a
+// balancer that keeps feeding the slow endpoint from one that shifts away:
+// round robin stays overloaded after the trigger is gone, power of two
+// choices recovers.
+func TestRetryStormScenario(t *testing.T) {
+	sc := RetryStormScenario(2)
+	rr := RunScenario(sc, NewRoundRobinStrategy())
+	if len(rr.Phases) != 3 || rr.Phases[2].Start != 12000 {
+		t.Fatalf("expected phases split at the trigger, got %+v", rr.Phases)
+	}
+	if after := rr.Phases[2]; pct(after.Success, after.Total) > 50 || rr.RetryAmplification < 2 {
+		t.Fatalf("expected round robin to stay in the retry storm: after=%+v amplification=%.2f", after, rr.RetryAmplification)
+	}
+	p2c := RunScenario(sc, NewPowerOfTwoChoicesStrategy(2, 0.2))
+	if after := p2c.Phases[2]; pct(after.Success, after.Total) < 99 {
+		t.Fatalf("expected power of two choices to recover after the trigger: %+v", after)
+	}
+}
+
+// TestJoiningEndpointWarmsUp checks that an endpoint joining mid-run is
+// offered to the strategy only from its JoinStep and starts slow.
+func TestJoiningEndpointWarmsUp(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.001},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.001, JoinStep: 100, WarmUp: &WarmUp{Requests: 100}},
+		},
+		TotalRequests: 600,
+		Seed:          1,
+	}
+	var bLat []float64
+	step := 0
+	s := &reportHook{Strategy: NewRoundRobinStrategy(), fn: func(endpoint string, latencySec float64, success bool) {
+		if endpoint == "b" {
+			if step < 100 {
+				t.Fatalf("b selected at step %d before joining", step)
+			}
+			bLat = append(bLat, latencySec)
+		}
+		step++
+	}}
+	res := RunScenario(sc, s)
+	if len(res.Phases) != 2 || res.Phases[1].Start != 100 {
+		t.Fatalf("expected a phase starting at the join, got %+v", res.Phases)
+	}
+	if len(bLat) != 250 {
+		t.Fatalf("expected b to get half of the requests after joining, got %d", len(bLat))
+	}
+	if bLat[0] < 0.055 || bLat[50] < 0.035 || bLat[50] > 0.045 || bLat[200] > 0.025 {
+		t.Fatalf("unexpected warm-up curve: first=%.3f mid=%.3f warm=%.3f", bLat[0], bLat[50], bLat[200])
+	}
+}
+
+// TestMultiSeedResetsStrategies checks that a strategy instance reused
+// across seeds starts every run afresh: AddService keeps learned state for
+// joining endpoints, so without a reset each run would inherit the last.
+func TestMultiSeedResetsStrategies(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, ErrorRate: 0.05},
+			{Addr: "b", MeanLatencySec: 0.030, ErrorRate: 0.01},
+		},
+		TotalRequests: 2000,
+	}
+	aggs := AggregateMultiSeed(sc, []Strategy{NewSwarmRouteAdapter()}, []int64{1, 1, 1})
+	if got := aggs[0].SuccessPct; got[0] != got[1] || got[1] != got[2] {
+		t.Fatalf("expected identical runs for identical seeds, got success %v", got)
+	}
+
+	// Every reset closes the instance it replaces, so reusing an adapter
+	// doesn't leak evaporation goroutines.
+	adapter := NewSwarmRouteAdapter()
+	before := runtime.NumGoroutine()
+	sc.TotalRequests = 10
+	for range 200 {
+		RunScenario(sc, adapter)
+	}
+	// Closed loops exit asynchronously; give them a moment.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before+10 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before+10 {
+		t.Fatalf("expected resets not to leak goroutines, went from %d to %d", before, after)
+	}
+}
+
+// TestClassBrownoutAndRouteByClass checks that a per-class override only
+// affects its class, and that routing by class lets a strategy keep reads
+// on an endpoint whose writes are browned out.
+func TestClassBrownoutAndRouteByClass(t *testing.T) {
+	slowWrites := 0.200
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002, Classes: map[string]ClassOverride{"write": {MeanLatencySec: &slowWrites}}},
+			{Addr: "b", MeanLatencySec: 0.040, JitterSec: 0.002},
+		},
+		Classes:       []RequestClass{{Name: "read", Weight: 4}, {Name: "write", Weight: 1}},
+		TotalRequests: 5000,
+		Seed:          1,
+	}
+	blind := RunScenario(sc, NewPowerOfTwoChoicesStrategy(1, 0.2))
+	if len(blind.Classes) != 2 || blind.Classes[0].Total+blind.Classes[1].Total != sc.TotalRequests {
+		t.Fatalf("unexpected class metrics: %+v", blind.Classes)
+	}
+	if share := float64(blind.Classes[1].Total) / float64(sc.TotalRequests); share < 0.17 || share > 0.23 {
+		t.Fatalf("expected about 20%% writes, got %.2f", share)
+	}
+
+	sc.RouteByClass = true
+	var services []string
+	routed := RunScenario(sc, &addServiceHook{Strategy: NewPowerOfTwoChoicesStrategy(1, 0.2), fn: func(name string) { services = append(services, name) }})
+	if !slices.Equal(services, []string{"svc/read", "svc/write"}) {
+		t.Fatalf("expected one service per class, got %v", services)
+	}
+	if r, b := routed.Classes[0].MeanLatMS, blind.Classes[0].MeanLatMS; r > 25 || r >= b {
+		t.Fatalf("expected routing by class to keep reads on a: routed=%.1fms blind=%.1fms", r, b)
+	}
+	if w := routed.Classes[1].MeanLatMS; w > 50 {
+		t.Fatalf("expected routed writes to avoid a, got %.1fms", w)
+	}
+}
+
+// addServiceHook records the services a strategy is given.
+type addServiceHook struct {
+	Strategy
+	fn func(name string)
+}
+
+func (h *addServiceHook) AddService(name string, endpoints []string) {
+	h.fn(name)
+	h.Strategy.AddService(name, endpoints)
+}
+
+// TestPopulationRevealsSynchronizedClients checks that every client of a
+// population learns only from its own requests, and that clients running
+// the same deterministic rotation move in lockstep, which a single client
+// can't show.
+func TestPopulationRevealsSynchronizedClients(t *testing.T) {
+	eps := make([]EndpointSpec, 4)
+	for i := range eps {
+		eps[i] = EndpointSpec{Addr: fmt.Sprintf("e%d", i+1), MeanLatencySec: 0.020, JitterSec: 0.002}
+	}
+	sc := Scenario{Service: "svc", Endpoints: eps, TotalRequests: 2000, Seed: 1}
+
+	single := RunScenario(sc, NewRoundRobinStrategy())
+	if single.PeakShare != 0.25 || single.ShareSwing != 0 {
+		t.Fatalf("expected a single round robin client to spread evenly: peak=%.2f swing=%.2f", single.PeakShare, single.ShareSwing)
+	}
+
+	reports := make([]int, 20)
+	clients := Clients(20, func(i int) Strategy {
+		return &reportHook{Strategy: NewRoundRobinStrategy(), fn: func(string, float64, bool) { reports[i]++ }}
+	})
+	pop := RunPopulation(sc, clients)
+	for i, n := range reports {
+		if n != sc.TotalRequests/20 {
+			t.Fatalf("client %d got %d reports, want %d", i, n, sc.TotalRequests/20)
+		}
+	}
+	if len(pop.Groups) != 1 || pop.Groups[0].Strategy != "RoundRobin" || pop.Groups[0].Total != sc.TotalRequests || pop.All.Strategy != "population" {
+		t.Fatalf("unexpected grouping: all=%s groups=%+v", pop.All.Strategy, pop.Groups)
+	}
+	if pop.All.PeakShare < 0.35 || pop.All.ShareSwing < 0.15 {
+		t.Fatalf("expected lockstep clients to herd: peak=%.2f swing=%.2f", pop.All.PeakShare, pop.All.ShareSwing)
+	}
+}
+
+// TestMixedPopulationReportsEachGroup checks the client split of a mixed
+// population and that each strategy's requests are reported separately.
+func TestMixedPopulationReportsEachGroup(t *testing.T) {
+	clients := MixedClients(10,
+		ClientGroup{Share: 0.7, New: func(int) Strategy { return NewRoundRobinStrategy() }},
+		ClientGroup{Share: 0.3, New: func(int) Strategy { return NewSwarmRouteAdapter() }},
+	)
+	if len(clients) != 10 || clients[6].Name() != "RoundRobin" || clients[7].Name() != "SwarmRoute" {
+		t.Fatalf("expected 7 RoundRobin then 3 SwarmRoute clients, got %d clients", len(clients))
+	}
+	if n := len(MixedClients(3, ClientGroup{Share: 1, New: func(int) Strategy { return NewRoundRobinStrategy() }}, ClientGroup{Share: 1, New: func(int) Strategy { return NewRoundRobinStrategy() }})); n != 3 {
+		t.Fatalf("expected rounding to keep 3 clients, got %d", n)
+	}
+
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "good", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "bad", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: 0.5},
+		},
+		TotalRequests: 5000,
+		Seed:          1,
+	}
+	pop := RunPopulation(sc, clients)
+	if len(pop.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(pop.Groups))
+	}
+	rr, sr := pop.Groups[0], pop.Groups[1]
+	if rr.Total != 3500 || sr.Total != 1500 || pop.All.Total != 5000 {
+		t.Fatalf("unexpected request split: rr=%d sr=%d all=%d", rr.Total, sr.Total, pop.All.Total)
+	}
+	if pct(sr.Success, sr.Total) < pct(rr.Success, rr.Total)+10 {
+		t.Fatalf("expected SwarmRoute clients to avoid the bad endpoint: rr=%d/%d sr=%d/%d", rr.Success, rr.Total, sr.Success, sr.Total)
+	}
+}
+
+// TestEndpointOutcomesAreCommonAcrossStrategies checks that strategies
+// meet the same sequence of outcomes on an endpoint however they spread
+// their requests.
+func TestEndpointOutcomesAreCommonAcrossStrategies(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, ErrorRate: 0.2},
+			{Addr: "b", MeanLatencySec: 0.030, ErrorRate: 0.1},
+		},
+		TotalRequests: 2000,
+		Seed:          7,
+	}
+	type outcome struct {
+		lat float64
+		ok  bool
+	}
+	record := func(s Strategy) map[string][]outcome {
+		seen := make(map[string][]outcome)
+		RunScenario(sc, &reportHook{Strategy: s, fn: func(endpoint string, latencySec float64, success bool) {
+			seen[endpoint] = append(seen[endpoint], outcome{latencySec, success})
+		}})
+		return seen
+	}
+	rr, rnd := record(NewRoundRobinStrategy()), record(NewRandomStrategy(3))
+	for _, ep := range []string{"a", "b"} {
+		n := min(len(rr[ep]), len(rnd[ep]))
+		if n < 500 || !slices.Equal(rr[ep][:n], rnd[ep][:n]) {
+			t.Fatalf("endpoint %s: outcomes differ between strategies over the first %d requests", ep, n)
+		}
+	}
+}
+
+// TestRunScenarioIsReproducible checks that RunScenario seeds strategies,
+// so SwarmRoute's results depend only on the scenario.
+func TestRunScenarioIsReproducible(t *testing.T) {
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, ErrorRate: 0.05},
+			{Addr: "b", MeanLatencySec: 0.030, ErrorRate: 0.02},
+			{Addr: "c", MeanLatencySec: 0.040, ErrorRate: 0.01},
+		},
+		TotalRequests: 3000,
+		Seed:          5,
+	}
+	first := RunScenario(sc, NewSwarmRouteAdapter())
+	if again := RunScenario(sc, NewSwarmRouteAdapter()); !reflect.DeepEqual(first, again) {
+		t.Fatalf("expected identical results, got selections %v and %v", first.Selection, again.Selection)
+	}
+	// A run that outlasts the library's one-second evaporation tick must
+	// give the same result: the adapter doesn't evaporate on the wall
+	// clock.
+	slow := sc
+	slow.Hooks = &Hooks{OnStep: func(step int) {
+		if step == sc.TotalRequests/2 {
+			time.Sleep(1100 * time.Millisecond)
+		}
+	}}
+	fast, paused := NewSwarmRouteAdapter(), NewSwarmRouteAdapter()
+	RunScenario(sc, fast)
+	if again := RunScenario(slow, paused); !reflect.DeepEqual(first, again) {
+		t.Fatalf("expected a slow run to match, got selections %v and %v", first.Selection, again.Selection)
+	}
+	if a, b := fast.SwarmRoute().PheromoneSnapshot(), paused.SwarmRoute().PheromoneSnapshot(); !reflect.DeepEqual(a, b) {
+		t.Fatalf("expected a slow run to learn the same pheromones, got %v and %v", a, b)
+	}
+	// A reused instance learns during the first run; Reset must forget it.
+	adapter := NewSwarmRouteAdapter()
+	RunScenario(sc, adapter)
+	if again := RunScenario(sc, adapter); !reflect.DeepEqual(first, again) {
+		t.Fatalf("expected a reused instance to be reset, got selections %v and %v", first.Selection, again.Selection)
+	}
+	rnd := NewRandomStrategy(1)
+	a := RunScenario(sc, rnd)
+	if b := RunScenario(sc, rnd); !reflect.DeepEqual(a, b) {
+		t.Fatalf("expected a reused instance to be reseeded, got selections %v and %v", a.Selection, b.Selection)
+	}
+}
+
+// TestHooksObserveRun checks that hooks see the start of the run, every
+// event, attempt, report and step, and can read the strategy's state as the run goes.
+func TestHooksObserveRun(t *testing.T) {
+	worse := 0.9
+	adapter := NewSwarmRouteAdapter()
+	var events, picks, reports, steps int
+	var started []string
+	var scores []float64
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002},
+		},
+		Events:        []EnvironmentEvent{{Step: 500, Endpoint: "b", NewErrorRate: &worse}},
+		TotalRequests: 2000,
+		Seed:          1,
+		Retry:         &RetryPolicy{MaxRetries: 1},
+		Hooks: &Hooks{
+			OnStart: func(seed int64, strategies []string) {
+				started = append(started, fmt.Sprint(seed, strategies))
+			},
+			OnEvent:  func(step int, ev EnvironmentEvent) { events++ },
+			OnPick:   func(step, client int, service, endpoint string) { picks++ },
+			OnReport: func(client int, service, endpoint string, latencySec float64, success bool) { reports++ },
+			OnStep: func(step int) {
+				steps++
+				score, _ := adapter.SwarmRoute().Score("svc", "b")
+				scores = append(scores, score)
+			},
+		},
+	}
+	res := RunScenario(sc, adapter)
+	if !slices.Equal(started, []string{"1 [SwarmRoute]"}) {
+		t.Fatalf("expected one start of seed 1 with SwarmRoute, got %q", started)
+	}
+	if events != 1 || steps != sc.TotalRequests || picks != res.Attempts || reports != res.Attempts {
+		t.Fatalf("unexpected hook calls: events=%d steps=%d picks=%d reports=%d attempts=%d", events, steps, picks, reports, res.Attempts)
+	}
+	if scores[len(scores)-1] >= scores[499] {
+		t.Fatalf("expected b's score to drop after it turned bad: before=%v end=%v", scores[499], scores[len(scores)-1])
+	}
+}
+
+// TestCheckpointResumeMatchesUninterruptedRun checks that resuming from a
+// checkpoint written to disk continues the environment, its random streams,
+// requests in flight and metrics exactly where the run left off.
+func TestCheckpointResumeMatchesUninterruptedRun(t *testing.T) {
+	slow := 0.060
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, ErrorRate: 0.05, Capacity: 2},
+			{Addr: "b", MeanLatencySec: 0.030, ErrorRate: 0.02, Capacity: 2},
+			{Addr: "c", MeanLatencySec: 0.025, JoinStep: 1500},
+		},
+		Events:        []EnvironmentEvent{{Step: 2500, Endpoint: "a", NewMeanLatency: &slow, Class: "write"}},
+		Classes:       []RequestClass{{Name: "read", Weight: 3}, {Name: "write", Weight: 1}},
+		TotalRequests: 4000,
+		Seed:          3,
+		TimeoutSec:    0.080,
+		Retry:         &RetryPolicy{MaxRetries: 1},
+		Load:          &Load{RPS: 120, Poisson: true},
+	}
+	want := RunScenario(sc, NewRoundRobinStrategy())
+
+	path := filepath.Join(t.TempDir(), "run.json")
+	saves := 0
+	sc.Checkpoints = &CheckpointPolicy{Every: 1000, Save: func(cp *Checkpoint, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		saves++
+		if cp.Step == 2000 {
+			if err := cp.SaveFile(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}}
+	if got := RunScenario(sc, NewRoundRobinStrategy()); !reflect.DeepEqual(got, want) || saves != 3 {
+		t.Fatalf("expected checkpointing not to change the run and to save 3 times, saved %d", saves)
+	}
+
+	cp, err := LoadCheckpointFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Checkpoints = nil
+	got, err := ResumeScenario(sc, NewRoundRobinStrategy(), cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("resumed run differs:\n got %s\nwant %s", FormatResults([]Results{got}), FormatResults([]Results{want}))
+	}
+	if again, _ := ResumeScenario(sc, NewRoundRobinStrategy(), cp); !reflect.DeepEqual(again, want) {
+		t.Fatal("expected resuming not to modify the checkpoint")
+	}
+
+	// A checkpoint of a strategy restores into a fresh instance of it.
+	var srCP *Checkpoint
+	sc.Checkpoints = &CheckpointPolicy{Every: 2000, Save: func(cp *Checkpoint, err error) { srCP = cp }}
+	RunScenario(sc, NewSwarmRouteAdapter())
+	sc.Checkpoints = nil
+	if got, err := ResumeScenario(sc, NewSwarmRouteAdapter(), srCP); err != nil || got.Total != sc.TotalRequests {
+		t.Fatalf("expected SwarmRoute to resume, got %d requests, %v", got.Total, err)
+	}
+
+	if _, err := ResumeScenario(Scenario{Seed: 4, TotalRequests: 4000}, NewRoundRobinStrategy(), cp); err == nil {
+		t.Fatal("expected resuming a different scenario to fail")
+	}
+}
+
+// TestWarmupStepsAreNotMeasured checks that warm-up requests drive learning
+// but stay out of the metrics.
+func TestWarmupStepsAreNotMeasured(t *testing.T) {
+	bad := 0.9
+	sc := Scenario{
+		Service: "svc",
+		Endpoints: []EndpointSpec{
+			{Addr: "a", MeanLatencySec: 0.020, JitterSec: 0.002},
+			{Addr: "b", MeanLatencySec: 0.020, JitterSec: 0.002, ErrorRate: bad},
+		},
+		Events:        []EnvironmentEvent{{Step: 500, Endpoint: "b", NewErrorRate: &bad}},
+		TotalRequests: 3000,
+		Seed:          1,
+		WarmupSteps:   1000,
+	}
+	reports := 0
+	res := RunScenario(sc, &reportHook{Strategy: NewSwarmRouteAdapter(), fn: func(string, float64, bool) { reports++ }})
+	if reports != sc.TotalRequests {
+		t.Fatalf("expected warm-up requests to be reported to the strategy, got %d reports", reports)
+	}
+	if res.Total != 2000 || res.Attempts != 2000 || res.Selection["a"]+res.Selection["b"] != 2000 {
+		t.Fatalf("expected only the 2000 measured requests in the metrics: %+v", res)
+	}
+	if len(res.Phases) != 1 || res.Phases[0].Start != 1000 || res.Phases[0].Total != 2000 {
+		t.Fatalf("expected one phase from the end of the warm-up, got %+v", res.Phases)
+	}
+	if res.Selection["b"] > 200 {
+		t.Fatalf("expected the warmed-up strategy to avoid b, got %d selections", res.Selection["b"])
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// stepLog writes Scenario.StepLog.  A nil *stepLog logs nothing.
+type stepLog struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newStepLog(w io.Writer) *stepLog {
+	if w == nil {
+		return nil
+	}
+	bw := bufio.NewWriter(w)
+	return &stepLog{w: bw, enc: json.NewEncoder(bw)}
+}
+
+// envJSON is the state of an endpoint in the step log.
+type envJSON struct {
+	MeanLatencySec float64 `json:"mean_latency_sec"`
+	JitterSec      float64 `json:"jitter_sec"`
+	ErrorRate      float64 `json:"error_rate"`
+	Down           bool    `json:"down"`
+	Blackhole      bool    `json:"blackhole"`
+	Degraded       bool    `json:"degraded"`
+	InFlight       int     `json:"in_flight,omitempty"`
+}
+
+// env logs the state of endpoint e at step.
+func (l *stepLog) env(step int, e *EndpointSpec, degraded bool) {
+	if l == nil {
+		return
+	}
+	l.enc.Encode(struct {
+		Type     string  `json:"type"`
+		Step     int     `json:"step"`
+		Endpoint string  `json:"endpoint"`
+		Env      envJSON `json:"env"`
+	}{"env", step, e.Addr, envJSON{e.MeanLatencySec, e.JitterSec, e.ErrorRate, e.Down, e.Blackhole, degraded, 0}})
+}
+
+// request logs the request of step.  endpoint is the last endpoint
+// attempted, whose state is e, and is empty if none could be picked.
+func (l *stepLog) request(step, client int, strategy, class, endpoint string, attempts int, latSec, waitSec float64, ok bool, e *EndpointSpec, degraded bool, inFlight int) {
+	if l == nil {
+		return
+	}
+	rec := struct {
+		Type      string   `json:"type"`
+		Step      int      `json:"step"`
+		Client    int      `json:"client"`
+		Strategy  string   `json:"strategy"`
+		Class     string   `json:"class,omitempty"`
+		Endpoint  string   `json:"endpoint"`
+		Attempts  int      `json:"attempts"`
+		LatencyMS float64  `json:"latency_ms"`
+		WaitMS    float64  `json:"wait_ms,omitempty"`
+		Success   bool     `json:"success"`
+		Env       *envJSON `json:"env,omitempty"`
+	}{"request", step, client, strategy, class, endpoint, attempts, latSec * 1000, waitSec * 1000, ok, nil}
+	if e != nil {
+		rec.Env = &envJSON{e.MeanLatencySec, e.JitterSec, e.ErrorRate, e.Down, e.Blackhole, degraded, inFlight}
+	}
+	l.enc.Encode(rec)
+}
+
+// flush writes out buffered records.
+func (l *stepLog) flush() {
+	if l != nil {
+		l.w.Flush()
+	}
+}
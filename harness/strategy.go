@@ -24,10 +24,58 @@ import (
 // It is intentionally aligned with the SwarmRoute library API to keep the simulator simple.
 type Strategy interface {
 	Name() string
+	// AddService sets the endpoints of a service.  It is called again
+	// with the full list when endpoints join mid-run (EndpointSpec.JoinStep);
+	// learned state of endpoints already known should be kept.
 	AddService(name string, endpoints []string)
 	PickEndpoint(service string) (string, error)
 	ReportResult(service, endpoint string, latencySec float64, success bool)
 }
 
+// ExcludingStrategy is implemented by strategies that can pick an endpoint
+// while avoiding some, which the simulator uses to send retries elsewhere.
+// As in the library, the exclusion is ignored if it would leave no endpoint.
+type ExcludingStrategy interface {
+	Strategy
+	PickEndpointExcluding(service string, exclude ...string) (string, error)
+}
+
+// SeedableStrategy is implemented by strategies with internal randomness.
+// RunScenario reseeds them from Scenario.Seed before every run, so their
+// random choices are reproducible; seeds passed to constructors only
+// matter outside the simulator.  Reseeding leaves learned state alone: a
+// run is independent of earlier runs of the same instance only if the
+// strategy is also a ResettableStrategy.
+type SeedableStrategy interface {
+	Strategy
+	Seed(seed int64)
+}
+
+// ResettableStrategy is implemented by strategies that can forget what
+// they learned.  RunScenario resets them before every run, before
+// reseeding, so reusing an instance across seeds and scenarios (as
+// AggregateMultiSeed does) gives the same results as fresh instances.
+type ResettableStrategy interface {
+	Strategy
+	Reset()
+}
+
+// ServerFeedback is what an endpoint piggybacks on a response, as in C3:
+// the requests queued ahead of this one when it arrived and the time spent
+// serving it, which excludes that queueing.
+type ServerFeedback struct {
+	QueueLen       int
+	ServiceTimeSec float64
+}
+
+// FeedbackStrategy is implemented by strategies that use server feedback.
+// The simulator calls ReportFeedback right before ReportResult for every
+// attempt the endpoint answered, i.e. not when it refused the connection
+// or the attempt timed out.
+type FeedbackStrategy interface {
+	Strategy
+	ReportFeedback(service, endpoint string, fb ServerFeedback)
+}
+
 // ErrNoEndpoints is returned when a strategy cannot select an endpoint for a service.
 var ErrNoEndpoints = fmt.Errorf("no endpoints for service")
@@ -21,37 +21,174 @@ import (
 )
 
 // SwarmRouteAdapter satisfies the Strategy interface by delegating to the library.
+// The library instance runs without wall-clock evaporation (see
+// swarmroute.NewManualSwarmRoute), so a seeded run gives the same result
+// however long it takes.
 type SwarmRouteAdapter struct {
 	sr *lib.SwarmRoute
+	// cfg and autoTune are what Reset starts over from.
+	cfg      lib.Config
+	autoTune *lib.AutoTunePolicy
+	// suffix distinguishes variants in results, e.g. "-autotune".
+	suffix string
 }
 
 func NewSwarmRouteAdapter() *SwarmRouteAdapter {
-	a := &SwarmRouteAdapter{sr: lib.NewSwarmRoute()}
-	// Configure tuning for simulation runs:
-	// - Decouple evaporation from wall-clock: half-life ~2000 requests.
-	a.sr.SetRequestEvapRate(0.0003466)
-	// - Lower base weight to allow truly bad endpoints to sink closer to zero.
-	a.sr.SetBaseWeight(0.05)
-	// - Tune positive vs negative update scales for net-negative expected update on bad endpoints.
-	a.sr.SetPosNegScale(0.25, 1.2)
-	// - Treat slow-but-successful as bad; set decay of positive pheromone on bad events.
-	a.sr.SetSlowThresholdSec(0.070) // ~70ms ≈ 2x healthy target
-	a.sr.SetBadPosDecay(0.20)
-	// - Optional periodic exploration to avoid over-concentration (every 500 picks).
-	a.sr.SetPeriodicExploration(500, 3.0)
-	return a
-}
-
-func (a *SwarmRouteAdapter) Name() string { return "SwarmRoute" }
+	// DefaultConfig carries the simulation tuning: request-scaled evaporation
+	// (half-life ~2000 requests), a low base weight so bad endpoints sink,
+	// net-negative updates on bad endpoints, slow successes (>70ms) treated
+	// as bad with positive decay, and exploration every 500 picks.
+	return NewSwarmRouteAdapterWithConfig(lib.DefaultConfig())
+}
+
+// NewSwarmRouteAdapterWithConfig adapts a library instance tuned with cfg,
+// e.g. one of swarmroute.Presets.
+func NewSwarmRouteAdapterWithConfig(cfg lib.Config) *SwarmRouteAdapter {
+	return &SwarmRouteAdapter{sr: lib.NewManualSwarmRoute(cfg), cfg: cfg}
+}
+
+// AdapterOption configures NewSwarmRouteAdapterWithOptions.
+type AdapterOption func(*adapterOptions)
+
+type adapterOptions struct {
+	cfg      lib.Config
+	params   map[string]float64
+	label    string
+	autoTune *lib.AutoTunePolicy
+}
+
+// WithConfig starts from cfg instead of swarmroute.DefaultConfig(), e.g.
+// one of swarmroute.Presets.
+func WithConfig(cfg lib.Config) AdapterOption {
+	return func(o *adapterOptions) { o.cfg = cfg }
+}
+
+// WithParam sets a configuration field by its swarmroute.Config JSON name,
+// e.g. WithParam("base_weight", 0.05), on top of the WithConfig
+// configuration whatever the order of the options.
+func WithParam(name string, value float64) AdapterOption {
+	return func(o *adapterOptions) { o.params[name] = value }
+}
+
+// WithLabel names the variant in results: "SwarmRoute-" + label, so
+// configurations compared in one run stay apart.
+func WithLabel(label string) AdapterOption {
+	return func(o *adapterOptions) { o.label = label }
+}
+
+// WithAutoTune enables online self-tuning with policy (see
+// SwarmRoute.SetAutoTune); unlabeled variants are labeled "autotune".
+func WithAutoTune(policy lib.AutoTunePolicy) AdapterOption {
+	return func(o *adapterOptions) { o.autoTune = &policy }
+}
+
+// NewSwarmRouteAdapterWithOptions adapts a library instance configured by
+// opts, starting from swarmroute.DefaultConfig().  It fails on unknown
+// parameter names and on configurations swarmroute.Config.Validate
+// rejects, rather than letting the library clamp them.
+func NewSwarmRouteAdapterWithOptions(opts ...AdapterOption) (*SwarmRouteAdapter, error) {
+	o := adapterOptions{cfg: lib.DefaultConfig(), params: make(map[string]float64)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	cfg, err := withParams(o.cfg, o.params)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	a := NewSwarmRouteAdapterWithConfig(cfg)
+	if o.autoTune != nil {
+		a.autoTune = o.autoTune
+		a.sr.SetAutoTune(o.autoTune)
+		if o.label == "" {
+			o.label = "autotune"
+		}
+	}
+	if o.label != "" {
+		a.suffix = "-" + o.label
+	}
+	return a, nil
+}
+
+func (a *SwarmRouteAdapter) Name() string { return "SwarmRoute" + a.suffix }
+
+// SwarmRoute returns the adapted library instance, e.g. to read pheromones
+// from Scenario.Hooks.
+func (a *SwarmRouteAdapter) SwarmRoute() *lib.SwarmRoute { return a.sr }
+
+// PheromoneSample is the pheromones of every endpoint of a service at a
+// step: Pos of the latency channel and Neg of the error channel, as in
+// SwarmRoute.PheromoneSnapshot.
+type PheromoneSample struct {
+	Step     int
+	Pos, Neg map[string]float64
+}
+
+// SamplePheromones returns the current pheromones of service, e.g. to trace
+// them from Hooks.OnStep.
+func (a *SwarmRouteAdapter) SamplePheromones(step int, service string) PheromoneSample {
+	snap := a.sr.PheromoneSnapshot()[service]
+	s := PheromoneSample{Step: step, Pos: make(map[string]float64, len(snap)), Neg: make(map[string]float64, len(snap))}
+	for addr, p := range snap {
+		s.Pos[addr], s.Neg[addr] = p.Pos, p.Neg
+	}
+	return s
+}
+
+// Seed seeds the library's selection (see SwarmRoute.SetSeed).  With no
+// wall-clock evaporation, that makes the adapter's picks a function of the
+// seed and the reports alone.
+func (a *SwarmRouteAdapter) Seed(seed int64) { a.sr.SetSeed(seed) }
 
+// Reset replaces the library instance with a fresh one configured as the
+// adapter was constructed, forgetting pheromones, counters and any
+// self-tuning, so runs of the same adapter are independent.  The old
+// instance is closed.
+func (a *SwarmRouteAdapter) Reset() {
+	a.sr.Close()
+	sr := lib.NewManualSwarmRoute(a.cfg)
+	if a.autoTune != nil {
+		sr.SetAutoTune(a.autoTune)
+	}
+	a.sr = sr
+}
+
+// MarshalState and UnmarshalState checkpoint the library state (see
+// SwarmRoute.Export).
+func (a *SwarmRouteAdapter) MarshalState() ([]byte, error)    { return a.sr.MarshalJSON() }
+func (a *SwarmRouteAdapter) UnmarshalState(data []byte) error { return a.sr.UnmarshalJSON(data) }
+
+// AddService uses SetEndpoints so that endpoints joining mid-run don't reset
+// what was learned about the others; RunScenario calls Reset first, so
+// nothing carries over between runs.
 func (a *SwarmRouteAdapter) AddService(name string, endpoints []string) {
-	a.sr.AddService(name, endpoints)
+	a.sr.SetEndpoints(name, endpoints)
 }
 
 func (a *SwarmRouteAdapter) PickEndpoint(service string) (string, error) {
 	return a.sr.PickEndpoint(service)
 }
 
+// PickEndpointExplained scores candidates by their sampling weight (see
+// SwarmRoute.PickEndpointExplained).
+func (a *SwarmRouteAdapter) PickEndpointExplained(service string) (string, []CandidateScore, error) {
+	ex, err := a.sr.PickEndpointExplained(service)
+	if err != nil {
+		return "", nil, err
+	}
+	scores := make([]CandidateScore, len(ex.Candidates))
+	for i, c := range ex.Candidates {
+		scores[i] = CandidateScore{Endpoint: c.Endpoint, Score: c.Weight, Probability: c.Probability, Excluded: c.Excluded}
+	}
+	return ex.Endpoint, scores, nil
+}
+
+func (a *SwarmRouteAdapter) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	return a.sr.PickEndpointExcluding(service, exclude...)
+}
+
 func (a *SwarmRouteAdapter) ReportResult(service, endpoint string, latencySec float64, success bool) {
 	a.sr.ReportResult(service, endpoint, latencySec, success)
 }
@@ -0,0 +1,249 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	lib "swarmroute"
+)
+
+// ParamGrid lists the values to try for SwarmRoute parameters, keyed by
+// their swarmroute.Config JSON names, e.g. "req_evap_rate", "base_weight",
+// "pos_reinforce", "neg_reinforce", "bad_pos_decay" or
+// "slow_threshold_sec".
+type ParamGrid map[string][]float64
+
+// ParseParamGrid parses a grid in flag form: space-separated
+// name=value|value|... entries, e.g. "req_evap_rate=0.0005|0.001
+// base_weight=0.01|0.05".
+func ParseParamGrid(s string) (ParamGrid, error) {
+	grid := make(ParamGrid)
+	for _, entry := range strings.Fields(s) {
+		name, values, ok := strings.Cut(entry, "=")
+		if !ok || values == "" {
+			return nil, fmt.Errorf("grid entry %q: want name=value|value|...", entry)
+		}
+		for _, v := range strings.Split(values, "|") {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("grid entry %q: %w", entry, err)
+			}
+			grid[name] = append(grid[name], f)
+		}
+	}
+	return grid, nil
+}
+
+// points returns every combination of the grid, varying the last name
+// (in sorted order) fastest.
+func (g ParamGrid) points() []map[string]float64 {
+	points := []map[string]float64{{}}
+	for _, name := range slices.Sorted(maps.Keys(g)) {
+		var next []map[string]float64
+		for _, p := range points {
+			for _, v := range g[name] {
+				q := maps.Clone(p)
+				q[name] = v
+				next = append(next, q)
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+// withParams returns base with the named parameters set.
+func withParams(base lib.Config, params map[string]float64) (lib.Config, error) {
+	data, err := json.Marshal(base)
+	if err != nil {
+		return lib.Config{}, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return lib.Config{}, err
+	}
+	for name, v := range params {
+		if _, ok := fields[name]; !ok {
+			return lib.Config{}, fmt.Errorf("unknown SwarmRoute parameter %q", name)
+		}
+		fields[name] = v
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return lib.Config{}, err
+	}
+	var cfg lib.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return lib.Config{}, fmt.Errorf("parameters %v: %w", params, err)
+	}
+	return cfg, nil
+}
+
+// Sweep tunes SwarmRoute by grid search: it runs every combination of Grid
+// on every scenario and seed, Parallel runs at a time, and reports the
+// configurations no other beats on every metric.  Parameters not in the
+// grid keep their Base values.  Runs are independent, but the library's
+// background evaporation ticks on the wall clock, so parallel sweeps are
+// as reproducible as the experiments themselves: close configurations can
+// swap places between sweeps.
+type Sweep struct {
+	Base      lib.Config // the zero Config means swarmroute.DefaultConfig()
+	Grid      ParamGrid
+	Scenarios []Scenario
+	Seeds     []int64
+	Parallel  int // runs at a time; 0 means GOMAXPROCS
+	// OnDone, if set, is called after each configuration finishes every
+	// scenario, e.g. to report progress.  Calls are serialized.
+	OnDone func(done, total int)
+}
+
+// SweepPoint is the outcome of one configuration: the means over all
+// scenarios and seeds of the success rate, p95 latency and bad-window
+// share (in percent), and the per-scenario aggregations behind them.
+type SweepPoint struct {
+	Params       map[string]float64
+	Config       lib.Config
+	SuccessPct   float64
+	P95ms        float64
+	BadShare     float64
+	Aggregations []MultiSeedAggregation // in Sweep.Scenarios order
+}
+
+// dominates reports whether p is at least as good as q on every metric
+// and better on one.
+func (p SweepPoint) dominates(q SweepPoint) bool {
+	if p.SuccessPct < q.SuccessPct || p.P95ms > q.P95ms || p.BadShare > q.BadShare {
+		return false
+	}
+	return p.SuccessPct > q.SuccessPct || p.P95ms < q.P95ms || p.BadShare < q.BadShare
+}
+
+// SweepResult holds every configuration of a Sweep in grid order, the
+// Pareto front of success (higher), p95 and bad share (lower) sorted by
+// success, and the best configuration by each of "success_pct", "p95_ms"
+// and "bad_share".
+type SweepResult struct {
+	Points []SweepPoint
+	Pareto []SweepPoint
+	Best   map[string]SweepPoint
+}
+
+// Run runs the sweep.  It fails before running anything if the grid
+// names a parameter SwarmRoute doesn't have.
+func (s Sweep) Run() (SweepResult, error) {
+	base := s.Base
+	if base == (lib.Config{}) {
+		base = lib.DefaultConfig()
+	}
+	params := s.Grid.points()
+	points := make([]SweepPoint, len(params))
+	for i, p := range params {
+		cfg, err := withParams(base, p)
+		if err != nil {
+			return SweepResult{}, err
+		}
+		points[i] = SweepPoint{Params: p, Config: cfg}
+	}
+
+	evaluate(points, s.Scenarios, s.Seeds, s.Parallel, s.OnDone)
+
+	res := SweepResult{Points: points, Best: make(map[string]SweepPoint)}
+	for _, p := range points {
+		if !slices.ContainsFunc(points, func(q SweepPoint) bool { return q.dominates(p) }) {
+			res.Pareto = append(res.Pareto, p)
+		}
+	}
+	slices.SortStableFunc(res.Pareto, func(a, b SweepPoint) int { return cmp.Compare(b.SuccessPct, a.SuccessPct) })
+	for _, p := range points {
+		if best, ok := res.Best["success_pct"]; !ok || p.SuccessPct > best.SuccessPct {
+			res.Best["success_pct"] = p
+		}
+		if best, ok := res.Best["p95_ms"]; !ok || p.P95ms < best.P95ms {
+			res.Best["p95_ms"] = p
+		}
+		if best, ok := res.Best["bad_share"]; !ok || p.BadShare < best.BadShare {
+			res.Best["bad_share"] = p
+		}
+	}
+	return res, nil
+}
+
+// evaluate runs every point's Config on every scenario and seed, parallel
+// runs at a time, and fills in its aggregations and means.  onDone, if
+// set, is called after each point finishes every scenario.
+func evaluate(points []SweepPoint, scenarios []Scenario, seeds []int64, parallel int, onDone func(done, total int)) {
+	for i := range points {
+		points[i].Aggregations = make([]MultiSeedAggregation, len(scenarios))
+	}
+	type job struct{ point, scenario int }
+	jobs := make(chan job)
+	if parallel <= 0 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		remaining = make([]int, len(points))
+		done      int
+	)
+	for i := range remaining {
+		remaining[i] = len(scenarios)
+	}
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				p := &points[j.point]
+				agg := AggregateMultiSeed(scenarios[j.scenario], []Strategy{NewSwarmRouteAdapterWithConfig(p.Config)}, seeds)
+				mu.Lock()
+				p.Aggregations[j.scenario] = agg[0]
+				if remaining[j.point]--; remaining[j.point] == 0 {
+					done++
+					if onDone != nil {
+						onDone(done, len(points))
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range points {
+		for k := range scenarios {
+			jobs <- job{i, k}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range points {
+		p := &points[i]
+		for _, a := range p.Aggregations {
+			p.SuccessPct += a.MeanSuccessPct / float64(len(p.Aggregations))
+			p.P95ms += a.MeanP95ms / float64(len(p.Aggregations))
+			p.BadShare += a.MeanBadShare / float64(len(p.Aggregations))
+		}
+	}
+}
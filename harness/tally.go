@@ -0,0 +1,518 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"math"
+	"sort"
+)
+
+// herdWindowSteps is the window, in steps, over which selection shares are
+// compared for Results.PeakShare and Results.ShareSwing, and over which the
+// trailing share of Results.Reactions is taken.
+const herdWindowSteps = 100
+
+// shareBucketSteps is the resolution, in steps, at which selections are
+// recorded for the share metrics.  It divides herdWindowSteps.
+const shareBucketSteps = 10
+
+// DefaultShedShare is the selection share below which a degraded endpoint
+// counts as shed when Scenario.ShedShare is zero.
+const DefaultShedShare = 0.10
+
+// DefaultReincludeFraction is the fraction of the fair share (one over the
+// number of endpoints) a recovered endpoint must reach again to count as
+// re-included when Scenario.ReincludeFraction is zero.
+const DefaultReincludeFraction = 0.5
+
+// tally accumulates the metrics of the requests of one strategy (or, in a
+// population, of one group of clients) during a run.  Its fields are
+// exported for checkpoints.
+type tally struct {
+	Requests, Success       int
+	Attempts, Timeouts      int
+	Overloaded              int
+	TimedOut, Dropped       int
+	StartSec                float64 // when the first measured request started
+	InFlight                []InFlightSample
+	WaitSum                 float64
+	Selections              map[string]int
+	Latencies               []float64
+	PhaseTotal, PhaseOK     []int
+	PhaseLat                [][]float64
+	PhaseSel                []map[string]int
+	ClassTotal, ClassOK     []int
+	ClassLat                [][]float64
+	BadTotal, BadToDegraded int
+	Buckets                 []map[string]int // selections per share bucket
+	BucketLat               [][]float64      // latencies per share bucket
+	Hist                    *Histogram
+	EndpointHist            map[string]*Histogram
+	KeyLast                 map[string]string // endpoint of each key's last request
+	KeyRepeats, KeySame     int
+}
+
+func newTally(phases, classes int, buckets []float64) *tally {
+	return &tally{
+		Selections:   make(map[string]int),
+		Hist:         newHistogram(buckets),
+		EndpointHist: make(map[string]*Histogram),
+		KeyLast:      make(map[string]string),
+		PhaseTotal:   make([]int, phases),
+		PhaseOK:      make([]int, phases),
+		PhaseLat:     make([][]float64, phases),
+		PhaseSel:     make([]map[string]int, phases),
+		ClassTotal:   make([]int, classes),
+		ClassOK:      make([]int, classes),
+		ClassLat:     make([][]float64, classes),
+	}
+}
+
+// keyed records that the request for key was served by addr.
+func (t *tally) keyed(key, addr string) {
+	if t.KeyLast == nil {
+		t.KeyLast = make(map[string]string)
+	}
+	if prev, ok := t.KeyLast[key]; ok {
+		t.KeyRepeats++
+		if prev == addr {
+			t.KeySame++
+		}
+	}
+	t.KeyLast[key] = addr
+}
+
+// attempt records an attempt on addr at step; degraded holds the endpoints
+// degraded at the time.
+func (t *tally) attempt(step, phase int, addr string, degraded map[string]int) {
+	t.Attempts++
+	t.Selections[addr]++
+	if t.PhaseSel[phase] == nil {
+		t.PhaseSel[phase] = make(map[string]int)
+	}
+	t.PhaseSel[phase][addr]++
+	if len(degraded) > 0 {
+		t.BadTotal++
+		if _, ok := degraded[addr]; ok {
+			t.BadToDegraded++
+		}
+	}
+	b := step / shareBucketSteps
+	for len(t.Buckets) <= b {
+		t.Buckets = append(t.Buckets, nil)
+	}
+	if t.Buckets[b] == nil {
+		t.Buckets[b] = make(map[string]int)
+	}
+	t.Buckets[b][addr]++
+}
+
+// served records the latency of a successful attempt on addr.
+func (t *tally) served(addr string, lat float64) {
+	h := t.EndpointHist[addr]
+	if h == nil {
+		h = newHistogram(t.Hist.BoundsMS)
+		t.EndpointHist[addr] = h
+	}
+	h.add(lat)
+}
+
+// skipped records a request no attempt could be made for.
+func (t *tally) skipped(wait float64) {
+	t.Requests++
+	t.Dropped++
+	t.WaitSum += wait
+}
+
+// request records the finished request of step; class is -1 without
+// request classes and lat, the end-to-end latency, only counts if ok.
+func (t *tally) request(step, phase, class int, ok bool, lat, wait float64) {
+	t.Requests++
+	t.WaitSum += wait
+	t.PhaseTotal[phase]++
+	if class >= 0 {
+		t.ClassTotal[class]++
+	}
+	if !ok {
+		return
+	}
+	t.Success++
+	t.Latencies = append(t.Latencies, lat)
+	t.Hist.add(lat)
+	b := step / shareBucketSteps
+	for len(t.BucketLat) <= b {
+		t.BucketLat = append(t.BucketLat, nil)
+	}
+	t.BucketLat[b] = append(t.BucketLat[b], lat)
+	t.PhaseOK[phase]++
+	t.PhaseLat[phase] = append(t.PhaseLat[phase], lat)
+	if class >= 0 {
+		t.ClassOK[class]++
+		t.ClassLat[class] = append(t.ClassLat[class], lat)
+	}
+}
+
+// herding returns the mean share of the busiest endpoint per window and the
+// mean total variation distance between the shares of consecutive windows.
+func (t *tally) herding() (peak, swing float64) {
+	var prev map[string]float64
+	windows, pairs := 0, 0
+	per := herdWindowSteps / shareBucketSteps
+	for i := 0; i < len(t.Buckets); i += per {
+		w := make(map[string]int)
+		total := 0
+		for _, b := range t.Buckets[i:min(i+per, len(t.Buckets))] {
+			for addr, n := range b {
+				w[addr] += n
+				total += n
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		shares := make(map[string]float64, len(w))
+		top := 0.0
+		for addr, n := range w {
+			shares[addr] = float64(n) / float64(total)
+			top = max(top, shares[addr])
+		}
+		peak += top
+		windows++
+		if prev != nil {
+			d := 0.0
+			for addr, s := range shares {
+				d += abs(s - prev[addr])
+			}
+			for addr, s := range prev {
+				if _, ok := shares[addr]; !ok {
+					d += s
+				}
+			}
+			swing += d / 2
+			pairs++
+		}
+		prev = shares
+	}
+	if windows > 0 {
+		peak /= float64(windows)
+	}
+	if pairs > 0 {
+		swing /= float64(pairs)
+	}
+	return peak, swing
+}
+
+// share returns addr's share of the selections made in steps [from, to),
+// both multiples of shareBucketSteps, and false if there were none.
+func (t *tally) share(addr string, from, to int) (float64, bool) {
+	n, total := 0, 0
+	for b := from / shareBucketSteps; b < to/shareBucketSteps && b < len(t.Buckets); b++ {
+		for a, c := range t.Buckets[b] {
+			total += c
+			if a == addr {
+				n += c
+			}
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(n) / float64(total), true
+}
+
+// series returns the trailing selection shares over the last
+// herdWindowSteps steps at every share bucket boundary with selections.
+func (t *tally) series() []ShareSample {
+	var out []ShareSample
+	for b := 1; b <= len(t.Buckets); b++ {
+		to := b * shareBucketSteps
+		from := max(0, to-herdWindowSteps)
+		shares := make(map[string]float64)
+		total := 0
+		for _, bucket := range t.Buckets[from/shareBucketSteps : b] {
+			for addr, n := range bucket {
+				shares[addr] += float64(n)
+				total += n
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		for addr := range shares {
+			shares[addr] /= float64(total)
+		}
+		out = append(out, ShareSample{Step: to, Shares: shares})
+	}
+	return out
+}
+
+// p95Series returns the p95 latency over the last herdWindowSteps steps at
+// every share bucket boundary with successful requests.
+func (t *tally) p95Series() []LatencySample {
+	var out []LatencySample
+	per := herdWindowSteps / shareBucketSteps
+	for b := 1; b <= len(t.BucketLat); b++ {
+		var lats []float64
+		for _, l := range t.BucketLat[max(0, b-per):b] {
+			lats = append(lats, l...)
+		}
+		if len(lats) == 0 {
+			continue
+		}
+		_, p95 := summarizeLatency(lats)
+		out = append(out, LatencySample{Step: b * shareBucketSteps, LatMS: p95 * 1000})
+	}
+	return out
+}
+
+// oscillation returns the standard deviation of every endpoint's share in
+// series within each phase, skipping the phase's first herdWindowSteps
+// steps, averaged over the endpoints selected during the run and weighted
+// by the samples per phase.
+func (t *tally) oscillation(series []ShareSample, starts []int, total int) float64 {
+	// Sum in a fixed order so results are reproducible to the bit
+	addrs := make([]string, 0, len(t.Selections))
+	for addr := range t.Selections {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	sum, weight := 0.0, 0
+	for i, start := range starts {
+		end := total
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		var phase []ShareSample
+		for _, s := range series {
+			if s.Step > start+herdWindowSteps && s.Step <= end {
+				phase = append(phase, s)
+			}
+		}
+		if len(phase) < 2 || len(t.Selections) == 0 {
+			continue
+		}
+		std := 0.0
+		for _, addr := range addrs {
+			mean, sq := 0.0, 0.0
+			for _, s := range phase {
+				mean += s.Shares[addr]
+			}
+			mean /= float64(len(phase))
+			for _, s := range phase {
+				d := s.Shares[addr] - mean
+				sq += d * d
+			}
+			std += math.Sqrt(sq / float64(len(phase)))
+		}
+		sum += std / float64(len(t.Selections)) * float64(len(phase))
+		weight += len(phase)
+	}
+	if weight == 0 {
+		return 0
+	}
+	return sum / float64(weight)
+}
+
+// firstShare returns how many steps after from addr's trailing share of the
+// selections first satisfied cond, checked at every share bucket boundary
+// up to end, or -1 if it never did.  The trailing share covers the last
+// herdWindowSteps steps, but none before from.
+func (t *tally) firstShare(addr string, from, end int, cond func(float64) bool) int {
+	lo := (from + shareBucketSteps - 1) / shareBucketSteps * shareBucketSteps
+	for to := lo + shareBucketSteps; to <= end; to += shareBucketSteps {
+		if s, ok := t.share(addr, max(lo, to-herdWindowSteps), to); ok && cond(s) {
+			return to - from
+		}
+	}
+	return -1
+}
+
+// reactions measures the reaction to every degraded window of a run of
+// total steps.  It returns the mean time to shed, counting windows never
+// shed at their full length, and the mean time to re-include over the
+// windows that recovered, counting endpoints never re-included at the steps
+// left until they degraded again or the run ended.
+func (t *tally) reactions(windows []DegradedWindow, total int, shedShare, reincludeShare float64) (rs []Reaction, meanShed, meanReinclude float64) {
+	if len(windows) == 0 {
+		return nil, 0, 0
+	}
+	recovered := 0
+	for i, w := range windows {
+		r := Reaction{Endpoint: w.Endpoint, Start: w.Start, End: w.End, ReincludeSteps: -1}
+		r.ShedSteps = t.firstShare(w.Endpoint, w.Start, w.End, func(s float64) bool { return s < shedShare })
+		if r.ShedSteps >= 0 {
+			meanShed += float64(r.ShedSteps)
+		} else {
+			meanShed += float64(w.End - w.Start)
+		}
+		if w.End < total {
+			r.Recovered = true
+			// Watch until the endpoint degrades again
+			until := total
+			for _, next := range windows[i+1:] {
+				if next.Endpoint == w.Endpoint && next.Start >= w.End {
+					until = min(until, next.Start)
+				}
+			}
+			r.ReincludeSteps = t.firstShare(w.Endpoint, w.End, until, func(s float64) bool { return s >= reincludeShare })
+			if r.ReincludeSteps >= 0 {
+				meanReinclude += float64(r.ReincludeSteps)
+			} else {
+				meanReinclude += float64(until - w.End)
+			}
+			recovered++
+		}
+		rs = append(rs, r)
+	}
+	if recovered > 0 {
+		meanReinclude /= float64(recovered)
+	}
+	return rs, meanShed / float64(len(windows)), meanReinclude
+}
+
+// fairness returns Jain's index of the selections in phase [start, end)
+// across the endpoints healthy throughout it: present from its start and
+// outside every degraded window.  It is 0 without such endpoints or
+// selections to them.
+func (t *tally) fairness(sc Scenario, phase, start, end int, windows []DegradedWindow) float64 {
+	var xs []float64
+	for _, e := range sc.Endpoints {
+		healthy := e.JoinStep <= start
+		for _, w := range windows {
+			if w.Endpoint == e.Addr && w.Start < end && w.End > start {
+				healthy = false
+			}
+		}
+		if healthy {
+			xs = append(xs, float64(t.PhaseSel[phase][e.Addr]))
+		}
+	}
+	return jain(xs)
+}
+
+// jain returns Jain's fairness index of xs, (Σx)² / (n·Σx²): 1 when all are
+// equal, 1/n when one takes everything, and 0 when all are zero.
+func jain(xs []float64) float64 {
+	sum, sq := 0.0, 0.0
+	for _, x := range xs {
+		sum += x
+		sq += x * x
+	}
+	if sq == 0 {
+		return 0
+	}
+	return sum * sum / (float64(len(xs)) * sq)
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// results builds the Results of the tally.  Scenario-wide values (phase
+// bounds, degraded windows, open-loop clock) come from the caller.
+func (t *tally) results(name string, sc Scenario, starts []int, windows []DegradedWindow, ol *openLoop) Results {
+	mean, p95 := summarizeLatency(t.Latencies)
+	ps := sc.Percentiles
+	if ps == nil {
+		ps = DefaultPercentiles
+	}
+	r := Results{
+		Strategy:           name,
+		Scenario:           sc.Name,
+		Seed:               sc.Seed,
+		Total:              t.Requests,
+		Success:            t.Success,
+		Failure:            t.Requests - t.Success,
+		MeanLatMS:          mean * 1000,
+		P95LatMS:           p95 * 1000,
+		Percentiles:        latencyPercentiles(t.Latencies, ps),
+		Histogram:          t.Hist,
+		EndpointHistograms: t.EndpointHist,
+		Selection:          t.Selections,
+		Timeouts:           t.Timeouts,
+		TimedOut:           t.TimedOut,
+		Dropped:            t.Dropped,
+		Attempts:           t.Attempts,
+		DegradedWindows:    windows,
+	}
+	if t.Requests > 0 {
+		r.RetryAmplification = float64(t.Attempts) / float64(t.Requests)
+	}
+	if ol != nil {
+		r.DurationSec, r.MaxInFlight = ol.end, ol.maxInFlight
+		r.InFlightSeries = t.InFlight
+		if d := ol.end - t.StartSec; d > 0 {
+			r.GoodputRPS = float64(t.Success) / d
+		}
+		if t.Requests > 0 {
+			r.MeanQueueWaitMS = t.WaitSum / float64(t.Requests) * 1000
+		}
+	}
+	r.Phases = make([]PhaseMetrics, len(starts))
+	for i, start := range starts {
+		end := sc.TotalRequests
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		m, p := summarizeLatency(t.PhaseLat[i])
+		r.Phases[i] = PhaseMetrics{Start: start, End: end, Total: t.PhaseTotal[i], Success: t.PhaseOK[i], MeanLatMS: m * 1000, P95LatMS: p * 1000,
+			Fairness: t.fairness(sc, i, start, end, windows)}
+	}
+	for i, c := range sc.Classes {
+		m, p := summarizeLatency(t.ClassLat[i])
+		r.Classes = append(r.Classes, ClassMetrics{Name: c.Name, Total: t.ClassTotal[i], Success: t.ClassOK[i], MeanLatMS: m * 1000, P95LatMS: p * 1000})
+	}
+	if t.Attempts > 0 {
+		r.OverloadShare = float64(t.Overloaded) / float64(t.Attempts)
+	}
+	if t.KeyRepeats > 0 {
+		r.KeyAffinity = float64(t.KeySame) / float64(t.KeyRepeats)
+	}
+	if t.BadTotal > 0 {
+		r.BadWindowDegradedShare = float64(t.BadToDegraded) / float64(t.BadTotal)
+	}
+	r.PeakShare, r.ShareSwing = t.herding()
+	r.ShareSeries = t.series()
+	r.P95Series = t.p95Series()
+	r.Oscillation = t.oscillation(r.ShareSeries, starts, sc.TotalRequests)
+	shed := sc.ShedShare
+	if shed <= 0 {
+		shed = DefaultShedShare
+	}
+	reinclude := sc.ReincludeFraction
+	if reinclude <= 0 {
+		reinclude = DefaultReincludeFraction
+	}
+	if n := len(sc.Endpoints); n > 0 {
+		reinclude /= float64(n)
+	}
+	r.Reactions, r.MeanShedSteps, r.MeanReincludeSteps = t.reactions(windows, sc.TotalRequests, shed, reinclude)
+	return r
+}
+
+// sortWindows orders degraded windows by start step, then endpoint.
+func sortWindows(windows []DegradedWindow) {
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].Start != windows[j].Start {
+			return windows[i].Start < windows[j].Start
+		}
+		return windows[i].Endpoint < windows[j].Endpoint
+	})
+}
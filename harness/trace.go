@@ -0,0 +1,325 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TraceRecord is one request of an access log: when it started, the
+// upstream that served it, its latency and its HTTP status (0 if no
+// response was received).
+type TraceRecord struct {
+	Time       time.Time
+	Upstream   string
+	LatencySec float64
+	Status     int
+}
+
+// Success reports whether the upstream answered without a server error.
+func (r TraceRecord) Success() bool { return r.Status > 0 && r.Status < 500 }
+
+// AccessLogFormat describes an access log: Format is "csv" (with a header
+// row), "ndjson" or "logfmt" (space-separated key=value pairs, as nginx
+// or HAProxy write with a custom log format), and the field names default
+// to "timestamp", "upstream", "latency" and "status".
+//
+// Timestamps are RFC 3339, nginx's $time_local (02/Jan/2006:15:04:05
+// -0700) or Unix seconds ($msec).  Latencies are seconds or Go durations
+// ("35ms").  For fields listing every attempt of a retried request, such
+// as nginx's "10.0.0.1:80, 10.0.0.2:80", the last attempt is used.
+// Records without an upstream ("" or "-") are skipped.
+type AccessLogFormat struct {
+	Format                                              string
+	TimeField, UpstreamField, LatencyField, StatusField string
+}
+
+// ReadAccessLog reads the records of an access log in time order.
+func ReadAccessLog(r io.Reader, f AccessLogFormat) ([]TraceRecord, error) {
+	names := [4]string{cmp.Or(f.TimeField, "timestamp"), cmp.Or(f.UpstreamField, "upstream"),
+		cmp.Or(f.LatencyField, "latency"), cmp.Or(f.StatusField, "status")}
+	var records []TraceRecord
+	add := func(line int, get func(string) (string, bool)) error {
+		var fields [4]string
+		for i, name := range names {
+			v, ok := get(name)
+			if !ok {
+				return fmt.Errorf("line %d: no %q field", line, name)
+			}
+			if j := strings.LastIndexByte(v, ','); j >= 0 {
+				v = v[j+1:]
+			}
+			fields[i] = strings.TrimSpace(v)
+		}
+		if fields[1] == "" || fields[1] == "-" {
+			return nil
+		}
+		rec := TraceRecord{Upstream: fields[1]}
+		var err error
+		if rec.Time, err = parseTraceTime(fields[0]); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.LatencySec, err = parseTraceLatency(fields[2]); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if fields[3] != "-" {
+			if rec.Status, err = strconv.Atoi(fields[3]); err != nil {
+				return fmt.Errorf("line %d: status: %w", line, err)
+			}
+		}
+		records = append(records, rec)
+		return nil
+	}
+
+	switch f.Format {
+	case "csv":
+		cr := csv.NewReader(r)
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("access log header: %w", err)
+		}
+		for line := 2; ; line++ {
+			row, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			err = add(line, func(name string) (string, bool) {
+				i := slices.Index(header, name)
+				if i < 0 || i >= len(row) {
+					return "", false
+				}
+				return row[i], true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	case "ndjson", "logfmt":
+		sc := bufio.NewScanner(r)
+		sc.Buffer(nil, 1<<20)
+		for line := 1; sc.Scan(); line++ {
+			text := strings.TrimSpace(sc.Text())
+			if text == "" {
+				continue
+			}
+			fields := make(map[string]string)
+			if f.Format == "ndjson" {
+				var obj map[string]any
+				if err := json.Unmarshal([]byte(text), &obj); err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				for k, v := range obj {
+					fields[k] = fmt.Sprint(v)
+				}
+			} else {
+				for _, kv := range splitLogfmt(text) {
+					k, v, _ := strings.Cut(kv, "=")
+					fields[k] = strings.Trim(v, `"`)
+				}
+			}
+			err := add(line, func(name string) (string, bool) {
+				v, ok := fields[name]
+				return v, ok
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", f.Format)
+	}
+	slices.SortStableFunc(records, func(a, b TraceRecord) int { return a.Time.Compare(b.Time) })
+	return records, nil
+}
+
+// splitLogfmt splits a logfmt line at spaces outside double quotes.
+func splitLogfmt(s string) []string {
+	var out []string
+	quoted, start := false, 0
+	for i := 0; i <= len(s); i++ {
+		switch {
+		case i < len(s) && s[i] == '"':
+			quoted = !quoted
+		case i == len(s) || (s[i] == ' ' && !quoted):
+			if i > start {
+				out = append(out, s[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return out
+}
+
+func parseTraceTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, frac := math.Modf(sec)
+		return time.Unix(int64(whole), int64(frac*1e9)), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "02/Jan/2006:15:04:05 -0700"} {
+		if t, err := time.Parse(layout, strings.Trim(s, "[]")); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("timestamp %q: want RFC 3339, 02/Jan/2006:15:04:05 -0700 or Unix seconds", s)
+}
+
+func parseTraceLatency(s string) (float64, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return sec, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("latency %q: want seconds or a duration", s)
+	}
+	return d.Seconds(), nil
+}
+
+// Trace is a recorded request stream, the basis of a scenario that
+// replays production traffic.
+type Trace struct {
+	records []TraceRecord
+	// byUpstream holds, per upstream, the indexes of its records.
+	byUpstream map[string][]int
+}
+
+// NewTrace returns the trace of records, which must be in time order.
+func NewTrace(records []TraceRecord) *Trace {
+	t := &Trace{records: records, byUpstream: make(map[string][]int)}
+	for i, r := range records {
+		t.byUpstream[r.Upstream] = append(t.byUpstream[r.Upstream], i)
+	}
+	return t
+}
+
+// Len returns the number of records.
+func (t *Trace) Len() int { return len(t.records) }
+
+// traceWindowMin is the number of records an upstream needs in a window
+// for its statistics to move the scenario's endpoint.
+const traceWindowMin = 5
+
+// Scenario converts the trace into a scenario with a request per record
+// and an endpoint per upstream.  The trace is cut into windows of
+// windowSec seconds, or kept whole if windowSec is not positive; each
+// upstream starts with the latency mean, jitter and error rate of its
+// first window and follows them in events at the first request of every
+// later window it has at least traceWindowMin records in (keeping its
+// latency through windows of only failures).  Note that a zero jitter,
+// from constant latencies, stands for the simulator's default of 30%.
+// The scenario has a single phase and a closed loop; set Load (the
+// trace's mean rate is Len over its duration) for open-loop traffic.
+//
+// The endpoints draw synthetic outcomes from those statistics.  Set the
+// scenario's Trace to t to replay the recorded outcomes instead: a
+// request sent to an upstream at step i gets the outcome of one of that
+// upstream's records closest in time to record i, so strategies are
+// judged on the actual latency shapes, errors and shifts of production.
+// The window statistics still decide when an endpoint counts as degraded.
+func (t *Trace) Scenario(windowSec float64) Scenario {
+	sc := Scenario{Name: "trace", Service: "svc", TotalRequests: len(t.records), Phases: []int{0}}
+	if len(t.records) == 0 {
+		return sc
+	}
+	start := t.records[0].Time
+	window := func(i int) int {
+		if !(windowSec > 0) {
+			return 0
+		}
+		return int(t.records[i].Time.Sub(start).Seconds() / windowSec)
+	}
+	for _, up := range slices.Sorted(maps.Keys(t.byUpstream)) {
+		idx := t.byUpstream[up]
+		var spec *EndpointSpec
+		for lo := 0; lo < len(idx); {
+			hi := lo
+			for hi < len(idx) && window(idx[hi]) == window(idx[lo]) {
+				hi++
+			}
+			mean, jitter, errRate := t.stats(idx[lo:hi])
+			switch {
+			case spec == nil:
+				sc.Endpoints = append(sc.Endpoints, EndpointSpec{Addr: up, MeanLatencySec: mean, JitterSec: jitter, ErrorRate: errRate})
+				spec = &sc.Endpoints[len(sc.Endpoints)-1]
+			case hi-lo >= traceWindowMin:
+				ev := EnvironmentEvent{Step: idx[lo], Endpoint: up, NewErrorRate: &errRate}
+				if errRate < 1 {
+					ev.NewMeanLatency, ev.NewJitterSec = &mean, &jitter
+				}
+				sc.Events = append(sc.Events, ev)
+			}
+			lo = hi
+		}
+	}
+	slices.SortStableFunc(sc.Events, func(a, b EnvironmentEvent) int { return cmp.Compare(a.Step, b.Step) })
+	return sc
+}
+
+// stats returns the mean and standard deviation of the successful
+// latencies of the records at idx, zero if none succeeded, and their
+// error rate.
+func (t *Trace) stats(idx []int) (mean, jitter, errRate float64) {
+	var lats []float64
+	for _, i := range idx {
+		if r := t.records[i]; r.Success() {
+			lats = append(lats, r.LatencySec)
+		}
+	}
+	for _, l := range lats {
+		mean += l / float64(len(lats))
+	}
+	for _, l := range lats {
+		jitter += (l - mean) * (l - mean) / float64(len(lats))
+	}
+	return mean, math.Sqrt(jitter), 1 - float64(len(lats))/float64(len(idx))
+}
+
+// traceNeighbors is how many of an upstream's records on either side of
+// the closest one a replayed outcome is drawn from, so an upstream
+// receiving more requests than it did in production doesn't repeat one.
+const traceNeighbors = 10
+
+// outcome returns the replayed latency and failure of a request to
+// upstream at step.  Upstreams absent from the trace fail.
+func (t *Trace) outcome(upstream string, step int, rng *rand.Rand) (latencySec float64, fail bool) {
+	idx := t.byUpstream[upstream]
+	if len(idx) == 0 {
+		return 0, true
+	}
+	at := t.records[min(step, len(t.records)-1)].Time
+	k, _ := slices.BinarySearchFunc(idx, at, func(i int, at time.Time) int { return t.records[i].Time.Compare(at) })
+	k = min(max(k+rng.Intn(2*traceNeighbors+1)-traceNeighbors, 0), len(idx)-1)
+	r := t.records[idx[k]]
+	return r.LatencySec, !r.Success()
+}
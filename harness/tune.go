@@ -0,0 +1,371 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	lib "swarmroute"
+)
+
+// ParamRange is the interval a Tune draws a parameter from: uniformly, or
+// log-uniformly if Log.  Integer parameters such as "explore_every_n" are
+// rounded.
+type ParamRange struct {
+	Min, Max float64
+	Log      bool
+}
+
+// ParamSpace maps SwarmRoute parameters, by their swarmroute.Config JSON
+// names, to the ranges a Tune searches.
+type ParamSpace map[string]ParamRange
+
+// ParseParamSpace parses a space in flag form: space-separated
+// name=min:max[:log] entries, e.g. "req_evap_rate=0.0001:0.005:log
+// base_weight=0.01:0.2".
+func ParseParamSpace(s string) (ParamSpace, error) {
+	space := make(ParamSpace)
+	for _, entry := range strings.Fields(s) {
+		name, spec, ok := strings.Cut(entry, "=")
+		parts := strings.Split(spec, ":")
+		if !ok || len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "log") {
+			return nil, fmt.Errorf("space entry %q: want name=min:max[:log]", entry)
+		}
+		var r ParamRange
+		var err error
+		if r.Min, err = strconv.ParseFloat(parts[0], 64); err != nil {
+			return nil, fmt.Errorf("space entry %q: %w", entry, err)
+		}
+		if r.Max, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return nil, fmt.Errorf("space entry %q: %w", entry, err)
+		}
+		r.Log = len(parts) == 3
+		space[name] = r
+	}
+	return space, nil
+}
+
+// Objective weighs the metrics of a SweepPoint, "success_pct", "p95_ms"
+// and "bad_share", into a single score: the sum of weight times metric.
+// Metrics to minimize take negative weights.  A Tune maximizes it, a Fuzz
+// compares strategies by it and FormatScoreTable ranks them by it.
+type Objective map[string]float64
+
+// DefaultObjective trades one point of success rate for 10ms of p95
+// latency or two points of bad-window share.
+var DefaultObjective = Objective{"success_pct": 1, "p95_ms": -0.1, "bad_share": -0.5}
+
+// ParseObjective parses an objective in flag form: space-separated
+// metric=weight entries, e.g. "success_pct=1 p95_ms=-0.1".
+func ParseObjective(s string) (Objective, error) {
+	o := make(Objective)
+	for _, entry := range strings.Fields(s) {
+		name, weight, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("objective entry %q: want metric=weight", entry)
+		}
+		if _, ok := objectiveMetrics[name]; !ok {
+			return nil, fmt.Errorf("objective entry %q: unknown metric %q", entry, name)
+		}
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("objective entry %q: %w", entry, err)
+		}
+		o[name] = w
+	}
+	return o, nil
+}
+
+// objectiveMetrics are the metrics an Objective can weigh.
+var objectiveMetrics = map[string]func(SweepPoint) float64{
+	"success_pct": func(p SweepPoint) float64 { return p.SuccessPct },
+	"p95_ms":      func(p SweepPoint) float64 { return p.P95ms },
+	"bad_share":   func(p SweepPoint) float64 { return p.BadShare },
+}
+
+// Score returns the objective value of p.
+func (o Objective) Score(p SweepPoint) float64 {
+	var score float64
+	for name, w := range o {
+		if metric, ok := objectiveMetrics[name]; ok {
+			score += w * metric(p)
+		}
+	}
+	return score
+}
+
+// ScoreAggregation returns the objective value of a's means over seeds,
+// which is also the mean of the per-seed scores.
+func (o Objective) ScoreAggregation(a MultiSeedAggregation) float64 {
+	return o.Score(SweepPoint{SuccessPct: a.MeanSuccessPct, P95ms: a.MeanP95ms, BadShare: a.MeanBadShare})
+}
+
+// Tune tunes SwarmRoute by search under a budget: it evaluates Budget
+// configurations drawn from Space on every scenario and seed and keeps the
+// one with the best Objective score.  Without TPE every configuration is
+// drawn at random; with TPE only the first quarter (at least 10) are, and
+// the rest come from a tree-structured Parzen estimator, which proposes
+// the candidate most likely to be among the best quarter so far.  Like a
+// Sweep, parameters not in Space keep their Base values and runs are
+// subject to the library's wall-clock evaporation.
+type Tune struct {
+	Base      lib.Config // the zero Config means swarmroute.DefaultConfig()
+	Space     ParamSpace
+	Objective Objective // nil means DefaultObjective
+	Scenarios []Scenario
+	Seeds     []int64
+	Budget    int
+	Seed      int64 // seeds the draws, not the scenarios
+	TPE       bool
+	Parallel  int // runs at a time; 0 means GOMAXPROCS
+	// OnDone, if set, is called after each configuration finishes every
+	// scenario with the best trial so far.  Calls are serialized.
+	OnDone func(done, total int, best TuneTrial)
+}
+
+// TuneTrial is one configuration evaluated by a Tune and its score.
+type TuneTrial struct {
+	SweepPoint
+	Score float64
+}
+
+// TuneResult holds the trials of a Tune in evaluation order and the best
+// of them.
+type TuneResult struct {
+	Trials []TuneTrial
+	Best   TuneTrial
+}
+
+// tpeCandidates is the number of candidates the Parzen estimator draws per
+// proposal, and tpeGamma the fraction of trials it considers good.
+const (
+	tpeCandidates = 24
+	tpeGamma      = 0.25
+)
+
+// Run runs the search.  It fails before running anything if Space names a
+// parameter SwarmRoute doesn't have or has an empty range.
+func (t Tune) Run() (TuneResult, error) {
+	base := t.Base
+	if base == (lib.Config{}) {
+		base = lib.DefaultConfig()
+	}
+	objective := t.Objective
+	if objective == nil {
+		objective = DefaultObjective
+	}
+	if t.Budget <= 0 {
+		return TuneResult{}, fmt.Errorf("tune budget must be > 0, got %d", t.Budget)
+	}
+	names := slices.Sorted(maps.Keys(t.Space))
+	for _, name := range names {
+		r := t.Space[name]
+		if _, err := withParams(base, map[string]float64{name: 0}); err != nil {
+			return TuneResult{}, err
+		}
+		if !(r.Min <= r.Max) || (r.Log && r.Min <= 0) {
+			return TuneResult{}, fmt.Errorf("parameter %s: bad range [%v, %v]", name, r.Min, r.Max)
+		}
+	}
+	parallel := t.Parallel
+	if parallel <= 0 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+
+	rng := rand.New(rand.NewSource(t.Seed))
+	initial := t.Budget
+	if t.TPE {
+		initial = min(t.Budget, max(10, t.Budget/4))
+	}
+	var res TuneResult
+	for len(res.Trials) < t.Budget {
+		n := initial - len(res.Trials)
+		if n <= 0 {
+			n = min(parallel, t.Budget-len(res.Trials))
+		}
+		points := make([]SweepPoint, n)
+		for i := range points {
+			var err error
+			for range 100 {
+				u := make([]float64, len(names))
+				if len(res.Trials) < initial {
+					for d := range u {
+						u[d] = rng.Float64()
+					}
+				} else {
+					u = tpePropose(rng, t.Space, names, res.Trials, u)
+				}
+				points[i], err = t.point(base, names, u)
+				if err == nil {
+					break
+				}
+			}
+			if err != nil {
+				return res, fmt.Errorf("no valid configuration in the space: %w", err)
+			}
+		}
+		done := len(res.Trials)
+		evaluate(points, t.Scenarios, t.Seeds, t.Parallel, nil)
+		for _, p := range points {
+			trial := TuneTrial{SweepPoint: p, Score: objective.Score(p)}
+			if len(res.Trials) == 0 || trial.Score > res.Best.Score {
+				res.Best = trial
+			}
+			res.Trials = append(res.Trials, trial)
+			if done++; t.OnDone != nil {
+				t.OnDone(done, t.Budget, res.Best)
+			}
+		}
+	}
+	return res, nil
+}
+
+// point maps u, one coordinate in [0, 1] per name, into the space and
+// returns the configuration, failing if it doesn't validate.
+func (t Tune) point(base lib.Config, names []string, u []float64) (SweepPoint, error) {
+	params := make(map[string]float64, len(names))
+	for d, name := range names {
+		r := t.Space[name]
+		v := r.Min + u[d]*(r.Max-r.Min)
+		if r.Log {
+			v = math.Exp(math.Log(r.Min) + u[d]*(math.Log(r.Max)-math.Log(r.Min)))
+		}
+		if intParam(name) {
+			v = math.Round(v)
+		}
+		params[name] = v
+	}
+	cfg, err := withParams(base, params)
+	if err != nil {
+		return SweepPoint{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return SweepPoint{}, err
+	}
+	return SweepPoint{Params: params, Config: cfg}, nil
+}
+
+// unit maps a trial's value of name back to [0, 1].
+func (r ParamRange) unit(v float64) float64 {
+	if r.Max == r.Min {
+		return 0.5
+	}
+	if r.Log {
+		return (math.Log(v) - math.Log(r.Min)) / (math.Log(r.Max) - math.Log(r.Min))
+	}
+	return (v - r.Min) / (r.Max - r.Min)
+}
+
+// intParam reports whether the Config field with JSON name name is an
+// integer.
+func intParam(name string) bool {
+	t := reflect.TypeFor[lib.Config]()
+	for i := range t.NumField() {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == name {
+			return t.Field(i).Type.Kind() == reflect.Int
+		}
+	}
+	return false
+}
+
+// tpePropose fills u with a TPE proposal: per dimension it splits trials
+// into the best tpeGamma and the rest, fits a Parzen density to each (in
+// unit coordinates, with a uniform prior component), draws tpeCandidates
+// from the good density and keeps the one maximizing good over bad.
+func tpePropose(rng *rand.Rand, space ParamSpace, names []string, trials []TuneTrial, u []float64) []float64 {
+	sorted := slices.SortedStableFunc(slices.Values(trials), func(a, b TuneTrial) int { return cmp.Compare(b.Score, a.Score) })
+	nGood := max(1, int(math.Ceil(tpeGamma*float64(len(sorted)))))
+	for d, name := range names {
+		r := space[name]
+		good := make([]float64, 0, nGood)
+		bad := make([]float64, 0, len(sorted)-nGood)
+		for i, tr := range sorted {
+			if i < nGood {
+				good = append(good, r.unit(tr.Params[name]))
+			} else {
+				bad = append(bad, r.unit(tr.Params[name]))
+			}
+		}
+		gbw, bbw := parzenBandwidth(good), parzenBandwidth(bad)
+		best := math.Inf(-1)
+		for range tpeCandidates {
+			x := rng.Float64()
+			if rng.Intn(len(good)+1) < len(good) {
+				// Truncate rather than clamp, which would pile candidates
+				// up on the bounds.
+				c := good[rng.Intn(len(good))]
+				for x = c + gbw*rng.NormFloat64(); x < 0 || x > 1; {
+					x = c + gbw*rng.NormFloat64()
+				}
+			}
+			if ratio := parzenDensity(x, good, gbw) / parzenDensity(x, bad, bbw); ratio > best {
+				best, u[d] = ratio, x
+			}
+		}
+	}
+	return u
+}
+
+// parzenBandwidth returns Scott's rule bandwidth for xs, at least 0.05 so
+// a collapsed set still explores.
+func parzenBandwidth(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0.25
+	}
+	var mean, sq float64
+	for _, x := range xs {
+		mean += x / float64(len(xs))
+	}
+	for _, x := range xs {
+		sq += (x - mean) * (x - mean)
+	}
+	sd := math.Sqrt(sq / float64(len(xs)-1))
+	return max(0.05, sd*math.Pow(float64(len(xs)), -0.2))
+}
+
+// parzenDensity is the density at x of Gaussians of bandwidth bw around
+// xs mixed with a uniform prior on [0, 1] weighted like one of them.
+func parzenDensity(x float64, xs []float64, bw float64) float64 {
+	density := 1.0
+	for _, c := range xs {
+		z := (x - c) / bw
+		density += math.Exp(-z*z/2) / (bw * math.Sqrt(2*math.Pi))
+	}
+	return density / float64(len(xs)+1)
+}
+
+// WritePreset writes cfg as a swarmroute.LoadConfig file, so a tuned
+// configuration can be deployed as is or passed to the "swarmroute"
+// strategy's config parameter.
+func WritePreset(w io.Writer, cfg lib.Config) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Config lib.Config `json:"config"`
+	}{cfg})
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"sync"
+	"time"
+)
+
+// HistorySample is one recorded point of an endpoint's pheromone history.
+type HistorySample struct {
+	Time   time.Time `json:"time"`
+	Pos    float64   `json:"pos"`
+	Neg    float64   `json:"neg"`
+	Weight float64   `json:"weight"`
+}
+
+// History records pheromone values of every endpoint into a bounded ring
+// per endpoint, so questions like "when did this endpoint start degrading"
+// can be answered after the fact.  Create it with RecordHistory.
+type History struct {
+	sr       *SwarmRoute
+	capacity int
+
+	mu    sync.RWMutex
+	rings map[string]map[string]*historyRing
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type historyRing struct {
+	buf  []HistorySample
+	next int // index of the next write once buf is full
+}
+
+func (r *historyRing) add(s HistorySample, capacity int) {
+	if len(r.buf) < capacity {
+		r.buf = append(r.buf, s)
+		return
+	}
+	r.buf[r.next] = s
+	r.next = (r.next + 1) % capacity
+}
+
+// samples returns the ring contents, oldest first.
+func (r *historyRing) samples() []HistorySample {
+	out := make([]HistorySample, 0, len(r.buf))
+	out = append(out, r.buf[r.next:]...)
+	return append(out, r.buf[:r.next]...)
+}
+
+// RecordHistory starts sampling the pheromones of every endpoint each
+// interval, keeping the last capacity samples per endpoint (so the history
+// covers interval*capacity).  An interval <= 0 disables the background
+// sampler; call Sample to record points manually.  Call Stop when done.
+func (sr *SwarmRoute) RecordHistory(interval time.Duration, capacity int) *History {
+	if capacity < 1 {
+		capacity = 1
+	}
+	h := &History{sr: sr, capacity: capacity, rings: make(map[string]map[string]*historyRing), stop: make(chan struct{})}
+	if interval > 0 {
+		go h.loop(interval)
+	}
+	return h
+}
+
+func (h *History) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.Sample()
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// Stop stops the background sampler.  Recorded history remains readable.
+func (h *History) Stop() {
+	h.once.Do(func() { close(h.stop) })
+}
+
+// Sample records one point for every endpoint.  Rings of endpoints that
+// are no longer registered are dropped.
+func (h *History) Sample() {
+	type point struct {
+		svc, addr string
+		s         HistorySample
+	}
+	sr := h.sr
+	sr.mu.RLock()
+	now := sr.now()
+	var points []point
+	for svc, eps := range sr.services {
+		p := sr.paramsLocked(svc)
+		for _, ep := range eps {
+			points = append(points, point{svc, ep.Address, HistorySample{
+				Time:   now,
+				Pos:    ep.Pheromones["latency"].Pos,
+				Neg:    ep.Pheromones["error"].Neg,
+				Weight: sr.weightLocked(&p, ep),
+			}})
+		}
+	}
+	sr.mu.RUnlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	old := h.rings
+	h.rings = make(map[string]map[string]*historyRing, len(old))
+	for _, p := range points {
+		ring := old[p.svc][p.addr]
+		if ring == nil {
+			ring = &historyRing{}
+		}
+		ring.add(p.s, h.capacity)
+		if h.rings[p.svc] == nil {
+			h.rings[p.svc] = make(map[string]*historyRing)
+		}
+		h.rings[p.svc][p.addr] = ring
+	}
+}
+
+// Series returns the recorded history of one endpoint, oldest first.
+func (h *History) Series(service, endpoint string) []HistorySample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ring := h.rings[service][endpoint]
+	if ring == nil {
+		return nil
+	}
+	return ring.samples()
+}
+
+// All returns the recorded history of every endpoint, keyed by service and
+// endpoint address.
+func (h *History) All() map[string]map[string][]HistorySample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	out := make(map[string]map[string][]HistorySample, len(h.rings))
+	for svc, eps := range h.rings {
+		out[svc] = make(map[string][]HistorySample, len(eps))
+		for addr, ring := range eps {
+			out[svc][addr] = ring.samples()
+		}
+	}
+	return out
+}
+
+// FirstAbove returns the start of the most recent unbroken run of samples
+// whose error pheromone exceeds threshold, i.e. when the endpoint's current
+// degradation began.  ok is false if the latest sample is not above the
+// threshold.
+func (h *History) FirstAbove(service, endpoint string, threshold float64) (t time.Time, ok bool) {
+	series := h.Series(service, endpoint)
+	for i := len(series) - 1; i >= 0 && series[i].Neg > threshold; i-- {
+		t, ok = series[i].Time, true
+	}
+	return t, ok
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryRingKeepsLatestSamples(t *testing.T) {
+	sr := NewSwarmRoute()
+	clock := time.Unix(1000, 0)
+	sr.now = func() time.Time { return clock }
+	sr.AddService("api", []string{"A"})
+	h := sr.RecordHistory(0, 3)
+	defer h.Stop()
+
+	for i := 0; i < 5; i++ {
+		if i >= 3 {
+			sr.ReportResult("api", "A", 0, false)
+		}
+		h.Sample()
+		clock = clock.Add(time.Second)
+	}
+
+	series := h.Series("api", "A")
+	if len(series) != 3 {
+		t.Fatalf("expected ring of 3 samples, got %d", len(series))
+	}
+	if !series[0].Time.Equal(time.Unix(1002, 0)) || !series[2].Time.Equal(time.Unix(1004, 0)) {
+		t.Fatalf("expected samples 1002..1004 oldest first, got %v .. %v", series[0].Time, series[2].Time)
+	}
+	if start, ok := h.FirstAbove("api", "A", 0); !ok || !start.Equal(time.Unix(1003, 0)) {
+		t.Fatalf("expected degradation to start at 1003, got %v %v", start, ok)
+	}
+
+	sr.SetEndpoints("api", []string{"B"})
+	h.Sample()
+	if h.Series("api", "A") != nil || len(h.Series("api", "B")) != 1 {
+		t.Fatalf("expected history to follow endpoint membership: %v", h.All())
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debugserver serves profiling and runtime stats for the commands,
+// behind their -pprof flag.
+package debugserver
+
+import (
+	"expvar"
+	"log"
+	"net/http"
+	_ "net/http/pprof" // registers /debug/pprof/ on http.DefaultServeMux
+	"runtime"
+	"time"
+)
+
+// Serve serves net/http/pprof under /debug/pprof/ and expvar under
+// /debug/vars on addr, so selection overhead can be profiled under load:
+//
+//	go tool pprof http://localhost:6060/debug/pprof/profile?seconds=10
+//
+// Besides expvar's memstats and cmdline, /debug/vars includes a compact
+// "runtime" summary.
+func Serve(addr string) {
+	start := time.Now()
+	expvar.Publish("runtime", expvar.Func(func() any {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return map[string]any{
+			"uptime_sec":        time.Since(start).Seconds(),
+			"goroutines":        runtime.NumGoroutine(),
+			"gomaxprocs":        runtime.GOMAXPROCS(0),
+			"heap_alloc_bytes":  m.HeapAlloc,
+			"heap_objects":      m.HeapObjects,
+			"total_alloc_bytes": m.TotalAlloc,
+			"num_gc":            m.NumGC,
+			"gc_pause_total_ns": m.PauseTotalNs,
+		}
+	}))
+	go func() {
+		log.Printf("pprof and runtime stats on http://%s/debug/pprof/ and /debug/vars", addr)
+		log.Println(http.ListenAndServe(addr, nil))
+	}()
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Settings is a global configuration plus per-service overrides, as loaded
+// by LoadConfig and applied by ApplySettings.
+type Settings struct {
+	Config   Config
+	Services map[string]Config
+}
+
+// Validate validates the global configuration and every override.
+func (s Settings) Validate() error {
+	errs := []error{s.Config.Validate()}
+	for svc, c := range s.Services {
+		if err := c.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", svc, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ApplySettings validates s and, if it is valid, replaces the global
+// tunables and all per-service overrides in one critical section.
+func (sr *SwarmRoute) ApplySettings(s Settings) error {
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("invalid swarmroute settings: %w", err)
+	}
+	overrides := make(map[string]Config, len(s.Services))
+	for svc, c := range s.Services {
+		overrides[svc] = c
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.setConfigLocked(s.Config)
+	sr.overrides = overrides
+	return nil
+}
+
+// configFile is the JSON layout read by LoadConfig.
+type configFile struct {
+	Preset   string                     `json:"preset"`
+	Config   json.RawMessage            `json:"config"`
+	Services map[string]json.RawMessage `json:"services"`
+}
+
+// LoadConfig builds Settings from an optional JSON file and SWARMROUTE_*
+// environment variables, so deployments can be configured without code.
+// The file looks like
+//
+//	{
+//	  "preset": "aggressive",
+//	  "config": {"slow_threshold_sec": 0.1},
+//	  "services": {"search": {"slow_threshold_sec": 0.25, "explore_every_n": 100}}
+//	}
+//
+// where every key is optional and uses the json names of Config.  If path
+// is empty, $SWARMROUTE_CONFIG_FILE is used when set.  Settings are layered
+// in this order, later layers winning:
+//
+//  1. the preset ($SWARMROUTE_PRESET, else the file's "preset", else
+//     "default"; see Presets)
+//  2. the file's "config"
+//  3. one environment variable per field: SWARMROUTE_ plus the upper-cased
+//     json name, e.g. SWARMROUTE_BASE_WEIGHT=0.05
+//  4. for each service in "services", its keys on top of the result
+//
+// Per-service overrides are only read from the file.  YAML is not supported
+// to keep the library free of dependencies; convert YAML to JSON first.
+// The result is validated.
+func LoadConfig(path string) (Settings, error) {
+	if path == "" {
+		path = os.Getenv("SWARMROUTE_CONFIG_FILE")
+	}
+	var data []byte
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return Settings{}, err
+		}
+	}
+	return parseSettings(data, os.Getenv)
+}
+
+func parseSettings(data []byte, getenv func(string) string) (Settings, error) {
+	var f configFile
+	if len(bytes.TrimSpace(data)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&f); err != nil {
+			return Settings{}, fmt.Errorf("config file: %w", err)
+		}
+	}
+	preset := f.Preset
+	if env := getenv("SWARMROUTE_PRESET"); env != "" {
+		preset = env
+	}
+	if preset == "" {
+		preset = "default"
+	}
+	global, ok := Presets()[preset]
+	if !ok {
+		return Settings{}, fmt.Errorf("unknown preset %q", preset)
+	}
+	if err := overlayJSON(&global, f.Config); err != nil {
+		return Settings{}, fmt.Errorf("config: %w", err)
+	}
+	if err := overlayEnv(&global, getenv); err != nil {
+		return Settings{}, err
+	}
+	s := Settings{Config: global, Services: make(map[string]Config, len(f.Services))}
+	for svc, raw := range f.Services {
+		c := global
+		if err := overlayJSON(&c, raw); err != nil {
+			return Settings{}, fmt.Errorf("service %s: %w", svc, err)
+		}
+		s.Services[svc] = c
+	}
+	return s, s.Validate()
+}
+
+// overlayJSON decodes the keys present in raw over c.
+func overlayJSON(c *Config, raw json.RawMessage) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(c)
+}
+
+// overlayEnv sets every field of c that has a SWARMROUTE_<JSON NAME>
+// environment variable.
+func overlayEnv(c *Config, getenv func(string) string) error {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		key := "SWARMROUTE_" + strings.ToUpper(name)
+		val := getenv(key)
+		if val == "" {
+			continue
+		}
+		switch f := v.Field(i); f.Kind() {
+		case reflect.Int:
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			f.SetInt(int64(n))
+		case reflect.Float64:
+			x, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			f.SetFloat(x)
+		}
+	}
+	return nil
+}
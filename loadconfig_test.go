@@ -0,0 +1,69 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigLayersFileEnvAndServices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarmroute.json")
+	file := `{
+	  "preset": "aggressive",
+	  "config": {"slow_threshold_sec": 0.1, "base_weight": 0.03},
+	  "services": {"search": {"slow_threshold_sec": 0.25}}
+	}`
+	if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SWARMROUTE_BASE_WEIGHT", "0.04")
+	t.Setenv("SWARMROUTE_EXPLORE_EVERY_N", "150")
+
+	s, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AggressiveConfig()
+	want.SlowThresholdSec, want.BaseWeight, want.ExploreEveryN = 0.1, 0.04, 150
+	if s.Config != want {
+		t.Fatalf("global config:\nwant %+v\ngot  %+v", want, s.Config)
+	}
+	want.SlowThresholdSec = 0.25
+	if s.Services["search"] != want {
+		t.Fatalf("search override:\nwant %+v\ngot  %+v", want, s.Services["search"])
+	}
+
+	sr := NewSwarmRoute()
+	if err := sr.ApplySettings(s); err != nil {
+		t.Fatal(err)
+	}
+	sr.AddService("search", []string{"A"})
+	sr.AddService("api", []string{"A"})
+	sr.ReportResult("search", "A", 0.2, true) // below the search threshold
+	sr.ReportResult("api", "A", 0.2, true)    // slow under the global threshold
+	snap := sr.PheromoneSnapshot()
+	if snap["search"]["A"].Pos == 0 || snap["api"]["A"].Neg == 0 {
+		t.Fatalf("expected per-service slow thresholds to apply: %+v", snap)
+	}
+
+	t.Setenv("SWARMROUTE_BAD_POS_DECAY", "2")
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected validation error for bad_pos_decay=2")
+	}
+}
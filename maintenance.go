@@ -0,0 +1,159 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// MaintenanceWindow is a daily recurring window during which an endpoint is
+// taken out of selection, e.g. a nightly reboot from 02:00 to 02:15.  After
+// the window the endpoint is slow-started: its selection weight ramps
+// linearly from zero back to full, so predictable maintenance doesn't have
+// to be learned from failures every night.
+type MaintenanceWindow struct {
+	// Start is the wall-clock time of day the window opens in Location,
+	// written as an offset from midnight: 2*time.Hour is 02:00 local time
+	// even on days a daylight-saving change makes longer or shorter.
+	Start time.Duration
+	// Duration is how long the endpoint stays drained.
+	Duration time.Duration
+	// SlowStart is how long the weight takes to ramp back to full after
+	// the window closes; zero returns the endpoint at full weight.
+	SlowStart time.Duration
+	// Location is the time zone of Start; nil means UTC.
+	Location *time.Location
+}
+
+func (w MaintenanceWindow) validate() error {
+	switch {
+	case w.Start < 0 || w.Start >= 24*time.Hour:
+		return errors.New("maintenance window start must be within a day")
+	case w.Duration <= 0:
+		return errors.New("maintenance window duration must be positive")
+	case w.SlowStart < 0:
+		return errors.New("maintenance window slow start must not be negative")
+	case w.Duration+w.SlowStart > 24*time.Hour:
+		return errors.New("maintenance window and slow start must fit in a day")
+	}
+	return nil
+}
+
+// phase reports whether t falls inside the window and, if not, the
+// slow-start weight multiplier at t (1 once the ramp is over).
+func (w MaintenanceWindow) phase(t time.Time) (inside bool, ramp float64) {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	y, m, d := t.Date()
+	start := w.startOn(y, m, d, loc)
+	if start.After(t) {
+		start = w.startOn(y, m, d-1, loc)
+	}
+	switch since := t.Sub(start); {
+	case since < w.Duration:
+		return true, 0
+	case since < w.Duration+w.SlowStart:
+		return false, float64(since-w.Duration) / float64(w.SlowStart)
+	}
+	return false, 1
+}
+
+// startOn returns the time the window opens on the given day.  It is
+// built from the wall-clock hour and minute, since adding Start to
+// midnight is off by an hour on daylight-saving change days.
+func (w MaintenanceWindow) startOn(y int, m time.Month, d int, loc *time.Location) time.Time {
+	h, rest := w.Start/time.Hour, w.Start%time.Hour
+	return time.Date(y, m, d, int(h), int(rest/time.Minute), int(rest%time.Minute/time.Second), int(rest%time.Second), loc)
+}
+
+// maintenance reports whether ep is inside one of its maintenance windows
+// at t and, if not, its slow-start weight multiplier.
+func (ep *Endpoint) maintenance(t time.Time) (inside bool, ramp float64) {
+	ramp = 1
+	for _, w := range ep.windows {
+		in, r := w.phase(t)
+		if in {
+			return true, 0
+		}
+		ramp = min(ramp, r)
+	}
+	return false, ramp
+}
+
+// available reports whether ep can be picked at t: it is active and not
+// inside a maintenance window.
+func (ep *Endpoint) available(t time.Time) bool {
+	if ep.state != StateActive {
+		return false
+	}
+	if len(ep.windows) == 0 {
+		return true
+	}
+	in, _ := ep.maintenance(t)
+	return !in
+}
+
+// AddMaintenanceWindow schedules a daily maintenance window for an
+// endpoint.  While inside the window the endpoint is treated as drained;
+// afterwards it is slow-started back.  It returns ErrUnknownEndpoint if the
+// endpoint is not registered, or an error if the window is invalid.
+func (sr *SwarmRoute) AddMaintenanceWindow(service, endpoint string, w MaintenanceWindow) error {
+	if err := w.validate(); err != nil {
+		return err
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	ep := sr.findLocked(service, endpoint)
+	if ep == nil {
+		return ErrUnknownEndpoint
+	}
+	// Never append in place: clones share the slice.
+	ep.windows = append(slices.Clip(ep.windows), w)
+	return nil
+}
+
+// ClearMaintenanceWindows removes every maintenance window of an endpoint,
+// ending a window or slow start in progress.  It returns ErrUnknownEndpoint
+// if the endpoint is not registered.
+func (sr *SwarmRoute) ClearMaintenanceWindows(service, endpoint string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	ep := sr.findLocked(service, endpoint)
+	if ep == nil {
+		return ErrUnknownEndpoint
+	}
+	ep.windows = nil
+	return nil
+}
+
+// InMaintenance reports whether an endpoint is currently inside one of its
+// maintenance windows.
+func (sr *SwarmRoute) InMaintenance(service, endpoint string) bool {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	ep := sr.findLocked(service, endpoint)
+	if ep == nil {
+		return false
+	}
+	in, _ := ep.maintenance(sr.now())
+	return in
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// MergeSnapshot blends a remote instance's PheromoneSnapshot into the local
+// pheromones, so any transport (a message bus, Kafka, a shared store) can
+// share observations between SwarmRoute instances.  For every endpoint known
+// to both sides:
+//
+//	local = (1-w)*local + w*remote
+//
+// where w is weight (clamped to 0..1) discounted by the snapshot's age: a
+// snapshot taken age seconds ago counts as much as its values would still
+// weigh locally after age seconds of evaporation, w = weight *
+// (1-evaporationRate)^age.  A zero takenAt means the snapshot is fresh.
+// Endpoints unknown locally are ignored.  It returns the number of endpoints
+// updated, or an error and changes nothing if weight is NaN or infinite.
+func (sr *SwarmRoute) MergeSnapshot(remote map[string]map[string]Pheromone, weight float64, takenAt time.Time) (int, error) {
+	if math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return 0, fmt.Errorf("merge weight must be finite, got %v", weight)
+	}
+	weight = min(max(weight, 0), 1)
+	sr.mu.Lock()
+	age := 0.0
+	if !takenAt.IsZero() {
+		age = max(sr.now().Sub(takenAt).Seconds(), 0)
+	}
+	var health []HealthEvent
+	merged := 0
+	for svc, eps := range remote {
+		q := sr.paramsLocked(svc)
+		w := weight * math.Pow(1-q.EvaporationRate, age)
+		if w <= 0 {
+			continue
+		}
+		for _, ep := range sr.services[svc] {
+			p, ok := eps[ep.Address]
+			if !ok {
+				continue
+			}
+			lat, errp := ep.Pheromones["latency"], ep.Pheromones["error"]
+			lat.Pos = (1-w)*lat.Pos + w*p.Pos
+			errp.Neg = (1-w)*errp.Neg + w*p.Neg
+			health = sr.checkHealthLocked(&q, svc, ep, health)
+			merged++
+		}
+	}
+	obs := sr.observers
+	sr.mu.Unlock()
+	for _, ro := range obs {
+		for _, h := range health {
+			ro.o.ObserveHealth(h)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RouteMux maps request paths to SwarmRoute services, so a single client
+// process keeps independent pheromone tables per traffic class (e.g.
+// /search → search-pool, /upload → upload-pool).  gRPC full method names
+// ("/pkg.Service/Method") are paths too, so the same mux routes gRPC calls.
+//
+// Patterns follow http.ServeMux conventions: a pattern ending in "/" matches
+// every path with that prefix, any other pattern matches the path exactly.
+// The longest matching pattern wins.  It is safe for concurrent use.
+type RouteMux struct {
+	sr *SwarmRoute
+
+	mu     sync.RWMutex
+	routes []muxRoute // sorted by descending pattern length
+	def    string
+}
+
+type muxRoute struct {
+	pattern string
+	service string
+}
+
+// NewRouteMux returns an empty mux that selects endpoints from sr.
+func NewRouteMux(sr *SwarmRoute) *RouteMux {
+	return &RouteMux{sr: sr}
+}
+
+// Handle maps pattern to service, replacing any previous mapping of the
+// same pattern.
+func (m *RouteMux) Handle(pattern, service string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.routes {
+		if m.routes[i].pattern == pattern {
+			m.routes[i].service = service
+			return
+		}
+	}
+	m.routes = append(m.routes, muxRoute{pattern: pattern, service: service})
+	sort.SliceStable(m.routes, func(i, j int) bool { return len(m.routes[i].pattern) > len(m.routes[j].pattern) })
+}
+
+// SetDefault sets the service used when no pattern matches.  An empty
+// service (the default) makes unmatched paths an error.
+func (m *RouteMux) SetDefault(service string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.def = service
+}
+
+// Service returns the service mapped to path.
+func (m *RouteMux) Service(path string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, r := range m.routes {
+		if r.pattern == path || (strings.HasSuffix(r.pattern, "/") && strings.HasPrefix(path, r.pattern)) {
+			return r.service, true
+		}
+	}
+	if m.def != "" {
+		return m.def, true
+	}
+	return "", false
+}
+
+// Pick resolves path to a service and selects one of its endpoints.
+func (m *RouteMux) Pick(path string) (service, endpoint string, err error) {
+	service, ok := m.Service(path)
+	if !ok {
+		return "", "", fmt.Errorf("no route for path %s", path)
+	}
+	endpoint, err = m.sr.PickEndpoint(service)
+	return service, endpoint, err
+}
+
+// RoundTripper returns an http.RoundTripper that routes each request through
+// the mux: the request path selects the service, SwarmRoute selects the
+// endpoint, the request URL's scheme and host are rewritten to the endpoint
+// (which must be an absolute URL such as "http://10.0.0.7:8080"), the
+// endpoint's path, if any, is prefixed to the request path (an endpoint
+// "http://host/api" serves /search as /api/search), and the outcome is
+// reported automatically.  Transport errors and 5xx responses count as
+// failures.  If base is nil, http.DefaultTransport is used.
+func (m *RouteMux) RoundTripper(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &muxTransport{mux: m, base: base}
+}
+
+type muxTransport struct {
+	mux  *RouteMux
+	base http.RoundTripper
+}
+
+func (t *muxTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, endpoint, err := t.mux.Pick(req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint %q of service %s: %w", endpoint, service, err)
+	}
+	out := req.Clone(req.Context())
+	out.URL.Scheme = target.Scheme
+	out.URL.Host = target.Host
+	if target.Path != "" {
+		out.URL.Path = joinPath(target.Path, req.URL.Path)
+		if target.RawPath != "" || req.URL.RawPath != "" {
+			out.URL.RawPath = joinPath(target.EscapedPath(), req.URL.EscapedPath())
+		}
+	}
+	out.Host = ""
+	t0 := time.Now()
+	resp, err := t.base.RoundTrip(out)
+	ok := err == nil && resp.StatusCode < 500
+	t.mux.sr.ReportResult(service, endpoint, time.Since(t0).Seconds(), ok)
+	return resp, err
+}
+
+// joinPath joins an endpoint's path prefix and a request path with a single
+// slash between them.
+func joinPath(prefix, path string) string {
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
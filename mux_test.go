@@ -0,0 +1,98 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteMuxRoutesPerTrafficClass(t *testing.T) {
+	backend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, name+" "+r.URL.Path)
+		}))
+	}
+	search, upload := backend("search"), backend("upload")
+	defer search.Close()
+	defer upload.Close()
+
+	sr := NewSwarmRoute()
+	sr.AddService("search-pool", []string{search.URL})
+	sr.AddService("upload-pool", []string{upload.URL})
+	mux := NewRouteMux(sr)
+	mux.Handle("/search", "search-pool")
+	mux.Handle("/upload/", "upload-pool")
+	mux.Handle("/pkg.Search/", "search-pool")
+
+	if svc, _ := mux.Service("/pkg.Search/Query"); svc != "search-pool" {
+		t.Fatalf("expected gRPC method to map to search-pool, got %q", svc)
+	}
+	if _, ok := mux.Service("/search/deep"); ok {
+		t.Fatalf("expected exact pattern /search not to match /search/deep")
+	}
+
+	client := &http.Client{Transport: mux.RoundTripper(nil)}
+	for path, want := range map[string]string{"/search": "search /search", "/upload/big": "upload /upload/big"} {
+		resp, err := client.Get("http://swarmroute.invalid" + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != want {
+			t.Fatalf("GET %s: expected %q, got %q", path, want, body)
+		}
+	}
+	if pos, _ := getPosNeg(t, sr, "upload-pool", upload.URL); pos <= 0 {
+		t.Fatalf("expected the transport to report success for upload-pool")
+	}
+	if _, err := client.Get("http://swarmroute.invalid/other"); err == nil {
+		t.Fatalf("expected error for unrouted path")
+	}
+}
+
+func TestRouteMuxKeepsEndpointPathPrefix(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.EscapedPath())
+	}))
+	defer backend.Close()
+
+	sr := NewSwarmRoute()
+	mux := NewRouteMux(sr)
+	mux.SetDefault("api")
+	client := &http.Client{Transport: mux.RoundTripper(nil)}
+	for _, tc := range []struct{ endpoint, path, want string }{
+		{backend.URL + "/api", "/search", "/api/search"},
+		{backend.URL + "/api/", "/search/", "/api/search/"},
+		{backend.URL + "/api", "/a%2Fb", "/api/a%2Fb"},
+		{backend.URL, "/search", "/search"},
+	} {
+		sr.AddService("api", []string{tc.endpoint})
+		resp, err := client.Get("http://swarmroute.invalid" + tc.path)
+		if err != nil {
+			t.Fatalf("GET %s via %s: %v", tc.path, tc.endpoint, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != tc.want {
+			t.Fatalf("GET %s via %s: expected %q, got %q", tc.path, tc.endpoint, tc.want, body)
+		}
+	}
+}
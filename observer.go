@@ -0,0 +1,149 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import "time"
+
+// PickEvent describes a completed endpoint selection.
+type PickEvent struct {
+	Service  string
+	Endpoint string
+	// Weight is the selection weight of the chosen endpoint at pick time.
+	Weight float64
+	// Explored is true if the endpoint was chosen by periodic forced
+	// exploration rather than weighted sampling.
+	Explored bool
+}
+
+// ReportEvent describes an outcome passed to ReportResult.
+type ReportEvent struct {
+	Service    string
+	Endpoint   string
+	LatencySec float64
+	Success    bool
+	// Slow is true for successful calls above the slow threshold.
+	Slow bool
+}
+
+// HealthEvent describes an endpoint crossing the "terrible" threshold of
+// negative pheromone (see SetPeriodicExploration).  Such endpoints are
+// considered ejected: weighted sampling sends them almost no traffic and
+// periodic exploration skips them.  Recovery happens when the error
+// pheromone decays back below the threshold.
+type HealthEvent struct {
+	Service  string
+	Endpoint string
+	// Ejected is true when the endpoint became ejected and false when it
+	// recovered.
+	Ejected bool
+	// Neg is the error pheromone at the time of the transition.
+	Neg float64
+}
+
+// ExpiryEvent describes an endpoint dropped because its endpoint TTL
+// elapsed (see SetEndpointTTL).
+type ExpiryEvent struct {
+	Service  string
+	Endpoint string
+	// Idle is how long the endpoint went without a report or a discovery
+	// confirmation.
+	Idle time.Duration
+}
+
+// Observer receives notifications about routing decisions and endpoint
+// health transitions.  Methods are called synchronously, never while
+// SwarmRoute's lock is held; implementations must be cheap and safe for
+// concurrent use.  Embed BaseObserver to implement only some methods.
+type Observer interface {
+	ObservePick(PickEvent)
+	ObserveReport(ReportEvent)
+	ObserveHealth(HealthEvent)
+	// ObserveNoHealthy is called when a pick finds no healthy endpoint.
+	// total is the number of registered endpoints.  The pick fails if no
+	// endpoint is selectable (none registered, or all drained, banned or in
+	// maintenance); otherwise all selectable endpoints are ejected and the
+	// pick proceeded anyway.
+	ObserveNoHealthy(service string, total int)
+	// ObserveExpiry is called for every endpoint dropped by the endpoint
+	// TTL.
+	ObserveExpiry(ExpiryEvent)
+}
+
+// BaseObserver is a no-op Observer meant for embedding.
+type BaseObserver struct{}
+
+func (BaseObserver) ObservePick(PickEvent)        {}
+func (BaseObserver) ObserveReport(ReportEvent)    {}
+func (BaseObserver) ObserveHealth(HealthEvent)    {}
+func (BaseObserver) ObserveNoHealthy(string, int) {}
+func (BaseObserver) ObserveExpiry(ExpiryEvent)    {}
+
+// AddObserver registers o to receive notifications and returns a function
+// that unregisters it.
+func (sr *SwarmRoute) AddObserver(o Observer) (remove func()) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.nextObserverID++
+	id := sr.nextObserverID
+	sr.observers = append(sr.observers, registeredObserver{id: id, o: o})
+	return func() {
+		sr.mu.Lock()
+		defer sr.mu.Unlock()
+		for i, ro := range sr.observers {
+			if ro.id == id {
+				sr.observers = append(sr.observers[:i:i], sr.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// registeredObserver pairs an observer with its registration id.  The
+// observers slice is never mutated in place, so callers may keep a copy of
+// the slice header after releasing the lock.
+type registeredObserver struct {
+	id int
+	o  Observer
+}
+
+// checkHealthLocked updates ep's ejected flag under the service parameters
+// p and appends a HealthEvent to events if it changed.  Callers must hold
+// sr.mu.
+func (sr *SwarmRoute) checkHealthLocked(p *Config, service string, ep *Endpoint, events []HealthEvent) []HealthEvent {
+	neg := ep.Pheromones["error"].Neg
+	ejected := neg > p.ExploreNegThreshold
+	if ejected == ep.ejected {
+		return events
+	}
+	ep.ejected = ejected
+	return append(events, HealthEvent{Service: service, Endpoint: ep.Address, Ejected: ejected, Neg: neg})
+}
+
+// Score returns the current selection weight of an endpoint, i.e. the value
+// weighted sampling is proportional to.  ok is false if the endpoint is
+// unknown.
+func (sr *SwarmRoute) Score(service, endpoint string) (weight float64, ok bool) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	p := sr.paramsLocked(service)
+	for _, ep := range sr.services[service] {
+		if ep.Address == endpoint {
+			return sr.weightLocked(&p, ep), true
+		}
+	}
+	return 0, false
+}
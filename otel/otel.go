@@ -0,0 +1,174 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otel instruments a SwarmRoute instance with OpenTelemetry metrics
+// and span events.  It lives in its own module so the core library stays
+// dependency-free.
+//
+// Metrics emitted (all attributed by service and endpoint):
+//
+//	swarmroute.picks              counter   picks, with explored=true|false
+//	swarmroute.reports            counter   reports, with outcome=success|slow|failure
+//	swarmroute.report.latency     histogram reported latency in seconds
+//	swarmroute.health.transitions counter   ejections/recoveries, with ejected=true|false
+//	swarmroute.no_healthy         counter   picks that found no healthy endpoint (service only)
+//	swarmroute.pheromone          gauge     current pheromone, with kind=pos|neg
+package otel
+
+import (
+	"context"
+
+	gotel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"swarmroute"
+)
+
+const scopeName = "swarmroute"
+
+// Option configures Instrument.
+type Option func(*options)
+
+type options struct {
+	mp metric.MeterProvider
+}
+
+// WithMeterProvider sets the meter provider; the global provider is used by
+// default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) { o.mp = mp }
+}
+
+// Instrumentation holds the instruments registered for one SwarmRoute.
+type Instrumentation struct {
+	swarmroute.BaseObserver
+
+	sr           *swarmroute.SwarmRoute
+	picks        metric.Int64Counter
+	reports      metric.Int64Counter
+	latency      metric.Float64Histogram
+	transitions  metric.Int64Counter
+	noHealthy    metric.Int64Counter
+	registration metric.Registration
+	remove       func()
+}
+
+// Instrument registers OpenTelemetry instruments for sr and attaches an
+// observer feeding them.  Call Close to detach.
+func Instrument(sr *swarmroute.SwarmRoute, opts ...Option) (*Instrumentation, error) {
+	o := options{mp: gotel.GetMeterProvider()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	meter := o.mp.Meter(scopeName)
+	in := &Instrumentation{sr: sr}
+	var err error
+	if in.picks, err = meter.Int64Counter("swarmroute.picks", metric.WithDescription("Endpoint selections.")); err != nil {
+		return nil, err
+	}
+	if in.reports, err = meter.Int64Counter("swarmroute.reports", metric.WithDescription("Reported call outcomes.")); err != nil {
+		return nil, err
+	}
+	if in.latency, err = meter.Float64Histogram("swarmroute.report.latency", metric.WithUnit("s"), metric.WithDescription("Reported call latency.")); err != nil {
+		return nil, err
+	}
+	if in.transitions, err = meter.Int64Counter("swarmroute.health.transitions", metric.WithDescription("Endpoint ejections and recoveries.")); err != nil {
+		return nil, err
+	}
+	if in.noHealthy, err = meter.Int64Counter("swarmroute.no_healthy", metric.WithDescription("Picks that found no healthy endpoint.")); err != nil {
+		return nil, err
+	}
+	gauge, err := meter.Float64ObservableGauge("swarmroute.pheromone", metric.WithDescription("Current pheromone per endpoint."))
+	if err != nil {
+		return nil, err
+	}
+	in.registration, err = meter.RegisterCallback(func(_ context.Context, ob metric.Observer) error {
+		for svc, eps := range sr.PheromoneSnapshot() {
+			for addr, p := range eps {
+				ob.ObserveFloat64(gauge, p.Pos, metric.WithAttributes(endpointAttrs(svc, addr, attribute.String("kind", "pos"))...))
+				ob.ObserveFloat64(gauge, p.Neg, metric.WithAttributes(endpointAttrs(svc, addr, attribute.String("kind", "neg"))...))
+			}
+		}
+		return nil
+	}, gauge)
+	if err != nil {
+		return nil, err
+	}
+	in.remove = sr.AddObserver(in)
+	return in, nil
+}
+
+// Close detaches the observer and unregisters the gauge callback.
+func (in *Instrumentation) Close() error {
+	in.remove()
+	return in.registration.Unregister()
+}
+
+// PickEndpoint selects an endpoint like SwarmRoute.PickEndpoint and, if the
+// span in ctx is recording, annotates it with a "swarmroute.pick" event
+// carrying the chosen endpoint and its current score.
+func (in *Instrumentation) PickEndpoint(ctx context.Context, service string) (string, error) {
+	addr, err := in.sr.PickEndpoint(service)
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return addr, err
+	}
+	if err != nil {
+		span.AddEvent("swarmroute.pick", trace.WithAttributes(
+			attribute.String("swarmroute.service", service),
+			attribute.String("swarmroute.error", err.Error()),
+		))
+		return addr, err
+	}
+	score, _ := in.sr.Score(service, addr)
+	span.AddEvent("swarmroute.pick", trace.WithAttributes(
+		attribute.String("swarmroute.service", service),
+		attribute.String("swarmroute.endpoint", addr),
+		attribute.Float64("swarmroute.score", score),
+	))
+	return addr, nil
+}
+
+func (in *Instrumentation) ObservePick(ev swarmroute.PickEvent) {
+	in.picks.Add(context.Background(), 1, metric.WithAttributes(endpointAttrs(ev.Service, ev.Endpoint, attribute.Bool("explored", ev.Explored))...))
+}
+
+func (in *Instrumentation) ObserveReport(ev swarmroute.ReportEvent) {
+	outcome := "success"
+	switch {
+	case !ev.Success:
+		outcome = "failure"
+	case ev.Slow:
+		outcome = "slow"
+	}
+	ctx := context.Background()
+	in.reports.Add(ctx, 1, metric.WithAttributes(endpointAttrs(ev.Service, ev.Endpoint, attribute.String("outcome", outcome))...))
+	in.latency.Record(ctx, ev.LatencySec, metric.WithAttributes(endpointAttrs(ev.Service, ev.Endpoint)...))
+}
+
+func (in *Instrumentation) ObserveHealth(ev swarmroute.HealthEvent) {
+	in.transitions.Add(context.Background(), 1, metric.WithAttributes(endpointAttrs(ev.Service, ev.Endpoint, attribute.Bool("ejected", ev.Ejected))...))
+}
+
+func (in *Instrumentation) ObserveNoHealthy(service string, total int) {
+	in.noHealthy.Add(context.Background(), 1, metric.WithAttributes(attribute.String("service", service)))
+}
+
+func endpointAttrs(service, endpoint string, extra ...attribute.KeyValue) []attribute.KeyValue {
+	return append([]attribute.KeyValue{attribute.String("service", service), attribute.String("endpoint", endpoint)}, extra...)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"swarmroute"
+)
+
+func TestInstrumentRecordsPicksAndPheromones(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	sr := swarmroute.NewSwarmRoute()
+	sr.AddService("api", []string{"A", "B"})
+	in, err := Instrument(sr, WithMeterProvider(mp))
+	if err != nil {
+		t.Fatalf("instrument: %v", err)
+	}
+	defer in.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		addr, err := in.PickEndpoint(ctx, "api")
+		if err != nil {
+			t.Fatalf("pick: %v", err)
+		}
+		sr.ReportResult("api", addr, 0.02, true)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	seen := map[string]bool{}
+	var picks int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			seen[m.Name] = true
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == "swarmroute.picks" {
+				for _, dp := range sum.DataPoints {
+					picks += dp.Value
+				}
+			}
+		}
+	}
+	for _, name := range []string{"swarmroute.picks", "swarmroute.reports", "swarmroute.report.latency", "swarmroute.pheromone"} {
+		if !seen[name] {
+			t.Fatalf("expected metric %s to be exported, got %v", name, seen)
+		}
+	}
+	if picks != 10 {
+		t.Fatalf("expected 10 picks, got %d", picks)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Save writes the current state (see Export) to w as JSON.
+func (sr *SwarmRoute) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(sr.Export())
+}
+
+// Load restores the learned state written by Save: pheromones, counters
+// and last-report times of the endpoints that are already registered.  It
+// is meant for warm restarts, after the services have been set up: the
+// live configuration, per-service overrides, administrative states and the
+// endpoint lists are kept, so tuning changed since the checkpoint stays in
+// effect, and endpoints the checkpoint doesn't know start from scratch.
+// In-flight counts from the previous process are discarded since those
+// calls will never be reported.  Use Import to replace everything.
+func (sr *SwarmRoute) Load(r io.Reader) error {
+	var st State
+	if err := json.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+	if st.Version != StateVersion {
+		return fmt.Errorf("unsupported state version %d (want %d)", st.Version, StateVersion)
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for svc, snaps := range st.Services {
+		p := sr.paramsLocked(svc)
+		for _, s := range snaps {
+			ep := sr.findLocked(svc, s.Address)
+			if ep == nil {
+				continue
+			}
+			for ch, ph := range s.Pheromones {
+				ep.Pheromones[ch] = &Pheromone{Pos: ph.Pos, Neg: ph.Neg}
+			}
+			ep.picks, ep.successes, ep.failures = s.Counters.Picks, s.Counters.Successes, s.Counters.Failures
+			ep.lastSeen = s.LastSeen
+			ep.ejected = ep.Pheromones["error"].Neg > p.ExploreNegThreshold
+		}
+	}
+	return nil
+}
+
+// SaveFile saves the state to path atomically: it writes a temporary file
+// in the same directory and renames it over path.
+func (sr *SwarmRoute) SaveFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after a successful rename
+	if err := sr.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadFile loads learned state saved by SaveFile (see Load).  A missing file returns an error
+// satisfying errors.Is(err, fs.ErrNotExist), which callers usually treat as
+// a cold start.
+func (sr *SwarmRoute) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sr.Load(f)
+}
+
+// Checkpointer periodically saves a SwarmRoute's state to a file.  Create
+// it with StartCheckpointer.
+type Checkpointer struct {
+	sr   *SwarmRoute
+	path string
+
+	mu      sync.Mutex
+	lastErr error
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// StartCheckpointer saves the state to path every interval (at least one
+// second) until Stop is called.  Pair it with LoadFile at startup, once the
+// services are registered:
+//
+//	sr.AddService("api", endpoints)
+//	if err := sr.LoadFile(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+//		log.Printf("swarmroute: ignoring checkpoint: %v", err)
+//	}
+//	cp := sr.StartCheckpointer(path, time.Minute)
+//	defer cp.Stop()
+func (sr *SwarmRoute) StartCheckpointer(path string, interval time.Duration) *Checkpointer {
+	if interval < time.Second {
+		interval = time.Second
+	}
+	cp := &Checkpointer{sr: sr, path: path, stop: make(chan struct{}), done: make(chan struct{})}
+	go cp.loop(interval)
+	return cp
+}
+
+func (cp *Checkpointer) loop(interval time.Duration) {
+	defer close(cp.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cp.save()
+		case <-cp.stop:
+			return
+		}
+	}
+}
+
+func (cp *Checkpointer) save() error {
+	err := cp.sr.SaveFile(cp.path)
+	cp.mu.Lock()
+	cp.lastErr = err
+	cp.mu.Unlock()
+	return err
+}
+
+// LastError returns the error of the most recent checkpoint, or nil.
+func (cp *Checkpointer) LastError() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.lastErr
+}
+
+// Stop stops the periodic checkpoints and writes a final one, returning
+// its error.  Subsequent calls return nil.
+func (cp *Checkpointer) Stop() error {
+	var err error
+	cp.once.Do(func() {
+		close(cp.stop)
+		<-cp.done
+		err = cp.save()
+	})
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckpointerWarmRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarmroute.json")
+	fresh := NewSwarmRoute()
+	if err := fresh.LoadFile(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist for missing checkpoint, got %v", err)
+	}
+
+	old := NewSwarmRoute()
+	old.AddService("api", []string{"good", "bad"})
+	old.ReportResult("api", "good", 0.01, true)
+	for i := 0; i < 5; i++ {
+		old.ReportResult("api", "bad", 0, false)
+	}
+	_, _ = old.PickEndpoint("api") // never reported
+	cp := old.StartCheckpointer(path, time.Hour)
+	if err := cp.Stop(); err != nil {
+		t.Fatalf("final checkpoint: %v", err)
+	}
+
+	// The new process was retuned in code and gained an endpoint.
+	cfg := AggressiveConfig()
+	restarted := NewSwarmRouteWithConfig(cfg)
+	restarted.AddService("api", []string{"good", "bad", "new"})
+	if err := restarted.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if restarted.Config() != cfg {
+		t.Fatalf("expected the new config to survive the load, got %+v", restarted.Config())
+	}
+	if got := restarted.Services()["api"]; len(got) != 3 {
+		t.Fatalf("expected the registered endpoints to be kept, got %v", got)
+	}
+	good, _ := restarted.Score("api", "good")
+	bad, _ := restarted.Score("api", "bad")
+	if good <= bad {
+		t.Fatalf("expected learned quality to survive restart: good=%v bad=%v", good, bad)
+	}
+	for _, c := range restarted.Counters()["api"] {
+		if c.InFlight != 0 {
+			t.Fatalf("expected in-flight counts to be discarded on load: %+v", c)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import "math"
+
+// halfLifeRate returns the per-request evaporation rate giving a half-life
+// of n requests.
+func halfLifeRate(n float64) float64 { return math.Ln2 / n }
+
+// DefaultConfig is the recommended general-purpose tuning: request-scaled
+// memory with a half-life of ~2000 requests, slow successes treated as bad,
+// and forced exploration every 500 picks.
+//
+// DefaultConfig and the other presets are starting points for common
+// traffic shapes.  They were derived from the tuning used by the
+// simulation harness; only the slow threshold is workload specific and
+// should be set to roughly twice the healthy latency of the service (the
+// presets assume ~35ms).
+func DefaultConfig() Config {
+	return Config{
+		EvaporationRate:     0.05,
+		PosReinforce:        0.25,
+		NegReinforce:        1.2,
+		ReqEvapRate:         halfLifeRate(2000),
+		BaseWeight:          0.05,
+		ExploreEveryN:       500,
+		ExploreNegThreshold: 3.0,
+		SlowThresholdSec:    0.070,
+		BadPosDecay:         0.20,
+	}
+}
+
+// ConservativeConfig shifts traffic slowly and remembers long: a half-life
+// of ~10000 requests, gentler penalties and a higher base weight.  Use it
+// where flapping is worse than reacting late.
+func ConservativeConfig() Config {
+	c := DefaultConfig()
+	c.EvaporationRate = 0.01
+	c.PosReinforce = 0.15
+	c.NegReinforce = 0.8
+	c.ReqEvapRate = halfLifeRate(10000)
+	c.BaseWeight = 0.10
+	c.ExploreEveryN = 1000
+	c.BadPosDecay = 0.10
+	return c
+}
+
+// AggressiveConfig converges fast and forgets fast: a half-life of ~500
+// requests, strong penalties and frequent exploration so recovered
+// endpoints are found quickly.
+func AggressiveConfig() Config {
+	c := DefaultConfig()
+	c.EvaporationRate = 0.10
+	c.PosReinforce = 0.5
+	c.NegReinforce = 2.0
+	c.ReqEvapRate = halfLifeRate(500)
+	c.BaseWeight = 0.02
+	c.ExploreEveryN = 200
+	c.BadPosDecay = 0.35
+	return c
+}
+
+// LowTrafficConfig suits services seeing a few requests per second or
+// less: each request carries more weight (half-life ~200 requests), the
+// wall-clock evaporation is slow so quiet periods don't erase what was
+// learned, and exploration is frequent because samples are scarce.
+func LowTrafficConfig() Config {
+	c := DefaultConfig()
+	c.EvaporationRate = 0.005
+	c.ReqEvapRate = halfLifeRate(200)
+	c.BaseWeight = 0.10
+	c.ExploreEveryN = 50
+	return c
+}
+
+// Presets returns the named presets: "default", "conservative",
+// "aggressive" and "low-traffic".
+func Presets() map[string]Config {
+	return map[string]Config{
+		"default":      DefaultConfig(),
+		"conservative": ConservativeConfig(),
+		"aggressive":   AggressiveConfig(),
+		"low-traffic":  LowTrafficConfig(),
+	}
+}
+
+// NewSwarmRouteWithConfig returns a SwarmRoute configured with cfg, e.g.
+// NewSwarmRouteWithConfig(DefaultConfig()).
+func NewSwarmRouteWithConfig(cfg Config) *SwarmRoute {
+	sr := NewSwarmRoute()
+	sr.ApplyConfig(cfg)
+	return sr
+}
+
+// NewManualSwarmRoute returns a SwarmRoute configured with cfg that starts
+// no background evaporation goroutine.  Pheromones decay per request
+// (ReqEvapRate) but never on the wall clock, and endpoint TTLs are only
+// enforced by ExpireStaleEndpoints.  Meant for simulations and tests,
+// whose results must not depend on how long they take to run.
+func NewManualSwarmRoute(cfg Config) *SwarmRoute {
+	sr := newSwarmRoute()
+	sr.ApplyConfig(cfg)
+	return sr
+}
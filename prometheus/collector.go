@@ -0,0 +1,83 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prometheus exports SwarmRoute's internal view of the fleet as a
+// prometheus.Collector.  It lives in its own module so the core library
+// stays dependency-free.
+//
+//	sr := swarmroute.NewSwarmRoute()
+//	prom.MustRegister(prometheus.NewCollector(sr))
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"swarmroute"
+)
+
+// Collector implements prom.Collector for a SwarmRoute instance.  Every
+// scrape reads a fresh snapshot, so there is no background work.
+type Collector struct {
+	sr *swarmroute.SwarmRoute
+
+	pos, neg, picks, reports, successRate, inFlight *prom.Desc
+}
+
+// NewCollector returns a collector exporting per-service/per-endpoint
+// metrics of sr:
+//
+//	swarmroute_pheromone_positive{service,endpoint}
+//	swarmroute_pheromone_negative{service,endpoint}
+//	swarmroute_selections_total{service,endpoint}
+//	swarmroute_reports_total{service,endpoint,outcome="success|failure"}
+//	swarmroute_success_ratio{service,endpoint}
+//	swarmroute_in_flight{service,endpoint}
+func NewCollector(sr *swarmroute.SwarmRoute) *Collector {
+	labels := []string{"service", "endpoint"}
+	return &Collector{
+		sr:          sr,
+		pos:         prom.NewDesc("swarmroute_pheromone_positive", "Positive (latency) pheromone per endpoint.", labels, nil),
+		neg:         prom.NewDesc("swarmroute_pheromone_negative", "Negative (error) pheromone per endpoint.", labels, nil),
+		picks:       prom.NewDesc("swarmroute_selections_total", "Endpoint selections made by PickEndpoint.", labels, nil),
+		reports:     prom.NewDesc("swarmroute_reports_total", "Outcomes reported via ReportResult.", append(labels, "outcome"), nil),
+		successRate: prom.NewDesc("swarmroute_success_ratio", "Fraction of reported calls that succeeded.", labels, nil),
+		inFlight:    prom.NewDesc("swarmroute_in_flight", "Picks not yet reported.", labels, nil),
+	}
+}
+
+// Describe implements prom.Collector.
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	for _, d := range []*prom.Desc{c.pos, c.neg, c.picks, c.reports, c.successRate, c.inFlight} {
+		ch <- d
+	}
+}
+
+// Collect implements prom.Collector.
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	snap := c.sr.PheromoneSnapshot()
+	for svc, eps := range c.sr.Counters() {
+		for addr, cnt := range eps {
+			p := snap[svc][addr]
+			ch <- prom.MustNewConstMetric(c.pos, prom.GaugeValue, p.Pos, svc, addr)
+			ch <- prom.MustNewConstMetric(c.neg, prom.GaugeValue, p.Neg, svc, addr)
+			ch <- prom.MustNewConstMetric(c.picks, prom.CounterValue, float64(cnt.Picks), svc, addr)
+			ch <- prom.MustNewConstMetric(c.reports, prom.CounterValue, float64(cnt.Successes), svc, addr, "success")
+			ch <- prom.MustNewConstMetric(c.reports, prom.CounterValue, float64(cnt.Failures), svc, addr, "failure")
+			ch <- prom.MustNewConstMetric(c.successRate, prom.GaugeValue, cnt.SuccessRate(), svc, addr)
+			ch <- prom.MustNewConstMetric(c.inFlight, prom.GaugeValue, float64(cnt.InFlight), svc, addr)
+		}
+	}
+}
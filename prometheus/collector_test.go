@@ -0,0 +1,58 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"swarmroute"
+)
+
+func TestCollectorExportsCounters(t *testing.T) {
+	sr := swarmroute.NewSwarmRoute()
+	sr.AddService("api", []string{"A"})
+	for i := 0; i < 3; i++ {
+		addr, err := sr.PickEndpoint("api")
+		if err != nil {
+			t.Fatalf("pick: %v", err)
+		}
+		sr.ReportResult("api", addr, 0.01, i != 0)
+	}
+	_, _ = sr.PickEndpoint("api") // leave one in flight
+
+	reg := prom.NewPedanticRegistry()
+	reg.MustRegister(NewCollector(sr))
+	expected := `
+# HELP swarmroute_selections_total Endpoint selections made by PickEndpoint.
+# TYPE swarmroute_selections_total counter
+swarmroute_selections_total{endpoint="A",service="api"} 4
+# HELP swarmroute_in_flight Picks not yet reported.
+# TYPE swarmroute_in_flight gauge
+swarmroute_in_flight{endpoint="A",service="api"} 1
+# HELP swarmroute_reports_total Outcomes reported via ReportResult.
+# TYPE swarmroute_reports_total counter
+swarmroute_reports_total{endpoint="A",outcome="failure",service="api"} 1
+swarmroute_reports_total{endpoint="A",outcome="success",service="api"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "swarmroute_selections_total", "swarmroute_in_flight", "swarmroute_reports_total"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotRegistered is returned by Registry.Heartbeat (and the HTTP client)
+// when the instance is unknown, e.g. because its registration expired.  The
+// caller should register again.
+var ErrNotRegistered = errors.New("instance not registered")
+
+// Instance is a server process registered in a Registry.
+type Instance struct {
+	Service       string            `json:"service"`
+	Addr          string            `json:"addr"`
+	Meta          map[string]string `json:"meta,omitempty"`
+	LastHeartbeat time.Time         `json:"last_heartbeat"`
+}
+
+// Registry is a small in-process service registry.  Server processes
+// register themselves (directly or over HTTP via Handler and RegistryClient)
+// and keep their registration alive with heartbeats; instances that miss
+// heartbeats for longer than the TTL are expired.  Subscribers, typically
+// SwarmRoute instances attached via Attach, are notified with the full
+// endpoint list of a service whenever its membership changes, in the order
+// of the changes.  It is safe for concurrent use.
+//
+// HTTP is the only remote transport; a gRPC front end is out of scope, but
+// a gRPC server can call Register and Heartbeat from its own handlers.
+type Registry struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	instances map[string]map[string]*Instance // service -> addr -> instance
+	subs      []func(service string, endpoints []string)
+	now       func() time.Time
+}
+
+// NewRegistry returns an empty registry that expires instances whose last
+// heartbeat is older than ttl.  A ttl <= 0 disables expiry.
+func NewRegistry(ttl time.Duration) *Registry {
+	return &Registry{ttl: ttl, instances: make(map[string]map[string]*Instance), now: time.Now}
+}
+
+// Register adds or refreshes an instance.  Re-registering an existing
+// instance replaces its metadata and counts as a heartbeat.
+func (r *Registry) Register(service, addr string, meta map[string]string) {
+	r.mu.Lock()
+	insts, ok := r.instances[service]
+	if !ok {
+		insts = make(map[string]*Instance)
+		r.instances[service] = insts
+	}
+	_, existed := insts[addr]
+	m := make(map[string]string, len(meta))
+	for k, v := range meta {
+		m[k] = v
+	}
+	insts[addr] = &Instance{Service: service, Addr: addr, Meta: m, LastHeartbeat: r.now()}
+	r.changedLocked(existed, service)
+	r.mu.Unlock()
+}
+
+// Heartbeat refreshes the liveness of a registered instance.  It returns
+// ErrNotRegistered if the instance is unknown.
+func (r *Registry) Heartbeat(service, addr string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inst, ok := r.instances[service][addr]
+	if !ok {
+		return ErrNotRegistered
+	}
+	inst.LastHeartbeat = r.now()
+	return nil
+}
+
+// Deregister removes an instance, e.g. during graceful shutdown.
+func (r *Registry) Deregister(service, addr string) {
+	r.mu.Lock()
+	insts := r.instances[service]
+	if _, ok := insts[addr]; !ok {
+		r.mu.Unlock()
+		return
+	}
+	delete(insts, addr)
+	r.changedLocked(false, service)
+	r.mu.Unlock()
+}
+
+// Expire drops instances whose last heartbeat is older than the TTL and
+// returns how many were dropped.  Run calls it periodically.
+func (r *Registry) Expire() int {
+	if r.ttl <= 0 {
+		return 0
+	}
+	r.mu.Lock()
+	cutoff := r.now().Add(-r.ttl)
+	dropped := 0
+	for svc, insts := range r.instances {
+		n := 0
+		for addr, inst := range insts {
+			if inst.LastHeartbeat.Before(cutoff) {
+				delete(insts, addr)
+				n++
+			}
+		}
+		if n > 0 {
+			dropped += n
+			r.changedLocked(false, svc)
+		}
+	}
+	r.mu.Unlock()
+	return dropped
+}
+
+// Run expires stale instances every ttl/2, but at most once a
+// millisecond, until ctx is done.
+func (r *Registry) Run(ctx context.Context) {
+	if r.ttl <= 0 {
+		<-ctx.Done()
+		return
+	}
+	ticker := time.NewTicker(max(r.ttl/2, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Expire()
+		}
+	}
+}
+
+// Instances returns the registered instances of a service sorted by address.
+func (r *Registry) Instances(service string) []Instance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.instancesLocked(service)
+}
+
+// Services returns all registered instances keyed by service name.
+func (r *Registry) Services() map[string][]Instance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string][]Instance, len(r.instances))
+	for svc := range r.instances {
+		out[svc] = r.instancesLocked(svc)
+	}
+	return out
+}
+
+// Subscribe registers fn to be called with the full endpoint list of a
+// service whenever its membership changes.  fn is called immediately for
+// every service already known.  Calls happen under the registry lock, so
+// they arrive in the order of the changes; fn must not call back into the
+// registry.
+func (r *Registry) Subscribe(fn func(service string, endpoints []string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, fn)
+	for svc := range r.instances {
+		fn(svc, r.addrsLocked(svc))
+	}
+}
+
+// Attach subscribes sr to the registry so its endpoint lists follow
+// registrations.  Membership changes use SetEndpoints, so learned pheromones
+// of surviving endpoints are preserved.
+func (r *Registry) Attach(sr *SwarmRoute) {
+	r.Subscribe(sr.SetEndpoints)
+}
+
+func (r *Registry) instancesLocked(service string) []Instance {
+	insts := r.instances[service]
+	out := make([]Instance, 0, len(insts))
+	for _, inst := range insts {
+		out = append(out, *inst)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Addr < out[j].Addr })
+	return out
+}
+
+func (r *Registry) addrsLocked(service string) []string {
+	addrs := make([]string, 0, len(r.instances[service]))
+	for addr := range r.instances[service] {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// changedLocked notifies subscribers of the current membership of service
+// unless the change was a refresh of an existing instance.  Notifying under
+// the lock keeps notifications in the order of the changes.
+func (r *Registry) changedLocked(refresh bool, service string) {
+	if refresh || len(r.subs) == 0 {
+		return
+	}
+	addrs := r.addrsLocked(service)
+	for _, fn := range r.subs {
+		fn(service, slices.Clone(addrs))
+	}
+}
+
+// registration is the JSON body of the registry HTTP API.
+type registration struct {
+	Service string            `json:"service"`
+	Addr    string            `json:"addr"`
+	Meta    map[string]string `json:"meta,omitempty"`
+}
+
+// Handler exposes the registry over HTTP so server processes can register
+// and heartbeat from other processes:
+//
+//	POST /v1/register    {"service":..., "addr":..., "meta":{...}}
+//	POST /v1/heartbeat   {"service":..., "addr":...}   (404 if unknown)
+//	POST /v1/deregister  {"service":..., "addr":...}
+//	GET  /v1/services    {"<service>": [instances...]}
+func (r *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	decode := func(w http.ResponseWriter, req *http.Request) (registration, bool) {
+		var reg registration
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return reg, false
+		}
+		if err := json.NewDecoder(req.Body).Decode(&reg); err != nil {
+			http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+			return reg, false
+		}
+		if reg.Service == "" || reg.Addr == "" {
+			http.Error(w, "service and addr are required", http.StatusBadRequest)
+			return reg, false
+		}
+		return reg, true
+	}
+	mux.HandleFunc("/v1/register", func(w http.ResponseWriter, req *http.Request) {
+		if reg, ok := decode(w, req); ok {
+			r.Register(reg.Service, reg.Addr, reg.Meta)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("/v1/heartbeat", func(w http.ResponseWriter, req *http.Request) {
+		if reg, ok := decode(w, req); ok {
+			if err := r.Heartbeat(reg.Service, reg.Addr); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("/v1/deregister", func(w http.ResponseWriter, req *http.Request) {
+		if reg, ok := decode(w, req); ok {
+			r.Deregister(reg.Service, reg.Addr)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("/v1/services", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(r.Services())
+	})
+	return mux
+}
+
+// RegistryClient talks to a Registry served over HTTP by Handler.
+type RegistryClient struct {
+	// BaseURL is the registry root, e.g. "http://registry:7070".
+	BaseURL string
+	// HTTP is the client used for requests; http.DefaultClient if nil.
+	HTTP *http.Client
+}
+
+// Register registers an instance with the remote registry.
+func (c *RegistryClient) Register(ctx context.Context, service, addr string, meta map[string]string) error {
+	return c.post(ctx, "/v1/register", registration{Service: service, Addr: addr, Meta: meta})
+}
+
+// Heartbeat refreshes an instance.  It returns ErrNotRegistered if the
+// registry no longer knows the instance.
+func (c *RegistryClient) Heartbeat(ctx context.Context, service, addr string) error {
+	return c.post(ctx, "/v1/heartbeat", registration{Service: service, Addr: addr})
+}
+
+// Deregister removes an instance from the remote registry.
+func (c *RegistryClient) Deregister(ctx context.Context, service, addr string) error {
+	return c.post(ctx, "/v1/deregister", registration{Service: service, Addr: addr})
+}
+
+// Services fetches all registered instances from the remote registry.
+func (c *RegistryClient) Services(ctx context.Context) (map[string][]Instance, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.BaseURL, "/")+"/v1/services", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry: GET /v1/services: %s", resp.Status)
+	}
+	var out map[string][]Instance
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("registry: decode services: %w", err)
+	}
+	return out, nil
+}
+
+// KeepAlive registers an instance and heartbeats every interval until ctx is
+// done, re-registering if the registry expired it in the meantime.  On
+// return it deregisters the instance on a best-effort basis.  It is meant to
+// run in its own goroutine for the lifetime of a server process.  It
+// returns an error at once if interval is not positive.
+func (c *RegistryClient) KeepAlive(ctx context.Context, service, addr string, meta map[string]string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("registry: keep-alive interval must be positive, got %v", interval)
+	}
+	if err := c.Register(ctx, service, addr, meta); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			dctx, cancel := context.WithTimeout(context.Background(), interval)
+			_ = c.Deregister(dctx, service, addr)
+			cancel()
+			return ctx.Err()
+		case <-ticker.C:
+			// Transient errors are retried on the next tick.
+			if err := c.Heartbeat(ctx, service, addr); errors.Is(err, ErrNotRegistered) {
+				_ = c.Register(ctx, service, addr, meta)
+			}
+		}
+	}
+}
+
+// Sync polls the remote registry every interval and applies the membership
+// to sr via SetEndpoints until ctx is done.  Services that disappear from the
+// registry are left with an empty endpoint list.  It returns an error at
+// once if interval is not positive.
+func (c *RegistryClient) Sync(ctx context.Context, sr *SwarmRoute, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("registry: sync interval must be positive, got %v", interval)
+	}
+	known := make(map[string]bool)
+	apply := func() {
+		svcs, err := c.Services(ctx)
+		if err != nil {
+			return // keep the last known membership on transient errors
+		}
+		for svc, insts := range svcs {
+			addrs := make([]string, len(insts))
+			for i, inst := range insts {
+				addrs[i] = inst.Addr
+			}
+			sr.SetEndpoints(svc, addrs)
+			known[svc] = true
+		}
+		for svc := range known {
+			if _, ok := svcs[svc]; !ok {
+				sr.SetEndpoints(svc, nil)
+				delete(known, svc)
+			}
+		}
+	}
+	apply()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			apply()
+		}
+	}
+}
+
+func (c *RegistryClient) client() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return http.DefaultClient
+}
+
+func (c *RegistryClient) post(ctx context.Context, path string, body registration) error {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.BaseURL, "/")+path, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound && path == "/v1/heartbeat":
+		return ErrNotRegistered
+	case resp.StatusCode/100 != 2:
+		return fmt.Errorf("registry: POST %s: %s", path, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistryHTTPRoundTripUpdatesSwarmRoute(t *testing.T) {
+	reg := NewRegistry(time.Minute)
+	now := time.Now()
+	reg.now = func() time.Time { return now }
+	sr := NewSwarmRoute()
+	reg.Attach(sr)
+
+	srv := httptest.NewServer(reg.Handler())
+	defer srv.Close()
+	client := &RegistryClient{BaseURL: srv.URL}
+	ctx := context.Background()
+
+	if err := client.Register(ctx, "api", "http://a:8080", map[string]string{"zone": "z1"}); err != nil {
+		t.Fatalf("register a: %v", err)
+	}
+	if err := client.Register(ctx, "api", "http://b:8080", nil); err != nil {
+		t.Fatalf("register b: %v", err)
+	}
+	sr.ReportResult("api", "http://a:8080", 0.01, true)
+	posA, _ := getPosNeg(t, sr, "api", "http://a:8080")
+
+	// b stops heartbeating; a keeps going. After the TTL only a remains and keeps its pheromone.
+	now = now.Add(40 * time.Second)
+	if err := client.Heartbeat(ctx, "api", "http://a:8080"); err != nil {
+		t.Fatalf("heartbeat a: %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if n := reg.Expire(); n != 1 {
+		t.Fatalf("expected 1 expired instance, got %d", n)
+	}
+	if err := client.Heartbeat(ctx, "api", "http://b:8080"); err != ErrNotRegistered {
+		t.Fatalf("expected ErrNotRegistered for expired instance, got %v", err)
+	}
+	snap := sr.PheromoneSnapshot()["api"]
+	if len(snap) != 1 {
+		t.Fatalf("expected only a to remain in SwarmRoute, got %v", snap)
+	}
+	if got, _ := getPosNeg(t, sr, "api", "http://a:8080"); got != posA {
+		t.Fatalf("expected pheromone of surviving endpoint to be preserved: before=%f after=%f", posA, got)
+	}
+
+	svcs, err := client.Services(ctx)
+	if err != nil {
+		t.Fatalf("services: %v", err)
+	}
+	if insts := svcs["api"]; len(insts) != 1 || insts[0].Meta["zone"] != "z1" {
+		t.Fatalf("unexpected instances: %+v", svcs)
+	}
+}
+
+// TestRegistryNotifiesInOrder checks that a subscriber's last notification
+// matches the final membership when changes race.
+func TestRegistryNotifiesInOrder(t *testing.T) {
+	reg := NewRegistry(0)
+	var last []string
+	reg.Subscribe(func(_ string, endpoints []string) { last = endpoints })
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			addr := fmt.Sprintf("http://%d:8080", i)
+			for range 200 {
+				reg.Register("api", addr, nil)
+				reg.Deregister("api", addr)
+			}
+			if i%2 == 0 {
+				reg.Register("api", addr, nil)
+			}
+		})
+	}
+	wg.Wait()
+	var want []string
+	for _, inst := range reg.Instances("api") {
+		want = append(want, inst.Addr)
+	}
+	if !slices.Equal(last, want) {
+		t.Fatalf("expected the last notification to match %v, got %v", want, last)
+	}
+}
+
+func TestRegistryClientRejectsNonPositiveInterval(t *testing.T) {
+	client := &RegistryClient{BaseURL: "http://registry.invalid"}
+	ctx := context.Background()
+	if err := client.KeepAlive(ctx, "api", "http://a:8080", nil, 0); err == nil {
+		t.Fatal("expected KeepAlive to reject a zero interval")
+	}
+	if err := client.Sync(ctx, NewSwarmRoute(), -time.Second); err == nil {
+		t.Fatal("expected Sync to reject a negative interval")
+	}
+}
+
+func TestRegistryRunWithTinyTTL(t *testing.T) {
+	reg := NewRegistry(time.Nanosecond) // ttl/2 rounds down to zero
+	reg.Register("api", "10.0.0.1:80", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reg.Run(ctx)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for len(reg.Instances("api")) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	if n := len(reg.Instances("api")); n != 0 {
+		t.Fatalf("expected the instance to expire, %d left", n)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+// ShadowPolicy is a selection policy evaluated alongside the live one
+// without controlling traffic.  *SwarmRoute implements it, so a candidate
+// configuration can be shadowed with
+//
+//	candidate := sr.Clone()
+//	candidate.SetBaseWeight(0.2)
+//	sr.SetShadow(candidate)
+type ShadowPolicy interface {
+	PickEndpoint(service string) (string, error)
+	ReportResult(service, endpoint string, latency float64, success bool)
+}
+
+// ShadowStats compares the shadow policy with the live one for a service.
+type ShadowStats struct {
+	// Picks counts live picks the shadow was consulted on; Divergent counts
+	// those where it chose a different endpoint (or failed to choose).
+	Picks     uint64 `json:"picks"`
+	Divergent uint64 `json:"divergent"`
+	// DivergenceRate is Divergent/Picks.
+	DivergenceRate float64 `json:"divergence_rate"`
+	// Expected outcomes of each policy's choices, estimated from the live
+	// success rate and latency EWMA of the chosen endpoint at pick time.
+	// Endpoints without reports are left out of the averages.
+	LiveSuccessRate      float64 `json:"live_success_rate"`
+	ShadowSuccessRate    float64 `json:"shadow_success_rate"`
+	LiveLatencyEWMASec   float64 `json:"live_latency_ewma_sec"`
+	ShadowLatencyEWMASec float64 `json:"shadow_latency_ewma_sec"`
+}
+
+// shadowCounters accumulates the sums behind ShadowStats.
+type shadowCounters struct {
+	picks, divergent uint64
+	live, shadow     outcomeSums
+}
+
+type outcomeSums struct {
+	successRate, latency float64
+	n, nLatency          uint64
+}
+
+func (o *outcomeSums) add(ep *Endpoint) {
+	if ep == nil {
+		return
+	}
+	if total := ep.successes + ep.failures; total > 0 {
+		o.successRate += float64(ep.successes) / float64(total)
+		o.n++
+	}
+	if len(ep.latency.window) > 0 {
+		o.latency += ep.latency.ewma
+		o.nLatency++
+	}
+}
+
+func (o outcomeSums) means() (successRate, latency float64) {
+	if o.n > 0 {
+		successRate = o.successRate / float64(o.n)
+	}
+	if o.nLatency > 0 {
+		latency = o.latency / float64(o.nLatency)
+	}
+	return successRate, latency
+}
+
+// SetShadow attaches a shadow policy that is consulted on every
+// PickEndpoint and fed every ReportResult, but never controls traffic.
+// Reports always describe the live choice, so a shadow SwarmRoute learns
+// from the endpoints the live policy actually used.  A nil policy detaches
+// the shadow.  Attaching or detaching resets ShadowStats.
+//
+// A shadow SwarmRoute without an evaporation loop of its own, such as one
+// from Clone or NewManualSwarmRoute, is evaporated whenever sr is, so both
+// policies forget at the same pace; one from NewSwarmRoute keeps its own
+// loop and is left alone.
+func (sr *SwarmRoute) SetShadow(p ShadowPolicy) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.shadow = p
+	sr.shadowGen++
+	sr.shadowStats = make(map[string]*shadowCounters)
+}
+
+// ShadowStats returns the comparison between the shadow and the live
+// policy per service.
+func (sr *SwarmRoute) ShadowStats() map[string]ShadowStats {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	out := make(map[string]ShadowStats, len(sr.shadowStats))
+	for svc, c := range sr.shadowStats {
+		st := ShadowStats{Picks: c.picks, Divergent: c.divergent}
+		if c.picks > 0 {
+			st.DivergenceRate = float64(c.divergent) / float64(c.picks)
+		}
+		st.LiveSuccessRate, st.LiveLatencyEWMASec = c.live.means()
+		st.ShadowSuccessRate, st.ShadowLatencyEWMASec = c.shadow.means()
+		out[svc] = st
+	}
+	return out
+}
+
+// consultShadow asks the shadow for its choice and records how it compares
+// with the live choice.  It must be called without holding sr.mu.
+func (sr *SwarmRoute) consultShadow(shadow ShadowPolicy, gen int, service, live string) {
+	choice, err := shadow.PickEndpoint(service)
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.shadowGen != gen {
+		return // detached or replaced meanwhile
+	}
+	c := sr.shadowStats[service]
+	if c == nil {
+		c = &shadowCounters{}
+		sr.shadowStats[service] = c
+	}
+	c.picks++
+	if err != nil || choice != live {
+		c.divergent++
+	}
+	c.live.add(sr.findLocked(service, live))
+	if err == nil {
+		c.shadow.add(sr.findLocked(service, choice))
+	}
+}
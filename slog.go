@@ -0,0 +1,116 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// LogOptions configures the structured logging observer.
+type LogOptions struct {
+	// SampleEvery logs only every Nth occurrence of high-frequency events
+	// (exploration picks and no-healthy-endpoint picks), counted per service
+	// and event kind.  Values <= 1 log every occurrence.  Ejections and
+	// recoveries are rare and always logged.
+	SampleEvery int
+	// LogExploration enables logging of exploration picks (at debug level).
+	LogExploration bool
+}
+
+// NewSlogObserver returns an Observer that logs notable routing events with
+// log/slog, so it works with whatever handler the host application
+// configures:
+//
+//	endpoint ejected          WARN
+//	endpoint recovered        INFO
+//	no healthy endpoints      ERROR (sampled)
+//	exploration pick          DEBUG (sampled, only with LogExploration)
+//
+// Attach it with AddObserver.  A nil logger uses slog.Default().
+func NewSlogObserver(logger *slog.Logger, opts LogOptions) Observer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogObserver{logger: logger, opts: opts, counts: make(map[sampleKey]int)}
+}
+
+type sampleKey struct {
+	kind    string
+	service string
+}
+
+type slogObserver struct {
+	BaseObserver
+	logger *slog.Logger
+	opts   LogOptions
+
+	mu     sync.Mutex
+	counts map[sampleKey]int
+}
+
+// sample reports whether this occurrence should be logged and how many
+// occurrences the logged line stands for.
+func (o *slogObserver) sample(kind, service string) (bool, int) {
+	if o.opts.SampleEvery <= 1 {
+		return true, 1
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	k := sampleKey{kind: kind, service: service}
+	o.counts[k]++
+	if o.counts[k] < o.opts.SampleEvery {
+		return false, 0
+	}
+	n := o.counts[k]
+	o.counts[k] = 0
+	return true, n
+}
+
+func (o *slogObserver) ObservePick(ev PickEvent) {
+	if !ev.Explored || !o.opts.LogExploration || !o.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	if ok, n := o.sample("explore", ev.Service); ok {
+		o.logger.Debug("swarmroute: exploration pick",
+			slog.String("service", ev.Service), slog.String("endpoint", ev.Endpoint),
+			slog.Float64("weight", ev.Weight), slog.Int("occurrences", n))
+	}
+}
+
+func (o *slogObserver) ObserveHealth(ev HealthEvent) {
+	if ev.Ejected {
+		o.logger.Warn("swarmroute: endpoint ejected",
+			slog.String("service", ev.Service), slog.String("endpoint", ev.Endpoint), slog.Float64("neg", ev.Neg))
+		return
+	}
+	o.logger.Info("swarmroute: endpoint recovered",
+		slog.String("service", ev.Service), slog.String("endpoint", ev.Endpoint), slog.Float64("neg", ev.Neg))
+}
+
+func (o *slogObserver) ObserveExpiry(ev ExpiryEvent) {
+	o.logger.Info("swarmroute: endpoint expired",
+		slog.String("service", ev.Service), slog.String("endpoint", ev.Endpoint), slog.Duration("idle", ev.Idle))
+}
+
+func (o *slogObserver) ObserveNoHealthy(service string, total int) {
+	if ok, n := o.sample("no_healthy", service); ok {
+		o.logger.Error("swarmroute: no healthy endpoints",
+			slog.String("service", service), slog.Int("endpoints", total), slog.Int("occurrences", n))
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// StateVersion is the version of the State format written by Export.
+const StateVersion = 1
+
+// State is a complete, serializable copy of a SwarmRoute's learned state and
+// configuration.  It is the basis for persistence, debugging dumps and
+// transferring state between processes.
+type State struct {
+	Version  int                           `json:"version"`
+	Config   Config                        `json:"config"`
+	Services map[string][]EndpointSnapshot `json:"services"`
+	// ServiceConfigs holds per-service overrides (SetServiceConfig).
+	ServiceConfigs map[string]Config `json:"service_configs,omitempty"`
+}
+
+// EndpointSnapshot is the serialized state of one endpoint.
+type EndpointSnapshot struct {
+	Address string `json:"address"`
+	// Pheromones holds every QoS channel ("latency", "error").
+	Pheromones map[string]Pheromone `json:"pheromones"`
+	// State is the administrative state name; empty means active.
+	State string `json:"state,omitempty"`
+	// Weight is the static weight multiplier (SetEndpointWeight); nil, as
+	// in a snapshot without "weight", means the default of 1.
+	Weight   *float64         `json:"weight,omitempty"`
+	Counters EndpointCounters `json:"counters"`
+	LastSeen time.Time        `json:"last_seen"`
+}
+
+// Export returns a deep copy of the current state.
+func (sr *SwarmRoute) Export() State {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	st := State{Version: StateVersion, Config: sr.configLocked(), Services: make(map[string][]EndpointSnapshot, len(sr.services))}
+	for svc, c := range sr.overrides {
+		if st.ServiceConfigs == nil {
+			st.ServiceConfigs = make(map[string]Config, len(sr.overrides))
+		}
+		st.ServiceConfigs[svc] = c
+	}
+	for svc, eps := range sr.services {
+		snaps := make([]EndpointSnapshot, len(eps))
+		for i, ep := range eps {
+			ph := make(map[string]Pheromone, len(ep.Pheromones))
+			for ch, p := range ep.Pheromones {
+				ph[ch] = *p
+			}
+			weight := ep.static
+			snaps[i] = EndpointSnapshot{
+				Address:    ep.Address,
+				Pheromones: ph,
+				Weight:     &weight,
+				Counters:   EndpointCounters{Picks: ep.picks, Successes: ep.successes, Failures: ep.failures, InFlight: ep.inFlight},
+				LastSeen:   ep.lastSeen.Round(0),
+			}
+			if ep.state != StateActive {
+				snaps[i].State = ep.state.String()
+			}
+		}
+		st.Services[svc] = snaps
+	}
+	return st
+}
+
+// Import replaces all services, endpoints and configuration with st.
+// Observers are kept; imported endpoints start in the ejection state their
+// pheromones imply, without emitting health events.  The state is validated
+// before anything is changed: the global and per-service configs with
+// Config.Validate, and the endpoints' states and weights.
+func (sr *SwarmRoute) Import(st State) error {
+	if st.Version != StateVersion {
+		return fmt.Errorf("unsupported state version %d (want %d)", st.Version, StateVersion)
+	}
+	if err := st.Config.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	overrides := make(map[string]Config, len(st.ServiceConfigs))
+	for svc, c := range st.ServiceConfigs {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("config of service %s: %w", svc, err)
+		}
+		overrides[svc] = c
+	}
+	services := make(map[string][]*Endpoint, len(st.Services))
+	for svc, snaps := range st.Services {
+		eps := make([]*Endpoint, len(snaps))
+		for i, s := range snaps {
+			ep := newEndpoint(s.Address, time.Time{})
+			for ch, p := range s.Pheromones {
+				ep.Pheromones[ch] = &Pheromone{Pos: p.Pos, Neg: p.Neg}
+			}
+			if s.State != "" {
+				state, err := ParseEndpointState(s.State)
+				if err != nil {
+					return fmt.Errorf("service %s endpoint %s: %w", svc, s.Address, err)
+				}
+				ep.state = state
+			}
+			if s.Weight != nil {
+				if w := *s.Weight; w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+					return fmt.Errorf("service %s endpoint %s: weight must be a finite value >= 0, got %v", svc, s.Address, w)
+				}
+				ep.static = *s.Weight
+			}
+			ep.picks, ep.successes, ep.failures = s.Counters.Picks, s.Counters.Successes, s.Counters.Failures
+			ep.inFlight = max(s.Counters.InFlight, 0)
+			ep.lastSeen = s.LastSeen
+			eps[i] = ep
+		}
+		services[svc] = eps
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.now == nil {
+		sr.now = time.Now
+	}
+	sr.setConfigLocked(st.Config)
+	sr.overrides = overrides
+	// Imported endpoints count as confirmed now, so an endpoint TTL starts
+	// over after a restart.
+	now := sr.now()
+	for svc, eps := range services {
+		p := sr.paramsLocked(svc)
+		for _, ep := range eps {
+			ep.ejected = ep.Pheromones["error"].Neg > p.ExploreNegThreshold
+			ep.confirmed = now
+		}
+	}
+	sr.services = services
+	sr.pickCount = make(map[string]int)
+	return nil
+}
+
+// MarshalJSON encodes the result of Export.
+func (sr *SwarmRoute) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sr.Export())
+}
+
+// UnmarshalJSON decodes a State and applies it with Import.  Call it on an
+// instance created by NewSwarmRoute; a zero SwarmRoute gets the state but no
+// background evaporation.
+func (sr *SwarmRoute) UnmarshalJSON(data []byte) error {
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	return sr.Import(st)
+}
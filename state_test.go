@@ -0,0 +1,86 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStateJSONRoundTrip(t *testing.T) {
+	clock := time.Unix(4000, 0)
+	now := func() time.Time { return clock }
+	src := NewSwarmRoute()
+	src.now = now
+	// Without wall-clock evaporation the background loop leaves the
+	// pheromones alone, so the round trip doesn't depend on when it ticks.
+	src.SetEvaporationRate(0)
+	src.SetRequestEvapRate(0.001)
+	src.SetBaseWeight(0.05)
+	src.SetPeriodicExploration(100, 2)
+	src.AddService("api", []string{"A", "B"})
+	src.ReportResult("api", "A", 0.01, true)
+	for i := 0; i < 3; i++ {
+		src.ReportResult("api", "B", 0, false)
+	}
+	_ = src.SetEndpointState("api", "B", StateBanned)
+	_ = src.SetEndpointWeight("api", "A", 2)
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := NewSwarmRoute()
+	dst.now = now
+	clock = clock.Add(time.Minute)
+	if err := json.Unmarshal(data, dst); err != nil {
+		t.Fatal(err)
+	}
+	again, err := json.Marshal(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Fatalf("state mismatch after round trip:\nwant %s\ngot  %s", data, again)
+	}
+	if dst.Config() != src.Config() {
+		t.Fatalf("config mismatch: %+v vs %+v", dst.Config(), src.Config())
+	}
+	if w, _ := dst.Score("api", "A"); w <= 0 {
+		t.Fatalf("expected imported endpoint A to keep its weight, got %v", w)
+	}
+	if seen := dst.Stats("api")[0].LastSeen; !seen.Equal(time.Unix(4000, 0)) {
+		t.Fatalf("expected A last seen at the exported time, got %v", seen)
+	}
+
+	if err := dst.UnmarshalJSON([]byte(`{"version":99}`)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+	if err := dst.UnmarshalJSON([]byte(`{"version":1,"config":{"evaporation_rate":2}}`)); err == nil {
+		t.Fatal("expected error for an invalid global config")
+	}
+	if err := dst.UnmarshalJSON([]byte(`{"version":1,"config":{"evaporation_rate":0.05,"base_weight":0.1},"services":{"api":[{"address":"A","weight":-1}]}}`)); err == nil {
+		t.Fatal("expected error for a negative weight")
+	}
+	if err := dst.UnmarshalJSON([]byte(`{"version":1,"config":{"evaporation_rate":0.05,"base_weight":0.1},"services":{"api":[{"address":"A"}]}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if ex, err := dst.PickEndpointExplained("api"); err != nil || ex.Candidates[0].Static != 1 {
+		t.Fatalf("expected a snapshot without a weight to default to 1, got %+v %v", ex, err)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// EndpointCounters are cumulative per-endpoint counters maintained by
+// PickEndpoint and ReportResult.
+type EndpointCounters struct {
+	// Picks counts selections made by PickEndpoint.
+	Picks uint64 `json:"picks"`
+	// Successes and Failures count outcomes passed to ReportResult.
+	Successes uint64 `json:"successes"`
+	Failures  uint64 `json:"failures"`
+	// InFlight counts picks that have not been reported yet.  It is only
+	// meaningful if every pick is followed by exactly one report.
+	InFlight int `json:"in_flight"`
+}
+
+// SuccessRate returns Successes/(Successes+Failures), or 0 without reports.
+func (c EndpointCounters) SuccessRate() float64 {
+	n := c.Successes + c.Failures
+	if n == 0 {
+		return 0
+	}
+	return float64(c.Successes) / float64(n)
+}
+
+// Counters returns a snapshot of the per-endpoint counters keyed by service
+// and endpoint address.
+func (sr *SwarmRoute) Counters() map[string]map[string]EndpointCounters {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	out := make(map[string]map[string]EndpointCounters, len(sr.services))
+	for svc, eps := range sr.services {
+		m := make(map[string]EndpointCounters, len(eps))
+		for _, ep := range eps {
+			m[ep.Address] = EndpointCounters{Picks: ep.picks, Successes: ep.successes, Failures: ep.failures, InFlight: ep.inFlight}
+		}
+		out[svc] = m
+	}
+	return out
+}
+
+// latencyWindowSize is the number of recent successful latencies kept per
+// endpoint for quantile estimation.
+const latencyWindowSize = 256
+
+// latencyEWMAAlpha is the smoothing factor of the latency EWMA.
+const latencyEWMAAlpha = 0.1
+
+// latencyStats keeps an EWMA and a sliding window of recent latencies.
+type latencyStats struct {
+	ewma   float64
+	window []float64
+	next   int
+}
+
+func (l *latencyStats) add(v float64) {
+	if len(l.window) == 0 {
+		l.ewma = v
+	} else {
+		l.ewma += latencyEWMAAlpha * (v - l.ewma)
+	}
+	if len(l.window) < latencyWindowSize {
+		l.window = append(l.window, v)
+		return
+	}
+	l.window[l.next] = v
+	l.next = (l.next + 1) % latencyWindowSize
+}
+
+// quantiles returns the requested quantiles of the window (nearest rank).
+func (l *latencyStats) quantiles(qs ...float64) []float64 {
+	out := make([]float64, len(qs))
+	if len(l.window) == 0 {
+		return out
+	}
+	sorted := append([]float64(nil), l.window...)
+	sort.Float64s(sorted)
+	for i, q := range qs {
+		idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+		out[i] = sorted[max(0, min(idx, len(sorted)-1))]
+	}
+	return out
+}
+
+// EndpointStats is an operator-oriented summary of one endpoint.  Unlike raw
+// pheromone values it is expressed in requests, ratios and seconds.
+type EndpointStats struct {
+	Address string `json:"address"`
+	// State is the administrative state (see SetEndpointState).
+	State string `json:"state"`
+	// Ejected reports whether the endpoint's error pheromone is above the
+	// ejection threshold.
+	Ejected bool `json:"ejected"`
+	EndpointCounters
+	// Requests is Successes+Failures.
+	Requests    uint64  `json:"requests"`
+	SuccessRate float64 `json:"success_rate"`
+	// Latency statistics in seconds over successful calls.  The EWMA uses a
+	// smoothing factor of 0.1; quantiles cover the last 256 successes.
+	LatencyEWMASec float64 `json:"latency_ewma_sec"`
+	LatencyP50Sec  float64 `json:"latency_p50_sec"`
+	LatencyP95Sec  float64 `json:"latency_p95_sec"`
+	LatencyP99Sec  float64 `json:"latency_p99_sec"`
+	// LastSeen is the time of the last report; zero if never reported.
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Stats returns per-endpoint statistics of a service in registration order,
+// or nil if the service is unknown.
+func (sr *SwarmRoute) Stats(service string) []EndpointStats {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	eps := sr.services[service]
+	if eps == nil {
+		return nil
+	}
+	out := make([]EndpointStats, len(eps))
+	for i, ep := range eps {
+		c := EndpointCounters{Picks: ep.picks, Successes: ep.successes, Failures: ep.failures, InFlight: ep.inFlight}
+		q := ep.latency.quantiles(0.50, 0.95, 0.99)
+		out[i] = EndpointStats{
+			Address:          ep.Address,
+			State:            ep.state.String(),
+			Ejected:          ep.ejected,
+			EndpointCounters: c,
+			Requests:         c.Successes + c.Failures,
+			SuccessRate:      c.SuccessRate(),
+			LatencyEWMASec:   ep.latency.ewma,
+			LatencyP50Sec:    q[0],
+			LatencyP95Sec:    q[1],
+			LatencyP99Sec:    q[2],
+			LastSeen:         ep.lastSeen,
+		}
+	}
+	return out
+}
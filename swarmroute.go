@@ -34,6 +34,29 @@ type Pheromone struct {
 type Endpoint struct {
 	Address    string
 	Pheromones map[string]*Pheromone
+	// conns counts live long-lived connections opened via PickConnection.
+	conns int
+	// warmUntil is the time until which the endpoint is assumed to have warm
+	// pooled connections; see SetReuseBonus.
+	warmUntil time.Time
+	// ejected is set while the error pheromone exceeds the terrible
+	// threshold; transitions are reported to observers as HealthEvents.
+	ejected bool
+	// state is the administrative state; only active endpoints are picked.
+	state EndpointState
+	// static is the operator-assigned weight multiplier (SetEndpointWeight).
+	static float64
+	// latency tracks successful call latencies for Stats; lastSeen is the
+	// time of the last report and confirmed the last time discovery listed
+	// the endpoint.
+	latency   latencyStats
+	lastSeen  time.Time
+	confirmed time.Time
+	// windows are the scheduled maintenance windows (AddMaintenanceWindow).
+	windows []MaintenanceWindow
+	// Cumulative counters; inFlight counts picks not yet reported.
+	picks, successes, failures uint64
+	inFlight                   int
 }
 
 // SwarmRoute maintains pheromone tables for multiple services and handles
@@ -66,12 +89,56 @@ type SwarmRoute struct {
 	// On bad events, reduce accumulated positive pheromone by this fraction
 	// (0..1). Default 0 to preserve prior behavior.
 	alphaBad float64
+	// Expected lifetime of a healthy long-lived connection; see
+	// SetConnectionLifetimeTarget.  0 rewards every normal close fully.
+	connLifetimeTarget time.Duration
+	// Stickiness bonus for endpoints with warm pooled connections: their
+	// weight is multiplied by (1 + reuseBonus).  0 disables reuse awareness.
+	reuseBonus float64
+	// How long an endpoint stays warm after a successful call or a pool report.
+	warmIdleTimeout time.Duration
+	// Endpoints with neither a report nor a discovery confirmation for this
+	// long are dropped by the evaporation loop.  0 disables the TTL.
+	endpointTTL time.Duration
+	// now is the clock used for time-based state; tests may replace it.
+	now func() time.Time
+	// rng, if set by SetSeed, replaces the global math/rand source for
+	// selection.  Guarded by mu like the pick counters.
+	rng *rand.Rand
+	// observers receive pick/report/health notifications; see AddObserver.
+	observers      []registeredObserver
+	nextObserverID int
+	// overrides holds per-service tunables replacing the global ones; see
+	// SetServiceConfig.
+	overrides map[string]Config
+	// failover configures PickCluster.
+	failover FailoverPolicy
+	// shadow is consulted on every pick and fed every report; see SetShadow.
+	shadow      ShadowPolicy
+	shadowGen   int
+	shadowStats map[string]*shadowCounters
+	// autoTune, if set, adjusts the tunables of every service; see
+	// SetAutoTune.
+	autoTune *autoTuner
+	// stop ends the background evaporation loop; nil if none runs.  See
+	// Close.
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 // NewSwarmRoute returns a new SwarmRoute with sensible defaults and starts
 // a background evaporation goroutine.
 func NewSwarmRoute() *SwarmRoute {
-	sr := &SwarmRoute{
+	sr := newSwarmRoute()
+	sr.stop = make(chan struct{})
+	go sr.evaporateLoop(sr.stop)
+	return sr
+}
+
+// newSwarmRoute returns a SwarmRoute with the defaults of NewSwarmRoute
+// and no evaporation loop.
+func newSwarmRoute() *SwarmRoute {
+	return &SwarmRoute{
 		services:            make(map[string][]*Endpoint),
 		evaporationRate:     0.05, // 5% evaporation per second
 		posReinforce:        1.0,
@@ -83,9 +150,59 @@ func NewSwarmRoute() *SwarmRoute {
 		pickCount:           make(map[string]int),
 		slowThresholdSec:    0.0, // disabled by default
 		alphaBad:            0.0, // no decay on bad events by default
+		now:                 time.Now,
 	}
-	go sr.evaporateLoop()
-	return sr
+}
+
+// Close stops the background evaporation goroutine started by
+// NewSwarmRoute.  The instance stays usable, but pheromones no longer
+// decay on the wall clock.  Close is idempotent.
+func (sr *SwarmRoute) Close() {
+	sr.stopOnce.Do(func() {
+		if sr.stop != nil {
+			close(sr.stop)
+		}
+	})
+}
+
+// SetSeed makes selection deterministic: picks draw from a private random
+// source seeded with seed instead of the global math/rand source, so the
+// same sequence of calls yields the same picks.  Meant for tests and
+// simulations.
+func (sr *SwarmRoute) SetSeed(seed int64) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.rng = rand.New(rand.NewSource(seed))
+}
+
+// intn and float64 draw from the seeded source if set, else from the
+// global one.  Call with mu held for writing.
+func (sr *SwarmRoute) intn(n int) int {
+	if sr.rng != nil {
+		return sr.rng.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+func (sr *SwarmRoute) float64() float64 {
+	if sr.rng != nil {
+		return sr.rng.Float64()
+	}
+	return rand.Float64()
+}
+
+// SetEvaporationRate sets the fraction (0..1) of pheromone evaporated per
+// second by the background loop.
+func (sr *SwarmRoute) SetEvaporationRate(r float64) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if r < 0 {
+		r = 0
+	}
+	if r > 1 {
+		r = 1
+	}
+	sr.evaporationRate = r
 }
 
 // SetRequestEvapRate sets the per-request evaporation rate (0..1).
@@ -176,37 +293,134 @@ func (sr *SwarmRoute) AddService(name string, endpoints []string) {
 	sr.mu.Lock()
 	defer sr.mu.Unlock()
 	eps := make([]*Endpoint, len(endpoints))
+	now := sr.now()
 	for i, addr := range endpoints {
-		eps[i] = &Endpoint{
-			Address: addr,
-			Pheromones: map[string]*Pheromone{
-				"latency": {Pos: 0, Neg: 0},
-				"error":   {Pos: 0, Neg: 0},
-			},
+		eps[i] = newEndpoint(addr, now)
+	}
+	sr.services[name] = eps
+}
+
+// SetEndpoints updates the endpoint list of a service while preserving the
+// learned state of endpoints that remain in the list.  New addresses start
+// with empty pheromones and addresses no longer listed are dropped.  Unlike
+// AddService it is safe to call on every discovery update.  The service is
+// created if it does not exist yet.
+func (sr *SwarmRoute) SetEndpoints(name string, endpoints []string) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	existing := make(map[string]*Endpoint, len(sr.services[name]))
+	for _, ep := range sr.services[name] {
+		existing[ep.Address] = ep
+	}
+	seen := make(map[string]bool, len(endpoints))
+	eps := make([]*Endpoint, 0, len(endpoints))
+	now := sr.now()
+	for _, addr := range endpoints {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		if ep, ok := existing[addr]; ok {
+			ep.confirmed = now
+			eps = append(eps, ep)
+			continue
 		}
+		eps = append(eps, newEndpoint(addr, now))
 	}
 	sr.services[name] = eps
 }
 
+// Services returns the endpoint addresses of every registered service, in
+// registration order.
+func (sr *SwarmRoute) Services() map[string][]string {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	out := make(map[string][]string, len(sr.services))
+	for svc, eps := range sr.services {
+		addrs := make([]string, len(eps))
+		for i, ep := range eps {
+			addrs[i] = ep.Address
+		}
+		out[svc] = addrs
+	}
+	return out
+}
+
+// newEndpoint returns an endpoint with empty "latency" and "error" channels,
+// confirmed by discovery at now.
+func newEndpoint(addr string, now time.Time) *Endpoint {
+	return &Endpoint{
+		Address:   addr,
+		static:    1,
+		confirmed: now,
+		Pheromones: map[string]*Pheromone{
+			"latency": {Pos: 0, Neg: 0},
+			"error":   {Pos: 0, Neg: 0},
+		},
+	}
+}
+
 // PickEndpoint selects an endpoint for the given service name using a
 // weighted-random strategy based on pheromones.  Endpoints with higher
 // positive pheromone and lower negative pheromone are more likely to be
 // chosen.  It returns an error if the service has no endpoints.
 func (sr *SwarmRoute) PickEndpoint(service string) (string, error) {
+	addr, _, err := sr.pick(service, nil, false)
+	return addr, err
+}
+
+// PickEndpointExcluding selects an endpoint like PickEndpoint while
+// avoiding the given endpoints, e.g. the ones a retried request already
+// failed on.  If every available endpoint is excluded the exclusion is
+// ignored, since retrying on a failed endpoint beats not retrying at all.
+func (sr *SwarmRoute) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	addr, _, err := sr.pick(service, exclude, false)
+	return addr, err
+}
+
+// pick implements PickEndpoint, skipping the endpoints in exclude unless
+// that leaves none; with explain set it also returns the weight breakdown
+// of every endpoint at decision time.
+func (sr *SwarmRoute) pick(service string, exclude []string, explain bool) (string, *Explanation, error) {
 	sr.mu.Lock()
-	eps, ok := sr.services[service]
-	if !ok || len(eps) == 0 {
+	obs := sr.observers
+	all, ok := sr.services[service]
+	if !ok || len(all) == 0 {
+		sr.mu.Unlock()
+		for _, ro := range obs {
+			ro.o.ObserveNoHealthy(service, 0)
+		}
+		return "", nil, fmt.Errorf("no endpoints for service %s", service)
+	}
+	eps := selectable(all, sr.now())
+	if len(eps) == 0 {
 		sr.mu.Unlock()
-		return "", fmt.Errorf("no endpoints for service %s", service)
+		for _, ro := range obs {
+			ro.o.ObserveNoHealthy(service, len(all))
+		}
+		return "", nil, fmt.Errorf("no available endpoints for service %s (all drained, banned or in maintenance)", service)
+	}
+	if len(exclude) > 0 {
+		eps = without(eps, exclude)
 	}
+	allEjected := true
+	for _, ep := range eps {
+		if !ep.ejected {
+			allEjected = false
+			break
+		}
+	}
+	p := sr.paramsLocked(service)
 	// Periodic forced exploration if configured.
 	sr.pickCount[service]++
-	doExplore := sr.exploreEveryN > 0 && (sr.pickCount[service]%sr.exploreEveryN == 0)
+	doExplore := p.ExploreEveryN > 0 && (sr.pickCount[service]%p.ExploreEveryN == 0)
+	var chosen *Endpoint
+	explored := false
 	if doExplore {
 		// Build a list of non-terrible endpoints based on negative pheromone.
 		candidates := make([]*Endpoint, 0, len(eps))
 		for _, ep := range eps {
-			if ep.Pheromones["error"].Neg <= sr.exploreNegThreshold {
+			if ep.Pheromones["error"].Neg <= p.ExploreNegThreshold {
 				candidates = append(candidates, ep)
 			}
 		}
@@ -214,116 +428,210 @@ func (sr *SwarmRoute) PickEndpoint(service string) (string, error) {
 			candidates = eps
 		}
 		// Sample uniformly among candidates.
-		idx := rand.Intn(len(candidates))
-		addr := candidates[idx].Address
-		sr.mu.Unlock()
-		return addr, nil
-	}
-	// Snapshot needed values under lock to avoid races with background evaporation.
-	type snap struct {
-		addr     string
-		pos, neg float64
+		chosen = candidates[sr.intn(len(candidates))]
+		explored = true
+	} else {
+		// Sample under lock to avoid races with background evaporation.
+		weights := make([]float64, len(eps))
+		total := 0.0
+		for i, ep := range eps {
+			weights[i] = sr.weightLocked(&p, ep)
+			total += weights[i]
+		}
+		// sample using cumulative distribution; the last endpoint is the
+		// fallback for rounding at the top of the range.
+		chosen = eps[len(eps)-1]
+		r := sr.float64() * total
+		cum := 0.0
+		for i, w := range weights {
+			cum += w
+			if r <= cum {
+				chosen = eps[i]
+				break
+			}
+		}
 	}
-	snaps := make([]snap, len(eps))
-	for i, ep := range eps {
-		snaps[i] = snap{addr: ep.Address, pos: ep.Pheromones["latency"].Pos, neg: ep.Pheromones["error"].Neg}
+	chosen.picks++
+	chosen.inFlight++
+	ev := PickEvent{Service: service, Endpoint: chosen.Address, Weight: sr.weightLocked(&p, chosen), Explored: explored}
+	var ex *Explanation
+	if explain {
+		ex = sr.explainLocked(&p, service, all, chosen, explored)
 	}
-	baseWeight := sr.baseWeight
+	shadow, shadowGen := sr.shadow, sr.shadowGen
 	sr.mu.Unlock()
-	weights := make([]float64, len(snaps))
-	total := 0.0
-	for i, sp := range snaps {
-		// combine latency positive pheromone and error negative pheromone.
-		pos := sp.pos
-		neg := sp.neg
-		// avoid zero weight by adding a small constant.
-		weight := (pos + baseWeight) / (1.0 + neg)
-		weights[i] = weight
-		total += weight
-	}
-	// sample using cumulative distribution.
-	r := rand.Float64() * total
-	cum := 0.0
-	for i, w := range weights {
-		cum += w
-		if r <= cum {
-			return snaps[i].addr, nil
+	for _, ro := range obs {
+		if allEjected {
+			ro.o.ObserveNoHealthy(service, len(eps))
 		}
+		ro.o.ObservePick(ev)
+	}
+	if shadow != nil {
+		sr.consultShadow(shadow, shadowGen, service, ev.Endpoint)
+	}
+	return ev.Endpoint, ex, nil
+}
+
+// weightLocked returns the selection weight of ep under the service
+// parameters p, combining latency positive pheromone and error negative
+// pheromone, scaled by the endpoint's static weight.  The base weight avoids
+// zero weight for cold endpoints.  Callers must hold sr.mu.
+func (sr *SwarmRoute) weightLocked(p *Config, ep *Endpoint) float64 {
+	pos := ep.Pheromones["latency"].Pos
+	neg := ep.Pheromones["error"].Neg
+	w := ep.static * (pos + p.BaseWeight) / (1.0 + neg)
+	if p.ReuseBonus > 0 && sr.now().Before(ep.warmUntil) {
+		w *= 1 + p.ReuseBonus
+	}
+	if len(ep.windows) > 0 {
+		_, ramp := ep.maintenance(sr.now())
+		w *= ramp
 	}
-	// fallback (should not happen).
-	return snaps[len(snaps)-1].addr, nil
+	return w
 }
 
 // ReportResult updates the pheromone values after a call has completed.  A
 // successful call deposits positive pheromone inversely proportional to
 // observed latency and slightly reduces accumulated error pheromone.  A
-// failed call deposits negative pheromone.
+// failed call deposits negative pheromone.  Reports for a banned endpoint
+// are ignored.
 func (sr *SwarmRoute) ReportResult(service, endpoint string, latency float64, success bool) {
 	sr.mu.Lock()
-	defer sr.mu.Unlock()
+	var health []HealthEvent
 	// Apply per-request evaporation across all pheromones to decouple from wall-clock.
-	if sr.reqEvapRate > 0 {
-		factor := 1.0 - sr.reqEvapRate
-		for _, eps := range sr.services {
+	if sr.reqEvapRate > 0 || len(sr.overrides) > 0 || sr.autoTune != nil {
+		for svc, eps := range sr.services {
+			q := sr.paramsLocked(svc)
+			if q.ReqEvapRate <= 0 {
+				continue
+			}
+			factor := 1.0 - q.ReqEvapRate
 			for _, ep := range eps {
 				for _, p := range ep.Pheromones {
 					p.Pos *= factor
 					p.Neg *= factor
 				}
+				health = sr.checkHealthLocked(&q, svc, ep, health)
 			}
 		}
 	}
-	eps, ok := sr.services[service]
-	if !ok {
-		return
-	}
-	isSlow := sr.slowThresholdSec > 0 && latency > sr.slowThresholdSec
-	for _, ep := range eps {
+	obs := sr.observers
+	p := sr.paramsLocked(service)
+	isSlow := p.SlowThresholdSec > 0 && latency > p.SlowThresholdSec
+	for _, ep := range sr.services[service] {
 		if ep.Address == endpoint {
+			if ep.state == StateBanned {
+				// A ban abandons the calls in flight; see StateBanned.
+				break
+			}
+			if sr.autoTune != nil {
+				sr.autoTune.observe(service, endpoint, sr.configuredParamsLocked(service), latency, success, sr.now())
+			}
 			if !success || isSlow {
 				// Treat failure or too-slow success as a bad event.
-				ep.Pheromones["error"].Neg += sr.negReinforce
-				if sr.alphaBad > 0 {
-					ep.Pheromones["latency"].Pos *= (1 - sr.alphaBad)
+				ep.Pheromones["error"].Neg += p.NegReinforce
+				if p.BadPosDecay > 0 {
+					ep.Pheromones["latency"].Pos *= (1 - p.BadPosDecay)
 				}
 				// Note: do not add positive pheromone on slow successes.
 				if success {
 					// still allow a small forgiveness of error on success to avoid permanent stickiness
-					ep.Pheromones["error"].Neg *= (1 - sr.evaporationRate)
+					ep.Pheromones["error"].Neg *= (1 - p.EvaporationRate)
 				}
 			} else {
 				// Fast, successful call → deposit positive pheromone inversely to latency.
-				delta := sr.posReinforce / (latency + 1e-6)
+				delta := p.PosReinforce / (latency + 1e-6)
 				ep.Pheromones["latency"].Pos += delta
 				// decay some of the error pheromone if present.
-				ep.Pheromones["error"].Neg *= (1 - sr.evaporationRate)
+				ep.Pheromones["error"].Neg *= (1 - p.EvaporationRate)
+			}
+			if success {
+				ep.successes++
+				ep.latency.add(latency)
+			} else {
+				ep.failures++
+			}
+			ep.lastSeen = sr.now()
+			if ep.inFlight > 0 {
+				ep.inFlight--
 			}
+			if success && p.ReuseBonus > 0 {
+				// A completed call leaves a pooled connection behind.
+				ep.warmUntil = sr.now().Add(secondsToDuration(p.WarmIdleTimeoutSec))
+			}
+			health = sr.checkHealthLocked(&p, service, ep, health)
 			break
 		}
 	}
+	shadow := sr.shadow
+	sr.mu.Unlock()
+	if shadow != nil {
+		shadow.ReportResult(service, endpoint, latency, success)
+	}
+	if len(obs) == 0 {
+		return
+	}
+	ev := ReportEvent{Service: service, Endpoint: endpoint, LatencySec: latency, Success: success, Slow: success && isSlow}
+	for _, ro := range obs {
+		ro.o.ObserveReport(ev)
+		for _, h := range health {
+			ro.o.ObserveHealth(h)
+		}
+	}
 }
 
-// evaporateOnce applies a single evaporation step to all pheromone values.
-// It is unexported but testable by package tests for deterministic checks.
+// evaporateOnce applies a single evaporation step to all pheromone values,
+// and to those of a shadow SwarmRoute without a loop of its own (see
+// SetShadow).  It is unexported but testable by package tests for
+// deterministic checks.
 func (sr *SwarmRoute) evaporateOnce() {
+	if s, ok := sr.decay().(*SwarmRoute); ok && s != sr && s.stop == nil {
+		s.decay()
+	}
+}
+
+// decay applies one evaporation step to sr alone and returns its shadow
+// policy, so that evaporateOnce never cascades past a single shadow.
+func (sr *SwarmRoute) decay() ShadowPolicy {
 	sr.mu.Lock()
-	defer sr.mu.Unlock()
-	for _, eps := range sr.services {
+	var health []HealthEvent
+	for svc, eps := range sr.services {
+		q := sr.paramsLocked(svc)
 		for _, ep := range eps {
 			for _, p := range ep.Pheromones {
-				p.Pos *= (1.0 - sr.evaporationRate)
-				p.Neg *= (1.0 - sr.evaporationRate)
+				p.Pos *= (1.0 - q.EvaporationRate)
+				p.Neg *= (1.0 - q.EvaporationRate)
 			}
+			health = sr.checkHealthLocked(&q, svc, ep, health)
 		}
 	}
+	expired := sr.expireLocked()
+	obs, shadow := sr.observers, sr.shadow
+	sr.mu.Unlock()
+	for _, ro := range obs {
+		for _, h := range health {
+			ro.o.ObserveHealth(h)
+		}
+		for _, ev := range expired {
+			ro.o.ObserveExpiry(ev)
+		}
+	}
+	return shadow
 }
 
 // evaporateLoop runs in a separate goroutine and periodically decays all
-// pheromone values to allow the system to forget outdated information.
-func (sr *SwarmRoute) evaporateLoop() {
+// pheromone values to allow the system to forget outdated information,
+// until stop is closed.
+func (sr *SwarmRoute) evaporateLoop(stop <-chan struct{}) {
 	ticker := time.NewTicker(time.Second)
-	for range ticker.C {
-		sr.evaporateOnce()
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sr.evaporateOnce()
+		case <-stop:
+			return
+		}
 	}
 }
 
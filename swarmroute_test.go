@@ -17,8 +17,17 @@
 package swarmroute
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log/slog"
 	"math"
 	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -183,6 +192,29 @@ func TestEvaporationLoopDecaysValues(t *testing.T) {
 	}
 }
 
+func TestCloseStopsEvaporationLoop(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for range 100 {
+		NewSwarmRoute().Close()
+	}
+	sr := NewSwarmRoute()
+	sr.Close()
+	sr.Close() // idempotent
+	sr.Clone().Close()
+	// Closed loops exit asynchronously; give them a moment.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before+5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before+5 {
+		t.Fatalf("expected Close to end the evaporation goroutines, went from %d to %d", before, after)
+	}
+	sr.AddService("svc", []string{"A"})
+	if _, err := sr.PickEndpoint("svc"); err != nil {
+		t.Fatalf("expected a closed instance to stay usable: %v", err)
+	}
+}
+
 func TestEvaporationExactTick(t *testing.T) {
 	rand.Seed(101)
 	sr := NewSwarmRoute()
@@ -251,3 +283,788 @@ func TestExplorationNonZeroOtherEndpoints(t *testing.T) {
 		t.Fatalf("expected exploration to give non-zero selections to others; got B=%d C=%d", countB, countC)
 	}
 }
+
+func TestPickConnectionEqualizesLiveConnections(t *testing.T) {
+	rand.Seed(5)
+	sr := NewSwarmRoute()
+	sr.evaporationRate = 0
+	svc := "ws"
+	sr.AddService(svc, []string{"A", "B", "C"})
+
+	for i := 0; i < 30; i++ {
+		if _, err := sr.PickConnection(svc); err != nil {
+			t.Fatalf("unexpected error picking connection: %v", err)
+		}
+	}
+	for addr, n := range sr.Connections(svc) {
+		if n != 10 {
+			t.Fatalf("expected 10 live connections on %s, got %d", addr, n)
+		}
+	}
+
+	// An endpoint whose connections keep erroring out early should receive fewer new connections.
+	sr.SetConnectionLifetimeTarget(time.Minute)
+	for i := 0; i < 10; i++ {
+		sr.ReportConnectionClosed(svc, "C", time.Second, CloseError)
+	}
+	for i := 0; i < 20; i++ {
+		if _, err := sr.PickConnection(svc); err != nil {
+			t.Fatalf("unexpected error picking connection: %v", err)
+		}
+	}
+	conns := sr.Connections(svc)
+	if conns["C"] >= conns["A"] || conns["C"] >= conns["B"] {
+		t.Fatalf("expected failing endpoint C to receive fewer connections, got %v", conns)
+	}
+}
+
+func TestConnectionErrorPenalizedWithoutLifetimeTarget(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.evaporationRate = 0
+	sr.AddService("ws", []string{"A", "B"})
+	if _, err := sr.PickConnection("ws"); err != nil {
+		t.Fatal(err)
+	}
+	sr.ReportConnectionClosed("ws", "A", time.Hour, CloseError)
+	if _, neg := getPosNeg(t, sr, "ws", "A"); neg != sr.negReinforce {
+		t.Fatalf("expected the full negative reinforcement without a target, got %v", neg)
+	}
+	sr.ReportConnectionClosed("ws", "B", time.Hour, CloseGoingAway)
+	if _, neg := getPosNeg(t, sr, "ws", "B"); neg != 0 {
+		t.Fatalf("expected GOAWAY not to be penalized, got %v", neg)
+	}
+}
+
+func TestReuseBonusFavorsWarmEndpoint(t *testing.T) {
+	rand.Seed(8)
+	sr := NewSwarmRoute()
+	sr.evaporationRate = 0
+	svc := "api"
+	sr.AddService(svc, []string{"A", "B"})
+	sr.SetReuseBonus(3.0, time.Minute)
+	sr.ReportPoolState(svc, "A", 4)
+
+	total := 4000
+	countA := 0
+	for i := 0; i < total; i++ {
+		addr, err := sr.PickEndpoint(svc)
+		if err != nil {
+			t.Fatalf("unexpected error picking endpoint: %v", err)
+		}
+		if addr == "A" {
+			countA++
+		}
+	}
+	// With equal pheromone, a bonus of 3 gives A a 4:1 weight advantage (~80%).
+	if share := float64(countA) / float64(total); share < 0.75 || share > 0.85 {
+		t.Fatalf("expected warm endpoint A to get ~80%% of picks, got %.2f", share)
+	}
+
+	sr.ReportPoolState(svc, "A", 0)
+	countA = 0
+	for i := 0; i < total; i++ {
+		addr, _ := sr.PickEndpoint(svc)
+		if addr == "A" {
+			countA++
+		}
+	}
+	if share := float64(countA) / float64(total); share > 0.6 {
+		t.Fatalf("expected cold endpoints to be balanced again, A got %.2f", share)
+	}
+}
+
+// recordingObserver records events.  Observers are called from whichever
+// goroutine picks, reports or evaporates, so it is guarded by mu.
+type recordingObserver struct {
+	BaseObserver
+	mu      sync.Mutex
+	picks   int
+	health  []HealthEvent
+	expired []ExpiryEvent
+}
+
+func (o *recordingObserver) ObservePick(PickEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.picks++
+}
+
+func (o *recordingObserver) ObserveHealth(h HealthEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.health = append(o.health, h)
+}
+
+func (o *recordingObserver) ObserveExpiry(ev ExpiryEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.expired = append(o.expired, ev)
+}
+
+// recorded returns copies of the events recorded so far.
+func (o *recordingObserver) recorded() (picks int, health []HealthEvent, expired []ExpiryEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.picks, slices.Clone(o.health), slices.Clone(o.expired)
+}
+
+func TestObserverSeesEjectionAndRecovery(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.evaporationRate = 0.5
+	svc := "svc"
+	sr.AddService(svc, []string{"A", "B"})
+	obs := &recordingObserver{}
+	remove := sr.AddObserver(obs)
+
+	for i := 0; i < 5; i++ {
+		sr.ReportResult(svc, "B", 0, false)
+	}
+	if _, err := sr.PickEndpoint(svc); err != nil {
+		t.Fatalf("unexpected error picking endpoint: %v", err)
+	}
+	if _, health, _ := obs.recorded(); len(health) != 1 || !health[0].Ejected || health[0].Endpoint != "B" {
+		t.Fatalf("expected a single ejection of B, got %+v", health)
+	}
+	// Two evaporation ticks bring neg from 5 to 1.25, below the threshold of 3.
+	sr.evaporateOnce()
+	sr.evaporateOnce()
+	if _, health, _ := obs.recorded(); len(health) != 2 || health[1].Ejected {
+		t.Fatalf("expected B to recover after evaporation, got %+v", health)
+	}
+	remove()
+	_, _ = sr.PickEndpoint(svc)
+	if picks, _, _ := obs.recorded(); picks != 1 {
+		t.Fatalf("expected removed observer to stop receiving picks, got %d", picks)
+	}
+}
+
+func TestSlogObserverSamplesNoHealthy(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	sr := NewSwarmRoute()
+	sr.AddObserver(NewSlogObserver(logger, LogOptions{SampleEvery: 10}))
+
+	for i := 0; i < 25; i++ {
+		_, _ = sr.PickEndpoint("missing")
+	}
+	if n := strings.Count(buf.String(), "no healthy endpoints"); n != 2 {
+		t.Fatalf("expected 2 sampled log lines for 25 failed picks, got %d:\n%s", n, buf.String())
+	}
+
+	sr.AddService("svc", []string{"A"})
+	for i := 0; i < 5; i++ {
+		sr.ReportResult("svc", "A", 0, false)
+	}
+	if !strings.Contains(buf.String(), "endpoint ejected") {
+		t.Fatalf("expected ejection to be logged:\n%s", buf.String())
+	}
+}
+
+func TestPublishExpvarExposesState(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.SetBaseWeight(0.05)
+	sr.AddService("api", []string{"A"})
+	_, _ = sr.PickEndpoint("api")
+	sr.PublishExpvar("swarmroute_test")
+
+	var got ExpvarState
+	if err := json.Unmarshal([]byte(expvar.Get("swarmroute_test").String()), &got); err != nil {
+		t.Fatalf("decode expvar: %v", err)
+	}
+	if got.Config.BaseWeight != 0.05 || got.Counters["api"]["A"].Picks != 1 {
+		t.Fatalf("unexpected expvar state: %+v", got)
+	}
+	if _, ok := got.Pheromones["api"]["A"]; !ok {
+		t.Fatalf("expected pheromones for api/A in expvar state: %+v", got)
+	}
+}
+
+func TestPickEndpointExplained(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.SetBaseWeight(1)
+	sr.AddService("api", []string{"A", "B", "C"})
+	if err := sr.SetEndpointWeight("api", "B", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := sr.SetEndpointState("api", "C", StateBanned); err != nil {
+		t.Fatal(err)
+	}
+
+	ex, err := sr.PickEndpointExplained("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex.Endpoint != "A" && ex.Endpoint != "B" {
+		t.Fatalf("picked %q, want A or B", ex.Endpoint)
+	}
+	if len(ex.Candidates) != 3 {
+		t.Fatalf("expected 3 candidates, got %+v", ex.Candidates)
+	}
+	a, b, c := ex.Candidates[0], ex.Candidates[1], ex.Candidates[2]
+	if math.Abs(a.Probability-0.25) > 1e-9 || math.Abs(b.Probability-0.75) > 1e-9 || b.Static != 3 {
+		t.Fatalf("unexpected breakdown: A=%+v B=%+v", a, b)
+	}
+	if c.Excluded != "banned" || c.Probability != 0 {
+		t.Fatalf("expected C excluded as banned, got %+v", c)
+	}
+}
+
+func TestDrainFinishesInFlightCallsAndBanAbandonsThem(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.AddService("api", []string{"A"})
+	for range 4 {
+		if _, err := sr.PickEndpoint("api"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = sr.SetEndpointState("api", "A", StateDrained)
+	sr.ReportResult("api", "A", 0.010, true)
+	if c := sr.Counters()["api"]["A"]; c.InFlight != 3 || c.Successes != 1 {
+		t.Fatalf("expected a drained endpoint to finish its calls, got %+v", c)
+	}
+	pos, _ := getPosNeg(t, sr, "api", "A")
+	_ = sr.SetEndpointState("api", "A", StateBanned)
+	sr.ReportResult("api", "A", 0.010, true)
+	if c := sr.Counters()["api"]["A"]; c.InFlight != 0 || c.Successes != 1 {
+		t.Fatalf("expected a ban to abandon the calls in flight, got %+v", c)
+	}
+	if got, _ := getPosNeg(t, sr, "api", "A"); got != pos {
+		t.Fatalf("expected a banned endpoint's reports ignored, pos went from %v to %v", pos, got)
+	}
+}
+
+func TestStatsSummarizesEndpoint(t *testing.T) {
+	sr := NewSwarmRoute()
+	clock := time.Unix(2000, 0)
+	sr.now = func() time.Time { return clock }
+	sr.AddService("api", []string{"A", "B"})
+	for i := 1; i <= 100; i++ {
+		sr.ReportResult("api", "A", float64(i)/1000, true)
+	}
+	sr.ReportResult("api", "A", 5, false)
+	_ = sr.SetEndpointState("api", "B", StateDrained)
+
+	stats := sr.Stats("api")
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 endpoints, got %d", len(stats))
+	}
+	a := stats[0]
+	if a.Requests != 101 || a.Failures != 1 || math.Abs(a.SuccessRate-100.0/101) > 1e-9 {
+		t.Fatalf("unexpected counts: %+v", a)
+	}
+	if a.LatencyP50Sec != 0.050 || a.LatencyP99Sec != 0.099 {
+		t.Fatalf("unexpected quantiles: p50=%v p99=%v", a.LatencyP50Sec, a.LatencyP99Sec)
+	}
+	if a.LatencyEWMASec < 0.080 || a.LatencyEWMASec > 0.100 || !a.LastSeen.Equal(clock) {
+		t.Fatalf("unexpected ewma/last seen: %+v", a)
+	}
+	if stats[1].State != "drained" || !stats[1].LastSeen.IsZero() {
+		t.Fatalf("unexpected stats for B: %+v", stats[1])
+	}
+	if sr.Stats("missing") != nil {
+		t.Fatal("expected nil stats for unknown service")
+	}
+}
+
+func TestMergeSnapshotDiscountsStaleData(t *testing.T) {
+	newLocal := func(clock time.Time) *SwarmRoute {
+		sr := NewSwarmRoute()
+		sr.now = func() time.Time { return clock }
+		sr.AddService("api", []string{"A"})
+		return sr
+	}
+	now := time.Unix(5000, 0)
+	remote := map[string]map[string]Pheromone{"api": {"A": {Pos: 0, Neg: 10}, "unknown": {Neg: 1}}}
+
+	fresh := newLocal(now)
+	if n, err := fresh.MergeSnapshot(remote, 0.5, now); n != 1 || err != nil {
+		t.Fatalf("expected 1 merged endpoint, got %d %v", n, err)
+	}
+	if got := fresh.PheromoneSnapshot()["api"]["A"].Neg; math.Abs(got-5) > 1e-9 {
+		t.Fatalf("fresh merge: neg=%v, want 5", got)
+	}
+
+	stale := newLocal(now)
+	_, _ = stale.MergeSnapshot(remote, 0.5, now.Add(-time.Minute))
+	want := 10 * 0.5 * math.Pow(1-stale.evaporationRate, 60)
+	if got := stale.PheromoneSnapshot()["api"]["A"].Neg; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("stale merge: neg=%v, want %v", got, want)
+	}
+
+	for _, w := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		sr := newLocal(now)
+		before := sr.PheromoneSnapshot()
+		if n, err := sr.MergeSnapshot(remote, w, now); n != 0 || err == nil {
+			t.Fatalf("weight %v: expected an error, got %d %v", w, n, err)
+		}
+		if got := sr.PheromoneSnapshot(); got["api"]["A"] != before["api"]["A"] {
+			t.Fatalf("weight %v: pheromones changed to %+v", w, got["api"]["A"])
+		}
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	sr := NewSwarmRoute()
+	clock := time.Unix(5000, 0)
+	sr.now = func() time.Time { return clock }
+	// Without evaporation the background loop cannot move sr's pheromones
+	// before they are cloned.
+	sr.SetEvaporationRate(0)
+	sr.AddService("api", []string{"A", "B"})
+	sr.ReportResult("api", "A", 0.01, true)
+
+	c := sr.Clone()
+	c.SetBaseWeight(5)
+	c.ReportResult("api", "B", 0, false)
+	_ = c.SetEndpointState("api", "A", StateDrained)
+
+	if sr.Config().BaseWeight == 5 {
+		t.Fatal("clone config change leaked into original")
+	}
+	if sr.PheromoneSnapshot()["api"]["B"].Neg != 0 {
+		t.Fatal("clone report leaked into original")
+	}
+	if ep, err := sr.PickEndpointExplained("api"); err != nil || ep.Candidates[0].Excluded != "" {
+		t.Fatalf("clone state change leaked into original: %+v %v", ep, err)
+	}
+	if got, want := c.PheromoneSnapshot()["api"]["A"].Pos, 1/(0.01+1e-6); math.Abs(got-want) > 1e-6 {
+		t.Fatalf("clone lost state: pos=%v, want %v", got, want)
+	}
+	clock = clock.Add(time.Minute)
+	c.ReportResult("api", "B", 0.01, true)
+	if seen := c.Stats("api")[1].LastSeen; !seen.Equal(clock) {
+		t.Fatalf("expected the clone to keep the injected clock, got %v", seen)
+	}
+}
+
+// fixedPolicy always picks the same endpoint.
+type fixedPolicy struct{ addr string }
+
+func (p fixedPolicy) PickEndpoint(string) (string, error)      { return p.addr, nil }
+func (fixedPolicy) ReportResult(string, string, float64, bool) {}
+
+func TestShadowPolicyDivergence(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.AddService("api", []string{"good", "bad"})
+	for i := 0; i < 20; i++ {
+		sr.ReportResult("api", "good", 0.01, true)
+		sr.ReportResult("api", "bad", 0.5, false)
+	}
+	_ = sr.SetEndpointState("api", "bad", StateDrained) // live always picks good
+	sr.SetShadow(fixedPolicy{addr: "bad"})
+
+	for i := 0; i < 10; i++ {
+		if ep, err := sr.PickEndpoint("api"); err != nil || ep != "good" {
+			t.Fatalf("shadow must not control traffic: %q %v", ep, err)
+		}
+	}
+	st := sr.ShadowStats()["api"]
+	if st.Picks != 10 || st.Divergent != 10 || st.DivergenceRate != 1 {
+		t.Fatalf("unexpected divergence: %+v", st)
+	}
+	if st.LiveSuccessRate != 1 || st.ShadowSuccessRate != 0 {
+		t.Fatalf("unexpected hypothetical outcomes: %+v", st)
+	}
+
+	sr.SetShadow(nil)
+	_, _ = sr.PickEndpoint("api")
+	if len(sr.ShadowStats()) != 0 {
+		t.Fatalf("expected stats reset after detaching: %+v", sr.ShadowStats())
+	}
+}
+
+func TestShadowCloneEvaporatesWithPrimary(t *testing.T) {
+	sr := NewSwarmRoute()
+	defer sr.Close()
+	sr.AddService("api", []string{"A"})
+	for i := 0; i < 5; i++ {
+		sr.ReportResult("api", "A", 0.01, true)
+	}
+	shadow := sr.Clone()
+	sr.SetShadow(shadow)
+	live := sr.PheromoneSnapshot()["api"]["A"]
+
+	sr.evaporateOnce()
+	got := shadow.PheromoneSnapshot()["api"]["A"]
+	if want := sr.PheromoneSnapshot()["api"]["A"]; got != want || got.Pos >= live.Pos {
+		t.Fatalf("shadow did not evaporate with the primary: shadow %+v, live %+v", got, want)
+	}
+
+	// A shadow with its own loop is left to it.  The loop is not started,
+	// so that none of its ticks can land inside the test.
+	own := newSwarmRoute()
+	own.stop = make(chan struct{})
+	own.AddService("api", []string{"A"})
+	own.ReportResult("api", "A", 0.01, true)
+	before := own.PheromoneSnapshot()["api"]["A"]
+	sr.SetShadow(own)
+	sr.evaporateOnce()
+	if after := own.PheromoneSnapshot()["api"]["A"]; after != before {
+		t.Fatalf("shadow with its own loop was evaporated again: %+v -> %+v", before, after)
+	}
+}
+
+func TestApplyConfigIsAtomic(t *testing.T) {
+	sr := NewSwarmRoute()
+	a, b := sr.Config(), sr.Config()
+	a.ReqEvapRate, a.ExploreEveryN, a.BaseWeight = 0.01, 10, 0.1
+	b.ReqEvapRate, b.ExploreEveryN, b.BaseWeight = 0.02, 20, 0.2
+	sr.ApplyConfig(a)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2000; i++ {
+			if i%2 == 0 {
+				sr.ApplyConfig(a)
+			} else {
+				sr.ApplyConfig(b)
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if c := sr.Config(); c != a && c != b {
+			t.Fatalf("observed mixed configuration: %+v", c)
+		}
+	}
+}
+
+func TestPresetsApplyUnchanged(t *testing.T) {
+	for name, cfg := range Presets() {
+		sr := NewSwarmRouteWithConfig(cfg)
+		if got := sr.Config(); got != cfg {
+			t.Errorf("preset %s was altered when applied:\nwant %+v\ngot  %+v", name, cfg, got)
+		}
+	}
+	if c, a := ConservativeConfig(), AggressiveConfig(); c.ReqEvapRate >= a.ReqEvapRate {
+		t.Errorf("conservative memory should be longer than aggressive: %v vs %v", c.ReqEvapRate, a.ReqEvapRate)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for name, cfg := range Presets() {
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+
+	bad := DefaultConfig()
+	bad.BaseWeight = -1
+	bad.ReuseBonus = 0.5 // without a warm idle timeout
+	err := bad.Validate()
+	if err == nil || !strings.Contains(err.Error(), "base_weight") || !strings.Contains(err.Error(), "warm_idle_timeout_sec") {
+		t.Fatalf("expected both problems reported, got %v", err)
+	}
+
+	sr := NewSwarmRouteWithConfig(DefaultConfig())
+	if err := sr.ApplyConfigStrict(bad); err == nil {
+		t.Fatal("expected ApplyConfigStrict to reject invalid config")
+	}
+	if sr.Config() != DefaultConfig() {
+		t.Fatal("rejected config must not be applied")
+	}
+}
+
+func TestUpdateConfigComposesConcurrentUpdates(t *testing.T) {
+	sr := NewSwarmRouteWithConfig(DefaultConfig())
+	entered, release := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = sr.UpdateConfig(func(c *Config) error {
+			close(entered)
+			<-release
+			c.BaseWeight = 0.5
+			return nil
+		})
+	}()
+	<-entered
+	go func() {
+		defer wg.Done()
+		_, _ = sr.UpdateConfig(func(c *Config) error {
+			c.ExploreEveryN = 7
+			return nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond) // let the second update run if it can
+	close(release)
+	wg.Wait()
+	if got := sr.Config(); got.BaseWeight != 0.5 || got.ExploreEveryN != 7 {
+		t.Fatalf("an update was lost: base_weight %v, explore_every_n %d", got.BaseWeight, got.ExploreEveryN)
+	}
+
+	before := sr.Config()
+	if _, err := sr.UpdateConfig(func(c *Config) error {
+		c.BaseWeight = -1
+		return nil
+	}); err == nil || sr.Config() != before {
+		t.Fatalf("expected an invalid update to be rejected and not applied: %v", err)
+	}
+	if _, err := sr.UpdateConfig(func(c *Config) error {
+		c.BaseWeight = 0.5
+		return errors.New("abort")
+	}); err == nil || sr.Config() != before {
+		t.Fatalf("expected a failed update not to be applied: %v", err)
+	}
+}
+
+func TestPickClusterFailsOver(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.AddService(ClusterService("search", "us-east"), []string{"e1", "e2"})
+	sr.AddService(ClusterService("search", "us-west"), []string{"w1"})
+	sr.AddService(ClusterService("search", "eu"), []string{"u1"})
+	sr.SetFailoverPolicy(FailoverPolicy{Local: "us-east", Order: []string{"us-west"}, MinHealth: 0.6})
+
+	svc, _, err := sr.PickCluster("search")
+	if err != nil || svc != "search@us-east" {
+		t.Fatalf("expected local cluster while healthy, got %q %v", svc, err)
+	}
+
+	_ = sr.SetEndpointState("search@us-east", "e1", StateDrained) // local health 0.5 < 0.6
+	svc, ep, err := sr.PickCluster("search")
+	if err != nil || svc != "search@us-west" || ep != "w1" {
+		t.Fatalf("expected spill-over to us-west, got %q %q %v", svc, ep, err)
+	}
+	if name, cluster := ParseClusterService(svc); name != "search" || cluster != "us-west" {
+		t.Fatalf("ParseClusterService(%q) = %q, %q", svc, name, cluster)
+	}
+
+	_ = sr.SetEndpointState("search@us-west", "w1", StateBanned)
+	if svc, _, _ := sr.PickCluster("search"); svc != "search@eu" {
+		t.Fatalf("expected spill-over to the remaining healthy cluster, got %q", svc)
+	}
+	if _, _, err := sr.PickCluster("missing"); err == nil {
+		t.Fatal("expected error for unknown multi-cluster service")
+	}
+}
+
+func TestPickClusterSkipsUnselectableLocalInFallback(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.AddService(ClusterService("search", "local"), []string{"l1"})
+	sr.AddService(ClusterService("search", "remote"), []string{"r1"})
+	sr.SetFailoverPolicy(FailoverPolicy{Local: "local", MinHealth: 0.5})
+	_ = sr.SetEndpointState("search@local", "l1", StateDrained)
+	for range 5 {
+		sr.ReportResult("search@remote", "r1", 0, false) // ejected, still selectable
+	}
+	svc, ep, err := sr.PickCluster("search")
+	if err != nil || svc != "search@remote" || ep != "r1" {
+		t.Fatalf("expected the ejected remote cluster over the drained local one, got %q %q %v", svc, ep, err)
+	}
+	_ = sr.SetEndpointState("search@remote", "r1", StateBanned)
+	if _, _, err := sr.PickCluster("search"); err == nil {
+		t.Fatal("expected an error with no selectable endpoint in any cluster")
+	}
+}
+
+func TestEndpointTTLExpiresStaleEndpoints(t *testing.T) {
+	sr := NewSwarmRoute()
+	clock := time.Unix(3000, 0)
+	sr.now = func() time.Time { return clock }
+	sr.SetEndpointTTL(time.Minute)
+	sr.AddService("api", []string{"A", "B", "C"})
+
+	clock = clock.Add(45 * time.Second)
+	sr.SetEndpoints("api", []string{"A", "B", "C"}) // re-confirms all three
+	sr.AddEndpoint("api", "D")
+
+	clock = clock.Add(30 * time.Second)
+	if n := sr.ExpireStaleEndpoints(); n != 0 {
+		t.Fatalf("expected no expiry within the TTL, dropped %d", n)
+	}
+	sr.ReportResult("api", "B", 0.01, true) // reports keep B alive
+	clock = clock.Add(45 * time.Second)
+	if n := sr.ExpireStaleEndpoints(); n != 3 {
+		t.Fatalf("expected 3 stale endpoints dropped, got %d", n)
+	}
+	if got := sr.EndpointStates("api"); len(got) != 1 || got["B"] != StateActive {
+		t.Fatalf("expected only the reporting endpoint to remain, got %v", got)
+	}
+
+	sr.SetEndpointTTL(0)
+	clock = clock.Add(time.Hour)
+	if n := sr.ExpireStaleEndpoints(); n != 0 {
+		t.Fatalf("expected expiry disabled, dropped %d", n)
+	}
+}
+
+func TestImportedEndpointsExpireOnTheInjectedClock(t *testing.T) {
+	src := NewSwarmRoute()
+	src.SetEndpointTTL(time.Minute)
+	src.AddService("api", []string{"A"})
+
+	sr := NewSwarmRoute()
+	clock := time.Unix(3000, 0)
+	sr.now = func() time.Time { return clock }
+	obs := &recordingObserver{}
+	sr.AddObserver(obs)
+	if err := sr.Import(src.Export()); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(45 * time.Second)
+	sr.evaporateOnce()
+	if _, _, expired := obs.recorded(); len(expired) != 0 {
+		t.Fatalf("expected the import to confirm A at the injected time, got %v", expired)
+	}
+	clock = clock.Add(30 * time.Second)
+	sr.evaporateOnce()
+	if _, _, expired := obs.recorded(); !slices.Equal(expired, []ExpiryEvent{{Service: "api", Endpoint: "A", Idle: 75 * time.Second}}) {
+		t.Fatalf("expected an expiry event for A, got %v", expired)
+	}
+}
+
+func TestMaintenanceWindowDrainsAndSlowStarts(t *testing.T) {
+	sr := NewSwarmRoute()
+	clock := time.Date(2025, 11, 3, 1, 59, 0, 0, time.UTC)
+	sr.now = func() time.Time { return clock }
+	sr.AddService("api", []string{"A", "B"})
+	w := MaintenanceWindow{Start: 2 * time.Hour, Duration: 15 * time.Minute, SlowStart: 10 * time.Minute}
+	if err := sr.AddMaintenanceWindow("api", "A", w); err != nil {
+		t.Fatal(err)
+	}
+	if err := sr.AddMaintenanceWindow("api", "A", MaintenanceWindow{Duration: 25 * time.Hour}); err == nil {
+		t.Fatal("expected invalid window to be rejected")
+	}
+	if err := sr.AddMaintenanceWindow("api", "missing", w); err != ErrUnknownEndpoint {
+		t.Fatalf("expected ErrUnknownEndpoint, got %v", err)
+	}
+	full, _ := sr.Score("api", "A")
+
+	clock = clock.Add(5 * time.Minute) // 02:04, inside the window
+	if !sr.InMaintenance("api", "A") {
+		t.Fatal("expected A to be in maintenance")
+	}
+	for range 50 {
+		if ep, _ := sr.PickEndpoint("api"); ep != "B" {
+			t.Fatalf("picked %s during its maintenance window", ep)
+		}
+	}
+
+	clock = time.Date(2025, 11, 3, 2, 20, 0, 0, time.UTC) // halfway through the slow start
+	if sr.InMaintenance("api", "A") {
+		t.Fatal("expected the window to be over")
+	}
+	if got, _ := sr.Score("api", "A"); math.Abs(got-full/2) > 1e-9 {
+		t.Fatalf("expected half weight during slow start, got %v of %v", got, full)
+	}
+	clock = clock.Add(24 * time.Hour) // same time the next day
+	if got, _ := sr.Score("api", "A"); math.Abs(got-full/2) > 1e-9 {
+		t.Fatalf("expected the window to recur daily, got %v of %v", got, full)
+	}
+	_ = sr.ClearMaintenanceWindows("api", "A")
+	if got, _ := sr.Score("api", "A"); got != full {
+		t.Fatalf("expected full weight after clearing windows, got %v", got)
+	}
+}
+
+func TestMaintenanceWindowFollowsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	w := MaintenanceWindow{Start: 3 * time.Hour, Duration: 15 * time.Minute, Location: loc}
+	for _, tc := range []struct {
+		at     time.Time
+		inside bool
+	}{
+		// Clocks jump from 02:00 to 03:00 and fall back from 02:00 to
+		// 01:00; the window still opens at 03:00 local time.
+		{time.Date(2025, 3, 9, 3, 5, 0, 0, loc), true},
+		{time.Date(2025, 3, 9, 4, 5, 0, 0, loc), false},
+		{time.Date(2025, 11, 2, 2, 5, 0, 0, loc), false},
+		{time.Date(2025, 11, 2, 3, 5, 0, 0, loc), true},
+	} {
+		if in, _ := w.phase(tc.at); in != tc.inside {
+			t.Errorf("at %v: expected inside=%v", tc.at, tc.inside)
+		}
+	}
+}
+
+func TestPickEndpointExcluding(t *testing.T) {
+	sr := NewSwarmRoute()
+	sr.AddService("api", []string{"A", "B", "C"})
+	for range 100 {
+		if ep, err := sr.PickEndpointExcluding("api", "A", "C"); err != nil || ep != "B" {
+			t.Fatalf("expected B, got %q %v", ep, err)
+		}
+	}
+	if ep, err := sr.PickEndpointExcluding("api", "A", "B", "C"); err != nil || ep == "" {
+		t.Fatalf("expected the exclusion to be ignored when nothing remains, got %q %v", ep, err)
+	}
+}
+
+func TestSetSeedMakesPicksReproducible(t *testing.T) {
+	picks := func() []string {
+		sr := NewSwarmRoute()
+		sr.SetSeed(42)
+		sr.SetPeriodicExploration(7, 3)
+		sr.AddService("api", []string{"A", "B", "C"})
+		var out []string
+		for i := range 200 {
+			ep, err := sr.PickEndpoint("api")
+			if err != nil {
+				t.Fatal(err)
+			}
+			sr.ReportResult("api", ep, 0.01*float64(i%5+1), i%9 != 0)
+			out = append(out, ep)
+		}
+		return out
+	}
+	if a, b := picks(), picks(); !slices.Equal(a, b) {
+		t.Fatal("expected identical picks with the same seed")
+	}
+}
+
+func TestAutoTuneBoostsOnLatencyShift(t *testing.T) {
+	sr := NewSwarmRouteWithConfig(DefaultConfig())
+	sr.SetAutoTune(&AutoTunePolicy{})
+	sr.AddService("api", []string{"A", "B"})
+	rng := rand.New(rand.NewSource(1))
+	report := func(n int, latency float64, success bool) {
+		for range n {
+			sr.ReportResult("api", "A", latency*(1+0.1*rng.NormFloat64()), success)
+		}
+	}
+	report(2000, 0.030, true)
+	st := sr.AutoTuneStatus()["api"]
+	if st.Drifts != 0 || st.Boost > 1.01 {
+		t.Fatalf("expected no drift under stationary noise, got %+v", st)
+	}
+	if st.LatencyCV < 0.05 || st.LatencyCV > 0.2 || st.ReqEvapRate >= DefaultConfig().ReqEvapRate {
+		t.Fatalf("expected ~10%% latency noise to lengthen the memory, got %+v", st)
+	}
+
+	// Failures are left to the error pheromone.
+	report(50, 0.030, false)
+	if st := sr.AutoTuneStatus()["api"]; st.Drifts != 0 {
+		t.Fatalf("expected rising errors not to boost, got %+v", st)
+	}
+	report(2000, 0.030, true)
+
+	report(40, 0.120, true)
+	st = sr.AutoTuneStatus()["api"]
+	if st.Drifts == 0 || st.Boost < 7 {
+		t.Fatalf("expected a 4x latency shift to be detected, got %+v", st)
+	}
+	if st.ReqEvapRate < 4*DefaultConfig().ReqEvapRate || st.ExploreEveryN > DefaultConfig().ExploreEveryN/4 {
+		t.Fatalf("expected faster forgetting and more exploration after the shift, got %+v", st)
+	}
+	if sr.Config() != DefaultConfig() {
+		t.Fatal("expected Config to keep the configured values")
+	}
+	drifts := st.Drifts
+	report(20000, 0.120, true)
+	if st := sr.AutoTuneStatus()["api"]; st.Drifts != drifts || st.Boost > 1.1 {
+		t.Fatalf("expected the boost to fade at the new level, got %+v", st)
+	}
+
+	sr.SetAutoTune(nil)
+	if sr.AutoTuneStatus() != nil {
+		t.Fatal("expected no status once disabled")
+	}
+}
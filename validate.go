@@ -0,0 +1,100 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swarmroute
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Validate reports every out-of-range or mutually inconsistent setting in
+// c, joined with errors.Join.  The Set* methods and ApplyConfig silently
+// clamp such values; use Validate or ApplyConfigStrict to surface them.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	fraction := func(name string, v float64) {
+		check(v >= 0 && v <= 1, "%s must be within [0, 1], got %v", name, v)
+	}
+	nonNegative := func(name string, v float64) {
+		check(v >= 0 && !math.IsInf(v, 1), "%s must be a finite value >= 0, got %v", name, v)
+	}
+
+	fraction("evaporation_rate", c.EvaporationRate)
+	nonNegative("pos_reinforce", c.PosReinforce)
+	nonNegative("neg_reinforce", c.NegReinforce)
+	fraction("req_evap_rate", c.ReqEvapRate)
+	nonNegative("base_weight", c.BaseWeight)
+	check(c.ExploreEveryN >= 0, "explore_every_n must be >= 0, got %d", c.ExploreEveryN)
+	nonNegative("explore_neg_threshold", c.ExploreNegThreshold)
+	nonNegative("slow_threshold_sec", c.SlowThresholdSec)
+	fraction("bad_pos_decay", c.BadPosDecay)
+	nonNegative("conn_lifetime_target_sec", c.ConnLifetimeTargetSec)
+	nonNegative("reuse_bonus", c.ReuseBonus)
+	nonNegative("warm_idle_timeout_sec", c.WarmIdleTimeoutSec)
+	nonNegative("endpoint_ttl_sec", c.EndpointTTLSec)
+
+	// Combinations that are individually valid but cannot work together.
+	check(c.EvaporationRate > 0 || c.ReqEvapRate > 0,
+		"evaporation_rate and req_evap_rate are both 0: learned state would never expire")
+	check(c.ReqEvapRate < 0.5,
+		"req_evap_rate %v forgets half of all learned state within 1-2 requests; use ln(2)/half-life-in-requests", c.ReqEvapRate)
+	check(c.BaseWeight > 0 || c.PosReinforce > 0,
+		"base_weight and pos_reinforce are both 0: every endpoint would have zero weight")
+	check(c.NegReinforce > 0 || c.ExploreEveryN == 0,
+		"neg_reinforce is 0 but exploration is enabled: no endpoint can ever be considered unhealthy")
+	check(c.ExploreEveryN == 0 || c.ExploreNegThreshold > 0,
+		"explore_neg_threshold must be > 0 when exploration is enabled, or a single failure excludes an endpoint")
+	check(c.ReuseBonus == 0 || c.WarmIdleTimeoutSec > 0,
+		"reuse_bonus is set but warm_idle_timeout_sec is 0: no endpoint would ever be warm")
+	return errors.Join(errs...)
+}
+
+// ApplyConfigStrict validates c and applies it atomically like ApplyConfig.
+// Nothing is changed if validation fails.
+func (sr *SwarmRoute) ApplyConfigStrict(c Config) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("invalid swarmroute config: %w", err)
+	}
+	sr.ApplyConfig(c)
+	return nil
+}
+
+// UpdateConfig edits the configuration in place: fn is applied to a copy of
+// the current configuration, which is then validated and applied like
+// ApplyConfigStrict, all in a single critical section, so concurrent
+// updates of different fields never undo each other.  fn runs with sr
+// locked and must not call sr's methods.  Nothing is changed if fn or
+// validation fails.  UpdateConfig returns the configuration now in effect.
+func (sr *SwarmRoute) UpdateConfig(fn func(*Config) error) (Config, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	c := sr.configLocked()
+	if err := fn(&c); err != nil {
+		return sr.configLocked(), err
+	}
+	if err := c.Validate(); err != nil {
+		return sr.configLocked(), fmt.Errorf("invalid swarmroute config: %w", err)
+	}
+	sr.setConfigLocked(c)
+	return sr.configLocked(), nil
+}
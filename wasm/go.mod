@@ -0,0 +1,12 @@
+module swarmroute/wasm
+
+go 1.25.0
+
+require (
+	github.com/tetratelabs/wazero v1.12.0
+	swarmroute v0.0.0
+)
+
+require golang.org/x/sys v0.44.0 // indirect
+
+replace swarmroute => ../
@@ -0,0 +1,91 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build wasip1
+
+// Command roundrobin is a round-robin strategy for the SwarmRoute WASM
+// ABI (see package swarmroute/wasm).  Build it with
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o roundrobin.wasm
+package main
+
+import "unsafe"
+
+var (
+	services [][]string
+	next     []int
+	// buffers keeps memory handed out by sr_alloc alive until the
+	// harness has passed it back.
+	buffers [][]byte
+)
+
+func main() {}
+
+//go:wasmexport sr_abi_version
+func abiVersion() int32 { return 1 }
+
+//go:wasmexport sr_name
+func name() uint64 {
+	s := "WasmRoundRobin"
+	return uint64(uintptr(unsafe.Pointer(unsafe.StringData(s))))<<32 | uint64(len(s))
+}
+
+//go:wasmexport sr_alloc
+func alloc(n uint32) uint32 {
+	b := make([]byte, n+1)
+	buffers = append(buffers, b)
+	return uint32(uintptr(unsafe.Pointer(&b[0])))
+}
+
+//go:wasmexport sr_add_service
+func addService(service int32, _, _, eps, epsLen uint32) {
+	for int(service) >= len(services) {
+		services = append(services, nil)
+		next = append(next, 0)
+	}
+	services[service] = split(unsafe.String((*byte)(unsafe.Pointer(uintptr(eps))), epsLen))
+	buffers = nil
+}
+
+//go:wasmexport sr_pick
+func pick(service int32, _, _ uint32) int32 {
+	buffers = nil
+	if int(service) >= len(services) || len(services[service]) == 0 {
+		return -1
+	}
+	i := next[service] % len(services[service])
+	next[service]++
+	return int32(i)
+}
+
+//go:wasmexport sr_report
+func report(int32, int32, float64, int32) {}
+
+func split(s string) []string {
+	var out []string
+	for len(s) > 0 {
+		i := 0
+		for i < len(s) && s[i] != '\n' {
+			i++
+		}
+		out = append(out, s[:i])
+		if i < len(s) {
+			i++
+		}
+		s = s[i:]
+	}
+	return out
+}
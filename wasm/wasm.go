@@ -0,0 +1,333 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wasm runs harness strategies compiled to WebAssembly, so
+// strategy experiments can be shared as a single sandboxed file and tried
+// without rebuilding the harness.  Modules run in wazero with WASI but
+// without filesystem or network access; stderr is passed through for
+// debugging.  It lives in its own module so the core
+// library stays dependency-free.
+//
+// Importing this package registers the "wasm" harness strategy, e.g.
+// -strategies wasm:file=roundrobin.wasm.
+//
+// # ABI
+//
+// A module exports its memory and these functions; strings are UTF-8
+// passed as pointer and length into that memory, and services and
+// endpoints are referred to by index:
+//
+//	sr_abi_version() i32                  must return 1
+//	sr_alloc(len u32) u32                 a buffer the harness fills before passing it
+//	                                      back; it may be freed when that call returns
+//	sr_name() u64                         optional: pointer<<32 | length of the name
+//	sr_add_service(service i32, name u32, name_len u32, endpoints u32, endpoints_len u32)
+//	                                      sets the endpoints, separated by '\n'; it is
+//	                                      called again with the full list when endpoints
+//	                                      join, keeping indexes of known ones
+//	sr_seed(seed i64)                     optional: reseed before every run
+//	sr_pick(service i32, exclude u32, exclude_count u32) i32
+//	                                      the index of the chosen endpoint, or -1 if
+//	                                      there is none; exclude is an array of i32
+//	                                      endpoint indexes to avoid on a retry, to be
+//	                                      ignored if it excludes every endpoint
+//	sr_report(service i32, endpoint i32, latency_sec f64, success i32)
+//	sr_feedback(service i32, endpoint i32, queue_len i32, service_time_sec f64)
+//	                                      optional: server feedback, called right
+//	                                      before sr_report when the endpoint answered
+//
+// Modules that need initialization export it as _initialize, which Go
+// (-buildmode=c-shared with GOOS=wasip1) and Rust cdylib reactors do.
+// testdata/roundrobin is a complete example in Go.
+package wasm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"swarmroute/harness"
+)
+
+// ABIVersion is the version of the ABI a module must implement.
+const ABIVersion = 1
+
+// cache shares compiled code between strategies loaded from the same
+// module, e.g. one per client in multi-client scenarios.
+var cache = wazero.NewCompilationCache()
+
+func init() {
+	harness.Register("wasm", func(p harness.Params) (harness.Strategy, error) {
+		if err := p.Only("file"); err != nil {
+			return nil, err
+		}
+		file, err := p.String("file", "")
+		if err != nil {
+			return nil, err
+		}
+		if file == "" {
+			return nil, fmt.Errorf("parameter file is required")
+		}
+		return LoadFile(file)
+	})
+}
+
+// ModuleStrategy is a harness strategy implemented by a WebAssembly
+// module.  A trap in the module fails every later pick with the first
+// error.
+type ModuleStrategy struct {
+	mu   sync.Mutex
+	name string
+	rt   wazero.Runtime
+	mod  api.Module
+
+	alloc, addService, seed, pick, report, feedback api.Function
+
+	services  map[string]int32
+	endpoints map[string][]string
+	err       error
+}
+
+// LoadFile reads and instantiates a module; without sr_name, the strategy
+// is named after the file.
+func LoadFile(path string) (*ModuleStrategy, error) {
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	s, err := NewModuleStrategy(wasm, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// NewModuleStrategy instantiates a module from its binary.  name is used
+// if the module does not export sr_name.
+func NewModuleStrategy(wasm []byte, name string) (*ModuleStrategy, error) {
+	ctx := context.Background()
+	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCompilationCache(cache))
+	s, err := instantiate(ctx, rt, wasm, name)
+	if err != nil {
+		rt.Close(ctx)
+		return nil, err
+	}
+	return s, nil
+}
+
+func instantiate(ctx context.Context, rt wazero.Runtime, wasm []byte, name string) (*ModuleStrategy, error) {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+		return nil, err
+	}
+	compiled, err := rt.CompileModule(ctx, wasm)
+	if err != nil {
+		return nil, err
+	}
+	mod, err := rt.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().
+		WithName("").WithStderr(os.Stderr).WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, err
+	}
+	s := &ModuleStrategy{
+		name:       name,
+		rt:         rt,
+		mod:        mod,
+		alloc:      mod.ExportedFunction("sr_alloc"),
+		addService: mod.ExportedFunction("sr_add_service"),
+		seed:       mod.ExportedFunction("sr_seed"),
+		pick:       mod.ExportedFunction("sr_pick"),
+		report:     mod.ExportedFunction("sr_report"),
+		feedback:   mod.ExportedFunction("sr_feedback"),
+		services:   make(map[string]int32),
+		endpoints:  make(map[string][]string),
+	}
+	for _, fn := range []string{"sr_alloc", "sr_add_service", "sr_pick", "sr_report"} {
+		if mod.ExportedFunction(fn) == nil {
+			return nil, fmt.Errorf("module does not export %s", fn)
+		}
+	}
+	version := mod.ExportedFunction("sr_abi_version")
+	if version == nil {
+		return nil, errors.New("module does not export sr_abi_version")
+	}
+	res, err := version.Call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v := api.DecodeI32(res[0]); v != ABIVersion {
+		return nil, fmt.Errorf("module implements ABI version %d, want %d", v, ABIVersion)
+	}
+	if fn := mod.ExportedFunction("sr_name"); fn != nil {
+		res, err := fn.Call(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := mod.Memory().Read(uint32(res[0]>>32), uint32(res[0]))
+		if !ok || len(b) == 0 {
+			return nil, errors.New("sr_name returned an invalid string")
+		}
+		s.name = string(b)
+	}
+	return s, nil
+}
+
+func (s *ModuleStrategy) Name() string { return s.name }
+
+func (s *ModuleStrategy) AddService(name string, endpoints []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.services[name]
+	if !ok {
+		id = int32(len(s.services))
+		s.services[name] = id
+	}
+	// Known endpoints keep their index, so joins append.
+	list := slices.Clone(s.endpoints[name])
+	for _, ep := range endpoints {
+		if !slices.Contains(list, ep) {
+			list = append(list, ep)
+		}
+	}
+	s.endpoints[name] = list
+	nameAt, nameLen := s.write([]byte(name))
+	epsAt, epsLen := s.write([]byte(strings.Join(list, "\n")))
+	s.call(s.addService, api.EncodeI32(id), nameAt, nameLen, epsAt, epsLen)
+}
+
+func (s *ModuleStrategy) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seed != nil {
+		s.call(s.seed, api.EncodeI64(seed))
+	}
+}
+
+func (s *ModuleStrategy) PickEndpoint(service string) (string, error) {
+	return s.PickEndpointExcluding(service)
+}
+
+// PickEndpointExcluding asks the module for an endpoint avoiding exclude.
+func (s *ModuleStrategy) PickEndpointExcluding(service string, exclude ...string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.services[service]
+	if !ok {
+		return "", harness.ErrNoEndpoints
+	}
+	list := s.endpoints[service]
+	var excludeAt, excludeCount uint64
+	if len(exclude) > 0 {
+		var b []byte
+		for _, ep := range exclude {
+			if i := slices.Index(list, ep); i >= 0 {
+				b = binary.LittleEndian.AppendUint32(b, uint32(i))
+			}
+		}
+		excludeAt, _ = s.write(b)
+		excludeCount = uint64(len(b) / 4)
+	}
+	res := s.call(s.pick, api.EncodeI32(id), excludeAt, excludeCount)
+	if s.err != nil {
+		return "", s.err
+	}
+	i := api.DecodeI32(res[0])
+	if i < 0 {
+		return "", harness.ErrNoEndpoints
+	}
+	if int(i) >= len(list) {
+		return "", fmt.Errorf("sr_pick returned endpoint %d of %d", i, len(list))
+	}
+	return list[i], nil
+}
+
+func (s *ModuleStrategy) ReportResult(service, endpoint string, latencySec float64, success bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ep, ok := s.lookup(service, endpoint)
+	if !ok {
+		return
+	}
+	var ok32 uint64
+	if success {
+		ok32 = 1
+	}
+	s.call(s.report, id, ep, api.EncodeF64(latencySec), ok32)
+}
+
+func (s *ModuleStrategy) ReportFeedback(service, endpoint string, fb harness.ServerFeedback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ep, ok := s.lookup(service, endpoint)
+	if !ok || s.feedback == nil {
+		return
+	}
+	s.call(s.feedback, id, ep, api.EncodeI32(int32(fb.QueueLen)), api.EncodeF64(fb.ServiceTimeSec))
+}
+
+// Close releases the module.
+func (s *ModuleStrategy) Close() error {
+	return s.rt.Close(context.Background())
+}
+
+// lookup returns the encoded indexes of a service and endpoint.
+func (s *ModuleStrategy) lookup(service, endpoint string) (id, ep uint64, ok bool) {
+	sid, ok := s.services[service]
+	if !ok {
+		return 0, 0, false
+	}
+	i := slices.Index(s.endpoints[service], endpoint)
+	if i < 0 {
+		return 0, 0, false
+	}
+	return api.EncodeI32(sid), api.EncodeI32(int32(i)), true
+}
+
+// write copies b into a buffer from sr_alloc and returns its pointer and
+// length.
+func (s *ModuleStrategy) write(b []byte) (ptr, n uint64) {
+	res := s.call(s.alloc, uint64(len(b)))
+	if s.err != nil {
+		return 0, 0
+	}
+	if !s.mod.Memory().Write(uint32(res[0]), b) {
+		s.err = fmt.Errorf("sr_alloc returned %d, outside memory", uint32(res[0]))
+		return 0, 0
+	}
+	return res[0], uint64(len(b))
+}
+
+// call calls fn unless an earlier call failed, recording the failure.
+func (s *ModuleStrategy) call(fn api.Function, params ...uint64) []uint64 {
+	if s.err != nil {
+		return nil
+	}
+	res, err := fn.Call(context.Background(), params...)
+	if err != nil {
+		s.err = fmt.Errorf("strategy module %s: %w", s.name, err)
+	}
+	return res
+}
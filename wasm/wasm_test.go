@@ -0,0 +1,77 @@
+// Copyright 2025 Esteban Alvarez. All Rights Reserved.
+//
+// Created: November 2025
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"swarmroute/harness"
+)
+
+// buildExample compiles testdata/roundrobin with the go command.
+func buildExample(t *testing.T) string {
+	t.Helper()
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	out := filepath.Join(t.TempDir(), "roundrobin.wasm")
+	cmd := exec.Command(goCmd, "build", "-buildmode=c-shared", "-o", out, ".")
+	cmd.Dir = filepath.Join("testdata", "roundrobin")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if b, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building the example: %v\n%s", err, b)
+	}
+	return out
+}
+
+func TestModuleStrategy(t *testing.T) {
+	s, err := harness.NewStrategy("wasm:file=" + buildExample(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := harness.Scenario{
+		Service:       "svc",
+		Endpoints:     []harness.EndpointSpec{{Addr: "a", MeanLatencySec: 0.02, ErrorRate: 0.1}, {Addr: "b", MeanLatencySec: 0.03}},
+		TotalRequests: 500,
+		Retry:         &harness.RetryPolicy{MaxRetries: 1},
+		Seed:          5,
+	}
+	got, want := harness.RunScenario(sc, s), harness.RunScenario(sc, harness.NewRoundRobinStrategy())
+	if got.Strategy != "WasmRoundRobin" || got.Success != want.Success || !maps.Equal(got.Selection, want.Selection) {
+		t.Fatalf("expected the module to route like round robin, got %+v want %+v", got, want)
+	}
+	if _, err := s.PickEndpoint("nope"); err != harness.ErrNoEndpoints {
+		t.Fatalf("expected ErrNoEndpoints for an unknown service, got %v", err)
+	}
+	if err := s.(*ModuleStrategy).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PickEndpoint("svc"); err == nil {
+		t.Fatal("expected a pick after Close to fail")
+	}
+	if _, err := NewModuleStrategy([]byte("\x00asm\x01\x00\x00\x00"), "empty"); err == nil {
+		t.Fatal("expected a module without the ABI to fail")
+	}
+	if _, err := harness.NewStrategy("wasm"); err == nil {
+		t.Fatal("expected wasm without file to fail")
+	}
+}